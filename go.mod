@@ -3,6 +3,7 @@ module github.com/iotaledger/wasp
 go 1.13
 
 require (
+	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/iotaledger/goshimmer v0.2.4-0.20200912082255-f9271bb65bc2
 	github.com/iotaledger/hive.go v0.0.0-20200824153656-adfc839cc240
 	github.com/labstack/echo v3.3.10+incompatible
@@ -15,6 +16,7 @@ require (
 	github.com/stretchr/testify v1.6.1
 	github.com/urfave/cli/v2 v2.2.0
 	go.dedis.ch/kyber/v3 v3.0.12
+	go.dedis.ch/protobuf v1.0.11
 	go.nanomsg.org/mangos/v3 v3.0.1
 	go.uber.org/atomic v1.6.0
 	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899
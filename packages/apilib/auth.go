@@ -0,0 +1,89 @@
+package apilib
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/util/tlsutil"
+)
+
+// Wasp nodes with token authentication enabled require the API token with the role granting access to
+// the endpoint. The token set by SetAuthToken is sent with all calls to Wasp nodes
+
+var (
+	authToken      string
+	authTokenMutex = &sync.RWMutex{}
+)
+
+// httpClient is used for all calls to the web API of Wasp nodes
+var httpClient = &http.Client{Transport: &tokenTransport{}}
+
+// transport of httpClient with the TLS configuration set by SetTLSConfig
+var (
+	transport      = http.DefaultTransport
+	transportMutex = &sync.RWMutex{}
+)
+
+// SetAuthToken sets the API token sent with calls to Wasp nodes. Empty token means no token is sent
+func SetAuthToken(token string) {
+	authTokenMutex.Lock()
+	defer authTokenMutex.Unlock()
+	authToken = token
+}
+
+func getAuthToken() string {
+	authTokenMutex.RLock()
+	defer authTokenMutex.RUnlock()
+	return authToken
+}
+
+// SetTLSConfig sets the TLS configuration of calls to hosts with the https:// scheme:
+// CAs verifying certificates of nodes from caFile (system CAs if empty) and the client certificate
+// required by admin endpoints of nodes from certFile and keyFile (none if empty)
+func SetTLSConfig(caFile, certFile, keyFile string) error {
+	cfg, err := tlsutil.ClientConfig(caFile, certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	setTransportTLS(cfg)
+	return nil
+}
+
+func setTransportTLS(cfg *tls.Config) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+
+	transportMutex.Lock()
+	defer transportMutex.Unlock()
+	transport = t
+}
+
+func getTransport() http.RoundTripper {
+	transportMutex.RLock()
+	defer transportMutex.RUnlock()
+	return transport
+}
+
+// baseURL returns the URL of the web API of the host. The host is host:port for plain HTTP or
+// the URL with the scheme, e.g. https://host:port
+func baseURL(host string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return strings.TrimSuffix(host, "/")
+	}
+	return "http://" + host
+}
+
+type tokenTransport struct{}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := getAuthToken()
+	if token == "" {
+		return getTransport().RoundTrip(req)
+	}
+	// the request must not be modified by the transport
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return getTransport().RoundTrip(req)
+}
@@ -0,0 +1,30 @@
+package apilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
+)
+
+// BrowseSCState runs the query on the solid state of the smart contract with the schema of its state.
+// See stateschema.ParseQuery for the syntax of the query
+func BrowseSCState(host string, scAddr *address.Address, query string) (*stateapi.BrowseResponse, error) {
+	data, err := json.Marshal(&stateapi.BrowseRequest{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s/sc/%s/state/browse", baseURL(host), v1.Prefix, scAddr.String())
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	var result stateapi.BrowseResponse
+	if err = decodeV1Response(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -139,6 +139,9 @@ func consistentPublicKeyInfo(pki1, pki2 *dkgapi.GetPubKeyInfoResponse) bool {
 	if pki1.PubKeyMaster != pki2.PubKeyMaster {
 		return false
 	}
+	if pki1.Scheme != pki2.Scheme {
+		return false
+	}
 	if pki1.N != pki2.N {
 		return false
 	}
@@ -158,6 +161,7 @@ func consistentPublicKeyInfo(pki1, pki2 *dkgapi.GetPubKeyInfoResponse) bool {
 
 func publicKeyInfoToString(pki *dkgapi.GetPubKeyInfoResponse) string {
 	ret := fmt.Sprintf("    Master public key: %s\n", pki.PubKeyMaster)
+	ret += fmt.Sprintf("    Scheme: %s\n", pki.Scheme)
 	ret += fmt.Sprintf("    N: %d\n", pki.N)
 	ret += fmt.Sprintf("    T: %d\n", pki.T)
 	ret += fmt.Sprintf("    Public keys: %+v\n", pki.PubKeys)
@@ -182,6 +186,14 @@ func consistentBootupRecords(bd1, bd2 *registry.BootupData) bool {
 			return false
 		}
 	}
+	if len(bd1.CommitteePubKeys) != len(bd2.CommitteePubKeys) {
+		return false
+	}
+	for i := range bd1.CommitteePubKeys {
+		if bd1.CommitteePubKeys[i] != bd2.CommitteePubKeys[i] {
+			return false
+		}
+	}
 	// access nodes can be any, do not check
 	return true
 }
@@ -25,6 +25,7 @@ type CreateSCParams struct {
 	CommitteeApiHosts     []string
 	CommitteePeeringHosts []string
 	AccessNodes           []string
+	AccessApiHosts        []string // web API hosts of access nodes, same order as AccessNodes
 	N                     uint16
 	T                     uint16
 	OwnerSigScheme        signaturescheme.SignatureScheme
@@ -60,8 +61,8 @@ func ActivateSC(host string, addr *address.Address) error {
 
 func postScRequest(host string, addr *address.Address, request string) error {
 	addrStr := addr.String()
-	url := fmt.Sprintf("http://%s/adm/sc/%s/%s", host, addrStr, request)
-	resp, err := http.Post(url, "application/json", nil)
+	url := fmt.Sprintf("%s/adm/sc/%s/%s", baseURL(host), addrStr, request)
+	resp, err := httpClient.Post(url, "application/json", nil)
 	if err != nil {
 		return err
 	}
@@ -174,8 +175,22 @@ func CreateSC(par CreateSCParams) (*address.Address, *balance.Color, error) {
 	fmt.Fprintf(textout, "checking program metadata: OK. VMType: '%s', description: '%s'\n",
 		md.VMType, md.Description)
 
+	// nodes are identified by the identity keys. Collect them from nodes
+	var accessPubKeys []string
+	committeePubKeys, err := GetNodePubKeys(par.CommitteeApiHosts, par.CommitteePeeringHosts)
+	if err == nil {
+		accessPubKeys, err = GetNodePubKeys(par.AccessApiHosts, par.AccessNodes)
+	}
+	fmt.Fprint(textout, par.Prefix)
+	if err != nil {
+		fmt.Fprintf(textout, "collecting identities of Wasp nodes.. FAILED: %v\n", err)
+		return nil, nil, err
+	} else {
+		fmt.Fprint(textout, "collecting identities of Wasp nodes.. OK.\n")
+	}
+
 	// generate distributed key set on committee nodes
-	scAddr, err := GenerateNewDistributedKeySet(par.CommitteeApiHosts, par.N, par.T)
+	scAddr, err := RunDKG(par.CommitteeApiHosts[0], par.CommitteePeeringHosts, committeePubKeys, par.T)
 
 	fmt.Fprint(textout, par.Prefix)
 	if err != nil {
@@ -222,12 +237,17 @@ func CreateSC(par CreateSCParams) (*address.Address, *balance.Color, error) {
 		fmt.Fprintf(textout, "posting origin transaction.. OK. Origin txid = %s\n", originTx.ID().String())
 	}
 
-	succ, errs := PutSCDataMulti(par.CommitteeApiHosts, registry.BootupData{
-		Address:        *scAddr,
-		OwnerAddress:   ownerAddr,
-		Color:          (balance.Color)(originTx.ID()),
-		CommitteeNodes: par.CommitteePeeringHosts,
-		AccessNodes:    par.AccessNodes,
+	apiHosts := make([]string, 0, len(par.CommitteeApiHosts)+len(par.AccessApiHosts))
+	apiHosts = append(apiHosts, par.CommitteeApiHosts...)
+	apiHosts = append(apiHosts, par.AccessApiHosts...)
+	succ, errs := PutSCDataMulti(apiHosts, registry.BootupData{
+		Address:          *scAddr,
+		OwnerAddress:     ownerAddr,
+		Color:            (balance.Color)(originTx.ID()),
+		CommitteeNodes:   par.CommitteePeeringHosts,
+		AccessNodes:      par.AccessNodes,
+		CommitteePubKeys: committeePubKeys,
+		AccessPubKeys:    accessPubKeys,
 	})
 
 	fmt.Fprint(textout, par.Prefix)
@@ -238,7 +258,6 @@ func CreateSC(par CreateSCParams) (*address.Address, *balance.Color, error) {
 	} else {
 		fmt.Fprint(textout, "sending smart contract metadata to Wasp nodes.. OK.\n")
 	}
-	// TODO not finished with access nodes
 
 	scColor := (balance.Color)(originTx.ID())
 	fmt.Fprint(textout, par.Prefix)
@@ -2,191 +2,198 @@ package apilib
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+
 	"github.com/iotaledger/wasp/packages/util"
-	"github.com/iotaledger/wasp/packages/util/multicall"
-	"math/rand"
-	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
 	"github.com/pkg/errors"
 )
 
-// GenerateNewDistributedKeySetOld calls nodes one after the other to produce distributed key set
-func GenerateNewDistributedKeySetOld(nodes []string, n, t uint16) (*address.Address, error) {
-	if len(nodes) != int(n) {
-		return nil, errors.New("wrong params")
+// GenerateNewDistributedKeySet runs DKG among nodes to produce distributed key set.
+// The first node initiates the DKG, the rest of them participate through peering
+func GenerateNewDistributedKeySet(apiHosts, peeringHosts []string, t uint16) (*address.Address, error) {
+	if len(apiHosts) == 0 {
+		return nil, errors.New("no hosts")
+	}
+	if err := tcrypto.ValidateDKSParams(t, uint16(len(apiHosts)), 0); err != nil {
+		return nil, err
+	}
+	if util.ContainsDuplicates(peeringHosts) {
+		return nil, fmt.Errorf("duplicate hosts")
+	}
+	pubKeys, err := GetNodePubKeys(apiHosts, peeringHosts)
+	if err != nil {
+		return nil, err
+	}
+	if err := IntroducePeers(apiHosts, peeringHosts, pubKeys); err != nil {
+		return nil, err
+	}
+	return RunDKG(apiHosts[0], peeringHosts, pubKeys, t)
+}
+
+// RunDKG asks the node to initiate DKG among participants with known identities.
+// The node must be one of participants
+func RunDKG(apiHost string, peeringHosts, pubKeys []string, t uint16) (*address.Address, error) {
+	addr, err := callRunDKG(apiHost, dkgapi.RunDKGRequest{
+		PeeringHosts: peeringHosts,
+		PubKeys:      pubKeys,
+		T:            t,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if addr.Version() != address.VersionBLS {
+		return nil, errors.New("DKG returned non-BLS address")
+	}
+	return addr, nil
+}
+
+// GenerateNewMultiSigKeySet forms multi-signature committee of nodes with quorum t: each node generates its own key
+// and deals shares of it to all members, the address is made from the aggregated public key.
+// Nodes are members in the order of apiHosts. No DKG is run among nodes, shares are passed through the API sealed for
+// seal keys of members. A member which receives a bad share fails, naming the dealer
+func GenerateNewMultiSigKeySet(apiHosts []string, t uint16) (*address.Address, error) {
+	if len(apiHosts) < 2 {
+		return nil, errors.New("at least 2 hosts are needed")
+	}
+	if util.ContainsDuplicates(apiHosts) {
+		return nil, fmt.Errorf("duplicate hosts")
 	}
-	if err := tcrypto.ValidateDKSParams(t, n, 0); err != nil {
+	if err := tcrypto.ValidateDKSParams(t, uint16(len(apiHosts)), 0); err != nil {
 		return nil, err
 	}
-	// temporary numeric id during DKG
-	params := dkgapi.NewDKSRequest{
-		TmpId: rand.Int(),
-		N:     n,
-		T:     t,
-	}
-	// generate new key shares
-	// results in the matrix
-	priSharesMatrix := make([][]string, params.N)
-	for i, host := range nodes {
-		par := params
-		par.Index = uint16(i)
-		resp, err := callNewKey(host, par)
+	pubKeys := make([]string, len(apiHosts))
+	sealKeys := make([]string, len(apiHosts))
+	for i, host := range apiHosts {
+		key, err := callNewMultiSigKey(host)
 		if err != nil {
-			return nil, err
-		}
-		if len(resp.PriShares) != int(params.N) {
-			return nil, errors.New("apilib: len(resp.PriShares) != int(params.N)")
+			return nil, fmt.Errorf("%s: %v", host, err)
 		}
-		priSharesMatrix[i] = resp.PriShares
+		pubKeys[i], sealKeys[i] = key.PubKey, key.SealKey
 	}
-
-	// aggregate private shares
-	pubShares := make([]string, params.N)
-	priSharesCol := make([]string, params.N)
-	for col, host := range nodes {
-		for row := range nodes {
-			priSharesCol[row] = priSharesMatrix[row][col]
-		}
-		resp, err := callAggregate(host, dkgapi.AggregateDKSRequest{
-			TmpId:     params.TmpId,
-			Index:     uint16(col),
-			PriShares: priSharesCol,
+	deals := make([]*dkgapi.DealMultiSigResponse, len(apiHosts))
+	for i, host := range apiHosts {
+		var err error
+		deals[i], err = callDealMultiSig(host, dkgapi.DealMultiSigRequest{
+			PubKeys:  pubKeys,
+			SealKeys: sealKeys,
+			T:        t,
 		})
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s: %v", host, err)
+		}
+		if len(deals[i].Shares) != len(apiHosts) {
+			return nil, fmt.Errorf("%s: wrong number of shares in the deal", host)
 		}
-		pubShares[col] = resp.PubShare
 	}
-
-	// commit keys
-	var addrRet *address.Address
-	for _, host := range nodes {
-		addr, err := callCommit(host, dkgapi.CommitDKSRequest{
-			TmpId:     params.TmpId,
-			PubShares: pubShares,
+	commits := make([][]string, len(apiHosts))
+	for i := range deals {
+		commits[i] = deals[i].Commits
+	}
+	var ret *address.Address
+	for i, host := range apiHosts {
+		shares := make([]string, len(apiHosts))
+		for j := range deals {
+			shares[j] = deals[j].Shares[i]
+		}
+		resp, err := callCommitMultiSig(host, dkgapi.CommitMultiSigRequest{
+			PubKeys: pubKeys,
+			T:       t,
+			Commits: commits,
+			Shares:  shares,
 		})
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s: %v", host, err)
+		}
+		if int(resp.Index) != i {
+			return nil, fmt.Errorf("%s: wrong index of the member %d, expected %d", host, resp.Index, i)
 		}
-		if addrRet != nil && !bytes.Equal(addrRet.Bytes(), addr.Bytes()) {
-			return nil, errors.New("key commit returned different addresses from different nodes")
+		addr, err := address.FromBase58(resp.Address)
+		if err != nil {
+			return nil, err
 		}
-		if addr.Version() != address.VersionBLS {
-			return nil, errors.New("key commit returned non-BLS address")
+		if ret != nil && addr != *ret {
+			return nil, fmt.Errorf("%s: inconsistent address %s, expected %s", host, addr.String(), ret.String())
 		}
-		addrRet = addr
+		ret = &addr
 	}
-	return addrRet, nil
+	return ret, nil
 }
 
-// GenerateNewDistributedKeySet calls nodes in parallel to produce distributed key set
-func GenerateNewDistributedKeySet(hosts []string, n, t uint16) (*address.Address, error) {
-	if len(hosts) != int(n) {
-		return nil, errors.New("wrong params")
+// ReshareKeySet reshares the key set of the smart contract to new holders of shares.
+// committeeApiHosts are all members of the current committee: the resharing is authorized on each of them and
+// initiated by the first one. New holders may be any nodes, including members of the current committee.
+// After resharing, bootup data of the smart contract is copied from the initiator
+// to new holders which don't have it yet. The smart contract is activated on them if it is active on the initiator
+func ReshareKeySet(committeeApiHosts []string, addr *address.Address, apiHosts, peeringHosts []string, t uint16) error {
+	if len(committeeApiHosts) == 0 {
+		return fmt.Errorf("no committee hosts")
 	}
-	if err := tcrypto.ValidateDKSParams(t, n, 0); err != nil {
-		return nil, err
+	initiatorApiHost := committeeApiHosts[0]
+	if err := tcrypto.ValidateDKSParams(t, uint16(len(apiHosts)), 0); err != nil {
+		return err
 	}
-
-	if util.ContainsDuplicates(hosts) {
-		return nil, fmt.Errorf("duplicate hosts")
+	if util.ContainsDuplicates(peeringHosts) {
+		return fmt.Errorf("duplicate hosts")
 	}
-	// temporary numeric id during DKG
-	// generate new key shares
-	// results in the matrix
-	tmpId := rand.Int()
-
-	funs := make([]func() error, len(hosts))
-	priSharesMatrix := make([][]string, n)
-	for i, host := range hosts {
-		h := host
-		idx := i
-		funs[i] = func() error {
-			resp, err := callNewKey(h, dkgapi.NewDKSRequest{
-				TmpId: tmpId,
-				N:     n,
-				T:     t,
-				Index: uint16(idx),
-			})
-			if err != nil {
-				return err
-			}
-			if len(resp.PriShares) != int(n) {
-				return errors.New("inconsistency: len(resp.PriShares) != int(params.N)")
-			}
-			priSharesMatrix[idx] = resp.PriShares
-			return nil
-		}
+	pubKeys, err := GetNodePubKeys(apiHosts, peeringHosts)
+	if err != nil {
+		return err
 	}
-	succ, errs := multicall.MultiCall(funs, 2*time.Second)
-	if !succ {
-		return nil, multicall.WrapErrors(errs)
+	if err := introduceReshareParticipants(committeeApiHosts, apiHosts, peeringHosts, pubKeys); err != nil {
+		return err
 	}
-
-	// aggregate private shares
-	pubShares := make([]string, n)
-
-	for col, host := range hosts {
-		priSharesCol := make([]string, n)
-		for row := range hosts {
-			priSharesCol[row] = priSharesMatrix[row][col]
-		}
-		h := host
-		c := col
-		funs[col] = func() error {
-			resp, err := callAggregate(h, dkgapi.AggregateDKSRequest{
-				TmpId:     tmpId,
-				Index:     uint16(c),
-				PriShares: priSharesCol,
-			})
-			if err != nil {
-				return err
-			}
-			pubShares[c] = resp.PubShare
-			return nil
+	for _, host := range committeeApiHosts[1:] {
+		if err := AuthorizeReshare(host, addr, pubKeys, t); err != nil {
+			return fmt.Errorf("%s: %v", host, err)
 		}
 	}
-	succ, errs = multicall.MultiCall(funs, 2*time.Second)
-	if !succ {
-		return nil, multicall.WrapErrors(errs)
+	err = callReshare(initiatorApiHost, dkgapi.ReshareRequest{
+		Address:      addr.String(),
+		PeeringHosts: peeringHosts,
+		PubKeys:      pubKeys,
+		T:            t,
+	})
+	if err != nil {
+		return err
 	}
-
-	// commit keys
-	var addrRet *address.Address
-	for i, host := range hosts {
-		h := host
-		funs[i] = func() error {
-			addr, err := callCommit(h, dkgapi.CommitDKSRequest{
-				TmpId:     tmpId,
-				PubShares: pubShares,
-			})
-			if err != nil {
-				return err
-			}
-			if addrRet != nil && *addrRet != *addr {
-				return errors.New("key commit returned different addresses from different nodes")
-			}
-			if addr.Version() != address.VersionBLS {
-				return errors.New("key commit returned non-BLS address")
+	bd, exists, err := GetSCData(initiatorApiHost, addr)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bootup data of %s not found on %s", addr.String(), initiatorApiHost)
+	}
+	for _, host := range apiHosts {
+		_, exists, err := GetSCData(host, addr)
+		if err != nil {
+			return fmt.Errorf("%s: %v", host, err)
+		}
+		if exists {
+			continue
+		}
+		if err := PutSCData(host, *bd); err != nil {
+			return fmt.Errorf("%s: %v", host, err)
+		}
+		if bd.Active {
+			if err := ActivateSC(host, addr); err != nil {
+				return fmt.Errorf("%s: %v", host, err)
 			}
-			addrRet = addr
-			return nil
 		}
 	}
-	succ, errs = multicall.MultiCall(funs, 2*time.Second)
-	if !succ {
-		return nil, multicall.WrapErrors(errs)
-	}
-	return addrRet, nil
+	return nil
 }
 
-func ExportDKShare(node string, address *address.Address) (string, error) {
+// ExportDKShare exports the key share from the node, sealed for the node with the identity public key recipientPubKey
+func ExportDKShare(node string, address *address.Address, recipientPubKey string) (string, error) {
 	return callExportDKShare(node, dkgapi.ExportDKShareRequest{
-		Address: address.String(),
+		Address:         address.String(),
+		RecipientPubKey: recipientPubKey,
 	})
 }
 
@@ -195,3 +202,42 @@ func ImportDKShare(node string, base58blob string) error {
 		Blob: base58blob,
 	})
 }
+
+// introduceReshareParticipants introduces members of the current committee and new holders to each other
+func introduceReshareParticipants(committeeApiHosts, apiHosts, peeringHosts, pubKeys []string) error {
+	allApiHosts := append([]string{}, apiHosts...)
+	allPeeringHosts := append([]string{}, peeringHosts...)
+	allPubKeys := append([]string{}, pubKeys...)
+	for _, host := range committeeApiHosts {
+		netid, pubKey, err := GetNodeIdentity(host)
+		if err != nil {
+			return fmt.Errorf("%s: %v", host, err)
+		}
+		known := false
+		for _, pk := range allPubKeys {
+			known = known || pk == pubKey
+		}
+		if known {
+			continue
+		}
+		allApiHosts = append(allApiHosts, host)
+		allPeeringHosts = append(allPeeringHosts, netid)
+		allPubKeys = append(allPubKeys, pubKey)
+	}
+	return IntroducePeers(allApiHosts, allPeeringHosts, allPubKeys)
+}
+
+// AuthorizeReshare allows the member of the committee to deal its key share in the resharing of the key set
+// to new holders with identity public keys pubKeys and quorum t, initiated by another member
+func AuthorizeReshare(host string, addr *address.Address, pubKeys []string, t uint16) error {
+	data, err := json.Marshal(&v1.ReshareAuthorization{PubKeys: pubKeys, T: t})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s%s/dkshares/%s/reshare/authorize", baseURL(host), v1.Prefix, addr.String())
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	return decodeV1Response(resp, nil)
+}
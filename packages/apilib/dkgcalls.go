@@ -8,74 +8,36 @@ import (
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/pkg/errors"
 )
 
-func callNewKey(netLoc string, params dkgapi.NewDKSRequest) (*dkgapi.NewDKSResponse, error) {
+func callRunDKG(netLoc string, params dkgapi.RunDKGRequest) (*address.Address, error) {
 	data, err := json.Marshal(params)
 	if err != nil {
 		return nil, err
 	}
-	url := fmt.Sprintf("http://%s/adm/newdks", netLoc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/rundkg", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
-	result := &dkgapi.NewDKSResponse{}
+	result := &dkgapi.RunDKGResponse{}
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
-	if result.Err == "" {
-		return result, nil
+	if result.Err != "" {
+		return nil, errors.New(result.Err)
 	}
-	return nil, errors.New(result.Err)
-}
-
-func callAggregate(netLoc string, params dkgapi.AggregateDKSRequest) (*dkgapi.AggregateDKSResponse, error) {
-	data, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	url := fmt.Sprintf("http://%s/adm/aggregatedks", netLoc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	result := &dkgapi.AggregateDKSResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	if err != nil {
-		return nil, err
-	}
-	if result.Err == "" {
-		return result, nil
-	}
-	return nil, errors.New(result.Err)
-}
-
-func callCommit(netloc string, params dkgapi.CommitDKSRequest) (*address.Address, error) {
-	data, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	url := fmt.Sprintf("http://%s/adm/commitdks", netloc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned code %d", url, resp.StatusCode)
 	}
-	result := &dkgapi.CommitDKSResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	addrRet, err := address.FromBase58(result.Address)
 	if err != nil {
 		return nil, err
 	}
-	if result.Err == "" {
-		addrRet, err := address.FromBase58(result.Address)
-		if err != nil {
-			return nil, err
-		}
-		return &addrRet, nil
-	}
-	return nil, errors.New(result.Err)
+	return &addrRet, nil
 }
 
 func callGetPubKeyInfo(netLoc string, params dkgapi.GetPubKeyInfoRequest) *dkgapi.GetPubKeyInfoResponse {
@@ -83,8 +45,8 @@ func callGetPubKeyInfo(netLoc string, params dkgapi.GetPubKeyInfoRequest) *dkgap
 	if err != nil {
 		return &dkgapi.GetPubKeyInfoResponse{Err: err.Error()}
 	}
-	url := fmt.Sprintf("http://%s/adm/getpubkeyinfo", netLoc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/getpubkeyinfo", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return &dkgapi.GetPubKeyInfoResponse{Err: err.Error()}
 	}
@@ -101,8 +63,8 @@ func callExportDKShare(netLoc string, params dkgapi.ExportDKShareRequest) (strin
 	if err != nil {
 		return "", err
 	}
-	url := fmt.Sprintf("http://%s/adm/exportdkshare", netLoc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/exportdkshare", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return "", err
 	}
@@ -119,8 +81,8 @@ func callImportDKShare(netLoc string, params dkgapi.ImportDKShareRequest) error
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("http://%s/adm/importdkshare", netLoc)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/importdkshare", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
@@ -131,3 +93,95 @@ func callImportDKShare(netLoc string, params dkgapi.ImportDKShareRequest) error
 	}
 	return err
 }
+
+func callReshare(netLoc string, params dkgapi.ReshareRequest) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/adm/reshare", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	result := &misc.SimpleResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned code %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func callNewMultiSigKey(netLoc string) (*dkgapi.NewMultiSigKeyResponse, error) {
+	url := fmt.Sprintf("%s/adm/multisig/newkey", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &dkgapi.NewMultiSigKeyResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != "" {
+		return nil, errors.New(result.Err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned code %d", url, resp.StatusCode)
+	}
+	return result, nil
+}
+
+func callDealMultiSig(netLoc string, params dkgapi.DealMultiSigRequest) (*dkgapi.DealMultiSigResponse, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/adm/multisig/deal", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	result := &dkgapi.DealMultiSigResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != "" {
+		return nil, errors.New(result.Err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned code %d", url, resp.StatusCode)
+	}
+	return result, nil
+}
+
+func callCommitMultiSig(netLoc string, params dkgapi.CommitMultiSigRequest) (*dkgapi.CommitMultiSigResponse, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/adm/multisig/commit", baseURL(netLoc))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	result := &dkgapi.CommitMultiSigResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != "" {
+		return nil, errors.New(result.Err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned code %d", url, resp.StatusCode)
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package apilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/util/multicall"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
+)
+
+// how often readiness of nodes is checked while waiting
+const readyCheckPeriod = 200 * time.Millisecond
+
+// GetNodeInfo returns the version, the identity, the connection to Goshimmer and readiness of the node
+func GetNodeInfo(host string) (*admapi.NodeInfo, error) {
+	resp, err := httpClient.Get(fmt.Sprintf("%s%s/node/info", baseURL(host), v1.Prefix))
+	if err != nil {
+		return nil, err
+	}
+	var result admapi.NodeInfo
+	if err = decodeV1Response(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CheckNodeReady returns nil if the node is ready, otherwise the error with reasons
+func CheckNodeReady(host string) error {
+	resp, err := httpClient.Get(fmt.Sprintf("%s/health/ready", baseURL(host)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var status admapi.HealthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return fmt.Errorf("health check returned code %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", status.Status, strings.Join(status.Problems, "; "))
+	}
+	return nil
+}
+
+// WaitUntilNodesReady waits until all nodes are ready. Returns the last error of each node which is not
+// ready after the timeout
+func WaitUntilNodesReady(hosts []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	funs := make([]func() error, len(hosts))
+	for i := range hosts {
+		host := hosts[i]
+		funs[i] = func() error {
+			for {
+				err := CheckNodeReady(host)
+				if err == nil || time.Now().After(deadline) {
+					return err
+				}
+				time.Sleep(readyCheckPeriod)
+			}
+		}
+	}
+	succ, errs := multicall.MultiCall(funs, timeout+readyCheckPeriod)
+	if succ {
+		return nil
+	}
+	problems := make([]string, 0)
+	for i, err := range errs {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", hosts[i], err))
+		}
+	}
+	return fmt.Errorf("nodes are not ready: %s", strings.Join(problems, ", "))
+}
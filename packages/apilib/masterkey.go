@@ -0,0 +1,37 @@
+package apilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+)
+
+// RotateMasterKey calls the node to re-encrypt its secrets with the new master key.
+// The new key is derived from passphrase or, if it is empty, generated to keyFile on the node host
+func RotateMasterKey(host string, passphrase string, keyFile string) error {
+	data, err := json.Marshal(&admapi.RotateMasterKeyRequest{
+		Passphrase: passphrase,
+		KeyFile:    keyFile,
+	})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/adm/rotatemasterkey", baseURL(host))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	var respbody misc.SimpleResponse
+	err = json.NewDecoder(resp.Body).Decode(&respbody)
+	if err != nil {
+		return fmt.Errorf("response status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || respbody.Error != "" {
+		return fmt.Errorf("response status %d: %s", resp.StatusCode, respbody.Error)
+	}
+	return nil
+}
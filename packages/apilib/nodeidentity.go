@@ -0,0 +1,51 @@
+package apilib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+)
+
+// GetNodeIdentity calls node to get its network id and base58 encoded identity public key
+func GetNodeIdentity(host string) (string, string, error) {
+	url := fmt.Sprintf("%s/adm/nodeidentity", baseURL(host))
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", "", err
+	}
+	var dresp admapi.NodeIdentityResponse
+	err = json.NewDecoder(resp.Body).Decode(&dresp)
+	if err != nil {
+		return "", "", err
+	}
+	if dresp.Error != "" {
+		return "", "", errors.New(dresp.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	return dresp.NetID, dresp.PubKey, nil
+}
+
+// GetNodePubKeys collects identity public keys of nodes by their web API hosts.
+// Network id of each node must be equal to the corresponding peering host
+func GetNodePubKeys(apiHosts []string, peeringHosts []string) ([]string, error) {
+	if len(apiHosts) != len(peeringHosts) {
+		return nil, fmt.Errorf("number of API hosts and peering hosts must be equal")
+	}
+	ret := make([]string, len(apiHosts))
+	for i, host := range apiHosts {
+		netid, pubKey, err := GetNodeIdentity(host)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", host, err)
+		}
+		if netid != peeringHosts[i] {
+			return nil, fmt.Errorf("%s: node network id is %s, expected %s", host, netid, peeringHosts[i])
+		}
+		ret[i] = pubKey
+	}
+	return ret, nil
+}
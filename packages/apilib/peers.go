@@ -0,0 +1,79 @@
+package apilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+)
+
+// GetPeerAddresses calls the node to get its address book
+func GetPeerAddresses(host string) ([]*registry.PeerAddress, error) {
+	url := fmt.Sprintf("%s/adm/peers", baseURL(host))
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	var dresp admapi.PeerAddressBookResponse
+	err = json.NewDecoder(resp.Body).Decode(&dresp)
+	if err != nil {
+		return nil, err
+	}
+	if dresp.Error != "" {
+		return nil, errors.New(dresp.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	return dresp.AddressBook, nil
+}
+
+// SetPeerAddress calls the node to put network location of the node with the identity pubKey into its address book
+func SetPeerAddress(host string, pubKey string, netID string) error {
+	data, err := json.Marshal(&registry.PeerAddress{
+		PubKey: pubKey,
+		NetID:  netID,
+	})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/adm/peers", baseURL(host))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	var respbody misc.SimpleResponse
+	err = json.NewDecoder(resp.Body).Decode(&respbody)
+	if err != nil {
+		return fmt.Errorf("response status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || respbody.Error != "" {
+		return fmt.Errorf("response status %d: %s", resp.StatusCode, respbody.Error)
+	}
+	return nil
+}
+
+// IntroducePeers puts network locations of all nodes into address books of each other.
+// Nodes accept peering and DKG sessions only from known identities, so participants
+// of the DKG must be introduced to each other before it is run
+func IntroducePeers(apiHosts, peeringHosts, pubKeys []string) error {
+	if len(apiHosts) != len(peeringHosts) || len(apiHosts) != len(pubKeys) {
+		return fmt.Errorf("number of API hosts, peering hosts and public keys must be equal")
+	}
+	for i, host := range apiHosts {
+		for j := range apiHosts {
+			if i == j {
+				continue
+			}
+			if err := SetPeerAddress(host, pubKeys[j], peeringHosts[j]); err != nil {
+				return fmt.Errorf("%s: %v", host, err)
+			}
+		}
+	}
+	return nil
+}
@@ -6,28 +6,33 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/stateschema"
 	"github.com/iotaledger/wasp/packages/util/multicall"
 	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
 )
 
-// PutProgramMetadata calls node to write program code and ProgramMetadata record
-func PutProgram(host string, vmType string, description string, code []byte) (*hashing.HashValue, error) {
+// PutProgramMetadata calls node to write program code and ProgramMetadata record. The schema of the state may be nil
+func PutProgram(host string, vmType string, description string, code []byte, schema *stateschema.Schema) (*hashing.HashValue, error) {
 	data, err := json.Marshal(&admapi.PutProgramRequest{
 		ProgramMetadata: admapi.ProgramMetadata{
 			VMType:      vmType,
 			Description: description,
+			StateSchema: schema,
 		},
 		Code: code,
 	})
 	if err != nil {
 		return nil, err
 	}
-	url := fmt.Sprintf("http://%s/adm/program", host)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/program", baseURL(host))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +54,8 @@ func PutProgram(host string, vmType string, description string, code []byte) (*h
 
 // GetProgramMetadata calls node to get ProgramMetadata by program hash
 func GetProgramMetadata(host string, progHash *hashing.HashValue) (*registry.ProgramMetadata, error) {
-	url := fmt.Sprintf("http://%s/adm/program/%s", host, progHash.String())
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/adm/program/%s", baseURL(host), progHash.String())
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +77,7 @@ func GetProgramMetadata(host string, progHash *hashing.HashValue) (*registry.Pro
 		ProgramHash: *progHash,
 		VMType:      dresp.VMType,
 		Description: dresp.Description,
+		StateSchema: dresp.StateSchema,
 	}, nil
 }
 
@@ -116,5 +122,63 @@ func consistentProgramMetadata(md1, md2 *registry.ProgramMetadata) bool {
 	if md1.Description != md2.Description {
 		return false
 	}
+	if !reflect.DeepEqual(md1.StateSchema, md2.StateSchema) {
+		return false
+	}
 	return true
 }
+
+// GetProgramInfo calls node to get the program with the hash of its code and smart contracts using it.
+// Returns nil if the node doesn't know the program
+func GetProgramInfo(host string, progHash *hashing.HashValue) (*v1.ProgramInfo, error) {
+	url := fmt.Sprintf("%s%s/programs/%s", baseURL(host), v1.Prefix, progHash.String())
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil
+	}
+	var result v1.ProgramInfo
+	if err := decodeV1Response(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CheckProgramCode checks if all hosts have the code of the program and the hash of the code stored by
+// each of them is equal to the program hash. Returns error listing hosts with missing or corrupted code
+func CheckProgramCode(hosts []string, progHash *hashing.HashValue) error {
+	funs := make([]func() error, len(hosts))
+	infos := make([]*v1.ProgramInfo, len(hosts))
+	for i, host := range hosts {
+		h := host
+		idx := i
+		funs[i] = func() error {
+			var err error
+			infos[idx], err = GetProgramInfo(h, progHash)
+			return err
+		}
+	}
+	succ, errs := multicall.MultiCall(funs, 1*time.Second)
+	if !succ {
+		return multicall.WrapErrors(errs)
+	}
+	problems := make([]string, 0)
+	for i, info := range infos {
+		switch {
+		case info == nil:
+			problems = append(problems, fmt.Sprintf("%s: program not found", hosts[i]))
+		case info.Builtin:
+		case info.CodeHash == "":
+			problems = append(problems, fmt.Sprintf("%s: program code not found", hosts[i]))
+		case info.CodeHash != progHash.String():
+			problems = append(problems, fmt.Sprintf("%s: program code is corrupted, its hash is %s", hosts[i], info.CodeHash))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("inconsistent code of the program %s: %s", progHash.String(), strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
 	"net/http"
+	"time"
 )
 
 func QueryRequestProcessingStatusMulti(host string, addr *address.Address, reqs []sctransaction.RequestId) (map[sctransaction.RequestId]bool, error) {
@@ -22,9 +24,9 @@ func QueryRequestProcessingStatusMulti(host string, addr *address.Address, reqs
 	if err != nil {
 		return nil, err
 	}
-	url := fmt.Sprintf("http://%s/sc/state/request", host)
+	url := fmt.Sprintf("%s/sc/state/request", baseURL(host))
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +57,35 @@ func IsRequestProcessed(host string, addr *address.Address, reqid sctransaction.
 	_, ok := m[reqid]
 	return ok, nil
 }
+
+// WaitRequestProcessed waits with long-poll calls to the Wasp node until the request is processed.
+// Returns the receipt of the request, which is nil if the node can't restore it
+func WaitRequestProcessed(host string, addr *address.Address, reqid *sctransaction.RequestId, timeout time.Duration) (*stateapi.RequestReceipt, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("request %s wasn't processed in %v", reqid.ToBase58(), timeout)
+		}
+		url := fmt.Sprintf("%s%s/sc/%s/requests/%s/wait?timeout=%s", baseURL(host), v1.Prefix, addr.String(), reqid.ToBase58(), remaining)
+		status, err := callWaitRequest(url)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status == stateapi.RequestStatusCompleted {
+			return status.Receipt, nil
+		}
+	}
+}
+
+func callWaitRequest(url string) (*stateapi.RequestStatusResponse, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	var status stateapi.RequestStatusResponse
+	if err = decodeV1Response(resp, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
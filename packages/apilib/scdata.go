@@ -18,18 +18,20 @@ import (
 // PutSCData calls node to write BootupData record
 func PutSCData(host string, bd registry.BootupData) error {
 	data, err := json.Marshal(&admapi.BootupDataJsonable{
-		Address:        bd.Address.String(),
-		OwnerAddress:   bd.OwnerAddress.String(),
-		Color:          bd.Color.String(),
-		CommitteeNodes: bd.CommitteeNodes,
-		AccessNodes:    bd.AccessNodes,
-		Active:         bd.Active,
+		Address:          bd.Address.String(),
+		OwnerAddress:     bd.OwnerAddress.String(),
+		Color:            bd.Color.String(),
+		CommitteeNodes:   bd.CommitteeNodes,
+		AccessNodes:      bd.AccessNodes,
+		CommitteePubKeys: bd.CommitteePubKeys,
+		AccessPubKeys:    bd.AccessPubKeys,
+		Active:           bd.Active,
 	})
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("http://%s/adm/putscdata", host)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/putscdata", baseURL(host))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
@@ -53,8 +55,8 @@ func GetSCData(host string, addr *address.Address) (*registry.BootupData, bool,
 	if err != nil {
 		return nil, false, err
 	}
-	url := fmt.Sprintf("http://%s/adm/getscdata", host)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	url := fmt.Sprintf("%s/adm/getscdata", baseURL(host))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, false, err
 	}
@@ -73,9 +75,11 @@ func GetSCData(host string, addr *address.Address) (*registry.BootupData, bool,
 		return nil, false, nil
 	}
 	ret := &registry.BootupData{
-		CommitteeNodes: dresp.CommitteeNodes,
-		AccessNodes:    dresp.AccessNodes,
-		Active:         dresp.Active,
+		CommitteeNodes:   dresp.CommitteeNodes,
+		AccessNodes:      dresp.AccessNodes,
+		CommitteePubKeys: dresp.CommitteePubKeys,
+		AccessPubKeys:    dresp.AccessPubKeys,
+		Active:           dresp.Active,
 	}
 	if ret.Address, err = address.FromBase58(dresp.Address); err != nil {
 		return nil, false, err
@@ -92,7 +96,7 @@ func GetSCData(host string, addr *address.Address) (*registry.BootupData, bool,
 
 // gets list of all SCs from the node
 func GetSCList(url string) ([]address.Address, error) {
-	resp, err := http.Get(fmt.Sprintf("http://%s/adm/getsclist", url))
+	resp, err := httpClient.Get(fmt.Sprintf("%s/adm/getsclist", baseURL(url)))
 	if err != nil {
 		return nil, err
 	}
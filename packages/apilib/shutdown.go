@@ -2,10 +2,9 @@ package apilib
 
 import (
 	"fmt"
-	"net/http"
 )
 
 func Shutdown(host string) error {
-	_, err := http.Get(fmt.Sprintf("http://%s/adm/shutdown", host))
+	_, err := httpClient.Get(fmt.Sprintf("%s/adm/shutdown", baseURL(host)))
 	return err
 }
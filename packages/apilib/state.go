@@ -17,8 +17,8 @@ import (
 )
 
 func DumpSCState(host string, scAddress string) (*admapi.DumpSCStateResponse, error) {
-	url := fmt.Sprintf("http://%s/adm/sc/%s/dumpstate", host, scAddress)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/adm/sc/%s/dumpstate", baseURL(host), scAddress)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -49,12 +49,12 @@ type QuerySCStateResult struct {
 }
 
 func QuerySCState(host string, query *stateapi.QueryRequest) (*QuerySCStateResult, error) {
-	url := fmt.Sprintf("http://%s/sc/state/query", host)
+	url := fmt.Sprintf("%s/sc/state/query", baseURL(host))
 	data, err := json.Marshal(query)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
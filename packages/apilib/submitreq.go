@@ -0,0 +1,42 @@
+package apilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
+	"github.com/mr-tron/base58"
+)
+
+// SubmitRequest posts the signed request transaction through the Wasp node. The node posts it to the ledger
+// and puts requests to the smart contract into the backlog. Returns ids of requests to the smart contract
+func SubmitRequest(host string, scAddr *address.Address, tx *sctransaction.Transaction) ([]sctransaction.RequestId, error) {
+	data, err := json.Marshal(&stateapi.SubmitRequestRequest{
+		Transaction: base58.Encode(tx.Bytes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s/sc/%s/requests", baseURL(host), v1.Prefix, scAddr.String())
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	var result v1.SubmitRequestResponse
+	if err = decodeV1Response(resp, &result); err != nil {
+		return nil, err
+	}
+	ret := make([]sctransaction.RequestId, len(result.RequestIds))
+	for i, s := range result.RequestIds {
+		reqid, err := sctransaction.RequestIdFromBase58(s)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = *reqid
+	}
+	return ret, nil
+}
@@ -0,0 +1,28 @@
+package apilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
+)
+
+// decodeV1Response decodes the body of the successful response of the v1 API into the result,
+// or returns the error from the error response
+func decodeV1Response(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp v1.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+			return fmt.Errorf("%s %s returned code %d", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s returned code %d: %s", resp.Request.Method, resp.Request.URL.Path,
+			resp.StatusCode, errResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,25 @@
+package commiteeimpl
+
+import (
+	"github.com/iotaledger/wasp/packages/parameters"
+)
+
+// checkPeerClocks warns about committee peers with clocks too far from the local clock.
+// Timelocks of requests and timestamps of batches are checked against local clocks of nodes,
+// so committee nodes with drifting clocks may disagree about them
+func (c *committeeObj) checkPeerClocks() {
+	threshold := parameters.GetDuration(parameters.PeeringClockOffsetWarning)
+	for i, peer := range c.committeePeers() {
+		if peer == nil || !peer.IsAlive() {
+			continue
+		}
+		offset, ok := peer.ClockOffset()
+		if !ok {
+			continue
+		}
+		if offset > threshold || -offset > threshold {
+			c.log.Warnf("clock of committee peer #%d (%s) is off by %v. It may affect timelocks and batch timestamps",
+				i, peer.RemoteLocation(), offset)
+		}
+	}
+}
@@ -37,7 +37,9 @@ type committeeObj struct {
 	stateMgr        committee.StateManager
 	operator        committee.Operator
 	isCommitteeNode atomic.Bool
-	log             *logger.Logger
+	// number of invalid signature shares received from the committee peer
+	sigShareFaults []atomic.Uint32
+	log            *logger.Logger
 }
 
 func newCommitteeObj(bootupData *registry.BootupData, log *logger.Logger, onActivation func()) committee.Committee {
@@ -46,14 +48,23 @@ func newCommitteeObj(bootupData *registry.BootupData, log *logger.Logger, onActi
 	addr := bootupData.Address
 	if util.ContainsDuplicates(bootupData.CommitteeNodes) ||
 		util.ContainsDuplicates(bootupData.AccessNodes) ||
-		util.IntersectsLists(bootupData.CommitteeNodes, bootupData.AccessNodes) ||
-		util.ContainsInList(peering.MyNetworkId(), bootupData.AccessNodes) {
+		util.IntersectsLists(bootupData.CommitteeNodes, bootupData.AccessNodes) {
 
 		log.Errorf("can't create committee object for %s: bootup data contains duplicate node addresses. Committee nodes: %+v",
 			addr.String(), bootupData.CommitteeNodes)
 		return nil
 	}
-	dkshare, keyExists, err := registry.GetDKShare(&bootupData.Address)
+	if len(bootupData.CommitteePubKeys) != len(bootupData.CommitteeNodes) ||
+		len(bootupData.AccessPubKeys) != len(bootupData.AccessNodes) ||
+		util.ContainsDuplicates(bootupData.CommitteePubKeys) ||
+		util.ContainsDuplicates(bootupData.AccessPubKeys) ||
+		util.IntersectsLists(bootupData.CommitteePubKeys, bootupData.AccessPubKeys) {
+
+		log.Errorf("can't create committee object for %s: bootup data contains inconsistent node identities. Committee identities: %+v",
+			addr.String(), bootupData.CommitteePubKeys)
+		return nil
+	}
+	keys, keyExists, err := registry.GetCommitteeKeys(&bootupData.Address)
 	if err != nil {
 		log.Error(err)
 		return nil
@@ -61,17 +72,25 @@ func newCommitteeObj(bootupData *registry.BootupData, log *logger.Logger, onActi
 
 	if !keyExists {
 		// if key doesn't exists, the node still can provide access to the smart contract state as an "access node"
-		// for access nodes, committee nodes are ignored
-		if len(bootupData.AccessNodes) > 0 {
-			log.Info("can't find private key. Node will run as an access node for the address %s", addr.String())
-		} else {
-			log.Errorf("private key wasn't found and no access peers specified. Node can't run for the address %s", addr.String())
+		if !util.ContainsInList(peering.MyPubKey(), bootupData.AccessPubKeys) {
+			log.Errorf("private key wasn't found and the node is not among access nodes. Node can't run for the address %s",
+				addr.String())
 			return nil
 		}
+		if len(bootupData.CommitteeNodes) == 0 {
+			log.Errorf("no committee nodes specified. Access node can't run for the address %s", addr.String())
+			return nil
+		}
+		log.Infof("can't find private key. Node will run as an access node for the address %s", addr.String())
 	} else {
-		if !iAmInTheCommittee(bootupData.CommitteeNodes, dkshare.N, dkshare.Index) {
+		if util.ContainsInList(peering.MyPubKey(), bootupData.AccessPubKeys) {
+			log.Errorf("bootup data inconsistency: the own node %s is both committee and access node for %s",
+				peering.MyPubKey(), addr.String())
+			return nil
+		}
+		if !iAmInTheCommittee(bootupData.CommitteePubKeys, keys.Size(), keys.OwnIndex()) {
 			log.Errorf("bootup data inconsistency: the own node %s is not in the committee for %s: %+v",
-				peering.MyNetworkId(), addr.String(), bootupData.CommitteeNodes)
+				peering.MyPubKey(), addr.String(), bootupData.CommitteePubKeys)
 			return nil
 		}
 		// check for owner address. It is mandatory for the committee node
@@ -92,34 +111,48 @@ func newCommitteeObj(bootupData *registry.BootupData, log *logger.Logger, onActi
 		log:          log.Named(util.Short(bootupData.Address.String())),
 	}
 	if keyExists {
-		ret.ownIndex = dkshare.Index
-		ret.size = dkshare.N
-		ret.quorum = dkshare.T
-
-		numNil := 0
-		for _, remoteLocation := range bootupData.CommitteeNodes {
-			peer := peering.UsePeer(remoteLocation)
-			if peer == nil {
-				numNil++
-			}
-			ret.peers = append(ret.peers, peer)
-		}
-		if numNil != 1 || ret.peers[dkshare.Index] != nil {
-			// at this point must be exactly 1 element in ret.peers == to nil,
-			// the one with the index in the committee
-			ret.log.Panicf("failed to initialize peers of the committee. committeePeers: %+v. myId: %s", bootupData.CommitteeNodes, peering.MyNetworkId())
+		ret.ownIndex = keys.OwnIndex()
+		ret.size = keys.Size()
+		ret.quorum = keys.Quorum()
+	} else {
+		// access node has index after all committee nodes
+		// it only needs one committee peer connected to sync the state, because batches are validated by the ledger
+		ret.ownIndex = uint16(len(bootupData.CommitteeNodes) + indexInList(peering.MyPubKey(), bootupData.AccessPubKeys))
+		ret.size = uint16(len(bootupData.CommitteeNodes))
+		ret.quorum = 1
+		// access node does not run consensus
+		ret.isReadyConsensus = true
+	}
+	// peers are indexed same way in all committee and access nodes:
+	// first committee nodes, then access nodes. Own peer is nil
+	locations := append(append([]string{}, bootupData.CommitteeNodes...), bootupData.AccessNodes...)
+	pubKeys := append(append([]string{}, bootupData.CommitteePubKeys...), bootupData.AccessPubKeys...)
+	for i, remoteLocation := range locations {
+		peer, err := peering.UsePeer(remoteLocation, pubKeys[i])
+		if err != nil {
+			log.Errorf("can't create committee object for %s: %v", addr.String(), err)
+			ret.releasePeers()
+			return nil
 		}
+		ret.peers = append(ret.peers, peer)
 	}
-	for _, remoteLocation := range bootupData.AccessNodes {
-		p := peering.UsePeer(remoteLocation)
-		if p != nil {
-			ret.peers = append(ret.peers, p)
+	ret.sigShareFaults = make([]atomic.Uint32, len(ret.peers))
+	numNil := 0
+	for _, peer := range ret.peers {
+		if peer == nil {
+			numNil++
 		}
 	}
+	if numNil != 1 || ret.peers[ret.ownIndex] != nil {
+		// at this point must be exactly 1 element in ret.peers == to nil,
+		// the one with the own index
+		ret.log.Panicf("failed to initialize peers of the committee. committeePeers: %+v, accessPeers: %+v. myId: %s",
+			bootupData.CommitteeNodes, bootupData.AccessNodes, peering.MyNetworkId())
+	}
 
 	ret.stateMgr = statemgr.New(ret, ret.log)
 	if keyExists {
-		ret.operator = consensus.NewOperator(ret, dkshare, ret.log)
+		ret.operator = consensus.NewOperator(ret, keys, ret.log)
 		ret.isCommitteeNode.Store(true)
 	} else {
 		ret.isCommitteeNode.Store(false)
@@ -148,10 +181,28 @@ func newCommitteeObj(bootupData *registry.BootupData, log *logger.Logger, onActi
 	return ret
 }
 
-// iAmInTheCommittee checks if netLocations makes sense
-func iAmInTheCommittee(committeeNodes []string, n, index uint16) bool {
-	if len(committeeNodes) != int(n) {
+// releasePeers stops using all peers of the committee
+func (c *committeeObj) releasePeers() {
+	for _, pa := range c.peers {
+		if pa != nil {
+			peering.StopUsingPeer(pa.PeeringId())
+		}
+	}
+}
+
+// iAmInTheCommittee checks if the own identity is in the committee at the index of the key share
+func iAmInTheCommittee(committeePubKeys []string, n, index uint16) bool {
+	if len(committeePubKeys) != int(n) {
 		return false
 	}
-	return committeeNodes[index] == peering.MyNetworkId()
+	return committeePubKeys[index] == peering.MyPubKey()
+}
+
+func indexInList(elem string, lst []string) int {
+	for i, e := range lst {
+		if e == elem {
+			return i
+		}
+	}
+	return -1
 }
@@ -3,8 +3,13 @@ package commiteeimpl
 import (
 	"bytes"
 	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
 	"github.com/iotaledger/wasp/plugins/peering"
+	"time"
 )
 
 func (c *committeeObj) dispatchMessage(msg interface{}) {
@@ -48,6 +53,8 @@ func (c *committeeObj) dispatchMessage(msg interface{}) {
 		// receive request message
 		if c.operator != nil {
 			c.operator.EventRequestMsg(msgt)
+		} else {
+			c.forwardRequest(msgt)
 		}
 
 	case committee.BalancesMsg:
@@ -60,7 +67,17 @@ func (c *committeeObj) dispatchMessage(msg interface{}) {
 		if c.operator != nil {
 			c.operator.EventResultCalculated(msgt)
 		}
+
+	case *committee.ResultSignedMsg:
+		// signature share of the VM result is ready
+		if c.operator != nil {
+			c.operator.EventResultSigned(msgt)
+		}
+
 	case committee.TimerTick:
+		if int(msgt)%int(committee.CheckPeerClocksPeriod/committee.TimerTickPeriod) == 0 {
+			c.checkPeerClocks()
+		}
 
 		if msgt%2 == 0 {
 			if c.stateMgr != nil {
@@ -75,6 +92,19 @@ func (c *committeeObj) dispatchMessage(msg interface{}) {
 }
 
 func (c *committeeObj) processPeerMessage(msg *peering.PeerMessage) {
+	if msg.SenderIndex >= c.NumPeers() || msg.SenderIndex == c.ownIndex {
+		c.log.Errorf("processPeerMessage: wrong sender index %d", msg.SenderIndex)
+		return
+	}
+	if msg.Sender == nil || msg.Sender != c.peers[msg.SenderIndex] {
+		c.log.Warnf("processPeerMessage: sender index %d does not match the identity of the peer", msg.SenderIndex)
+		return
+	}
+	if msg.SenderIndex >= c.size && !isAccessNodeMsgType(msg.MsgType) {
+		c.log.Warnf("processPeerMessage: message type %d is not accepted from access node #%d",
+			msg.MsgType, msg.SenderIndex)
+		return
+	}
 
 	rdr := bytes.NewReader(msg.MsgData)
 
@@ -100,6 +130,7 @@ func (c *committeeObj) processPeerMessage(msg *peering.PeerMessage) {
 		c.stateMgr.EvidenceStateIndex(msgt.StateIndex)
 
 		msgt.SenderIndex = msg.SenderIndex
+		msgt.Timestamp = msg.Timestamp
 
 		if c.operator != nil {
 			c.operator.EventNotifyReqMsg(msgt)
@@ -192,7 +223,119 @@ func (c *committeeObj) processPeerMessage(msg *peering.PeerMessage) {
 		msgt.SenderIndex = msg.SenderIndex
 		c.testTrace(msgt)
 
+	case committee.MsgForwardRequest:
+		msgt := &committee.ForwardRequestMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		msgt.SenderIndex = msg.SenderIndex
+		c.eventForwardRequestMsg(msgt)
+
+	case committee.MsgGetProgramCode:
+		msgt := &committee.GetProgramCodeMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		msgt.SenderIndex = msg.SenderIndex
+		c.eventGetProgramCodeMsg(msgt)
+
+	case committee.MsgProgramCode:
+		msgt := &committee.ProgramCodeMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		msgt.SenderIndex = msg.SenderIndex
+		if c.operator != nil {
+			c.operator.EventProgramCodeMsg(msgt)
+		}
+
 	default:
 		c.log.Errorf("processPeerMessage: wrong msg type")
 	}
 }
+
+// isAccessNodeMsgType returns true if the message of the type may be sent by the access node.
+// Access nodes sync the state and forward requests, they can't take part in the consensus
+func isAccessNodeMsgType(msgType byte) bool {
+	switch msgType {
+	case committee.MsgStateIndexPingPong, committee.MsgGetBatch, committee.MsgBatchHeader,
+		committee.MsgStateUpdate, committee.MsgForwardRequest:
+		return true
+	}
+	return false
+}
+
+// forwardRequest is used by the access node to pass request it received from the ledger
+// to the committee nodes
+func (c *committeeObj) forwardRequest(msg *committee.RequestMsg) {
+	if msg.Unconfirmed {
+		// forwarded when it comes from the ledger
+		return
+	}
+	msgData := util.MustBytes(&committee.ForwardRequestMsg{
+		Transaction: msg.Transaction,
+		Index:       msg.Index,
+	})
+	numSent := c.SendMsgToCommitteePeers(committee.MsgForwardRequest, msgData, time.Now().UnixNano())
+	c.log.Debugf("request %s forwarded to %d committee peers", msg.RequestId().Short(), numSent)
+}
+
+// eventForwardRequestMsg handles request forwarded by the access node.
+// The request is processed the same way as the one received from the ledger:
+// it will only be selected for the batch if request token is present among SC outputs
+func (c *committeeObj) eventForwardRequestMsg(msg *committee.ForwardRequestMsg) {
+	if c.operator == nil {
+		return
+	}
+	if msg.Transaction.Requests()[msg.Index].Address() != c.address {
+		c.log.Warnf("forwarded request from peer #%d is not addressed to the smart contract", msg.SenderIndex)
+		return
+	}
+	c.operator.EventRequestMsg(&committee.RequestMsg{
+		Transaction: msg.Transaction,
+		Index:       msg.Index,
+	})
+}
+
+// eventGetProgramCodeMsg sends the code of the program of the smart contract to the committee peer which
+// misses it. Only the program run by the smart contract according to its solid state is sent
+func (c *committeeObj) eventGetProgramCodeMsg(msg *committee.GetProgramCodeMsg) {
+	vs, _, ok, err := state.LoadSolidState(&c.address)
+	if err != nil || !ok {
+		c.log.Debugf("program code requested by peer #%d: solid state is not available", msg.SenderIndex)
+		return
+	}
+	progHash, ok, err := vs.Variables().Codec().GetHashValue(vmconst.VarNameProgramHash)
+	if err != nil || !ok || *progHash != msg.ProgramHash {
+		c.log.Warnf("peer #%d requested code of the program %s, which is not the program of the smart contract",
+			msg.SenderIndex, msg.ProgramHash.String())
+		return
+	}
+	md, err := registry.GetProgramMetadata(progHash)
+	if err != nil || md == nil {
+		c.log.Debugf("program code requested by peer #%d: metadata of the program %s not found", msg.SenderIndex, progHash.String())
+		return
+	}
+	code, err := registry.GetProgramCode(progHash)
+	if err != nil {
+		c.log.Debugf("program code requested by peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	msgData := util.MustBytes(&committee.ProgramCodeMsg{
+		Metadata: md,
+		Code:     code,
+	})
+	if len(msgData) > peering.MaxMessageDataSize {
+		c.log.Warnf("program code requested by peer #%d: code of the program %s is too large to be sent (%d bytes)",
+			msg.SenderIndex, progHash.String(), len(code))
+		return
+	}
+	if err := c.SendMsg(msg.SenderIndex, committee.MsgProgramCode, msgData); err != nil {
+		c.log.Warnf("failed to send program code to peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	c.log.Infof("code of the program %s sent to peer #%d", progHash.String(), msg.SenderIndex)
+}
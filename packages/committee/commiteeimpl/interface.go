@@ -15,6 +15,10 @@ import (
 
 func init() {
 	committee.ConstructorNew = newCommitteeObj
+
+	// newer messages of these types supersede older ones
+	peering.SetDropPolicy(committee.MsgStateIndexPingPong, peering.DropOldest)
+	peering.SetDropPolicy(committee.MsgNotifyRequests, peering.DropOldest)
 }
 
 func (c *committeeObj) IsOpenQueue() bool {
@@ -120,11 +124,7 @@ func (c *committeeObj) Dismiss() {
 
 		close(c.chMsg)
 
-		for _, pa := range c.peers {
-			if pa != nil {
-				peering.StopUsingPeer(pa.PeeringId())
-			}
-		}
+		c.releasePeers()
 	})
 
 	publisher.Publish("dismissed_committee", c.address.String())
@@ -274,12 +274,27 @@ func (c *committeeObj) PeerStatus() []*committee.PeerStatus {
 		} else {
 			status.PeeringID = peer.PeeringId()
 			status.Connected = peer.IsAlive()
+			status.ClockOffset, status.ClockMeasured = peer.ClockOffset()
+			status.SigShareFaults = c.sigShareFaults[i].Load()
 		}
 		ret = append(ret, status)
 	}
 	return ret
 }
 
+func (c *committeeObj) RecordSigShareFault(peerIndex uint16) {
+	if int(peerIndex) >= len(c.sigShareFaults) {
+		return
+	}
+	c.sigShareFaults[peerIndex].Inc()
+}
+
+// IsCommitteeNode returns false if the node runs as an access node: it syncs and serves the state
+// but does not participate in the consensus
+func (c *committeeObj) IsCommitteeNode() bool {
+	return c.isCommitteeNode.Load()
+}
+
 func (c *committeeObj) GetRequestProcessingStatus(reqId *sctransaction.RequestId) committee.RequestProcessingStatus {
 	if c.IsDismissed() {
 		return committee.RequestProcessingStatusUnknown
@@ -298,3 +313,11 @@ func (c *committeeObj) GetRequestProcessingStatus(reqId *sctransaction.RequestId
 	}
 	return committee.RequestProcessingStatusCompleted
 }
+
+// ConsensusStats returns statistics of recent consensus rounds. Access node doesn't run consensus, returns nil
+func (c *committeeObj) ConsensusStats() *committee.ConsensusStats {
+	if !c.isCommitteeNode.Load() {
+		return nil
+	}
+	return c.operator.ConsensusStats()
+}
@@ -2,6 +2,7 @@ package committee
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
@@ -27,12 +28,17 @@ type Committee interface {
 	InitTestRound()
 	HasQuorum() bool
 	PeerStatus() []*PeerStatus
+	// RecordSigShareFault counts invalid signature share received from the peer
+	RecordSigShareFault(peerIndex uint16)
+	IsCommitteeNode() bool
 	//
 	SetReadyStateManager()
 	SetReadyConsensus()
 	Dismiss()
 	IsDismissed() bool
 	GetRequestProcessingStatus(*sctransaction.RequestId) RequestProcessingStatus
+	// returns nil for access node
+	ConsensusStats() *ConsensusStats
 }
 
 type PeerStatus struct {
@@ -40,6 +46,13 @@ type PeerStatus struct {
 	PeeringID string
 	IsSelf    bool
 	Connected bool
+	// peer's clock minus local clock, measured by heartbeats of the peering connection.
+	// Zero if not measured yet
+	ClockOffset time.Duration
+	// false if the clock offset is not measured yet
+	ClockMeasured bool
+	// number of invalid signature shares received from the peer since the start of the node
+	SigShareFaults uint32
 }
 
 func (p *PeerStatus) String() string {
@@ -73,12 +86,15 @@ type Operator interface {
 	EventNotifyReqMsg(*NotifyReqMsg)
 	EventStartProcessingBatchMsg(*StartProcessingBatchMsg)
 	EventResultCalculated(*vm.VMTask)
+	EventResultSigned(*ResultSignedMsg)
 	EventSignedHashMsg(*SignedHashMsg)
 	EventNotifyFinalResultPostedMsg(*NotifyFinalResultPostedMsg)
 	EventTransactionInclusionLevelMsg(msg *TransactionInclusionLevelMsg)
+	EventProgramCodeMsg(*ProgramCodeMsg)
 	EventTimerMsg(TimerTick)
 	//
 	IsRequestInBacklog(*sctransaction.RequestId) bool
+	ConsensusStats() *ConsensusStats
 }
 
 var ConstructorNew func(bootupData *registry.BootupData, log *logger.Logger, onActivation func()) Committee
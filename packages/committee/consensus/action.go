@@ -17,6 +17,7 @@ func (op *operator) takeAction() {
 	op.checkQuorum()
 	op.rotateLeader()
 	op.pullInclusionLevel()
+	op.startPipelinedCalculations()
 }
 
 func (op *operator) pullInclusionLevel() {
@@ -46,7 +47,10 @@ func (op *operator) rotateLeader() {
 	// starting from scratch with the new leader
 	op.leaderStatus = nil
 	op.sentResultToLeader = nil
+	op.sentResultBatch = nil
 	op.postedResultTxid = nil
+	op.discardPipelinedBatch("leader rotated")
+	op.statsLeaderRotated(leader)
 
 	op.log.Infof("LEADER ROTATED #%d --> #%d, I am the leader = %v",
 		prevlead, leader, op.iAmCurrentLeader())
@@ -67,11 +71,20 @@ func (op *operator) startCalculationsAsLeader() {
 		// no quorum, doesn't make sense to start
 		return
 	}
+	if op.startPipelinedBatchAsLeader() {
+		// batch was calculated in advance on top of the previous pending state
+		return
+	}
 	// select requests for the batch
 	reqs := op.selectRequestsToProcess()
 	if len(reqs) == 0 {
 		return
 	}
+	// determine timestamp as a median of clocks of the quorum of peers
+	ts, ok := op.batchTimestamp(op.stateTx.MustState().Timestamp())
+	if !ok {
+		return
+	}
 	reqIds := takeIds(reqs)
 	reqIdsStr := idsShortStr(reqIds)
 
@@ -89,16 +102,6 @@ func (op *operator) startCalculationsAsLeader() {
 		RequestIds:    reqIds,
 	})
 
-	// determine timestamp. Must be max(local clock, prev timestamp+1)
-	ts := time.Now().UnixNano()
-	prevTs := op.stateTx.MustState().Timestamp()
-	if ts <= prevTs {
-		op.log.Warnf("local clock is not ahead the timestamp of the previous state. prevTs: %d, currentTs: %d, diff: %d ns",
-			prevTs, ts, prevTs-ts)
-		ts = prevTs + 1
-		op.log.Info("timestamp was adjusted to %d", ts)
-	}
-
 	numSucc := op.committee.SendMsgToCommitteePeers(committee.MsgStartProcessingRequest, msgData, ts)
 
 	op.log.Debugf("%d 'msgStartProcessingRequest' messages sent to peers", numSucc)
@@ -109,6 +112,8 @@ func (op *operator) startCalculationsAsLeader() {
 		return
 	}
 
+	op.statsBatchStarted(len(reqs))
+
 	batchHash := vm.BatchHash(reqIds, ts, op.peerIndex())
 	op.leaderStatus = &leaderStatus{
 		reqs:          reqs,
@@ -116,6 +121,7 @@ func (op *operator) startCalculationsAsLeader() {
 		balances:      op.balances,
 		timestamp:     ts,
 		signedResults: make([]*signedResult, op.committee.Size()),
+		faultyPeers:   make([]bool, op.committee.Size()),
 	}
 	op.log.Debugw("runCalculationsAsync leader",
 		"batch hash", batchHash.String(),
@@ -129,6 +135,8 @@ func (op *operator) startCalculationsAsLeader() {
 		balances:        op.balances,
 		timestamp:       ts,
 		rewardAddress:   rewardAddress,
+		virtualState:    op.currentSCState,
+		stateTxId:       op.stateTx.ID(),
 	})
 	op.setNextConsensusStage(consensusStageLeaderCalculationsStarted)
 }
@@ -156,22 +164,18 @@ func (op *operator) checkQuorum() bool {
 			op.leaderStatus.signedResults[i] = nil // ignoring
 			continue
 		}
-		err := op.dkshare.VerifySigShare(op.leaderStatus.resultTx.EssenceBytes(), op.leaderStatus.signedResults[i].sigShare)
-		if err != nil {
-			op.log.Warnf("wrong signature from peer #%d: %v", i, err)
-			op.leaderStatus.signedResults[i] = nil // ignoring
+		// shares received before own result was calculated are verified here
+		if !op.verifySignedResult(uint16(i)) {
 			continue
 		}
-
-		sigShare := op.leaderStatus.signedResults[i].sigShare
-		idx, _ := sigShare.Index()
-		sigShares = append(sigShares, sigShare)
-		contributingPeers = append(contributingPeers, uint16(idx))
+		sigShares = append(sigShares, op.leaderStatus.signedResults[i].sigShare)
+		contributingPeers = append(contributingPeers, uint16(i))
 	}
 
 	if len(sigShares) < int(op.quorum()) {
 		return false
 	}
+	op.statsSignaturesCollected(len(sigShares))
 	// quorum detected
 	if err := op.aggregateSigShares(sigShares); err != nil {
 		op.log.Errorf("aggregateSigShares returned: %v", err)
@@ -211,6 +215,7 @@ func (op *operator) checkQuorum() bool {
 
 	op.setNextConsensusStage(consensusStageLeaderResultFinalized)
 	op.setFinalizedTransaction(&txid)
+	op.setPendingResult(txid, op.leaderStatus.resultTx, op.leaderStatus.batch)
 
 	return true
 }
@@ -220,8 +225,21 @@ func (op *operator) setNewSCState(stateTx *sctransaction.Transaction, variableSt
 	op.stateTx = stateTx
 	op.currentSCState = variableState
 	op.sentResultToLeader = nil
+	op.sentResultBatch = nil
+	if op.postedResultTxid != nil {
+		op.deletePostedResultTx()
+	}
 	op.postedResultTxid = nil
 
+	// pipelined batch survives the state transition only if it was calculated on top of the new state
+	op.pendingStateSince = 0
+	if op.pendingResult == nil || op.pendingResult.txid != stateTx.ID() || !synchronized {
+		op.discardPipelinedBatch("pending state was not confirmed")
+	} else {
+		op.pendingStateSince = op.pendingResult.since
+	}
+	op.pendingResult = nil
+
 	op.requestBalancesDeadline = time.Now()
 	//op.queryOutputs()
 
@@ -8,7 +8,6 @@ import (
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/processor"
-	"github.com/iotaledger/wasp/plugins/publisher"
 )
 
 func (op *operator) EventProcessorReady(msg committee.ProcessorIsReady) {
@@ -25,6 +24,7 @@ func (op *operator) EventProcessorReady(msg committee.ProcessorIsReady) {
 // EventStateTransitionMsg is triggered by new currentSCState transition message sent by currentSCState manager
 func (op *operator) EventStateTransitionMsg(msg *committee.StateTransitionMsg) {
 	op.setNewSCState(msg.StateTransaction, msg.VariableState, msg.Synchronized)
+	op.statsNewState(op.mustStateIndex(), op.peerPermutation.Current())
 
 	vh := op.currentSCState.Hash()
 	op.log.Infof("STATE FOR CONSENSUS #%d, synced: %v, leader: %d iAmTheLeader: %v tx: %s, state hash: %s, backlog: %d",
@@ -45,6 +45,7 @@ func (op *operator) EventStateTransitionMsg(msg *committee.StateTransitionMsg) {
 	} else {
 		op.setNextConsensusStage(consensusStageNoSync)
 	}
+	op.resumePostedResult()
 	op.takeAction()
 
 	// check is processor is ready for the current consensusStage. If no, initiate load of the processor
@@ -54,19 +55,9 @@ func (op *operator) EventStateTransitionMsg(msg *committee.StateTransitionMsg) {
 		op.log.Warnf("program hash is undefined. Only builtin requests can be processed")
 		return
 	}
-	progHashStr := progHash.String()
-	op.processorReady = processor.CheckProcessor(progHashStr)
+	op.processorReady = processor.CheckProcessor(progHash.String())
 	if !op.processorReady {
-		processor.LoadProcessorAsync(progHash, func(err error) {
-			if err == nil {
-				op.committee.ReceiveMessage(committee.ProcessorIsReady{
-					ProgramHash: progHashStr,
-				})
-				publisher.Publish("vmready", op.committee.Address().String(), progHashStr)
-			} else {
-				op.log.Warnf("failed to load processor: %v", err)
-			}
-		})
+		op.loadProcessor(progHash)
 	}
 }
 
@@ -93,7 +84,7 @@ func (op *operator) EventRequestMsg(reqMsg *committee.RequestMsg) {
 	// place request into the backlog list
 	req, _ := op.requestFromMsg(reqMsg)
 	if req == nil {
-		op.log.Warn("received already processed request id = %s", reqMsg.RequestId().Short())
+		op.log.Warnf("request id = %s not accepted to the backlog", reqMsg.RequestId().Short())
 		return
 	}
 	if reqMsg.Timelock() != 0 {
@@ -112,6 +103,7 @@ func (op *operator) EventNotifyReqMsg(msg *committee.NotifyReqMsg) {
 		"stateIdx", msg.StateIndex,
 	)
 	op.storeNotification(msg)
+	op.storePeerClock(msg)
 	op.markRequestsNotified([]*committee.NotifyReqMsg{msg})
 
 	op.takeAction()
@@ -155,17 +147,12 @@ func (op *operator) EventStartProcessingBatchMsg(msg *committee.StartProcessingB
 		return
 	}
 	// check timestamp
-	localts := time.Now().UnixNano()
-	diff := localts - msg.Timestamp
-	if diff < 0 {
-		diff = -diff
-	}
-	if diff > committee.MaxClockDifferenceAllowed.Nanoseconds() {
-		op.log.Warn("reject consensus on timestamp: clock difference is too big. Leader ts: %d, local ts: %d, diff: %d",
-			msg.Timestamp, localts, diff)
+	if !op.checkBatchTimestamp(msg) {
 		return
 	}
 
+	op.statsBatchStarted(len(reqs))
+
 	// start async calculation
 	op.runCalculationsAsync(runCalculationsParams{
 		requests:        reqs,
@@ -173,6 +160,8 @@ func (op *operator) EventStartProcessingBatchMsg(msg *committee.StartProcessingB
 		balances:        msg.Balances,
 		rewardAddress:   msg.RewardAddress,
 		leaderPeerIndex: msg.SenderIndex,
+		virtualState:    op.currentSCState,
+		stateTxId:       op.stateTx.ID(),
 	})
 	op.setNextConsensusStage(consensusStageSubCalculationsStarted)
 	op.takeAction()
@@ -183,6 +172,26 @@ func (op *operator) EventStartProcessingBatchMsg(msg *committee.StartProcessingB
 func (op *operator) EventResultCalculated(ctx *vm.VMTask) {
 	op.log.Debugf("eventResultCalculated")
 
+	op.updateVMTimeEstimate(ctx)
+	op.statsVMRunTime(ctx.RunTime)
+
+	if op.pipelined != nil && op.pipelined.task == ctx {
+		// result of the pipelined batch. It will be used when the pending state is confirmed
+		op.pipelined.calculated = true
+		op.log.Debugf("pipelined batch calculated. Batch size: %d, state index: %d",
+			ctx.ResultBatch.Size(), ctx.ResultBatch.StateIndex())
+		op.takeAction()
+		return
+	}
+	if op.takeAbandonedTask(ctx) {
+		op.log.Debugf("result of the abandoned pipelined batch discarded")
+		return
+	}
+	op.processResult(ctx)
+}
+
+// processResult sends result batch to the state manager and result to the leader
+func (op *operator) processResult(ctx *vm.VMTask) {
 	// check if result belongs to context
 	if ctx.ResultBatch.StateIndex() != op.mustStateIndex()+1 {
 		// out of context. ignore
@@ -209,6 +218,30 @@ func (op *operator) EventResultCalculated(ctx *vm.VMTask) {
 	op.takeAction()
 }
 
+// EventResultSigned the own signature share of the VM result is ready
+// the action is to save the result or to send it to the leader
+func (op *operator) EventResultSigned(msg *committee.ResultSignedMsg) {
+	if msg.Task != op.signingTask {
+		op.log.Debugf("signature share of the outdated result discarded")
+		return
+	}
+	op.signingTask = nil
+	if msg.Err != nil {
+		op.log.Errorf("error while signing transaction %v", msg.Err)
+		return
+	}
+	if msg.Task.ResultBatch.StateIndex() != op.mustStateIndex()+1 {
+		// out of context. ignore
+		return
+	}
+	if msg.Task.LeaderPeerIndex == op.committee.OwnPeerIndex() {
+		op.saveOwnSignedResult(msg.Task, msg.SigShare)
+	} else {
+		op.sendSignedResultToTheLeader(msg.Task, msg.SigShare)
+	}
+	op.takeAction()
+}
+
 // EventSignedHashMsg result received from another peer
 func (op *operator) EventSignedHashMsg(msg *committee.SignedHashMsg) {
 	op.log.Debugw("EventSignedHashMsg",
@@ -231,6 +264,10 @@ func (op *operator) EventSignedHashMsg(msg *committee.SignedHashMsg) {
 		op.log.Errorf("EventSignedHashMsg: msg.BatchHash != op.leaderStatus.batchHash")
 		return
 	}
+	if op.leaderStatus.faultyPeers[msg.SenderIndex] {
+		op.log.Debugf("EventSignedHashMsg: peer #%d is excluded from the round", msg.SenderIndex)
+		return
+	}
 	if op.leaderStatus.signedResults[msg.SenderIndex] != nil {
 		// repeating message from peer
 		op.log.Debugf("EventSignedHashMsg: op.leaderStatus.signedResults[msg.SenderIndex].essenceHash != nil")
@@ -240,6 +277,9 @@ func (op *operator) EventSignedHashMsg(msg *committee.SignedHashMsg) {
 		essenceHash: msg.EssenceHash,
 		sigShare:    msg.SigShare,
 	}
+	if !op.verifySignedResult(msg.SenderIndex) {
+		return
+	}
 	op.takeAction()
 }
 
@@ -261,6 +301,10 @@ func (op *operator) EventNotifyFinalResultPostedMsg(msg *committee.NotifyFinalRe
 	}
 	op.setNextConsensusStage(consensusStageSubResultFinalized)
 	op.setFinalizedTransaction(&msg.TxId)
+	if op.sentResultToLeader != nil && op.sentResultBatch != nil && op.sentResultToLeaderIndex == msg.SenderIndex {
+		// own result is the best guess about the pending state
+		op.setPendingResult(msg.TxId, op.sentResultToLeader, op.sentResultBatch)
+	}
 }
 
 func (op *operator) EventTransactionInclusionLevelMsg(msg *committee.TransactionInclusionLevelMsg) {
@@ -291,4 +335,8 @@ func (op *operator) EventTimerMsg(msg committee.TimerTick) {
 	if msg%2 == 0 {
 		op.takeAction()
 	}
+	if msg%40 == 0 {
+		op.evictUnconfirmedRequests()
+	}
+	op.repeatGetProgramCode()
 }
@@ -16,6 +16,8 @@ func (op *operator) setFinalizedTransaction(txid *valuetransaction.ID) {
 		op.log.Warn("duplicated transaction to follow")
 	}
 	op.postedResultTxid = txid
+	op.savePostedResultTx(op.mustStateIndex(), txid)
+	op.statsResultPosted()
 	op.nextPullInclusionLevel = time.Now().Add(initialTimeoutPullInclusionState)
 	op.log.Debugf("finalized tx set to %s", txid.String())
 }
@@ -39,6 +41,7 @@ func (op *operator) checkInclusionLevel(txid *valuetransaction.ID, level byte) {
 		// TODO not clear what to do. Need proper specs from Goshimmer
 		op.log.Warnf("!!!!!!! received 'rejected' for transaction %s. Not clear what to do. Need proper specs from Goshimmer",
 			op.postedResultTxid.String())
+		op.discardPipelinedBatch("pending transaction rejected")
 	}
 }
 
@@ -24,7 +24,7 @@ func (op *operator) sendRequestNotificationsToLeader() {
 	}
 	currentLeaderPeerIndex, _ := op.currentLeader()
 	reqs := op.requestCandidateList()
-	reqs = op.filterOutRequestsWithoutTokens(reqs)
+	reqs = filterOutRequestsWithoutTokens(reqs, op.balances)
 
 	// get not time-locked requests with the message known
 	if len(reqs) == 0 {
@@ -0,0 +1,101 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCommittee implements methods of the committee used by tests. The rest panic
+type testCommittee struct {
+	committee.Committee
+	size       uint16
+	quorum     uint16
+	ownIndex   uint16
+	peerStatus []*committee.PeerStatus
+	// types of messages sent to peers, by peer index
+	sent map[uint16][]byte
+}
+
+func (c *testCommittee) Size() uint16 {
+	return c.size
+}
+
+func (c *testCommittee) Quorum() uint16 {
+	return c.quorum
+}
+
+func (c *testCommittee) OwnPeerIndex() uint16 {
+	return c.ownIndex
+}
+
+func (c *testCommittee) PeerStatus() []*committee.PeerStatus {
+	return c.peerStatus
+}
+
+func (c *testCommittee) IsAlivePeer(peerIndex uint16) bool {
+	return true
+}
+
+func (c *testCommittee) SendMsg(targetPeerIndex uint16, msgType byte, msgData []byte) error {
+	if c.sent == nil {
+		c.sent = make(map[uint16][]byte)
+	}
+	c.sent[targetPeerIndex] = append(c.sent[targetPeerIndex], msgType)
+	return nil
+}
+
+// testKeys implements methods of committee keys used by tests. The rest panic
+type testKeys struct {
+	tcrypto.CommitteeKeys
+	size     uint16
+	quorum   uint16
+	ownIndex uint16
+}
+
+func (k *testKeys) Size() uint16 {
+	return k.size
+}
+
+func (k *testKeys) Quorum() uint16 {
+	return k.quorum
+}
+
+func (k *testKeys) OwnIndex() uint16 {
+	return k.ownIndex
+}
+
+// newTestOperator creates the operator of the node #ownIndex in the committee of size nodes with the quorum.
+// The current state has the timestamp
+func newTestOperator(t *testing.T, size, quorum, ownIndex uint16, stateTimestamp int64) *operator {
+	addr := address.Random()
+	vs := state.NewVirtualState(mapdb.NewMapDB(), &addr)
+	vs.ApplyStateUpdate(state.NewStateUpdate(nil).WithTimestamp(stateTimestamp))
+	assert.Equal(t, stateTimestamp, vs.Timestamp())
+
+	return &operator{
+		committee: &testCommittee{
+			size:     size,
+			quorum:   quorum,
+			ownIndex: ownIndex,
+		},
+		keys: &testKeys{
+			size:     size,
+			quorum:   quorum,
+			ownIndex: ownIndex,
+		},
+		currentSCState:     vs,
+		peerPermutation:    util.NewPermutation16(size, nil),
+		peerClocks:         make([]*peerClock, size),
+		timestampTolerance: 3 * time.Second,
+		log:                logger.NewNopLogger(),
+	}
+}
@@ -0,0 +1,139 @@
+// the file contains functions which persist consensus progress in the smart contract partition of the database:
+// the backlog of not processed requests and the result transaction posted in the current round.
+// It allows the committee to resume where it left off after deactivation/activation or restart of the node
+package consensus
+
+import (
+	"bytes"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/database"
+)
+
+func dbkeyBacklogRequest(reqId *sctransaction.RequestId) []byte {
+	return database.MakeKey(database.ObjectTypeRequestBacklog, reqId[:])
+}
+
+func dbkeyPostedResultTx() []byte {
+	return database.MakeKey(database.ObjectTypePostedResultTx)
+}
+
+// saveBacklogRequest stores the transaction of the request which is not processed yet
+func (op *operator) saveBacklogRequest(req *request) {
+	db := database.GetPartition(op.committee.Address())
+	if err := db.Set(dbkeyBacklogRequest(&req.reqId), req.reqTx.Bytes()); err != nil {
+		op.log.Errorf("failed to save request %s to the backlog: %v", req.reqId.Short(), err)
+	}
+}
+
+func (op *operator) deleteBacklogRequest(reqId *sctransaction.RequestId) {
+	db := database.GetPartition(op.committee.Address())
+	if err := db.Delete(dbkeyBacklogRequest(reqId)); err != nil {
+		op.log.Errorf("failed to delete request %s from the backlog: %v", reqId.Short(), err)
+	}
+}
+
+// loadBacklog restores request backlog from the database. Requests processed meanwhile are deleted
+func (op *operator) loadBacklog() {
+	db := database.GetPartition(op.committee.Address())
+	toDelete := make([]sctransaction.RequestId, 0)
+	err := db.Iterate([]byte{database.ObjectTypeRequestBacklog}, func(key kvstore.Key, value kvstore.Value) bool {
+		var reqId sctransaction.RequestId
+		copy(reqId[:], key[1:])
+
+		if op.isRequestProcessed(&reqId) {
+			toDelete = append(toDelete, reqId)
+			return true
+		}
+		vtx, _, err := valuetransaction.FromBytes(value)
+		if err != nil {
+			op.log.Errorf("loadBacklog: can't parse transaction of the request %s: %v", reqId.Short(), err)
+			toDelete = append(toDelete, reqId)
+			return true
+		}
+		tx, err := sctransaction.ParseValueTransaction(vtx)
+		if err != nil || int(reqId.Index()) >= len(tx.Requests()) {
+			op.log.Errorf("loadBacklog: wrong transaction of the request %s: %v", reqId.Short(), err)
+			toDelete = append(toDelete, reqId)
+			return true
+		}
+		req := op.newRequest(reqId)
+		req.reqTx = tx
+		req.whenMsgReceived = time.Now()
+		req.notifications[op.peerIndex()] = true
+		op.requests[reqId] = req
+		op.addRequestIdConcurrent(&reqId)
+		return true
+	})
+	if err != nil {
+		op.log.Errorf("loadBacklog: %v", err)
+	}
+	for i := range toDelete {
+		op.deleteBacklogRequest(&toDelete[i])
+	}
+	if len(op.requests) > 0 {
+		op.log.Infof("%d requests restored to the backlog", len(op.requests))
+	}
+}
+
+// savePostedResultTx stores id of the result transaction posted on top of the state with the index
+func (op *operator) savePostedResultTx(stateIndex uint32, txid *valuetransaction.ID) {
+	var buf bytes.Buffer
+	_ = util.WriteUint32(&buf, stateIndex)
+	buf.Write(txid[:])
+	db := database.GetPartition(op.committee.Address())
+	if err := db.Set(dbkeyPostedResultTx(), buf.Bytes()); err != nil {
+		op.log.Errorf("failed to save posted result transaction: %v", err)
+	}
+}
+
+func (op *operator) deletePostedResultTx() {
+	db := database.GetPartition(op.committee.Address())
+	if err := db.Delete(dbkeyPostedResultTx()); err != nil {
+		op.log.Errorf("failed to delete posted result transaction: %v", err)
+	}
+}
+
+// loadPostedResultTx returns id of the result transaction posted before the restart
+// and the index of the state it was calculated on
+func (op *operator) loadPostedResultTx() (uint32, *valuetransaction.ID, bool) {
+	db := database.GetPartition(op.committee.Address())
+	data, err := db.Get(dbkeyPostedResultTx())
+	if err != nil || len(data) != 4+valuetransaction.IDLength {
+		return 0, nil, false
+	}
+	var txid valuetransaction.ID
+	copy(txid[:], data[4:])
+	return util.Uint32From4Bytes(data[:4]), &txid, true
+}
+
+// resumePostedResult is called upon the first synchronized state after the start of the operator.
+// If the result transaction posted before the restart was calculated on top of the current state,
+// it is followed until confirmed, the same way as before the restart, and no new batch is started.
+// Otherwise it was either confirmed or is outdated
+func (op *operator) resumePostedResult() {
+	if !op.resumePending || op.consensusStage == consensusStageNoSync {
+		return
+	}
+	op.resumePending = false
+
+	stateIndex, txid, ok := op.loadPostedResultTx()
+	if !ok {
+		return
+	}
+	if stateIndex != op.mustStateIndex() {
+		op.deletePostedResultTx()
+		return
+	}
+	op.log.Infof("resuming consensus round: following result transaction %s posted before the restart", txid.String())
+	if op.iAmCurrentLeader() {
+		op.setNextConsensusStage(consensusStageLeaderResultFinalized)
+	} else {
+		op.setNextConsensusStage(consensusStageSubResultFinalized)
+	}
+	op.setFinalizedTransaction(txid)
+}
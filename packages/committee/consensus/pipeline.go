@@ -0,0 +1,220 @@
+// the file contains functions responsible for pipelined batch processing:
+// the next leader starts calculating the next batch on top of the pending state, i.e. state of the batch
+// which was finalized and posted to the ledger but not confirmed yet.
+// The pipelined result is used only if the pending state is confirmed, otherwise it is discarded
+package consensus
+
+import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// maximum number of abandoned VM tasks remembered. Results of older ones are discarded by saveOwnResult
+const maxAbandonedTasks = 8
+
+type pendingResult struct {
+	txid     valuetransaction.ID
+	resultTx *sctransaction.Transaction
+	batch    state.Batch
+	// local time when the result became pending
+	since int64
+}
+
+type pipelinedBatch struct {
+	// id of the pending state transaction
+	baseTxId valuetransaction.ID
+	// hash of the pending state
+	baseStateHash hashing.HashValue
+	reqs          []*request
+	balances      map[valuetransaction.ID][]*balance.Balance
+	rewardAddress address.Address
+	timestamp     int64
+	task          *vm.VMTask
+	calculated    bool
+}
+
+func (op *operator) setPendingResult(txid valuetransaction.ID, resultTx *sctransaction.Transaction, batch state.Batch) {
+	if !op.pipeliningEnabled || batch == nil {
+		return
+	}
+	op.pendingResult = &pendingResult{
+		txid:     txid,
+		resultTx: resultTx,
+		batch:    batch,
+		since:    time.Now().UnixNano(),
+	}
+}
+
+func (op *operator) discardPipelinedBatch(reason string) {
+	op.pendingResult = nil
+	if op.pipelined == nil {
+		return
+	}
+	op.log.Infof("pipelined batch of %d requests discarded: %s", len(op.pipelined.reqs), reason)
+	op.dropPipelinedBatch()
+}
+
+// dropPipelinedBatch removes the pipelined batch. If its calculations are still running,
+// the VM task is remembered as abandoned, so its result is ignored when it comes
+func (op *operator) dropPipelinedBatch() {
+	p := op.pipelined
+	op.pipelined = nil
+	if p == nil || p.calculated {
+		return
+	}
+	op.abandonedTasks = append(op.abandonedTasks, p.task)
+	if len(op.abandonedTasks) > maxAbandonedTasks {
+		op.abandonedTasks = op.abandonedTasks[1:]
+	}
+}
+
+// takeAbandonedTask returns true and forgets the task if it is abandoned
+func (op *operator) takeAbandonedTask(task *vm.VMTask) bool {
+	for i, t := range op.abandonedTasks {
+		if t == task {
+			op.abandonedTasks = append(op.abandonedTasks[:i], op.abandonedTasks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// iAmNextLeader checks if the node will be the leader for the state approved by the transaction txid
+// Repeats the logic of resetLeader
+func (op *operator) iAmNextLeader(txid valuetransaction.ID) bool {
+	perm := util.NewPermutation16(op.size(), txid.Bytes())
+	for i := uint16(0); i < op.size(); i++ {
+		if op.committee.IsAlivePeer(perm.Current()) {
+			break
+		}
+		perm.Next()
+	}
+	return perm.Current() == op.committee.OwnPeerIndex()
+}
+
+// startPipelinedCalculations starts calculations of the next batch on top of the pending state
+// if the node will be the leader for the pending state
+func (op *operator) startPipelinedCalculations() {
+	if !op.pipeliningEnabled || op.pendingResult == nil || op.pipelined != nil {
+		return
+	}
+	if op.currentSCState == nil || op.balances == nil || !op.committee.HasQuorum() {
+		return
+	}
+	if !op.iAmNextLeader(op.pendingResult.txid) {
+		return
+	}
+	pendingState := op.currentSCState.Clone()
+	if err := pendingState.ApplyBatch(op.pendingResult.batch); err != nil {
+		op.log.Warnf("startPipelinedCalculations: %v", err)
+		op.pendingResult = nil
+		return
+	}
+	balances := util.BalancesAfterTransaction(op.balances, op.committee.Address(),
+		op.pendingResult.resultTx.Transaction, op.pendingResult.txid)
+
+	// requests of the pending batch do not have tokens in the pending balances, so they are not selected
+	reqs := op.selectRequestsWithBalances(balances)
+	if len(reqs) == 0 {
+		return
+	}
+	ts, ok := op.batchTimestamp(pendingState.Timestamp())
+	if !ok {
+		return
+	}
+	rewardAddress := op.getRewardAddress()
+	task := op.runCalculationsAsync(runCalculationsParams{
+		requests:        reqs,
+		leaderPeerIndex: op.committee.OwnPeerIndex(),
+		balances:        balances,
+		rewardAddress:   rewardAddress,
+		timestamp:       ts,
+		virtualState:    pendingState,
+		stateTxId:       op.pendingResult.txid,
+	})
+	if task == nil {
+		return
+	}
+	op.pipelined = &pipelinedBatch{
+		baseTxId:      op.pendingResult.txid,
+		baseStateHash: *pendingState.Hash(),
+		reqs:          reqs,
+		balances:      balances,
+		rewardAddress: rewardAddress,
+		timestamp:     ts,
+		task:          task,
+	}
+	op.log.Debugf("pipelined calculations started on top of pending state #%d, txid: %s, reqs: %+v",
+		pendingState.StateIndex(), op.pendingResult.txid.String(), idsShortStr(takeIds(reqs)))
+}
+
+// startPipelinedBatchAsLeader uses result of the pipelined batch if it is still valid in the current state:
+// it sends the batch to subordinates and proceeds with the own result without running the VM again
+// Returns false if the pipelined batch can't be used
+func (op *operator) startPipelinedBatchAsLeader() bool {
+	p := op.pipelined
+	if p == nil {
+		return false
+	}
+	op.dropPipelinedBatch()
+
+	if !p.calculated {
+		op.log.Debugf("pipelined batch not used: calculations not finished")
+		return false
+	}
+	if p.baseTxId != op.stateTx.ID() || p.baseStateHash != *op.currentSCState.Hash() {
+		op.log.Warnf("pipelined batch not used: calculated on top of different state")
+		return false
+	}
+	if time.Now().UnixNano()-p.timestamp > committee.MaxPipelinedBatchAge.Nanoseconds() {
+		op.log.Debugf("pipelined batch not used: timestamp is too old")
+		return false
+	}
+	reqIds := takeIds(p.reqs)
+	reqs := op.takeFromIds(reqIds)
+	if len(reqs) != len(reqIds) || len(op.filterNotReadyYet(reqs)) != len(reqIds) {
+		op.log.Debugf("pipelined batch not used: some requests are not ready")
+		return false
+	}
+	if !util.BalancesContained(p.balances, op.balances) {
+		op.log.Debugf("pipelined batch not used: outputs differ from the confirmed ones")
+		return false
+	}
+	msgData := util.MustBytes(&committee.StartProcessingBatchMsg{
+		PeerMsgHeader: committee.PeerMsgHeader{
+			StateIndex: op.mustStateIndex(),
+		},
+		RewardAddress: p.rewardAddress,
+		Balances:      p.balances,
+		RequestIds:    reqIds,
+		Pipelined:     true,
+	})
+	numSucc := op.committee.SendMsgToCommitteePeers(committee.MsgStartProcessingRequest, msgData, p.timestamp)
+	if numSucc < op.quorum()-1 {
+		op.log.Errorf("only %d 'msgStartProcessingRequest' sends of pipelined batch succeeded", numSucc)
+		return false
+	}
+	op.leaderStatus = &leaderStatus{
+		reqs:          p.reqs,
+		batchHash:     vm.BatchHash(reqIds, p.timestamp, op.peerIndex()),
+		balances:      p.balances,
+		timestamp:     p.timestamp,
+		signedResults: make([]*signedResult, op.committee.Size()),
+		faultyPeers:   make([]bool, op.committee.Size()),
+	}
+	op.statsBatchStarted(len(p.reqs))
+	op.log.Infof("pipelined batch started. State index: %d, reqs: %+v", op.mustStateIndex(), idsShortStr(reqIds))
+
+	op.setNextConsensusStage(consensusStageLeaderCalculationsStarted)
+	op.processResult(p.task)
+	return true
+}
@@ -0,0 +1,37 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropPipelinedBatch(t *testing.T) {
+	op := newTestOperator(t, 4, 3, 0, 0)
+
+	// result of the dropped pipelined batch is discarded when it comes
+	task := &vm.VMTask{}
+	op.pipelined = &pipelinedBatch{task: task}
+	op.discardPipelinedBatch("test")
+	assert.Nil(t, op.pipelined)
+	op.EventResultCalculated(task)
+	assert.Equal(t, 0, len(op.abandonedTasks))
+
+	// calculated pipelined batch is not remembered
+	op.pipelined = &pipelinedBatch{task: &vm.VMTask{}, calculated: true}
+	op.dropPipelinedBatch()
+	assert.Equal(t, 0, len(op.abandonedTasks))
+
+	// only last abandoned tasks are remembered
+	tasks := make([]*vm.VMTask, maxAbandonedTasks+1)
+	for i := range tasks {
+		tasks[i] = &vm.VMTask{}
+		op.pipelined = &pipelinedBatch{task: tasks[i]}
+		op.dropPipelinedBatch()
+	}
+	assert.Equal(t, maxAbandonedTasks, len(op.abandonedTasks))
+	assert.False(t, op.takeAbandonedTask(tasks[0]))
+	assert.True(t, op.takeAbandonedTask(tasks[maxAbandonedTasks]))
+	assert.False(t, op.takeAbandonedTask(tasks[maxAbandonedTasks]))
+}
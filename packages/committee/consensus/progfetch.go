@@ -0,0 +1,178 @@
+package consensus
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/processor"
+	"github.com/iotaledger/wasp/plugins/publisher"
+)
+
+// program code missing in the registry of the node is fetched from committee peers.
+// Peers are asked one by one until the code arrives. The code is checked against the program hash,
+// the metadata can't be checked, so it is saved only when F+1 peers sent the same metadata
+
+type programFetch struct {
+	progHash hashing.HashValue
+	// the peer asked last
+	peerIndex uint16
+	// when the request is repeated to the next peer
+	deadline time.Time
+	// encoded metadata received from peers, by peer index
+	metadata map[uint16][]byte
+}
+
+// loadProcessor starts loading of the processor or fetching of the program code if it is missing
+func (op *operator) loadProcessor(progHash *hashing.HashValue) {
+	available, err := isProgramAvailable(progHash)
+	if err != nil {
+		op.log.Errorf("failed to check program %s: %v", progHash.String(), err)
+		return
+	}
+	if !available {
+		op.startProgramFetch(progHash)
+		return
+	}
+	progHashStr := progHash.String()
+	processor.LoadProcessorAsync(progHash, func(err error) {
+		if err == nil {
+			op.committee.ReceiveMessage(committee.ProcessorIsReady{
+				ProgramHash: progHashStr,
+			})
+			publisher.Publish("vmready", op.committee.Address().String(), progHashStr)
+		} else {
+			op.log.Warnf("failed to load processor: %v", err)
+		}
+	})
+}
+
+// isProgramAvailable returns true if the program is builtin or its metadata and code are in the registry
+func isProgramAvailable(progHash *hashing.HashValue) (bool, error) {
+	if registry.IsBuiltinProgram(progHash) {
+		return true, nil
+	}
+	md, err := registry.GetProgramMetadata(progHash)
+	if err != nil || md == nil {
+		return false, err
+	}
+	codeHash, _, err := registry.GetProgramCodeHash(progHash)
+	if err != nil || codeHash == nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (op *operator) startProgramFetch(progHash *hashing.HashValue) {
+	if op.programFetch != nil && op.programFetch.progHash == *progHash {
+		// already fetching
+		return
+	}
+	op.log.Infof("code of the program %s is missing. Fetching it from committee peers", progHash.String())
+	op.programFetch = &programFetch{
+		progHash:  *progHash,
+		peerIndex: op.peerIndex(),
+		metadata:  make(map[uint16][]byte),
+	}
+	op.sendGetProgramCode()
+}
+
+// sendGetProgramCode sends the request for the program code to the next alive committee peer
+func (op *operator) sendGetProgramCode() {
+	op.programFetch.deadline = time.Now().Add(committee.RepeatGetProgramCodeAfter)
+	for i := uint16(1); i < op.size(); i++ {
+		peerIndex := (op.programFetch.peerIndex + i) % op.size()
+		if peerIndex == op.peerIndex() || !op.committee.IsAlivePeer(peerIndex) {
+			continue
+		}
+		op.programFetch.peerIndex = peerIndex
+		msgData := util.MustBytes(&committee.GetProgramCodeMsg{
+			ProgramHash: op.programFetch.progHash,
+		})
+		if err := op.committee.SendMsg(peerIndex, committee.MsgGetProgramCode, msgData); err != nil {
+			op.log.Warnf("failed to request program code from peer #%d: %v", peerIndex, err)
+			continue
+		}
+		op.log.Debugf("program code requested from peer #%d", peerIndex)
+		return
+	}
+	op.log.Debugf("no alive peers to request program code from")
+}
+
+// voteProgramMetadata records metadata of the program received from the peer.
+// Returns number of peers which sent the same metadata and number of peers required to agree: F+1,
+// where F is the number of faulty nodes tolerated by the committee
+func (op *operator) voteProgramMetadata(msg *committee.ProgramCodeMsg) (uint16, uint16) {
+	data := util.MustBytes(msg.Metadata)
+	op.programFetch.metadata[msg.SenderIndex] = data
+
+	numAgreed := uint16(0)
+	for _, d := range op.programFetch.metadata {
+		if bytes.Equal(d, data) {
+			numAgreed++
+		}
+	}
+	numRequired := op.size() - op.quorum() + 1
+	if numRequired > op.size()-1 {
+		numRequired = op.size() - 1
+	}
+	return numAgreed, numRequired
+}
+
+func (op *operator) repeatGetProgramCode() {
+	if op.programFetch == nil || time.Now().Before(op.programFetch.deadline) {
+		return
+	}
+	op.sendGetProgramCode()
+}
+
+// EventProgramCodeMsg is triggered by the program code received from the peer. The code is checked against
+// the program hash, saved in the registry and the processor is loaded
+func (op *operator) EventProgramCodeMsg(msg *committee.ProgramCodeMsg) {
+	if op.programFetch == nil || op.programFetch.progHash != msg.Metadata.ProgramHash {
+		op.log.Debugf("unexpected program code from peer #%d ignored", msg.SenderIndex)
+		return
+	}
+	if codeHash := hashing.HashData(msg.Code); *codeHash != msg.Metadata.ProgramHash {
+		op.log.Warnf("program code from peer #%d doesn't match the program hash %s",
+			msg.SenderIndex, msg.Metadata.ProgramHash.String())
+		return
+	}
+	if _, err := registry.SaveProgramCode(msg.Code); err != nil {
+		op.log.Errorf("failed to save program code: %v", err)
+		return
+	}
+	md, err := registry.GetProgramMetadata(&msg.Metadata.ProgramHash)
+	if err != nil {
+		op.log.Errorf("failed to load program metadata: %v", err)
+		return
+	}
+	if md == nil {
+		// the code is verified, the metadata is trusted only if enough peers agree on it
+		numAgreed, numRequired := op.voteProgramMetadata(msg)
+		if numAgreed < numRequired {
+			op.log.Infof("metadata of the program %s received from peer #%d. %d of %d required peers agree",
+				msg.Metadata.ProgramHash.String(), msg.SenderIndex, numAgreed, numRequired)
+			op.sendGetProgramCode()
+			return
+		}
+		if msg.Metadata.StateSchema != nil && msg.Metadata.StateSchema.Validate() != nil {
+			op.log.Warnf("invalid state schema of the program from peer #%d ignored", msg.SenderIndex)
+			msg.Metadata.StateSchema = nil
+		}
+		if err := msg.Metadata.Save(); err != nil {
+			op.log.Errorf("failed to save program metadata: %v", err)
+			return
+		}
+	}
+	op.log.Infof("code of the program %s received from peer #%d", msg.Metadata.ProgramHash.String(), msg.SenderIndex)
+	op.programFetch = nil
+
+	progHash, ok := op.getProgramHash()
+	if ok && *progHash == msg.Metadata.ProgramHash && !op.processorReady {
+		op.loadProcessor(progHash)
+	}
+}
@@ -0,0 +1,83 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	database.InitMemDB()
+}
+
+func programCodeMsg(sender uint16, code []byte, progHash *hashing.HashValue, description string) *committee.ProgramCodeMsg {
+	return &committee.ProgramCodeMsg{
+		PeerMsgHeader: committee.PeerMsgHeader{SenderIndex: sender},
+		Metadata: &registry.ProgramMetadata{
+			ProgramHash: *progHash,
+			VMType:      "wasmtimevm",
+			Description: description,
+		},
+		Code: code,
+	}
+}
+
+// assertProgramNotSaved checks that the program is not available in the registry of the node
+func assertProgramNotSaved(t *testing.T, progHash *hashing.HashValue) {
+	md, err := registry.GetProgramMetadata(progHash)
+	assert.NoError(t, err)
+	assert.Nil(t, md)
+	available, err := isProgramAvailable(progHash)
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+func TestProgramFetch(t *testing.T) {
+	// F = 1, metadata is saved when 2 peers agree
+	op := newTestOperator(t, 4, 3, 0, 0)
+	code := []byte("program code")
+	progHash := hashing.HashData(code)
+
+	op.startProgramFetch(progHash)
+	assert.Equal(t, []byte{committee.MsgGetProgramCode}, op.committee.(*testCommittee).sent[1])
+
+	// the code which doesn't match the program hash is not saved
+	op.EventProgramCodeMsg(programCodeMsg(1, []byte("other code"), progHash, "the program"))
+	assertProgramNotSaved(t, progHash)
+	codeHash, _, err := registry.GetProgramCodeHash(progHash)
+	assert.NoError(t, err)
+	assert.Nil(t, codeHash)
+	assert.Equal(t, 0, len(op.programFetch.metadata))
+
+	// the correct code is saved, metadata of one peer isn't trusted. The next peer is asked
+	op.EventProgramCodeMsg(programCodeMsg(1, code, progHash, "forged"))
+	assertProgramNotSaved(t, progHash)
+	assert.Equal(t, []byte{committee.MsgGetProgramCode}, op.committee.(*testCommittee).sent[2])
+	// the same peer repeating its metadata is still one vote
+	op.EventProgramCodeMsg(programCodeMsg(1, code, progHash, "forged"))
+	assertProgramNotSaved(t, progHash)
+	// the vote of the peer which sent the wrong code doesn't count
+	op.EventProgramCodeMsg(programCodeMsg(2, []byte("other code"), progHash, "forged"))
+	assertProgramNotSaved(t, progHash)
+
+	// different metadata don't add up
+	op.EventProgramCodeMsg(programCodeMsg(2, code, progHash, "the program"))
+	assertProgramNotSaved(t, progHash)
+	assert.NotNil(t, op.programFetch)
+
+	// F+1 peers agree
+	op.EventProgramCodeMsg(programCodeMsg(3, code, progHash, "the program"))
+	md, err := registry.GetProgramMetadata(progHash)
+	assert.NoError(t, err)
+	if assert.NotNil(t, md) {
+		assert.Equal(t, "the program", md.Description)
+	}
+	saved, err := registry.GetProgramCode(progHash)
+	assert.NoError(t, err)
+	assert.Equal(t, code, saved)
+	assert.Nil(t, op.programFetch)
+}
@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"fmt"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/committee"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
@@ -10,6 +11,16 @@ import (
 	"time"
 )
 
+const (
+	// maximum number of not confirmed requests in the backlog
+	maxUnconfirmedRequests = 1000
+	// maximum number of not confirmed requests from one sender address in the backlog.
+	// One sender can't take all the room for not confirmed requests
+	maxUnconfirmedRequestsPerSender = 50
+	// not confirmed requests are evicted from the backlog after the timeout
+	unconfirmedRequestTimeout = 5 * time.Minute
+)
+
 func (op *operator) newRequest(reqId sctransaction.RequestId) *request {
 	reqLog := op.log.Named(reqId.Short())
 	ret := &request{
@@ -43,23 +54,41 @@ func (op *operator) requestFromMsg(reqMsg *committee.RequestMsg) (*request, bool
 	}
 	ret, ok := op.requests[*reqId]
 	msgFirstTime := !ok || ret.reqTx == nil
+	if msgFirstTime && reqMsg.Unconfirmed {
+		if err := op.checkUnconfirmedLimits(reqMsg.Transaction.Sender()); err != nil {
+			op.log.Warnf("not confirmed request %s rejected: %v", reqId.Short(), err)
+			return nil, false
+		}
+	}
 
 	publish := false
 	if ok {
 		if msgFirstTime {
 			ret.reqTx = reqMsg.Transaction
 			ret.whenMsgReceived = time.Now()
+			ret.unconfirmed = reqMsg.Unconfirmed
+			ret.sender = *reqMsg.Transaction.Sender()
 			publish = true
 		}
 	} else {
 		ret = op.newRequest(*reqId)
 		ret.whenMsgReceived = time.Now()
 		ret.reqTx = reqMsg.Transaction
+		ret.unconfirmed = reqMsg.Unconfirmed
+		ret.sender = *reqMsg.Transaction.Sender()
 		op.requests[*reqId] = ret
 		op.addRequestIdConcurrent(reqId)
 		publish = true
 	}
+	if ret.unconfirmed && !reqMsg.Unconfirmed {
+		// the ledger confirmed the transaction of the submitted request
+		ret.unconfirmed = false
+		op.saveBacklogRequest(ret)
+	}
 	if publish {
+		if !ret.unconfirmed {
+			op.saveBacklogRequest(ret)
+		}
 		publisher.Publish("request_in",
 			op.committee.Address().String(),
 			reqMsg.Transaction.ID().String(),
@@ -78,6 +107,41 @@ func (op *operator) requestFromMsg(reqMsg *committee.RequestMsg) (*request, bool
 	return ret, msgFirstTime
 }
 
+// checkUnconfirmedLimits checks if one more not confirmed request of the sender fits into the backlog
+func (op *operator) checkUnconfirmedLimits(sender *address.Address) error {
+	total, fromSender := 0, 0
+	for _, req := range op.requests {
+		if !req.unconfirmed {
+			continue
+		}
+		total++
+		if req.sender == *sender {
+			fromSender++
+		}
+	}
+	if total >= maxUnconfirmedRequests {
+		return fmt.Errorf("too many not confirmed requests")
+	}
+	if fromSender >= maxUnconfirmedRequestsPerSender {
+		return fmt.Errorf("too many not confirmed requests from %s", sender.String())
+	}
+	return nil
+}
+
+// evictUnconfirmedRequests removes from the backlog submitted requests which were not confirmed by the ledger in time
+func (op *operator) evictUnconfirmedRequests() {
+	nowis := time.Now()
+	for reqId, req := range op.requests {
+		if !req.unconfirmed || nowis.Sub(req.whenMsgReceived) < unconfirmedRequestTimeout {
+			continue
+		}
+		delete(op.requests, reqId)
+		rid := reqId
+		op.removeRequestIdConcurrent(&rid)
+		op.log.Debugf("removed from backlog: request %s was not confirmed in time", reqId.Short())
+	}
+}
+
 func (req *request) requestCode() sctransaction.RequestCode {
 	return req.reqTx.Requests()[req.reqId.Index()].RequestCode()
 }
@@ -115,6 +179,7 @@ func (op *operator) deleteCompletedRequests() error {
 	for _, rid := range toDelete {
 		delete(op.requests, *rid)
 		op.removeRequestIdConcurrent(rid)
+		op.deleteBacklogRequest(rid)
 		op.log.Debugf("removed from backlog: processed request %s", rid.String())
 	}
 	return nil
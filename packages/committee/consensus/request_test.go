@@ -0,0 +1,50 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// addUnconfirmed puts num not confirmed requests of the sender to the backlog
+func addUnconfirmed(op *operator, sender address.Address, num int) {
+	for i := 0; i < num; i++ {
+		reqId := sctransaction.NewRequestId(valuetransaction.RandomID(), 0)
+		op.requests[reqId] = &request{
+			reqId:       reqId,
+			unconfirmed: true,
+			sender:      sender,
+		}
+	}
+}
+
+func TestCheckUnconfirmedLimits(t *testing.T) {
+	op := newTestOperator(t, 4, 3, 0, 0)
+	op.requests = make(map[sctransaction.RequestId]*request)
+	spammer := address.Random()
+	other := address.Random()
+
+	assert.NoError(t, op.checkUnconfirmedLimits(&spammer))
+	addUnconfirmed(op, spammer, maxUnconfirmedRequestsPerSender-1)
+	assert.NoError(t, op.checkUnconfirmedLimits(&spammer))
+
+	// the sender reached its limit, others still can submit
+	addUnconfirmed(op, spammer, 1)
+	assert.Error(t, op.checkUnconfirmedLimits(&spammer))
+	assert.NoError(t, op.checkUnconfirmedLimits(&other))
+
+	// confirmed requests don't count
+	for _, req := range op.requests {
+		req.unconfirmed = false
+	}
+	assert.NoError(t, op.checkUnconfirmedLimits(&spammer))
+
+	// the total limit
+	for len(op.requests) < maxUnconfirmedRequests+maxUnconfirmedRequestsPerSender {
+		addUnconfirmed(op, address.Random(), maxUnconfirmedRequestsPerSender-1)
+	}
+	assert.Error(t, op.checkUnconfirmedLimits(&other))
+}
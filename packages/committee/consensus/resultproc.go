@@ -8,8 +8,12 @@ import (
 	"github.com/iotaledger/wasp/packages/committee"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/publisher"
 	"github.com/iotaledger/wasp/plugins/runvm"
 )
 
@@ -19,13 +23,17 @@ type runCalculationsParams struct {
 	balances        map[valuetransaction.ID][]*balance.Balance
 	rewardAddress   address.Address
 	timestamp       int64
+	// state on top of which the batch is calculated and id of the transaction which approves it
+	virtualState state.VirtualState
+	stateTxId    valuetransaction.ID
 }
 
 // runs the VM for requests and posts result to committee's queue
-func (op *operator) runCalculationsAsync(par runCalculationsParams) {
-	if op.currentSCState == nil {
+// returns the VM task or nil if calculations were not started
+func (op *operator) runCalculationsAsync(par runCalculationsParams) *vm.VMTask {
+	if par.virtualState == nil {
 		op.log.Debugf("runCalculationsAsync: variable currentSCState is not known")
-		return
+		return nil
 	}
 	var progHash hashing.HashValue
 	if ph, ok := op.getProgramHash(); ok {
@@ -37,14 +45,14 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 		ProgramHash:     progHash,
 		Address:         *op.committee.Address(),
 		Color:           *op.committee.Color(),
-		Entropy:         (hashing.HashValue)(op.stateTx.ID()),
+		Entropy:         (hashing.HashValue)(par.stateTxId),
 		Balances:        par.balances,
 		OwnerAddress:    *op.committee.OwnerAddress(),
 		RewardAddress:   par.rewardAddress,
-		MinimumReward:   op.getMinimumReward(),
+		MinimumReward:   minimumReward(par.virtualState),
 		Requests:        takeRefs(par.requests),
 		Timestamp:       par.timestamp,
-		VirtualState:    op.currentSCState,
+		VirtualState:    par.virtualState,
 		Log:             op.log,
 	}
 	ctx.OnFinish = func(err error) {
@@ -56,7 +64,31 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 	}
 	if err := runvm.RunComputationsAsync(ctx); err != nil {
 		op.log.Errorf("RunComputationsAsync: %v", err)
+		return nil
+	}
+	for _, req := range par.requests {
+		publisher.Publish("request_processing",
+			op.committee.Address().String(),
+			req.reqId.TransactionId().String(),
+			fmt.Sprintf("%d", req.reqId.Index()),
+		)
 	}
+	return ctx
+}
+
+// signResultAsync signs essence of the result transaction with the own signature share
+// and posts the signature share to the committee's queue
+func (op *operator) signResultAsync(result *vm.VMTask) {
+	op.signingTask = result
+	addr := op.keys.KeyAddress()
+	go func() {
+		sigShare, err := peering.NodeSigner().SignShare(addr, result.ResultTransaction.EssenceBytes())
+		op.committee.ReceiveMessage(&committee.ResultSignedMsg{
+			Task:     result,
+			SigShare: sigShare,
+			Err:      err,
+		})
+	}()
 }
 
 func (op *operator) sendResultToTheLeader(result *vm.VMTask) {
@@ -66,13 +98,15 @@ func (op *operator) sendResultToTheLeader(result *vm.VMTask) {
 			stages[consensusStageSubCalculationsStarted].name, stages[op.consensusStage].name)
 		return
 	}
+	op.signResultAsync(result)
+}
 
-	sigShare, err := op.dkshare.SignShare(result.ResultTransaction.EssenceBytes())
-	if err != nil {
-		op.log.Errorf("error while signing transaction %v", err)
+func (op *operator) sendSignedResultToTheLeader(result *vm.VMTask, sigShare tbdn.SigShare) {
+	if op.consensusStage != consensusStageSubCalculationsStarted {
+		op.log.Debugf("signed result on SUB dismissed because stage changed from '%s' to '%s'",
+			stages[consensusStageSubCalculationsStarted].name, stages[op.consensusStage].name)
 		return
 	}
-
 	reqids := make([]sctransaction.RequestId, len(result.Requests))
 	for i := range reqids {
 		reqids[i] = *result.Requests[i].RequestId()
@@ -104,38 +138,48 @@ func (op *operator) sendResultToTheLeader(result *vm.VMTask) {
 	}
 	op.sentResultToLeader = result.ResultTransaction
 	op.sentResultToLeaderIndex = result.LeaderPeerIndex
+	op.sentResultBatch = result.ResultBatch
 
 	op.setNextConsensusStage(consensusStageSubCalculationsFinished)
 }
 
 func (op *operator) saveOwnResult(result *vm.VMTask) {
-	if op.consensusStage != consensusStageLeaderCalculationsStarted {
-		op.log.Debugf("calculation result on LEADER dismissed because stage changed from '%s' to '%s'",
-			stages[consensusStageLeaderCalculationsStarted].name, stages[op.consensusStage].name)
+	if !op.isOwnResultCurrent(result) {
 		return
 	}
-	sigShare, err := op.dkshare.SignShare(result.ResultTransaction.EssenceBytes())
-	if err != nil {
-		op.log.Errorf("error while signing transaction %v", err)
-		return
+	if len(result.Requests) != int(result.ResultBatch.Size()) {
+		panic("len(result.RequestIds) != int(result.ResultBatch.Size())")
 	}
+	op.signResultAsync(result)
+}
 
+// isOwnResultCurrent checks if the result calculated by the leader belongs to the current batch
+func (op *operator) isOwnResultCurrent(result *vm.VMTask) bool {
+	if op.consensusStage != consensusStageLeaderCalculationsStarted {
+		op.log.Debugf("calculation result on LEADER dismissed because stage changed from '%s' to '%s'",
+			stages[consensusStageLeaderCalculationsStarted].name, stages[op.consensusStage].name)
+		return false
+	}
 	reqids := make([]sctransaction.RequestId, len(result.Requests))
 	for i := range reqids {
 		reqids[i] = *result.Requests[i].RequestId()
 	}
-
 	bh := vm.BatchHash(reqids, result.Timestamp, result.LeaderPeerIndex)
-	if bh != op.leaderStatus.batchHash {
-		panic("bh != op.leaderStatus.batchHash")
-	}
-	if len(result.Requests) != int(result.ResultBatch.Size()) {
-		panic("len(result.RequestIds) != int(result.ResultBatch.Size())")
+	if op.leaderStatus == nil || bh != op.leaderStatus.batchHash {
+		// stale result of another batch, for example of the dropped pipelined one
+		op.log.Warnf("calculation result on LEADER dismissed: batch hash %s doesn't match the current batch", bh.String())
+		return false
 	}
+	return true
+}
 
+func (op *operator) saveOwnSignedResult(result *vm.VMTask, sigShare tbdn.SigShare) {
+	if !op.isOwnResultCurrent(result) {
+		return
+	}
 	essenceHash := hashing.HashData(result.ResultTransaction.EssenceBytes())
 	op.log.Debugw("saveOwnResult",
-		"batchHash", bh.String(),
+		"batchHash", op.leaderStatus.batchHash.String(),
 		"ts", result.Timestamp,
 		"essenceHash", essenceHash.String(),
 	)
@@ -152,7 +196,7 @@ func (op *operator) saveOwnResult(result *vm.VMTask) {
 func (op *operator) aggregateSigShares(sigShares [][]byte) error {
 	resTx := op.leaderStatus.resultTx
 
-	finalSignature, err := op.dkshare.RecoverFullSignature(sigShares, resTx.EssenceBytes())
+	finalSignature, err := op.keys.RecoverFullSignature(sigShares, resTx.EssenceBytes())
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
 	"sort"
 	"time"
 )
@@ -16,17 +17,24 @@ import (
 // 3. selects maximum possible set of those which were seen by same quorum of peers
 // only requests in "full batches" are selected, it means request is in the selection together with ALL other requests
 // from the same request transaction, or it is not selected
+// 4. limits the selection by the maximum batch size and by the VM run time budget
 func (op *operator) selectRequestsToProcess() []*request {
+	return op.selectRequestsWithBalances(op.balances)
+}
+
+// selectRequestsWithBalances selects requests which have request tokens in the provided balances.
+// The balances may be different from the current ones when the batch is pipelined
+func (op *operator) selectRequestsWithBalances(balances map[valuetransaction.ID][]*balance.Balance) []*request {
 	candidates := op.requestCandidateList()
 	if len(candidates) == 0 {
 		return nil
 	}
 	before := takeIds(candidates)
-	candidates = op.filterOutRequestsWithoutTokens(candidates)
+	candidates = filterOutRequestsWithoutTokens(candidates, balances)
 	after := takeIds(candidates)
 	if len(before) != len(after) {
 		op.log.Warnf("filtered out requests without tokens: %+v -> %+v", idsShortStr(before), idsShortStr(after))
-		op.log.Debugf("\nbalances dumped: %s\n", util.BalancesToString(op.balances))
+		op.log.Debugf("\nbalances dumped: %s\n", util.BalancesToString(balances))
 	}
 	if len(candidates) == 0 {
 		return nil
@@ -54,9 +62,72 @@ func (op *operator) selectRequestsToProcess() []*request {
 	if len(ret) == 0 {
 		return nil
 	}
+	return op.limitBatchSize(ret)
+}
+
+// limitBatchSize truncates the selection to the maximum batch size and to the number of requests
+// which are expected to fit into the VM run time budget. The expected VM run time is estimated from previous batches.
+// Requests of one request transaction are never split between batches: the selection is cut only between
+// request transactions, in the order of arrival. All requests of the first request transaction are always selected
+func (op *operator) limitBatchSize(reqs []*request) []*request {
+	limit := len(reqs)
+	if op.maxBatchSize > 0 && op.maxBatchSize < limit {
+		limit = op.maxBatchSize
+	}
+	if op.maxBatchVMTime > 0 && op.vmTimePerRequest > 0 {
+		if byTime := int(op.maxBatchVMTime / op.vmTimePerRequest); byTime < limit {
+			limit = byTime
+		}
+	}
+	if limit >= len(reqs) {
+		return reqs
+	}
+	// number of selected requests of each request transaction, in the order of arrival
+	numReqs := make(map[valuetransaction.ID]int)
+	txids := make([]valuetransaction.ID, 0)
+	for _, req := range reqs {
+		txid := *req.reqId.TransactionId()
+		if _, ok := numReqs[txid]; !ok {
+			txids = append(txids, txid)
+		}
+		numReqs[txid]++
+	}
+	included := make(map[valuetransaction.ID]bool)
+	num := 0
+	for i, txid := range txids {
+		if i > 0 && num+numReqs[txid] > limit {
+			break
+		}
+		included[txid] = true
+		num += numReqs[txid]
+	}
+	if num == len(reqs) {
+		return reqs
+	}
+	op.log.Debugf("batch limited to %d requests out of %d selected. Limit: %d, estimated VM time per request: %v",
+		num, len(reqs), limit, op.vmTimePerRequest)
+	ret := make([]*request, 0, num)
+	for _, req := range reqs {
+		if included[*req.reqId.TransactionId()] {
+			ret = append(ret, req)
+		}
+	}
 	return ret
 }
 
+// updateVMTimeEstimate updates moving average of the VM run time per request
+func (op *operator) updateVMTimeEstimate(task *vm.VMTask) {
+	if len(task.Requests) == 0 || task.RunTime <= 0 {
+		return
+	}
+	sample := task.RunTime / time.Duration(len(task.Requests))
+	if op.vmTimePerRequest == 0 {
+		op.vmTimePerRequest = sample
+		return
+	}
+	op.vmTimePerRequest = (3*op.vmTimePerRequest + sample) / 4
+}
+
 // all requests from the backlog which has known messages and are not timelocked
 // sort by arrival time
 func (op *operator) requestCandidateList() []*request {
@@ -141,11 +212,11 @@ func (op *operator) filterNotReadyYet(reqs []*request) []*request {
 
 // filterOutRequestsWithoutTokens leaves only those first requests
 // which has corresponding request tokens.
-func (op *operator) filterOutRequestsWithoutTokens(reqs []*request) []*request {
-	if op.balances == nil {
+func filterOutRequestsWithoutTokens(reqs []*request, balances map[valuetransaction.ID][]*balance.Balance) []*request {
+	if balances == nil {
 		return nil
 	}
-	byColor, _ := util.BalancesByColor(op.balances)
+	byColor, _ := util.BalancesByColor(balances)
 	ret := reqs[:0]
 	for _, req := range reqs {
 		col := (balance.Color)(*req.reqId.TransactionId())
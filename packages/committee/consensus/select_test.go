@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRequests returns requests of request transactions, in the order of transactions.
+// numReqs is the number of requests of each transaction
+func newTestRequests(numReqs ...int) []*request {
+	ret := make([]*request, 0)
+	for i, n := range numReqs {
+		txid := (valuetransaction.ID)(*hashing.HashData([]byte{byte(i)}))
+		for j := 0; j < n; j++ {
+			ret = append(ret, &request{reqId: sctransaction.NewRequestId(txid, uint16(j))})
+		}
+	}
+	return ret
+}
+
+func TestLimitBatchSize(t *testing.T) {
+	op := newTestOperator(t, 4, 3, 0, 0)
+	reqs := newTestRequests(3, 2, 1)
+
+	op.maxBatchSize = 0
+	assert.Equal(t, reqs, op.limitBatchSize(reqs))
+	op.maxBatchSize = 6
+	assert.Equal(t, reqs, op.limitBatchSize(reqs))
+
+	// requests of one transaction are not split
+	op.maxBatchSize = 4
+	assert.Equal(t, reqs[:3], op.limitBatchSize(reqs))
+	op.maxBatchSize = 5
+	assert.Equal(t, reqs[:5], op.limitBatchSize(reqs))
+
+	// the first transaction is taken whole even over the limit
+	op.maxBatchSize = 2
+	assert.Equal(t, reqs[:3], op.limitBatchSize(reqs))
+
+	// limit by the VM run time budget
+	op.maxBatchSize = 0
+	op.maxBatchVMTime = 5 * time.Second
+	op.vmTimePerRequest = time.Second
+	assert.Equal(t, reqs[:5], op.limitBatchSize(reqs))
+	op.vmTimePerRequest = 10 * time.Second
+	assert.Equal(t, reqs[:3], op.limitBatchSize(reqs))
+}
+
+func TestLimitBatchSizeInterleaved(t *testing.T) {
+	op := newTestOperator(t, 4, 3, 0, 0)
+	reqs := newTestRequests(2, 2)
+	// requests of transactions arrived interleaved: A0 B0 A1 B1
+	reqs[1], reqs[2] = reqs[2], reqs[1]
+
+	op.maxBatchSize = 3
+	ret := op.limitBatchSize(reqs)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, *reqs[0].reqId.TransactionId(), *ret[0].reqId.TransactionId())
+	assert.Equal(t, *reqs[0].reqId.TransactionId(), *ret[1].reqId.TransactionId())
+}
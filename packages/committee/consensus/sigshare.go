@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/wasp/plugins/publisher"
+)
+
+// verifySignedResult checks signature share of the peer against its public key share.
+// Only checked when leader has own result and the essence hash of the peer is the same.
+// Returns false if the peer was found faulty
+func (op *operator) verifySignedResult(peerIndex uint16) bool {
+	res := op.leaderStatus.signedResults[peerIndex]
+	if res == nil || res.verified || op.leaderStatus.resultTx == nil {
+		return true
+	}
+	ownResult := op.leaderStatus.signedResults[op.committee.OwnPeerIndex()]
+	if ownResult == nil || res.essenceHash != ownResult.essenceHash {
+		return true
+	}
+	err := op.keys.VerifySigShareOfPeer(peerIndex, op.leaderStatus.resultTx.EssenceBytes(), res.sigShare)
+	if err == nil {
+		res.verified = true
+		return true
+	}
+	op.markFaultyPeer(peerIndex, err)
+	return false
+}
+
+// markFaultyPeer excludes the peer from the current round, counts the fault and publishes it
+func (op *operator) markFaultyPeer(peerIndex uint16, err error) {
+	op.log.Warnf("invalid signature share from peer #%d: %v. The peer is excluded from the round", peerIndex, err)
+
+	op.leaderStatus.signedResults[peerIndex] = nil
+	op.leaderStatus.faultyPeers[peerIndex] = true
+	op.committee.RecordSigShareFault(peerIndex)
+	op.statsSigShareFault(peerIndex)
+
+	publisher.Publish("sigshare_fault",
+		op.committee.Address().String(),
+		fmt.Sprintf("%d", op.mustStateIndex()),
+		fmt.Sprintf("%d", peerIndex),
+	)
+}
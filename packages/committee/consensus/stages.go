@@ -62,6 +62,7 @@ var stages = map[int]*stageParams{
 			consensusStageLeaderStarting,
 			consensusStageSubStarting,
 			consensusStageLeaderCalculationsStarted,
+			consensusStageLeaderResultFinalized,
 		},
 	},
 	// unlimited time for VM TODO VM timeouts/gas budget to prevent loops
@@ -184,6 +185,7 @@ func (op *operator) setNextConsensusStage(nextStage int) {
 	}
 	saveStage := op.consensusStage
 	op.consensusStage = nextStage
+	op.statsStage(nextStageParams.name)
 	op.consensusStageDeadline = time.Now().Add(nextStageParams.timeout)
 	timeout := "timeout: not set"
 	if nextStageParams.timeoutSet {
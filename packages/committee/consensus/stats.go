@@ -0,0 +1,167 @@
+// the file contains recording of consensus round statistics for observability:
+// last rounds are kept in the ring buffer and cumulative metrics are updated along
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/committee"
+)
+
+const (
+	// number of last consensus rounds kept in the operator
+	roundHistorySize = 32
+	// maximum number of stage transitions recorded for one round
+	maxStagesPerRound = 64
+)
+
+type roundStats struct {
+	sync.RWMutex
+	metrics committee.ConsensusMetrics
+	// ring buffer of rounds. next is position of the next round
+	rounds  [roundHistorySize]*committee.ConsensusRound
+	next    int
+	current *committee.ConsensusRound
+}
+
+// statsNewState starts a new round if the state index changed.
+// The previous round is confirmed if its result was posted and the state index moved forward
+func (op *operator) statsNewState(stateIndex uint32, leader uint16) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.StateIndex = stateIndex
+	if s.current != nil {
+		if s.current.StateIndex == stateIndex {
+			s.current.Leader = leader
+			return
+		}
+		if !s.current.Posted.IsZero() && stateIndex == s.current.StateIndex+1 {
+			s.current.Confirmed = true
+			s.current.InclusionLatency = time.Since(s.current.Posted)
+			s.metrics.ResultsConfirmed++
+			s.metrics.LastInclusionLatency = s.current.InclusionLatency
+		}
+	}
+	s.current = &committee.ConsensusRound{
+		StateIndex: stateIndex,
+		Started:    time.Now(),
+		Leader:     leader,
+		Stages:     make([]committee.ConsensusStageTransition, 0),
+	}
+	s.rounds[s.next] = s.current
+	s.next = (s.next + 1) % roundHistorySize
+	s.metrics.Rounds++
+}
+
+func (op *operator) statsStage(stage string) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.Stage = stage
+	if s.current == nil || len(s.current.Stages) >= maxStagesPerRound {
+		return
+	}
+	s.current.Stages = append(s.current.Stages, committee.ConsensusStageTransition{
+		Stage: stage,
+		Time:  time.Now(),
+	})
+}
+
+func (op *operator) statsLeaderRotated(leader uint16) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.LeaderRotations++
+	if s.current == nil {
+		return
+	}
+	s.current.Leader = leader
+	s.current.LeaderChanges++
+}
+
+func (op *operator) statsBatchStarted(batchSize int) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.BatchesStarted++
+	s.metrics.RequestsInBatches += uint64(batchSize)
+	if s.current != nil {
+		s.current.BatchSize = batchSize
+	}
+}
+
+func (op *operator) statsVMRunTime(d time.Duration) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.VMRunTime += d
+	if s.current != nil {
+		s.current.VMRunTime += d
+	}
+}
+
+func (op *operator) statsSignaturesCollected(n int) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	if s.current != nil {
+		s.current.SignaturesCollected = n
+	}
+}
+
+func (op *operator) statsSigShareFault(peerIndex uint16) {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.SigShareFaults++
+	if s.current != nil {
+		s.current.FaultyPeers = append(s.current.FaultyPeers, peerIndex)
+	}
+}
+
+func (op *operator) statsResultPosted() {
+	s := &op.stats
+	s.Lock()
+	defer s.Unlock()
+
+	s.metrics.ResultsPosted++
+	if s.current != nil && s.current.Posted.IsZero() {
+		s.current.Posted = time.Now()
+	}
+}
+
+// ConsensusStats returns copy of the metrics and of the recent rounds. Used by APIs
+func (op *operator) ConsensusStats() *committee.ConsensusStats {
+	s := &op.stats
+	s.RLock()
+	defer s.RUnlock()
+
+	ret := &committee.ConsensusStats{
+		Metrics: s.metrics,
+		Rounds:  make([]*committee.ConsensusRound, 0, roundHistorySize),
+	}
+	for i := 0; i < roundHistorySize; i++ {
+		r := s.rounds[(s.next+i)%roundHistorySize]
+		if r == nil {
+			continue
+		}
+		rcopy := *r
+		rcopy.Stages = make([]committee.ConsensusStageTransition, len(r.Stages))
+		copy(rcopy.Stages, r.Stages)
+		if r.FaultyPeers != nil {
+			rcopy.FaultyPeers = make([]uint16, len(r.FaultyPeers))
+			copy(rcopy.FaultyPeers, r.FaultyPeers)
+		}
+		ret.Rounds = append(ret.Rounds, &rcopy)
+	}
+	return ret
+}
@@ -0,0 +1,154 @@
+// the file contains functions responsible for the consensus on the batch timestamp:
+// peers include their local time in request notifications to the leader,
+// the leader takes median of the clocks of the quorum of peers as the timestamp of the batch
+// and peers refuse to process the batch if the timestamp is outside the tolerance of the local clock
+// or of the median of clocks of peers, measured by heartbeats of peering connections
+package consensus
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/committee"
+)
+
+// clock reading of the peer taken from the request notification
+type peerClock struct {
+	// state index of the notification
+	stateIndex uint32
+	// peer's clock minus local clock at the moment the notification was received
+	offset int64
+}
+
+// storePeerClock saves clock offset of the peer who sent the notification
+func (op *operator) storePeerClock(msg *committee.NotifyReqMsg) {
+	if msg.Timestamp == 0 || int(msg.SenderIndex) >= len(op.peerClocks) {
+		return
+	}
+	op.peerClocks[msg.SenderIndex] = &peerClock{
+		stateIndex: msg.StateIndex,
+		offset:     msg.Timestamp - time.Now().UnixNano(),
+	}
+}
+
+// medianTimestamp calculates the timestamp for the batch as a median of current clocks of peers,
+// notified the leader in the current state, including the own clock.
+// Returns false if less than quorum of clocks are known
+func (op *operator) medianTimestamp() (int64, bool) {
+	stateIndex, ok := op.stateIndex()
+	if !ok {
+		return 0, false
+	}
+	offsets := make([]int64, 0, op.size())
+	for i, pc := range op.peerClocks {
+		switch {
+		case uint16(i) == op.peerIndex():
+			offsets = append(offsets, 0)
+		case pc != nil && pc.stateIndex == stateIndex:
+			offsets = append(offsets, pc.offset)
+		}
+	}
+	if len(offsets) < int(op.quorum()) {
+		return 0, false
+	}
+	return time.Now().UnixNano() + medianOffset(offsets), true
+}
+
+// localMedianTimestamp is the median of current clocks of committee peers, including the own clock,
+// as seen by the node. Clock offsets of peers are measured by heartbeats of peering connections.
+// Returns false if less than quorum of clocks are known
+func (op *operator) localMedianTimestamp() (int64, bool) {
+	offsets := make([]int64, 0, op.size())
+	for _, ps := range op.committee.PeerStatus() {
+		switch {
+		case ps.IsSelf:
+			offsets = append(offsets, 0)
+		case ps.Connected && ps.ClockMeasured:
+			offsets = append(offsets, ps.ClockOffset.Nanoseconds())
+		}
+	}
+	if len(offsets) < int(op.quorum()) {
+		return 0, false
+	}
+	return time.Now().UnixNano() + medianOffset(offsets), true
+}
+
+// medianOffset sorts clock offsets and returns the median. The lower one of two in the middle if the number is even
+func medianOffset(offsets []int64) int64 {
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] < offsets[j]
+	})
+	return offsets[(len(offsets)-1)/2]
+}
+
+func (op *operator) withinTolerance(ts, reference int64) bool {
+	diff := ts - reference
+	return diff <= op.timestampTolerance.Nanoseconds() && -diff <= op.timestampTolerance.Nanoseconds()
+}
+
+// batchTimestamp determines timestamp of the next batch on top of the state with timestamp prevTs.
+// Must be max(median of clocks, prev timestamp+1). Returns false if the timestamp can't be determined
+// or if the leader itself would refuse it
+func (op *operator) batchTimestamp(prevTs int64) (int64, bool) {
+	ts, ok := op.medianTimestamp()
+	if !ok {
+		op.log.Debugf("batch timestamp can't be determined: less than quorum of peer clocks are known")
+		return 0, false
+	}
+	if localts := time.Now().UnixNano(); !op.withinTolerance(ts, localts) {
+		op.log.Warnf("median of peer clocks differs from the local clock by %v, more than tolerance %v",
+			time.Duration(ts-localts), op.timestampTolerance)
+		return 0, false
+	}
+	if ts <= prevTs {
+		op.log.Warnf("median clock is not ahead the timestamp of the previous state. prevTs: %d, currentTs: %d, diff: %d ns",
+			prevTs, ts, prevTs-ts)
+		ts = prevTs + 1
+		op.log.Infof("timestamp was adjusted to %d", ts)
+	}
+	return ts, true
+}
+
+// checkBatchTimestamp checks if timestamp proposed by the leader is acceptable: it must be within tolerance of the local
+// clock and of the median of clocks of peers, if it is known.
+// Timestamp of the pipelined batch may be in the past. It is only accepted if the node knew the current state as pending,
+// i.e. posted but not confirmed yet. The timestamp must be after the timestamp of that state and not older than the
+// moment the node learned about the pending state
+func (op *operator) checkBatchTimestamp(msg *committee.StartProcessingBatchMsg) bool {
+	localts := time.Now().UnixNano()
+	median, medianKnown := op.localMedianTimestamp()
+	if msg.Pipelined {
+		if !op.pipeliningEnabled || op.pendingStateSince == 0 {
+			op.log.Warnf("reject consensus on timestamp: pipelined batch on top of the state which was not pending")
+			return false
+		}
+		if msg.Timestamp <= op.currentSCState.Timestamp() {
+			op.log.Warnf("reject consensus on timestamp: pipelined batch timestamp %d is not after state timestamp %d",
+				msg.Timestamp, op.currentSCState.Timestamp())
+			return false
+		}
+		tolerance := op.timestampTolerance.Nanoseconds()
+		if msg.Timestamp < op.pendingStateSince-tolerance {
+			op.log.Warnf("reject consensus on timestamp: pipelined batch timestamp %d is older than the pending state, known since %d",
+				msg.Timestamp, op.pendingStateSince)
+			return false
+		}
+		if msg.Timestamp-localts > tolerance || (medianKnown && msg.Timestamp-median > tolerance) {
+			op.log.Warnf("reject consensus on timestamp of pipelined batch: it is in the future. Leader ts: %d, local ts: %d",
+				msg.Timestamp, localts)
+			return false
+		}
+		return true
+	}
+	if !op.withinTolerance(msg.Timestamp, localts) {
+		op.log.Warnf("reject consensus on timestamp: clock difference is too big. Leader ts: %d, local ts: %d, diff: %d",
+			msg.Timestamp, localts, localts-msg.Timestamp)
+		return false
+	}
+	if medianKnown && !op.withinTolerance(msg.Timestamp, median) {
+		op.log.Warnf("reject consensus on timestamp: difference from the median of peer clocks is too big. Leader ts: %d, median: %d, diff: %d",
+			msg.Timestamp, median, median-msg.Timestamp)
+		return false
+	}
+	return true
+}
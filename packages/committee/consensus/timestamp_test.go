@@ -0,0 +1,206 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBatchTimestamp(t *testing.T) {
+	now := time.Now()
+	stateTs := now.Add(-20 * time.Second).UnixNano()
+	op := newTestOperator(t, 4, 3, 1, stateTs)
+	msg := func(ts time.Time, pipelined bool) *committee.StartProcessingBatchMsg {
+		return &committee.StartProcessingBatchMsg{
+			Timestamp: ts.UnixNano(),
+			Pipelined: pipelined,
+		}
+	}
+
+	assert.True(t, op.checkBatchTimestamp(msg(now, false)))
+	assert.True(t, op.checkBatchTimestamp(msg(now.Add(-2*time.Second), false)))
+	assert.False(t, op.checkBatchTimestamp(msg(now.Add(-5*time.Second), false)))
+	assert.False(t, op.checkBatchTimestamp(msg(now.Add(5*time.Second), false)))
+
+	// pipelined batch is only accepted on top of the state which was pending
+	pendingSince := now.Add(-10 * time.Second)
+	old := msg(pendingSince.Add(time.Second), true)
+	assert.False(t, op.checkBatchTimestamp(old))
+	op.pendingStateSince = pendingSince.UnixNano()
+	assert.False(t, op.checkBatchTimestamp(old))
+	op.pipeliningEnabled = true
+	assert.True(t, op.checkBatchTimestamp(old))
+	// not pipelined batch with the same timestamp is too old
+	assert.False(t, op.checkBatchTimestamp(msg(pendingSince.Add(time.Second), false)))
+
+	// pipelined batch can't be older than the pending state
+	assert.True(t, op.checkBatchTimestamp(msg(pendingSince.Add(-2*time.Second), true)))
+	assert.False(t, op.checkBatchTimestamp(msg(pendingSince.Add(-5*time.Second), true)))
+	op.pendingStateSince = stateTs
+	assert.False(t, op.checkBatchTimestamp(msg(time.Unix(0, stateTs), true)))
+	assert.True(t, op.checkBatchTimestamp(msg(time.Unix(0, stateTs+1), true)))
+
+	// nor in the future
+	assert.True(t, op.checkBatchTimestamp(msg(now.Add(2*time.Second), true)))
+	assert.False(t, op.checkBatchTimestamp(msg(now.Add(5*time.Second), true)))
+}
+
+func TestMedianOffset(t *testing.T) {
+	tests := []struct {
+		offsets []int64
+		median  int64
+	}{
+		{[]int64{0}, 0},
+		{[]int64{5, 0}, 0},
+		{[]int64{5, -3, 0}, 0},
+		{[]int64{7, 5, -3, 0}, 0},
+		{[]int64{7, 5, -3, 6}, 5},
+		{[]int64{-1, -100, 100, 1, 0}, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.median, medianOffset(tt.offsets), "offsets %v", tt.offsets)
+	}
+}
+
+// setTestPeerClocks sets clock offsets of peers in seconds as notified in the state with the index.
+// nil means the clock of the peer is unknown
+func setTestPeerClocks(op *operator, stateIndex uint32, offsets ...*int64) {
+	for i, offs := range offsets {
+		if offs == nil {
+			op.peerClocks[i] = nil
+			continue
+		}
+		op.peerClocks[i] = &peerClock{
+			stateIndex: stateIndex,
+			offset:     *offs * int64(time.Second),
+		}
+	}
+}
+
+func secs(s int64) *int64 {
+	return &s
+}
+
+func TestMedianTimestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		// clock offsets of peers in seconds. Own clock (index 0) is ignored
+		offsets []*int64
+		// peers which notified in the previous state
+		stale []int
+		ok    bool
+		// expected median offset in seconds
+		median int64
+	}{
+		{"all clocks", []*int64{nil, secs(1), secs(2), secs(-1)}, nil, true, 0},
+		{"all clocks ahead", []*int64{nil, secs(1), secs(2), secs(3)}, nil, true, 1},
+		{"quorum of clocks", []*int64{nil, secs(2), secs(3), nil}, nil, true, 2},
+		{"less than quorum", []*int64{nil, secs(2), nil, nil}, nil, false, 0},
+		{"stale entry not counted", []*int64{nil, secs(2), secs(3), nil}, []int{2}, false, 0},
+		{"stale entry ignored", []*int64{nil, secs(2), secs(3), secs(-10)}, []int{3}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := newTestOperator(t, 4, 3, 0, 0)
+			stateIndex := op.mustStateIndex()
+			setTestPeerClocks(op, stateIndex+1, tt.offsets...)
+			setTestPeerClocks(op, stateIndex, tt.offsets...)
+			for _, i := range tt.stale {
+				op.peerClocks[i].stateIndex = stateIndex + 1
+			}
+			before := time.Now().UnixNano()
+			ts, ok := op.medianTimestamp()
+			after := time.Now().UnixNano()
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			median := tt.median * int64(time.Second)
+			assert.True(t, ts >= before+median && ts <= after+median)
+		})
+	}
+}
+
+func TestBatchTimestamp(t *testing.T) {
+	now := time.Now().UnixNano()
+	tests := []struct {
+		name    string
+		offsets []*int64
+		prevTs  int64
+		ok      bool
+		// expected offset of the timestamp from now in seconds, unless it is adjusted to the prevTs+1
+		median   int64
+		adjusted bool
+	}{
+		{"within tolerance", []*int64{nil, secs(2), secs(3), secs(2)}, 0, true, 2, false},
+		{"median in the future", []*int64{nil, secs(4), secs(5), secs(4)}, 0, false, 0, false},
+		{"median in the past", []*int64{nil, secs(-4), secs(-5), secs(-4)}, 0, false, 0, false},
+		{"no quorum of clocks", []*int64{nil, secs(1), nil, nil}, 0, false, 0, false},
+		{"after the previous state", []*int64{nil, secs(0), secs(0), secs(0)}, now - int64(time.Second), true, 0, false},
+		{"adjusted to the previous state", []*int64{nil, secs(-2), secs(-2), secs(-2)}, now, true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := newTestOperator(t, 4, 3, 0, 0)
+			setTestPeerClocks(op, op.mustStateIndex(), tt.offsets...)
+			before := time.Now().UnixNano()
+			ts, ok := op.batchTimestamp(tt.prevTs)
+			after := time.Now().UnixNano()
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			if tt.adjusted {
+				assert.Equal(t, tt.prevTs+1, ts)
+				return
+			}
+			median := tt.median * int64(time.Second)
+			assert.True(t, ts >= before+median && ts <= after+median)
+		})
+	}
+}
+
+func TestCheckBatchTimestampMedian(t *testing.T) {
+	peerStatus := func(offsets ...*int64) []*committee.PeerStatus {
+		ret := make([]*committee.PeerStatus, len(offsets))
+		for i, offs := range offsets {
+			ret[i] = &committee.PeerStatus{Index: i, IsSelf: i == 1}
+			if offs != nil {
+				ret[i].Connected = true
+				ret[i].ClockMeasured = true
+				ret[i].ClockOffset = time.Duration(*offs) * time.Second
+			}
+		}
+		return ret
+	}
+	unmeasured := func(ps []*committee.PeerStatus, index int) []*committee.PeerStatus {
+		ps[index].ClockMeasured = false
+		return ps
+	}
+	tests := []struct {
+		name       string
+		peerStatus []*committee.PeerStatus
+		// leader's timestamp relative to the local clock in seconds
+		leaderTs int64
+		ok       bool
+	}{
+		{"median is not known", peerStatus(secs(2), nil, nil, nil), 2, true},
+		{"median is local", peerStatus(secs(2), nil, secs(-2), secs(0)), 2, true},
+		{"median is behind", peerStatus(secs(-2), nil, secs(-2), secs(-2)), 2, false},
+		{"median is ahead", peerStatus(secs(2), nil, secs(2), secs(2)), 2, true},
+		{"median is ahead, leader is behind", peerStatus(secs(2), nil, secs(2), secs(2)), -2, false},
+		{"unmeasured clocks are ignored", unmeasured(peerStatus(secs(2), nil, secs(-2), secs(-2)), 3), 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := newTestOperator(t, 4, 3, 1, 0)
+			op.committee.(*testCommittee).peerStatus = tt.peerStatus
+			msg := &committee.StartProcessingBatchMsg{
+				Timestamp: time.Now().Add(time.Duration(tt.leaderTs) * time.Second).UnixNano(),
+			}
+			assert.Equal(t, tt.ok, op.checkBatchTimestamp(msg))
+		})
+	}
+}
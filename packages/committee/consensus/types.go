@@ -11,17 +11,19 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/committee"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
 	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/vmconst"
 )
 
 type operator struct {
 	committee committee.Committee
-	dkshare   *tcrypto.DKShare
+	keys      tcrypto.CommitteeKeys
 	//currentSCState
 	currentSCState state.VirtualState
 	stateTx        *sctransaction.Transaction
@@ -33,6 +35,8 @@ type operator struct {
 	//
 	requestBalancesDeadline time.Time
 	processorReady          bool
+	// fetching of the missing program code from peers. nil if not in progress
+	programFetch *programFetch
 
 	// notifications with future currentSCState indices
 	notificationsBacklog []*committee.NotifyReqMsg
@@ -45,10 +49,42 @@ type operator struct {
 	leaderStatus            *leaderStatus
 	sentResultToLeaderIndex uint16
 	sentResultToLeader      *sctransaction.Transaction
+	sentResultBatch         state.Batch
+	// VM result which is being signed with the own signature share. Signing is asynchronous,
+	// because the remote signer may be slow. Results signed for other tasks are ignored
+	signingTask *vm.VMTask
 
 	postedResultTxid       *valuetransaction.ID
 	nextPullInclusionLevel time.Time // if postedResultTxid != nil
 
+	// batch selection limits
+	maxBatchSize   int
+	maxBatchVMTime time.Duration
+	// moving average of the VM run time per request, measured on previous batches
+	vmTimePerRequest time.Duration
+
+	// pipelining of batches
+	pipeliningEnabled bool
+	// result of the batch finalized by the leader but not confirmed yet
+	pendingResult *pendingResult
+	// next batch calculated on top of the pending result
+	pipelined *pipelinedBatch
+	// local time when the current state became known as pending, 0 if it wasn't.
+	// Pipelined batches of other leaders are accepted only on top of such state
+	pendingStateSince int64
+	// VM tasks of pipelined batches dropped before calculations were finished. Their results are ignored
+	abandonedTasks []*vm.VMTask
+
+	// clocks of peers taken from notifications, indexed by peer index
+	peerClocks []*peerClock
+	// maximum difference between batch timestamp and local clock
+	timestampTolerance time.Duration
+
+	// statistics of recent consensus rounds
+	stats roundStats
+	// true until the round, in-flight before the restart, is resumed
+	resumePending bool
+
 	log *logger.Logger
 
 	// data for concurrent access, from APIs mostly
@@ -65,11 +101,15 @@ type leaderStatus struct {
 	resultTx      *sctransaction.Transaction
 	finalized     bool
 	signedResults []*signedResult
+	// peers which sent invalid signature shares. Excluded until the end of the round
+	faultyPeers []bool
 }
 
 type signedResult struct {
 	essenceHash hashing.HashValue
 	sigShare    tbdn.SigShare
+	// sigShare was verified against public key share of the peer
+	verified bool
 }
 
 // keeps stateTx of the request
@@ -80,37 +120,49 @@ type request struct {
 	reqTx *sctransaction.Transaction
 	// time when request message was received by the operator
 	whenMsgReceived time.Time
+	// the request transaction was submitted through the node and is not confirmed yet.
+	// Such request is not persisted and is evicted from the backlog if not confirmed in time
+	unconfirmed bool
+	// sender of the request transaction
+	sender address.Address
 	// notification vector for the current currentSCState
 	notifications []bool
 
 	log *logger.Logger
 }
 
-func NewOperator(committee committee.Committee, dkshare *tcrypto.DKShare, log *logger.Logger) *operator {
+func NewOperator(committee committee.Committee, keys tcrypto.CommitteeKeys, log *logger.Logger) *operator {
 	defer committee.SetReadyConsensus()
 
 	ret := &operator{
 		committee:           committee,
-		dkshare:             dkshare,
+		keys:                keys,
 		requests:            make(map[sctransaction.RequestId]*request),
 		requestIdsProtected: make(map[sctransaction.RequestId]bool),
 		peerPermutation:     util.NewPermutation16(committee.Size(), nil),
+		maxBatchSize:        parameters.GetInt(parameters.ConsensusMaxBatchSize),
+		maxBatchVMTime:      parameters.GetDuration(parameters.ConsensusMaxBatchVMTime),
+		pipeliningEnabled:   parameters.GetBool(parameters.ConsensusPipelining),
+		peerClocks:          make([]*peerClock, committee.Size()),
+		timestampTolerance:  parameters.GetDuration(parameters.ConsensusTimestampTolerance),
+		resumePending:       true,
 		log:                 log.Named("c"),
 	}
 	ret.setNextConsensusStage(consensusStageNoSync)
+	ret.loadBacklog()
 	return ret
 }
 
 func (op *operator) peerIndex() uint16 {
-	return op.dkshare.Index
+	return op.keys.OwnIndex()
 }
 
 func (op *operator) quorum() uint16 {
-	return op.dkshare.T
+	return op.keys.Quorum()
 }
 
 func (op *operator) size() uint16 {
-	return op.dkshare.N
+	return op.keys.Size()
 }
 
 func (op *operator) stateIndex() (uint32, bool) {
@@ -147,7 +199,11 @@ func (op *operator) getMinimumReward() int64 {
 	if _, ok := op.stateIndex(); !ok {
 		return 0
 	}
-	vt, ok, err := op.currentSCState.Variables().Codec().GetInt64(vmconst.VarNameMinimumReward)
+	return minimumReward(op.currentSCState)
+}
+
+func minimumReward(vs state.VirtualState) int64 {
+	vt, ok, err := vs.Variables().Codec().GetInt64(vmconst.VarNameMinimumReward)
 	if err != nil {
 		panic(err)
 	}
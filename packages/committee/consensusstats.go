@@ -0,0 +1,53 @@
+package committee
+
+import "time"
+
+// ConsensusStageTransition records the moment consensus operator moved to the stage
+type ConsensusStageTransition struct {
+	Stage string    `json:"stage"`
+	Time  time.Time `json:"time"`
+}
+
+// ConsensusRound is statistics of the consensus round: the attempt of the committee
+// to produce the next state on top of the state with StateIndex
+type ConsensusRound struct {
+	StateIndex uint32    `json:"stateIndex"`
+	Started    time.Time `json:"started"`
+	// the last leader of the round
+	Leader        uint16                     `json:"leader"`
+	LeaderChanges int                        `json:"leaderChanges"`
+	Stages        []ConsensusStageTransition `json:"stages"`
+	// number of requests in the last batch started in the round
+	BatchSize int           `json:"batchSize"`
+	VMRunTime time.Duration `json:"vmRunTime"`
+	// number of valid signature shares collected by the leader. Zero on subordinates
+	SignaturesCollected int `json:"signaturesCollected"`
+	// indices of peers which sent invalid signature shares to the leader in the round
+	FaultyPeers []uint16 `json:"faultyPeers"`
+	// time when the result transaction was posted to the ledger. Zero if not posted
+	Posted    time.Time `json:"posted"`
+	Confirmed bool      `json:"confirmed"`
+	// time from posting the result transaction until the new state was received
+	InclusionLatency time.Duration `json:"inclusionLatency"`
+}
+
+// ConsensusMetrics are cumulative counters of the consensus operator since the start of the node
+type ConsensusMetrics struct {
+	StateIndex           uint32        `json:"stateIndex"`
+	Stage                string        `json:"stage"`
+	Rounds               uint64        `json:"rounds"`
+	LeaderRotations      uint64        `json:"leaderRotations"`
+	BatchesStarted       uint64        `json:"batchesStarted"`
+	RequestsInBatches    uint64        `json:"requestsInBatches"`
+	VMRunTime            time.Duration `json:"vmRunTime"`
+	ResultsPosted        uint64        `json:"resultsPosted"`
+	ResultsConfirmed     uint64        `json:"resultsConfirmed"`
+	LastInclusionLatency time.Duration `json:"lastInclusionLatency"`
+	SigShareFaults       uint64        `json:"sigShareFaults"`
+}
+
+type ConsensusStats struct {
+	Metrics ConsensusMetrics `json:"metrics"`
+	// recent rounds, the latest is the last
+	Rounds []*ConsensusRound `json:"rounds"`
+}
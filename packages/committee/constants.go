@@ -29,6 +29,14 @@ const (
 	// Request is repeated if necessary.
 	StateTransactionRequestTimeout = 10 * time.Second
 
-	// maximum time difference allowed between leader and local clocks for consensus
-	MaxClockDifferenceAllowed = 3 * time.Second
+	// maximum age of the timestamp of the pipelined batch, i.e. batch calculated by the leader
+	// on top of the unconfirmed state. It must cover the confirmation time of the previous batch
+	MaxPipelinedBatchAge = 3 * ConfirmationTime
+
+	// period of checking clock offsets of committee peers
+	CheckPeerClocksPeriod = 1 * time.Minute
+
+	// if the program code is missing, the node requests it from a committee peer. If the code doesn't arrive,
+	// the request is repeated to the next peer after some time
+	RepeatGetProgramCodeAfter = 10 * time.Second
 )
@@ -22,6 +22,8 @@ type BalancesMsg struct {
 type RequestMsg struct {
 	*sctransaction.Transaction
 	Index uint16
+	// the transaction was submitted through the node and is not confirmed by the ledger yet
+	Unconfirmed bool
 }
 
 func (reqMsg *RequestMsg) RequestId() *sctransaction.RequestId {
@@ -2,7 +2,9 @@ package committee
 
 import (
 	"fmt"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
@@ -100,6 +102,9 @@ func (msg *StartProcessingBatchMsg) Write(w io.Writer) error {
 	if err := waspconn.WriteBalances(w, msg.Balances); err != nil {
 		return err
 	}
+	if err := util.WriteBoolByte(w, msg.Pipelined); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -124,6 +129,9 @@ func (msg *StartProcessingBatchMsg) Read(r io.Reader) error {
 	if msg.Balances, err = waspconn.ReadBalances(r); err != nil {
 		return err
 	}
+	if err := util.ReadBoolByte(r, &msg.Pipelined); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -281,3 +289,63 @@ func (msg *TestTraceMsg) Read(r io.Reader) error {
 	}
 	return nil
 }
+
+func (msg *ForwardRequestMsg) Write(w io.Writer) error {
+	if err := util.WriteBytes32(w, msg.Transaction.Bytes()); err != nil {
+		return err
+	}
+	return util.WriteUint16(w, msg.Index)
+}
+
+func (msg *ForwardRequestMsg) Read(r io.Reader) error {
+	data, err := util.ReadBytes32(r)
+	if err != nil {
+		return err
+	}
+	vtx, _, err := valuetransaction.FromBytes(data)
+	if err != nil {
+		return err
+	}
+	if msg.Transaction, err = sctransaction.ParseValueTransaction(vtx); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &msg.Index); err != nil {
+		return err
+	}
+	if int(msg.Index) >= len(msg.Transaction.Requests()) {
+		return fmt.Errorf("wrong request index %d", msg.Index)
+	}
+	return nil
+}
+
+func (msg *GetProgramCodeMsg) Write(w io.Writer) error {
+	_, err := w.Write(msg.ProgramHash[:])
+	return err
+}
+
+func (msg *GetProgramCodeMsg) Read(r io.Reader) error {
+	return util.ReadHashValue(r, &msg.ProgramHash)
+}
+
+func (msg *ProgramCodeMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.Metadata.ProgramHash[:]); err != nil {
+		return err
+	}
+	if err := msg.Metadata.Write(w); err != nil {
+		return err
+	}
+	return util.WriteBytes32(w, msg.Code)
+}
+
+func (msg *ProgramCodeMsg) Read(r io.Reader) error {
+	msg.Metadata = &registry.ProgramMetadata{}
+	if err := util.ReadHashValue(r, &msg.Metadata.ProgramHash); err != nil {
+		return err
+	}
+	if err := msg.Metadata.Read(r); err != nil {
+		return err
+	}
+	var err error
+	msg.Code, err = util.ReadBytes32(r)
+	return err
+}
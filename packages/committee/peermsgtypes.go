@@ -5,9 +5,11 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/plugins/peering"
 )
 
@@ -21,6 +23,9 @@ const (
 	MsgStateUpdate             = 6 + peering.FirstCommitteeMsgCode
 	MsgBatchHeader             = 7 + peering.FirstCommitteeMsgCode
 	MsgTestTrace               = 8 + peering.FirstCommitteeMsgCode
+	MsgForwardRequest          = 9 + peering.FirstCommitteeMsgCode
+	MsgGetProgramCode          = 10 + peering.FirstCommitteeMsgCode
+	MsgProgramCode             = 11 + peering.FirstCommitteeMsgCode
 )
 
 type TimerTick int
@@ -44,6 +49,8 @@ type StateIndexPingPongMsg struct {
 // the receiving operator will ignore repeating messages
 type NotifyReqMsg struct {
 	PeerMsgHeader
+	// local time of the sender. Field is set upon receive the message to sender's timestamp
+	Timestamp int64
 	// list of request ids ordered by the time of arrival
 	RequestIds []sctransaction.RequestId
 }
@@ -70,6 +77,9 @@ type StartProcessingBatchMsg struct {
 	RewardAddress address.Address
 	// balances/outputs
 	Balances map[valuetransaction.ID][]*balance.Balance
+	// true if the batch was calculated by the leader on top of the unconfirmed state.
+	// The timestamp of the pipelined batch may be older than the timestamp of the current state transaction
+	Pipelined bool
 }
 
 // after calculations the result peer responds to the start processing msg
@@ -141,6 +151,13 @@ type PendingBatchMsg struct {
 	Batch state.Batch
 }
 
+// message is posted by the consensus operator to itself when the VM result is signed with the own signature share
+type ResultSignedMsg struct {
+	Task     *vm.VMTask
+	SigShare tbdn.SigShare
+	Err      error
+}
+
 // message sent to notify VM processor is ready. It is a successful finish of asynchronous loading of the processor
 type ProcessorIsReady struct {
 	ProgramHash string // base58
@@ -152,3 +169,27 @@ type StateTransactionEvidenced struct {
 	TxId      valuetransaction.ID
 	StateHash hashing.HashValue
 }
+
+// access node forwards requests it received to the committee nodes
+type ForwardRequestMsg struct {
+	PeerMsgHeader
+	// request transaction
+	Transaction *sctransaction.Transaction
+	// index of the request block in the transaction
+	Index uint16
+}
+
+// committee node requests the code of the program of the smart contract from the peer
+// when the code is missing in its registry
+type GetProgramCodeMsg struct {
+	PeerMsgHeader
+	ProgramHash hashing.HashValue
+}
+
+// the peer responds to GetProgramCodeMsg with the metadata and the code of the program.
+// The receiver checks the code against the program hash
+type ProgramCodeMsg struct {
+	PeerMsgHeader
+	Metadata *registry.ProgramMetadata
+	Code     []byte
+}
@@ -331,11 +331,18 @@ func (sm *stateManager) numPongs() uint16 {
 }
 
 func (sm *stateManager) numPongsHasQuorum() bool {
+	if !sm.committee.IsCommitteeNode() {
+		// access node itself is not counted in the quorum
+		return sm.numPongs() >= sm.committee.Quorum()
+	}
 	return sm.numPongs() >= sm.committee.Quorum()-1
 }
 
+// only pongs from committee peers are counted
 func (sm *stateManager) pingPongReceived(senderIndex uint16) {
-	sm.pingPong[senderIndex] = true
+	if int(senderIndex) < len(sm.pingPong) {
+		sm.pingPong[senderIndex] = true
+	}
 }
 
 func (sm *stateManager) respondPongToPeer(targetPeerIndex uint16) {
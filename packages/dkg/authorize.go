@@ -0,0 +1,111 @@
+package dkg
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+)
+
+// DefaultReshareAuthorizationTTL is the time the authorization of resharing is valid if not specified
+const DefaultReshareAuthorizationTTL = 10 * time.Minute
+
+// reshareAuthorization is the consent of the admin of the node to deal its key share to new holders
+type reshareAuthorization struct {
+	pubKeys []string
+	t       uint16
+	expires time.Time
+}
+
+// AuthorizeReshare allows the node, as an old holder of the key share, to take part in resharing of the key set
+// of the smart contract to new holders with identity public keys peerPubKeys and quorum t, initiated by another
+// member of the current committee. The authorization is used once and expires after ttl
+func AuthorizeReshare(addr *address.Address, peerPubKeys []string, t uint16, ttl time.Duration) error {
+	return waspNode.authorizeReshare(addr, peerPubKeys, t, ttl)
+}
+
+func (n *node) authorizeReshare(addr *address.Address, peerPubKeys []string, t uint16, ttl time.Duration) error {
+	_, exists, err := n.env.getDKShare(addr)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the node doesn't hold key share of %s", addr.String())
+	}
+	if ttl <= 0 {
+		ttl = DefaultReshareAuthorizationTTL
+	}
+	n.reshareAuthorizationsMutex.Lock()
+	defer n.reshareAuthorizationsMutex.Unlock()
+
+	n.reshareAuthorizations[*addr] = &reshareAuthorization{
+		pubKeys: append([]string{}, peerPubKeys...),
+		t:       t,
+		expires: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// takeReshareAuthorization consumes the authorization matching parameters of the resharing session
+func (n *node) takeReshareAuthorization(init *InitMsg) bool {
+	n.reshareAuthorizationsMutex.Lock()
+	defer n.reshareAuthorizationsMutex.Unlock()
+
+	auth, ok := n.reshareAuthorizations[init.Address]
+	if !ok {
+		return false
+	}
+	if time.Now().After(auth.expires) {
+		delete(n.reshareAuthorizations, init.Address)
+		return false
+	}
+	if auth.t != init.T || !equalStrings(auth.pubKeys, init.PeerPubKeys) {
+		return false
+	}
+	delete(n.reshareAuthorizations, init.Address)
+	return true
+}
+
+// checkReshareInit checks the resharing session started by another node against the local registry.
+// The initiator must be a member of the current committee according to the bootup data of the node and
+// the old holders must be that committee. Old holders deal their shares only if the resharing was authorized
+// by the admin of the node with the same parameters
+func (n *node) checkReshareInit(init *InitMsg, senderPubKey string) error {
+	bd, err := n.env.getBootupData(&init.Address)
+	if err != nil {
+		return err
+	}
+	_, isOldHolder, err := n.env.getDKShare(&init.Address)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		if isOldHolder {
+			return fmt.Errorf("no bootup data of %s", init.Address.String())
+		}
+		// the new holder which doesn't know the smart contract yet
+		return nil
+	}
+	if indexOf(senderPubKey, bd.CommitteePubKeys) < 0 {
+		return fmt.Errorf("the initiator is not a member of the committee of %s", init.Address.String())
+	}
+	if !equalStrings(init.OldPeerPubKeys, bd.CommitteePubKeys) {
+		return fmt.Errorf("old holders of shares are not the committee of %s", init.Address.String())
+	}
+	if isOldHolder && !n.takeReshareAuthorization(init) {
+		return fmt.Errorf("resharing of %s is not authorized by the admin", init.Address.String())
+	}
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
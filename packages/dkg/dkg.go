@@ -0,0 +1,321 @@
+// package implements distributed key generation between Wasp nodes over peering,
+// without trusted dealer. It runs Pedersen DKG with verifiable (Feldman) secret sharing and
+// complaint handling: each participant deals shares of its own random polynomial, encrypted to the receivers.
+// The private key share of the node is the sum of shares received from qualified dealers,
+// so no party ever knows private shares of others or the master secret.
+// The DKG is started by the admin call to one of participants, the initiator
+package dkg
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+	MaxTimeout     = 5 * time.Minute
+	// maximum number of DKG sessions running in the node at the same time
+	maxSessions = 8
+	// messages of unknown sessions are kept for some time, they may arrive before the initiator's message
+	maxOrphanMessages = 256
+	orphanTTL         = 1 * time.Minute
+)
+
+// keys are generated in G2 group of BN256, same as in tcrypto
+var suite = bn256.NewSuiteG2()
+
+var log *logger.Logger
+
+func InitLogger() {
+	log = logger.NewLogger("dkg")
+}
+
+type orphanMsg struct {
+	msg      *sessionMsg
+	received time.Time
+}
+
+// node runs DKG sessions of the node in its environment
+type node struct {
+	env      environment
+	sessions map[hashing.HashValue]*session
+	// ids of recently closed sessions. Late messages of them are ignored
+	closed        map[hashing.HashValue]time.Time
+	orphans       map[hashing.HashValue][]*orphanMsg
+	numOrphans    int
+	sessionsMutex sync.Mutex
+	// consents of the admin to deal key shares in resharing sessions started by other nodes
+	reshareAuthorizations      map[address.Address]*reshareAuthorization
+	reshareAuthorizationsMutex sync.Mutex
+}
+
+func newNode(env environment) *node {
+	return &node{
+		env:                   env,
+		sessions:              make(map[hashing.HashValue]*session),
+		closed:                make(map[hashing.HashValue]time.Time),
+		orphans:               make(map[hashing.HashValue][]*orphanMsg),
+		reshareAuthorizations: make(map[address.Address]*reshareAuthorization),
+	}
+}
+
+// waspNode runs DKG sessions of the Wasp node over peering
+var waspNode = newNode(waspEnvironment{})
+
+// RunDKG runs DKG among participants as initiator. The own node must be among participants.
+// Blocks until the DKG is finished. Returns address of the generated key set
+func RunDKG(peerLocations, peerPubKeys []string, t uint16, timeout time.Duration) (*address.Address, error) {
+	return waspNode.runDKG(peerLocations, peerPubKeys, t, timeout)
+}
+
+func (n *node) runDKG(peerLocations, peerPubKeys []string, t uint16, timeout time.Duration) (*address.Address, error) {
+	id, err := newSessionId()
+	if err != nil {
+		return nil, err
+	}
+	return n.runSession(&InitMsg{
+		SessionId:     id,
+		T:             t,
+		Timeout:       uint32(timeout / time.Millisecond),
+		PeerLocations: peerLocations,
+		PeerPubKeys:   peerPubKeys,
+	})
+}
+
+// RunReshare reshares the key set of the smart contract from the current committee to new holders
+// of shares as initiator. The own node must be a member of the current committee.
+// The master public key and the address remain the same, all old shares become useless.
+// Blocks until resharing is finished
+func RunReshare(addr *address.Address, peerLocations, peerPubKeys []string, t uint16, timeout time.Duration) error {
+	return waspNode.runReshare(addr, peerLocations, peerPubKeys, t, timeout)
+}
+
+func (n *node) runReshare(addr *address.Address, peerLocations, peerPubKeys []string, t uint16, timeout time.Duration) error {
+	bd, err := n.env.getBootupData(addr)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		return fmt.Errorf("unknown smart contract %s", addr.String())
+	}
+	ks, exists, err := n.env.getDKShare(addr)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the node doesn't hold key share of %s", addr.String())
+	}
+	coeffs := ks.PubCoeffs()
+	pubCoeffs := make([][]byte, len(coeffs))
+	for i, c := range coeffs {
+		if pubCoeffs[i], err = c.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+	id, err := newSessionId()
+	if err != nil {
+		return err
+	}
+	_, err = n.runSession(&InitMsg{
+		SessionId:        id,
+		T:                t,
+		Timeout:          uint32(timeout / time.Millisecond),
+		PeerLocations:    peerLocations,
+		PeerPubKeys:      peerPubKeys,
+		Reshare:          true,
+		Address:          *addr,
+		OldT:             ks.T,
+		OldPeerLocations: bd.CommitteeNodes,
+		OldPeerPubKeys:   bd.CommitteePubKeys,
+		PubCoeffs:        pubCoeffs,
+	})
+	return err
+}
+
+func (n *node) runSession(init *InitMsg) (*address.Address, error) {
+	type result struct {
+		addr *address.Address
+		err  error
+	}
+	chDone := make(chan result, 1)
+	err := n.startSession(init, true, func(addr *address.Address, err error) {
+		chDone <- result{addr, err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := <-chDone
+	return res.addr, res.err
+}
+
+// ProcessPeerMessage dispatches DKG message to the session. Message from the initiator starts new session
+func ProcessPeerMessage(msg *peering.PeerMessage) {
+	if msg.Sender == nil {
+		return
+	}
+	waspNode.processPeerMessage(&sessionMsg{msg: msg, sender: msg.Sender})
+}
+
+func (n *node) processPeerMessage(smsg *sessionMsg) {
+	msg := smsg.msg
+	var id hashing.HashValue
+	if len(msg.MsgData) < len(id) {
+		return
+	}
+	copy(id[:], msg.MsgData)
+
+	n.sessionsMutex.Lock()
+	s, ok := n.sessions[id]
+	_, isClosed := n.closed[id]
+	n.sessionsMutex.Unlock()
+	if isClosed {
+		return
+	}
+	if smsg.sender == nil || !n.env.isKnownIdentity(smsg.sender.PubKey()) {
+		// DKG messages are accepted only from nodes in the address book or in the list of allowed peers
+		return
+	}
+	if ok {
+		s.receive(smsg)
+		return
+	}
+	if msg.MsgType != MsgInit {
+		n.addOrphan(id, smsg)
+		return
+	}
+	init := &InitMsg{}
+	if err := init.Read(bytes.NewReader(msg.MsgData)); err != nil {
+		log.Warnf("wrong DKG init message: %v", err)
+		return
+	}
+	senderPubKey := smsg.sender.PubKey()
+	if indexOf(senderPubKey, init.PeerPubKeys) < 0 && indexOf(senderPubKey, init.OldPeerPubKeys) < 0 {
+		log.Warnf("DKG init message from the node which is not a participant")
+		return
+	}
+	if err := n.checkParticipants(init.PeerLocations, init.PeerPubKeys); err != nil {
+		log.Warnf("rejected DKG session %s: %v", init.SessionId.String(), err)
+		return
+	}
+	if err := n.checkParticipants(init.OldPeerLocations, init.OldPeerPubKeys); err != nil {
+		log.Warnf("rejected DKG session %s: %v", init.SessionId.String(), err)
+		return
+	}
+	if init.Reshare {
+		if err := n.checkReshareInit(init, senderPubKey); err != nil {
+			log.Warnf("rejected resharing session %s: %v", init.SessionId.String(), err)
+			return
+		}
+	}
+	err := n.startSession(init, false, func(addr *address.Address, err error) {
+		if err != nil {
+			log.Errorf("DKG session %s failed: %v", init.SessionId.String(), err)
+		}
+	})
+	if err != nil {
+		log.Warnf("can't start DKG session %s: %v", init.SessionId.String(), err)
+	}
+}
+
+// checkParticipants checks if all participants of the session started by another node are known identities.
+// Participants from the address book must be listed with the location from the address book,
+// so the node never dials locations chosen by the initiator
+func (n *node) checkParticipants(locations, pubKeys []string) error {
+	if len(locations) != len(pubKeys) {
+		return fmt.Errorf("number of locations and public keys mismatch")
+	}
+	for i, pubKey := range pubKeys {
+		if pubKey == n.env.myPubKey() {
+			continue
+		}
+		if !n.env.isKnownIdentity(pubKey) {
+			return fmt.Errorf("unknown participant %s", pubKey)
+		}
+		loc, ok, err := n.env.getPeerAddress(pubKey)
+		if err != nil {
+			return err
+		}
+		if ok && loc != locations[i] {
+			return fmt.Errorf("location of participant %s doesn't match the address book", pubKey)
+		}
+	}
+	return nil
+}
+
+func (n *node) startSession(init *InitMsg, initiator bool, onFinish func(addr *address.Address, err error)) error {
+	n.sessionsMutex.Lock()
+	defer n.sessionsMutex.Unlock()
+
+	if _, ok := n.sessions[init.SessionId]; ok {
+		return fmt.Errorf("duplicate DKG session id")
+	}
+	if _, ok := n.closed[init.SessionId]; ok {
+		return fmt.Errorf("duplicate DKG session id")
+	}
+	if len(n.sessions) >= maxSessions {
+		return fmt.Errorf("too many DKG sessions")
+	}
+	for _, s := range n.sessions {
+		if init.Reshare && s.reshare && s.address == init.Address {
+			return fmt.Errorf("resharing of %s is already in progress", init.Address.String())
+		}
+	}
+	s, err := newSession(n, init, initiator, onFinish)
+	if err != nil {
+		return err
+	}
+	n.sessions[s.id] = s
+	// messages received before the session was started
+	for _, o := range n.orphans[s.id] {
+		s.receive(o.msg)
+	}
+	n.numOrphans -= len(n.orphans[s.id])
+	delete(n.orphans, s.id)
+
+	go s.run()
+	return nil
+}
+
+func (n *node) closeSession(s *session) {
+	n.sessionsMutex.Lock()
+	delete(n.sessions, s.id)
+	n.closed[s.id] = time.Now()
+	n.sessionsMutex.Unlock()
+
+	s.releasePeers()
+	s.log.Debugf("DKG session closed")
+}
+
+func (n *node) addOrphan(id hashing.HashValue, msg *sessionMsg) {
+	n.sessionsMutex.Lock()
+	defer n.sessionsMutex.Unlock()
+
+	// clean up expired
+	for oid, msgs := range n.orphans {
+		if time.Since(msgs[0].received) > orphanTTL {
+			n.numOrphans -= len(msgs)
+			delete(n.orphans, oid)
+		}
+	}
+	for cid, when := range n.closed {
+		if time.Since(when) > orphanTTL {
+			delete(n.closed, cid)
+		}
+	}
+	if n.numOrphans >= maxOrphanMessages {
+		return
+	}
+	n.orphans[id] = append(n.orphans[id], &orphanMsg{
+		msg:      msg,
+		received: time.Now(),
+	})
+	n.numOrphans++
+}
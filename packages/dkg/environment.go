@@ -0,0 +1,88 @@
+package dkg
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/peering"
+)
+
+// environment is everything DKG sessions need from the node: its identity, connections to participants,
+// the registry and committees. The Wasp node uses peering and the registry (see waspEnvironment).
+// Tests run several nodes in one process, each with its own environment
+type environment interface {
+	myPubKey() string
+	isKnownIdentity(pubKey string) bool
+	getPeerAddress(pubKey string) (string, bool, error)
+	// usePeer returns nil for the own identity
+	usePeer(location, pubKey string) (participant, error)
+	stopUsingPeer(p participant)
+	getDKShare(addr *address.Address) (*tcrypto.DKShare, bool, error)
+	saveDKShare(ks *tcrypto.DKShare) error
+	replaceDKShare(ks *tcrypto.DKShare) error
+	deleteDKShare(addr *address.Address) error
+	getBootupData(addr *address.Address) (*registry.BootupData, error)
+	saveBootupData(bd *registry.BootupData) error
+	restartCommittee(bd *registry.BootupData) error
+}
+
+// participant is the connection to another participant of DKG sessions
+type participant interface {
+	PubKey() string
+	SendMsg(msg *peering.PeerMessage) error
+}
+
+type waspEnvironment struct{}
+
+func (waspEnvironment) myPubKey() string {
+	return peering.MyPubKey()
+}
+
+func (waspEnvironment) isKnownIdentity(pubKey string) bool {
+	return peering.IsKnownIdentity(pubKey)
+}
+
+func (waspEnvironment) getPeerAddress(pubKey string) (string, bool, error) {
+	return registry.GetPeerAddress(pubKey)
+}
+
+func (waspEnvironment) usePeer(location, pubKey string) (participant, error) {
+	peer, err := peering.UsePeer(location, pubKey)
+	if err != nil || peer == nil {
+		return nil, err
+	}
+	return peer, nil
+}
+
+func (waspEnvironment) stopUsingPeer(p participant) {
+	peering.StopUsingPeer(p.(*peering.Peer).PeeringId())
+}
+
+func (waspEnvironment) getDKShare(addr *address.Address) (*tcrypto.DKShare, bool, error) {
+	return registry.GetDKShare(addr)
+}
+
+func (waspEnvironment) saveDKShare(ks *tcrypto.DKShare) error {
+	return registry.SaveDKShareToRegistry(ks)
+}
+
+func (waspEnvironment) replaceDKShare(ks *tcrypto.DKShare) error {
+	return registry.ReplaceDKShare(ks)
+}
+
+func (waspEnvironment) deleteDKShare(addr *address.Address) error {
+	return registry.DeleteDKShare(addr)
+}
+
+func (waspEnvironment) getBootupData(addr *address.Address) (*registry.BootupData, error) {
+	return registry.GetBootupData(addr)
+}
+
+func (waspEnvironment) saveBootupData(bd *registry.BootupData) error {
+	return registry.SaveBootupData(bd)
+}
+
+func (waspEnvironment) restartCommittee(bd *registry.BootupData) error {
+	return committees.RestartCommittee(bd)
+}
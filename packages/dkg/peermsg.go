@@ -0,0 +1,345 @@
+package dkg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+)
+
+func (msg *InitMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, msg.T); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, msg.Timeout); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, msg.PeerLocations); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, msg.PeerPubKeys); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, msg.Reshare); err != nil {
+		return err
+	}
+	if !msg.Reshare {
+		return nil
+	}
+	if _, err := w.Write(msg.Address[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, msg.OldT); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, msg.OldPeerLocations); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, msg.OldPeerPubKeys); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(msg.PubCoeffs))); err != nil {
+		return err
+	}
+	return writeByteArrays(w, msg.PubCoeffs...)
+}
+
+func (msg *InitMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &msg.T); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &msg.Timeout); err != nil {
+		return err
+	}
+	var err error
+	if msg.PeerLocations, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if msg.PeerPubKeys, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if err = util.ReadBoolByte(r, &msg.Reshare); err != nil {
+		return err
+	}
+	if !msg.Reshare {
+		return nil
+	}
+	if err = util.ReadAddress(r, &msg.Address); err != nil {
+		return err
+	}
+	if err = util.ReadUint16(r, &msg.OldT); err != nil {
+		return err
+	}
+	if msg.OldPeerLocations, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if msg.OldPeerPubKeys, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	var num uint16
+	if err = util.ReadUint16(r, &num); err != nil {
+		return err
+	}
+	msg.PubCoeffs = make([][]byte, num)
+	arrs := make([]*[]byte, num)
+	for i := range arrs {
+		arrs[i] = &msg.PubCoeffs[i]
+	}
+	return readByteArrays(r, arrs...)
+}
+
+func (msg *PubKeyMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, msg.PubKey)
+}
+
+func (msg *PubKeyMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	var err error
+	msg.PubKey, err = util.ReadBytes16(r)
+	return err
+}
+
+func (msg *DealMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, msg.Deal.Index); err != nil {
+		return err
+	}
+	if err := writeByteArrays(w, msg.Deal.Deal.DHKey, msg.Deal.Deal.Signature, msg.Deal.Deal.Nonce, msg.Deal.Deal.Cipher); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, msg.Deal.Signature)
+}
+
+func (msg *DealMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	msg.Deal = &dkg.Deal{Deal: &vss.EncryptedDeal{}}
+	if err := util.ReadUint32(r, &msg.Deal.Index); err != nil {
+		return err
+	}
+	ed := msg.Deal.Deal
+	if err := readByteArrays(r, &ed.DHKey, &ed.Signature, &ed.Nonce, &ed.Cipher); err != nil {
+		return err
+	}
+	var err error
+	msg.Deal.Signature, err = util.ReadBytes16(r)
+	return err
+}
+
+func (msg *ResponseMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, msg.Response.Index); err != nil {
+		return err
+	}
+	resp := msg.Response.Response
+	if err := util.WriteBytes16(w, resp.SessionID); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, resp.Index); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, resp.Status); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, resp.Signature)
+}
+
+func (msg *ResponseMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	msg.Response = &dkg.Response{Response: &vss.Response{}}
+	if err := util.ReadUint32(r, &msg.Response.Index); err != nil {
+		return err
+	}
+	resp := msg.Response.Response
+	var err error
+	if resp.SessionID, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &resp.Index); err != nil {
+		return err
+	}
+	if err = util.ReadBoolByte(r, &resp.Status); err != nil {
+		return err
+	}
+	resp.Signature, err = util.ReadBytes16(r)
+	return err
+}
+
+func (msg *JustificationMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, msg.Justification.Index); err != nil {
+		return err
+	}
+	j := msg.Justification.Justification
+	if err := util.WriteBytes16(w, j.SessionID); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, j.Index); err != nil {
+		return err
+	}
+	if err := writeVssDeal(w, j.Deal); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, j.Signature)
+}
+
+func (msg *JustificationMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	msg.Justification = &dkg.Justification{Justification: &vss.Justification{}}
+	if err := util.ReadUint32(r, &msg.Justification.Index); err != nil {
+		return err
+	}
+	j := msg.Justification.Justification
+	var err error
+	if j.SessionID, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &j.Index); err != nil {
+		return err
+	}
+	if j.Deal, err = readVssDeal(r); err != nil {
+		return err
+	}
+	j.Signature, err = util.ReadBytes16(r)
+	return err
+}
+
+func (msg *ResultMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.SessionId[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.PubPolyHash[:]); err != nil {
+		return err
+	}
+	return util.WriteString16(w, msg.Error)
+}
+
+func (msg *ResultMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.SessionId); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &msg.PubPolyHash); err != nil {
+		return err
+	}
+	var err error
+	msg.Error, err = util.ReadString16(r)
+	return err
+}
+
+func writeByteArrays(w io.Writer, arrs ...[]byte) error {
+	for _, a := range arrs {
+		if err := util.WriteBytes16(w, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readByteArrays(r io.Reader, arrs ...*[]byte) error {
+	for _, a := range arrs {
+		var err error
+		if *a, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeVssDeal(w io.Writer, d *vss.Deal) error {
+	if d == nil || d.SecShare == nil {
+		return errors.New("empty deal")
+	}
+	if err := util.WriteBytes16(w, d.SessionID); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, uint32(d.SecShare.I)); err != nil {
+		return err
+	}
+	data, err := d.SecShare.V.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, data); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, d.T); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(d.Commitments))); err != nil {
+		return err
+	}
+	for _, c := range d.Commitments {
+		if data, err = c.MarshalBinary(); err != nil {
+			return err
+		}
+		if err := util.WriteBytes16(w, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readVssDeal(r io.Reader) (*vss.Deal, error) {
+	ret := &vss.Deal{SecShare: &share.PriShare{}}
+	var err error
+	if ret.SessionID, err = util.ReadBytes16(r); err != nil {
+		return nil, err
+	}
+	var idx uint32
+	if err = util.ReadUint32(r, &idx); err != nil {
+		return nil, err
+	}
+	ret.SecShare.I = int(idx)
+	data, err := util.ReadBytes16(r)
+	if err != nil {
+		return nil, err
+	}
+	ret.SecShare.V = suite.Scalar()
+	if err = ret.SecShare.V.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if err = util.ReadUint32(r, &ret.T); err != nil {
+		return nil, err
+	}
+	var num uint16
+	if err = util.ReadUint16(r, &num); err != nil {
+		return nil, err
+	}
+	ret.Commitments = make([]kyber.Point, num)
+	for i := range ret.Commitments {
+		if data, err = util.ReadBytes16(r); err != nil {
+			return nil, err
+		}
+		ret.Commitments[i] = suite.Point()
+		if err = ret.Commitments[i].UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,144 @@
+package dkg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/assert"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+)
+
+func newGenerators(t *testing.T, n, thr int) []*dkg.DistKeyGenerator {
+	longterms := make([]kyber.Scalar, n)
+	pubs := make([]kyber.Point, n)
+	for i := range longterms {
+		longterms[i] = suite.Scalar().Pick(suite.RandomStream())
+		pubs[i] = suite.Point().Mul(longterms[i], nil)
+	}
+	ret := make([]*dkg.DistKeyGenerator, n)
+	for i := range ret {
+		var err error
+		ret[i], err = dkg.NewDistKeyGenerator(suite, longterms[i], pubs, thr)
+		assert.NoError(t, err)
+	}
+	return ret
+}
+
+func TestInitMsg(t *testing.T) {
+	msg := &InitMsg{
+		SessionId:     *hashing.RandomHash(nil),
+		T:             3,
+		Timeout:       30000,
+		PeerLocations: []string{"127.0.0.1:4000", "127.0.0.1:4001"},
+		PeerPubKeys:   []string{"key1", "key2"},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, msg.Write(&buf))
+	back := &InitMsg{}
+	assert.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	assert.EqualValues(t, msg, back)
+}
+
+func TestInitMsgReshare(t *testing.T) {
+	coeff, err := suite.Point().Pick(suite.RandomStream()).MarshalBinary()
+	assert.NoError(t, err)
+	msg := &InitMsg{
+		SessionId:        *hashing.RandomHash(nil),
+		T:                3,
+		Timeout:          30000,
+		PeerLocations:    []string{"127.0.0.1:4000", "127.0.0.1:4002"},
+		PeerPubKeys:      []string{"key1", "key3"},
+		Reshare:          true,
+		Address:          address.Random(),
+		OldT:             2,
+		OldPeerLocations: []string{"127.0.0.1:4000", "127.0.0.1:4001"},
+		OldPeerPubKeys:   []string{"key1", "key2"},
+		PubCoeffs:        [][]byte{coeff, coeff},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, msg.Write(&buf))
+	back := &InitMsg{}
+	assert.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	assert.EqualValues(t, msg, back)
+}
+
+// deals, responses and justifications must pass through encoding unchanged
+func TestDKGMessages(t *testing.T) {
+	const n, thr = 4, 3
+	gens := newGenerators(t, n, thr)
+	id := *hashing.RandomHash(nil)
+
+	deals, err := gens[0].Deals()
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, (&DealMsg{SessionId: id, Deal: deals[1]}).Write(&buf))
+	dealBack := &DealMsg{}
+	assert.NoError(t, dealBack.Read(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, id, dealBack.SessionId)
+
+	resp, err := gens[1].ProcessDeal(dealBack.Deal)
+	assert.NoError(t, err)
+	assert.Equal(t, vss.StatusApproval, resp.Response.Status)
+
+	buf.Reset()
+	assert.NoError(t, (&ResponseMsg{SessionId: id, Response: resp}).Write(&buf))
+	respBack := &ResponseMsg{}
+	assert.NoError(t, respBack.Read(bytes.NewReader(buf.Bytes())))
+	assert.EqualValues(t, resp, respBack.Response)
+
+	_, err = gens[2].ProcessDeal(deals[2])
+	assert.NoError(t, err)
+	just, err := gens[2].ProcessResponse(respBack.Response)
+	assert.NoError(t, err)
+	assert.Nil(t, just)
+
+	// justification is produced only upon complaint. Encode the plaintext deal made up of random values
+	commits := make([]kyber.Point, thr)
+	for i := range commits {
+		commits[i] = suite.Point().Pick(suite.RandomStream())
+	}
+	j := &dkg.Justification{
+		Index: 0,
+		Justification: &vss.Justification{
+			SessionID: resp.Response.SessionID,
+			Index:     1,
+			Deal: &vss.Deal{
+				SessionID:   resp.Response.SessionID,
+				SecShare:    &share.PriShare{I: 1, V: suite.Scalar().Pick(suite.RandomStream())},
+				T:           thr,
+				Commitments: commits,
+			},
+			Signature: []byte("signature"),
+		},
+	}
+	buf.Reset()
+	assert.NoError(t, (&JustificationMsg{SessionId: id, Justification: j}).Write(&buf))
+	justBack := &JustificationMsg{}
+	assert.NoError(t, justBack.Read(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, j.Index, justBack.Justification.Index)
+	jb := justBack.Justification.Justification
+	assert.Equal(t, j.Justification.SessionID, jb.SessionID)
+	assert.True(t, j.Justification.Deal.SecShare.V.Equal(jb.Deal.SecShare.V))
+	assert.Equal(t, len(j.Justification.Deal.Commitments), len(jb.Deal.Commitments))
+	for i, c := range j.Justification.Deal.Commitments {
+		assert.True(t, c.Equal(jb.Deal.Commitments[i]))
+	}
+}
+
+func TestResultMsg(t *testing.T) {
+	msg := &ResultMsg{
+		SessionId:   *hashing.RandomHash(nil),
+		PubPolyHash: *hashing.RandomHash(nil),
+		Error:       "some error",
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, msg.Write(&buf))
+	back := &ResultMsg{}
+	assert.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	assert.EqualValues(t, msg, back)
+}
@@ -0,0 +1,71 @@
+package dkg
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/plugins/peering"
+	dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+)
+
+// DKG peer messages. All of them start with the id of the DKG session
+const (
+	MsgInit          = 0 + peering.FirstNodeMsgCode
+	MsgPubKey        = 1 + peering.FirstNodeMsgCode
+	MsgDeal          = 2 + peering.FirstNodeMsgCode
+	MsgResponse      = 3 + peering.FirstNodeMsgCode
+	MsgJustification = 4 + peering.FirstNodeMsgCode
+	MsgResult        = 5 + peering.FirstNodeMsgCode
+)
+
+// InitMsg is sent by the initiator of the DKG to all participants
+type InitMsg struct {
+	SessionId hashing.HashValue
+	T         uint16
+	// session timeout in milliseconds
+	Timeout uint32
+	// network locations and identity public keys of participants, in the order of their indices.
+	// In case of resharing these are holders of new shares
+	PeerLocations []string
+	PeerPubKeys   []string
+	// resharing only: the key set is reshared from old holders of shares to new ones,
+	// the master public key and the address remain the same
+	Reshare          bool
+	Address          address.Address
+	OldT             uint16
+	OldPeerLocations []string
+	OldPeerPubKeys   []string
+	// public commitments of the master polynomial. Needed for new holders to verify deals
+	PubCoeffs [][]byte
+}
+
+// PubKeyMsg carries the session key of the participant. Deals to the participant are encrypted with it
+type PubKeyMsg struct {
+	SessionId hashing.HashValue
+	PubKey    []byte
+}
+
+// DealMsg carries the deal of the sender, encrypted to the receiver
+type DealMsg struct {
+	SessionId hashing.HashValue
+	Deal      *dkg.Deal
+}
+
+// ResponseMsg carries approval or complaint of the sender about the deal. Broadcast to all participants
+type ResponseMsg struct {
+	SessionId hashing.HashValue
+	Response  *dkg.Response
+}
+
+// JustificationMsg is the answer of the dealer to the complaint about its deal. Broadcast to all participants
+type JustificationMsg struct {
+	SessionId     hashing.HashValue
+	Justification *dkg.Justification
+}
+
+// ResultMsg is the outcome of the DKG at the sender. Keys are saved only if all participants
+// have the same public polynomial
+type ResultMsg struct {
+	SessionId   hashing.HashValue
+	PubPolyHash hashing.HashValue
+	Error       string
+}
@@ -0,0 +1,52 @@
+package dkg
+
+// commitReshare replaces the old key share of the node with the new one, or deletes it if the node
+// is not a holder anymore. Old shares are useless after resharing, so the leaked old share doesn't compromise the key.
+// The committee of the smart contract is restarted with new holders of shares
+func (s *session) commitReshare() error {
+	if s.isReceiver() {
+		if err := s.node.env.replaceDKShare(s.dkshare); err != nil {
+			return err
+		}
+		s.log.Infow("Replaced key share",
+			"address", s.dkshare.Address.String(),
+			"N", s.dkshare.N,
+			"T", s.dkshare.T,
+			"Index", s.dkshare.Index,
+		)
+	} else {
+		if err := s.node.env.deleteDKShare(&s.address); err != nil {
+			return err
+		}
+		s.log.Infof("deleted key share of %s: the node is not a holder anymore", s.address.String())
+	}
+	bd, err := s.node.env.getBootupData(&s.address)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		// the node doesn't know the smart contract yet
+		return nil
+	}
+	bd.CommitteeNodes = make([]string, len(s.newNodes))
+	bd.CommitteePubKeys = make([]string, len(s.newNodes))
+	for i, idx := range s.newNodes {
+		bd.CommitteeNodes[i] = s.locations[idx]
+		bd.CommitteePubKeys[i] = s.pubKeys[idx]
+	}
+	// new holders of shares can't be access nodes at the same time
+	accessNodes := make([]string, 0, len(bd.AccessNodes))
+	accessPubKeys := make([]string, 0, len(bd.AccessPubKeys))
+	for i, pk := range bd.AccessPubKeys {
+		if indexOf(pk, bd.CommitteePubKeys) < 0 {
+			accessNodes = append(accessNodes, bd.AccessNodes[i])
+			accessPubKeys = append(accessPubKeys, pk)
+		}
+	}
+	bd.AccessNodes = accessNodes
+	bd.AccessPubKeys = accessPubKeys
+	if err := s.node.env.saveBootupData(bd); err != nil {
+		return err
+	}
+	return s.node.env.restartCommittee(bd)
+}
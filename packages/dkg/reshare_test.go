@@ -0,0 +1,160 @@
+package dkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupCommittee runs the DKG among the first n nodes and saves bootup data of the smart contract
+// with that committee and the rest of nodes as access nodes
+func setupCommittee(t *testing.T, tnet *testNetwork, n int, thr uint16) *address.Address {
+	addr, err := tnet.nodes[0].node.runDKG(tnet.locations()[:n], tnet.pubKeys()[:n], thr, 10*time.Second)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tnet.waitClosed(t)
+	bd := &registry.BootupData{
+		Address:          *addr,
+		CommitteeNodes:   tnet.locations()[:n],
+		CommitteePubKeys: tnet.pubKeys()[:n],
+		AccessNodes:      tnet.locations()[n:],
+		AccessPubKeys:    tnet.pubKeys()[n:],
+		Active:           true,
+	}
+	for _, tn := range tnet.nodes {
+		assert.NoError(t, tn.saveBootupData(bd))
+	}
+	return addr
+}
+
+// authorizeAll authorizes resharing at all old holders of shares except the initiator
+func authorizeAll(t *testing.T, tnet *testNetwork, addr *address.Address, initiator int, pubKeys []string, thr uint16) {
+	for _, tn := range tnet.nodes {
+		if _, ok, _ := tn.getDKShare(addr); ok && tn.index != initiator {
+			assert.NoError(t, tn.node.authorizeReshare(addr, pubKeys, thr, time.Minute))
+		}
+	}
+}
+
+func TestReshare(t *testing.T) {
+	tnet := newTestNetwork(5)
+	defer tnet.close()
+	addr := setupCommittee(t, tnet, 4, 3)
+
+	// node #3 leaves the committee, the access node #4 joins it
+	newNodes := []*testNode{tnet.nodes[0], tnet.nodes[1], tnet.nodes[2], tnet.nodes[4]}
+	locations := []string{tnet.nodes[0].location, tnet.nodes[1].location, tnet.nodes[2].location, tnet.nodes[4].location}
+	pubKeys := []string{tnet.nodes[0].pubKey, tnet.nodes[1].pubKey, tnet.nodes[2].pubKey, tnet.nodes[4].pubKey}
+	authorizeAll(t, tnet, addr, 0, pubKeys, 3)
+
+	err := tnet.nodes[0].node.runReshare(addr, locations, pubKeys, 3, 10*time.Second)
+	assert.NoError(t, err)
+	tnet.waitClosed(t)
+
+	// the address remains the same, new holders sign with it
+	checkKeySet(t, newNodes, addr)
+	for _, tn := range newNodes {
+		ks, _, _ := tn.getDKShare(addr)
+		assert.EqualValues(t, *addr, *ks.Address)
+	}
+	_, ok, _ := tnet.nodes[3].getDKShare(addr)
+	assert.False(t, ok)
+
+	for _, tn := range tnet.nodes {
+		bd, err := tn.getBootupData(addr)
+		assert.NoError(t, err)
+		assert.EqualValues(t, locations, bd.CommitteeNodes)
+		assert.EqualValues(t, pubKeys, bd.CommitteePubKeys)
+		assert.Len(t, bd.AccessPubKeys, 0)
+		assert.EqualValues(t, 1, tn.restarts)
+	}
+}
+
+func TestReshareNotAuthorized(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+	addr := setupCommittee(t, tnet, 4, 3)
+	before := tnet.nodes[1].savedDKShares()[0]
+
+	// only one of other old holders authorized resharing, others don't take part in it
+	assert.NoError(t, tnet.nodes[1].node.authorizeReshare(addr, tnet.pubKeys(), 4, time.Minute))
+
+	err := tnet.nodes[0].node.runReshare(addr, tnet.locations(), tnet.pubKeys(), 4, 2*time.Second)
+	assert.EqualError(t, err, "DKG session timeout")
+	tnet.waitClosed(t)
+
+	for _, tn := range tnet.nodes {
+		ks, ok, _ := tn.getDKShare(addr)
+		assert.True(t, ok)
+		assert.EqualValues(t, 3, ks.T)
+		assert.EqualValues(t, 0, tn.restarts)
+	}
+	after, _, _ := tnet.nodes[1].getDKShare(addr)
+	assert.True(t, before == after)
+	checkKeySet(t, tnet.nodes, addr)
+}
+
+func TestReshareAuthorizationMismatch(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+	addr := setupCommittee(t, tnet, 4, 3)
+
+	// the admin authorized resharing with another quorum
+	authorizeAll(t, tnet, addr, 0, tnet.pubKeys(), 3)
+
+	err := tnet.nodes[0].node.runReshare(addr, tnet.locations(), tnet.pubKeys(), 4, 2*time.Second)
+	assert.EqualError(t, err, "DKG session timeout")
+	tnet.waitClosed(t)
+
+	for _, tn := range tnet.nodes {
+		ks, _, _ := tn.getDKShare(addr)
+		assert.EqualValues(t, 3, ks.T)
+	}
+}
+
+func TestReshareNonMemberInitiator(t *testing.T) {
+	tnet := newTestNetwork(5)
+	defer tnet.close()
+	addr := setupCommittee(t, tnet, 4, 3)
+	authorizeAll(t, tnet, addr, -1, tnet.pubKeys(), 3)
+
+	// the access node tries to reshare the key set to itself and the committee
+	ks, _, _ := tnet.nodes[0].getDKShare(addr)
+	coeffs := ks.PubCoeffs()
+	pubCoeffs := make([][]byte, len(coeffs))
+	for i, c := range coeffs {
+		var err error
+		pubCoeffs[i], err = c.MarshalBinary()
+		assert.NoError(t, err)
+	}
+	_, err := tnet.nodes[4].node.runSession(&InitMsg{
+		SessionId:        *hashing.RandomHash(nil),
+		T:                3,
+		Timeout:          2000,
+		PeerLocations:    tnet.locations(),
+		PeerPubKeys:      tnet.pubKeys(),
+		Reshare:          true,
+		Address:          *addr,
+		OldT:             3,
+		OldPeerLocations: tnet.locations()[:4],
+		OldPeerPubKeys:   tnet.pubKeys()[:4],
+		PubCoeffs:        pubCoeffs,
+	})
+	assert.EqualError(t, err, "DKG session timeout")
+	tnet.waitClosed(t)
+
+	_, ok, _ := tnet.nodes[4].getDKShare(addr)
+	assert.False(t, ok)
+	for _, tn := range tnet.nodes[:4] {
+		ks, _, _ := tn.getDKShare(addr)
+		assert.EqualValues(t, 4, ks.N)
+		assert.EqualValues(t, 0, tn.restarts)
+		// the authorization is not used by the rejected session
+		assert.True(t, tn.node.takeReshareAuthorization(&InitMsg{Address: *addr, T: 3, PeerPubKeys: tnet.pubKeys()}))
+	}
+}
@@ -0,0 +1,740 @@
+package dkg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+)
+
+const (
+	tickPeriod = 500 * time.Millisecond
+	// maximum number of messages kept until the session is able to process them
+	maxPendingPerPeer = 64
+)
+
+// session is one run of the DKG protocol at the node.
+// All messages are processed in one goroutine
+type session struct {
+	node      *node
+	id        hashing.HashValue
+	initiator bool
+	// network locations and identities of all participants. In case of resharing these are old holders of shares
+	// followed by new holders which are not among old ones. Otherwise old and new holders are same
+	locations []string
+	pubKeys   []string
+	n         uint16
+	ownIndex  uint16
+	// indices of old and new holders of shares among participants, in the order of their share indices
+	oldNodes []uint16
+	newNodes []uint16
+	oldT     uint16
+	newT     uint16
+	// resharing only
+	reshare   bool
+	address   address.Address
+	pubCoeffs []kyber.Point
+	// own share of the reshared key set. Nil if the node is not an old holder
+	oldShare *tcrypto.DKShare
+	// peers are indexed same way as participants. Own peer is nil
+	peers   []participant
+	started time.Time
+	timeout time.Duration
+	// session key pair. Deals are encrypted to participants with their session keys
+	longterm     kyber.Scalar
+	longtermPubs []kyber.Point
+	numPubs      int
+	gen          *dkg.DistKeyGenerator
+	// dealer indices of processed deals
+	dealsProcessed map[uint32]bool
+	// own shares revealed by justifications of dealers the node complained about, by dealer index
+	justifiedShares map[uint32]*share.PriShare
+	// messages which can't be processed yet, for example responses to not yet received deals
+	pending []*sessionMsg
+	// messages not sent yet because peer was not connected, by peer index
+	outbox [][]*peering.PeerMessage
+	// outcome of the DKG at each new holder of shares
+	results  []*ResultMsg
+	dkshare  *tcrypto.DKShare
+	finished bool
+	onFinish func(addr *address.Address, err error)
+	chMsg    chan *sessionMsg
+	log      *logger.Logger
+}
+
+// sessionMsg is the message of the session with the participant it was received from
+type sessionMsg struct {
+	msg    *peering.PeerMessage
+	sender participant
+}
+
+func newSession(n *node, init *InitMsg, initiator bool, onFinish func(addr *address.Address, err error)) (*session, error) {
+	if init.Timeout == 0 || time.Duration(init.Timeout)*time.Millisecond > MaxTimeout {
+		return nil, fmt.Errorf("wrong timeout %d ms", init.Timeout)
+	}
+	ret := &session{
+		node:            n,
+		id:              init.SessionId,
+		initiator:       initiator,
+		newT:            init.T,
+		reshare:         init.Reshare,
+		started:         time.Now(),
+		timeout:         time.Duration(init.Timeout) * time.Millisecond,
+		dealsProcessed:  make(map[uint32]bool),
+		justifiedShares: make(map[uint32]*share.PriShare),
+		pending:         make([]*sessionMsg, 0),
+		onFinish:        onFinish,
+	}
+	var err error
+	if init.Reshare {
+		err = ret.initParticipants(init.OldPeerLocations, init.OldPeerPubKeys, init.PeerLocations, init.PeerPubKeys)
+		if err == nil {
+			err = ret.initReshare(init)
+		}
+	} else {
+		err = ret.initParticipants(init.PeerLocations, init.PeerPubKeys, init.PeerLocations, init.PeerPubKeys)
+		ret.oldT = init.T
+	}
+	if err != nil {
+		return nil, err
+	}
+	numPeers := ret.n
+	ret.longtermPubs = make([]kyber.Point, numPeers)
+	ret.outbox = make([][]*peering.PeerMessage, numPeers)
+	ret.results = make([]*ResultMsg, numPeers)
+	ret.chMsg = make(chan *sessionMsg, maxPendingPerPeer*int(numPeers)+maxOrphanMessages)
+	ret.log = log.Named(util.Short(init.SessionId.String()))
+
+	ret.peers = make([]participant, 0, numPeers)
+	for i, loc := range ret.locations {
+		peer, err := n.env.usePeer(loc, ret.pubKeys[i])
+		if err != nil {
+			ret.releasePeers()
+			return nil, err
+		}
+		ret.peers = append(ret.peers, peer)
+	}
+	ret.longterm = suite.Scalar().Pick(suite.RandomStream())
+	ret.longtermPubs[ret.ownIndex] = suite.Point().Mul(ret.longterm, nil)
+	ret.numPubs = 1
+
+	if initiator {
+		ret.sendToAll(MsgInit, init)
+	}
+	pubKeyBin, err := ret.longtermPubs[ret.ownIndex].MarshalBinary()
+	if err != nil {
+		ret.releasePeers()
+		return nil, err
+	}
+	ret.sendToAll(MsgPubKey, &PubKeyMsg{
+		SessionId: ret.id,
+		PubKey:    pubKeyBin,
+	})
+	if init.Reshare {
+		ret.log.Infof("resharing session started. Address %s, old N = %d, T = %d, new N = %d, T = %d, own index = %d, initiator = %v",
+			init.Address.String(), len(ret.oldNodes), ret.oldT, len(ret.newNodes), ret.newT, ret.ownIndex, initiator)
+	} else {
+		ret.log.Infof("DKG session started. N = %d, T = %d, own index = %d, initiator = %v",
+			numPeers, ret.newT, ret.ownIndex, initiator)
+	}
+	return ret, nil
+}
+
+// initParticipants builds the list of participants from old and new holders of shares
+func (s *session) initParticipants(oldLocations, oldPubKeys, newLocations, newPubKeys []string) error {
+	if len(oldLocations) != len(oldPubKeys) || len(newLocations) != len(newPubKeys) {
+		return fmt.Errorf("number of identities must be equal to number of participants")
+	}
+	if util.ContainsDuplicates(oldLocations) || util.ContainsDuplicates(oldPubKeys) ||
+		util.ContainsDuplicates(newLocations) || util.ContainsDuplicates(newPubKeys) {
+		return fmt.Errorf("duplicate participants")
+	}
+	if err := tcrypto.ValidateDKSParams(s.newT, uint16(len(newLocations)), 0); err != nil {
+		return err
+	}
+	s.locations = append([]string{}, oldLocations...)
+	s.pubKeys = append([]string{}, oldPubKeys...)
+	s.oldNodes = make([]uint16, len(oldLocations))
+	for i := range s.oldNodes {
+		s.oldNodes[i] = uint16(i)
+	}
+	s.newNodes = make([]uint16, len(newLocations))
+	for i, pk := range newPubKeys {
+		idx := indexOf(pk, s.pubKeys)
+		switch {
+		case idx < 0:
+			idx = len(s.pubKeys)
+			s.locations = append(s.locations, newLocations[i])
+			s.pubKeys = append(s.pubKeys, pk)
+		case s.locations[idx] != newLocations[i]:
+			return fmt.Errorf("participant %s is listed with different network locations", pk)
+		}
+		s.newNodes[i] = uint16(idx)
+	}
+	if util.ContainsDuplicates(s.locations) {
+		return fmt.Errorf("duplicate participants")
+	}
+	s.n = uint16(len(s.locations))
+	ownIndex := indexOf(s.node.env.myPubKey(), s.pubKeys)
+	if ownIndex < 0 {
+		return fmt.Errorf("the node is not a participant of the DKG")
+	}
+	s.ownIndex = uint16(ownIndex)
+	return nil
+}
+
+// initReshare checks the reshared key set against the own share, if the node is an old holder
+func (s *session) initReshare(init *InitMsg) error {
+	s.oldT = init.OldT
+	s.address = init.Address
+	if err := tcrypto.ValidateDKSParams(s.oldT, uint16(len(s.oldNodes)), 0); err != nil {
+		return err
+	}
+	if len(init.PubCoeffs) != int(s.oldT) {
+		return fmt.Errorf("wrong number of public coefficients")
+	}
+	s.pubCoeffs = make([]kyber.Point, len(init.PubCoeffs))
+	for i, data := range init.PubCoeffs {
+		s.pubCoeffs[i] = suite.Point()
+		if err := s.pubCoeffs[i].UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+	if address.FromBLSPubKey(init.PubCoeffs[0]) != s.address {
+		return fmt.Errorf("public coefficients don't correspond to the address %s", s.address.String())
+	}
+	oldShare, exists, err := s.node.env.getDKShare(&s.address)
+	if err != nil {
+		return err
+	}
+	ownOld := s.oldIndex(s.ownIndex)
+	if ownOld < 0 {
+		if exists {
+			return fmt.Errorf("new holder already has a key share of %s", s.address.String())
+		}
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("key share of %s not found", s.address.String())
+	}
+	if !oldShare.HasPrivateKey() {
+		return fmt.Errorf("key share of %s is kept by the remote signer, the node can't deal it to new holders", s.address.String())
+	}
+	if oldShare.N != uint16(len(s.oldNodes)) || oldShare.T != s.oldT || oldShare.Index != uint16(ownOld) {
+		return fmt.Errorf("reshared key set is inconsistent with the own key share")
+	}
+	for i, c := range oldShare.PubCoeffs() {
+		if !c.Equal(s.pubCoeffs[i]) {
+			return fmt.Errorf("reshared key set is inconsistent with the own key share")
+		}
+	}
+	s.oldShare = oldShare
+	return nil
+}
+
+func newSessionId() (hashing.HashValue, error) {
+	var ret hashing.HashValue
+	_, err := rand.Read(ret[:])
+	return ret, err
+}
+
+func (s *session) run() {
+	ticker := time.NewTicker(tickPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-s.chMsg:
+			s.processMsg(msg)
+		case <-ticker.C:
+			if s.timerTick() {
+				return
+			}
+		}
+	}
+}
+
+func (s *session) receive(msg *sessionMsg) {
+	select {
+	case s.chMsg <- msg:
+	default:
+		s.log.Warnf("message queue is full. Message dropped")
+	}
+}
+
+func (s *session) releasePeers() {
+	for _, peer := range s.peers {
+		if peer != nil {
+			s.node.env.stopUsingPeer(peer)
+		}
+	}
+}
+
+func indexOf(s string, lst []string) int {
+	for i, e := range lst {
+		if e == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// oldIndex returns index of the participant among old holders of shares, or -1
+func (s *session) oldIndex(peerIndex uint16) int {
+	for i, idx := range s.oldNodes {
+		if idx == peerIndex {
+			return i
+		}
+	}
+	return -1
+}
+
+// newIndex returns index of the participant among new holders of shares, or -1
+func (s *session) newIndex(peerIndex uint16) int {
+	for i, idx := range s.newNodes {
+		if idx == peerIndex {
+			return i
+		}
+	}
+	return -1
+}
+
+// isReceiver is true if the node receives a new share
+func (s *session) isReceiver() bool {
+	return s.newIndex(s.ownIndex) >= 0
+}
+
+// timerTick resends undelivered messages and handles timeouts.
+// Returns true if the session is over and can be closed
+func (s *session) timerTick() bool {
+	s.flushOutbox()
+
+	if s.finished {
+		// the session is kept until all messages are delivered, other participants may still need them
+		if s.outboxEmpty() || time.Since(s.started) > s.timeout {
+			s.node.closeSession(s)
+			return true
+		}
+		return false
+	}
+	switch {
+	case time.Since(s.started) > s.timeout:
+		s.finish(nil, fmt.Errorf("DKG session timeout"))
+		s.node.closeSession(s)
+		return true
+
+	case s.gen != nil && s.isReceiver() && s.results[s.ownIndex] == nil && time.Since(s.started) > s.timeout/2:
+		// not all deals were certified in time. Deals of participants which didn't get enough
+		// approvals or didn't justify complaints are excluded
+		s.gen.SetTimeout()
+		if s.gen.ThresholdCertified() {
+			s.log.Warnf("not all deals were certified in time. Qualified dealers: %+v", s.gen.QUAL())
+			s.produceResult()
+		} else {
+			s.setOwnResult(nil, fmt.Errorf("distributed key not certified"))
+		}
+	}
+	return false
+}
+
+func (s *session) processMsg(smsg *sessionMsg) {
+	msg := smsg.msg
+	if msg.SenderIndex >= s.n || msg.SenderIndex == s.ownIndex || smsg.sender == nil || smsg.sender != s.peers[msg.SenderIndex] {
+		s.log.Warnf("message from unexpected sender #%d", msg.SenderIndex)
+		return
+	}
+	if s.finished {
+		return
+	}
+	if !s.processPeerMessage(msg) {
+		// can't process it yet
+		if len(s.pending) < maxPendingPerPeer*int(s.n) {
+			s.pending = append(s.pending, smsg)
+		}
+		return
+	}
+	s.processPending()
+	s.checkProgress()
+}
+
+// processPending retries messages received too early, until no more of them can be processed
+func (s *session) processPending() {
+	for progress := true; progress; {
+		progress = false
+		remaining := s.pending[:0]
+		for _, smsg := range s.pending {
+			if s.processPeerMessage(smsg.msg) {
+				progress = true
+			} else {
+				remaining = append(remaining, smsg)
+			}
+		}
+		s.pending = remaining
+	}
+}
+
+// processPeerMessage returns false if the message can't be processed yet
+func (s *session) processPeerMessage(msg *peering.PeerMessage) bool {
+	rdr := bytes.NewReader(msg.MsgData)
+
+	switch msg.MsgType {
+	case MsgInit:
+		// initiator resends it until delivered, nothing to do
+
+	case MsgPubKey:
+		msgt := &PubKeyMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			s.log.Error(err)
+			return true
+		}
+		s.eventPubKeyMsg(msgt, msg.SenderIndex)
+
+	case MsgDeal:
+		if s.gen == nil {
+			return false
+		}
+		msgt := &DealMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			s.log.Error(err)
+			return true
+		}
+		s.eventDealMsg(msgt, msg.SenderIndex)
+
+	case MsgResponse:
+		msgt := &ResponseMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			s.log.Error(err)
+			return true
+		}
+		// node which doesn't receive a new share only checks responses to the own deal
+		if s.gen == nil || (s.isReceiver() && !s.dealsProcessed[msgt.Response.Index]) {
+			return false
+		}
+		s.eventResponseMsg(msgt, msg.SenderIndex)
+
+	case MsgJustification:
+		msgt := &JustificationMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			s.log.Error(err)
+			return true
+		}
+		if !s.isReceiver() {
+			return true
+		}
+		if s.gen == nil || !s.dealsProcessed[msgt.Justification.Index] {
+			return false
+		}
+		if err := s.gen.ProcessJustification(msgt.Justification); err != nil {
+			s.log.Warnf("wrong justification from #%d: %v", msg.SenderIndex, err)
+			return true
+		}
+		if j := msgt.Justification.Justification; int(j.Index) == s.newIndex(s.ownIndex) && j.Deal.SecShare.I == int(j.Index) {
+			s.justifiedShares[msgt.Justification.Index] = j.Deal.SecShare
+		}
+
+	case MsgResult:
+		msgt := &ResultMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			s.log.Error(err)
+			return true
+		}
+		if s.newIndex(msg.SenderIndex) < 0 {
+			s.log.Warnf("result from #%d which is not a holder of new share", msg.SenderIndex)
+			return true
+		}
+		if s.results[msg.SenderIndex] == nil {
+			s.results[msg.SenderIndex] = msgt
+		}
+
+	default:
+		s.log.Errorf("wrong message type %d", msg.MsgType)
+	}
+	return true
+}
+
+func (s *session) eventPubKeyMsg(msg *PubKeyMsg, senderIndex uint16) {
+	if s.longtermPubs[senderIndex] != nil {
+		return
+	}
+	pub := suite.Point()
+	if err := pub.UnmarshalBinary(msg.PubKey); err != nil {
+		s.log.Warnf("wrong session key from #%d: %v", senderIndex, err)
+		return
+	}
+	s.longtermPubs[senderIndex] = pub
+	s.numPubs++
+	if s.numPubs < int(s.n) {
+		return
+	}
+	// all session keys are known. Deals can be created
+	var err error
+	s.gen, err = s.newGenerator()
+	if err != nil {
+		s.setOwnResult(nil, err)
+		return
+	}
+	deals, err := s.gen.Deals()
+	if err != nil {
+		s.setOwnResult(nil, err)
+		return
+	}
+	if idx := s.oldIndex(s.ownIndex); idx >= 0 && s.isReceiver() {
+		// own deal is processed by the generator
+		s.dealsProcessed[uint32(idx)] = true
+	}
+	// deals are indexed by new holders of shares
+	for i, deal := range deals {
+		s.sendTo(s.newNodes[i], MsgDeal, &DealMsg{
+			SessionId: s.id,
+			Deal:      deal,
+		})
+	}
+}
+
+func (s *session) newGenerator() (*dkg.DistKeyGenerator, error) {
+	oldNodes := make([]kyber.Point, len(s.oldNodes))
+	for i, idx := range s.oldNodes {
+		oldNodes[i] = s.longtermPubs[idx]
+	}
+	if !s.reshare {
+		return dkg.NewDistKeyGenerator(suite, s.longterm, oldNodes, int(s.newT))
+	}
+	newNodes := make([]kyber.Point, len(s.newNodes))
+	for i, idx := range s.newNodes {
+		newNodes[i] = s.longtermPubs[idx]
+	}
+	c := &dkg.Config{
+		Suite:        suite,
+		Longterm:     s.longterm,
+		OldNodes:     oldNodes,
+		NewNodes:     newNodes,
+		Threshold:    int(s.newT),
+		OldThreshold: int(s.oldT),
+	}
+	if s.oldShare != nil {
+		c.Share = &dkg.DistKeyShare{
+			Share:   s.oldShare.PriShare(),
+			Commits: s.pubCoeffs,
+		}
+	} else {
+		c.PublicCoeffs = s.pubCoeffs
+	}
+	return dkg.NewDistKeyHandler(c)
+}
+
+func (s *session) eventDealMsg(msg *DealMsg, senderIndex uint16) {
+	if int(msg.Deal.Index) != s.oldIndex(senderIndex) || s.dealsProcessed[msg.Deal.Index] {
+		return
+	}
+	resp, err := s.gen.ProcessDeal(msg.Deal)
+	if err != nil {
+		// the deal will be excluded because no response is issued
+		s.log.Warnf("wrong deal from #%d: %v", senderIndex, err)
+		return
+	}
+	s.dealsProcessed[msg.Deal.Index] = true
+	if !resp.Response.Status {
+		s.log.Warnf("complaint about the deal from #%d", senderIndex)
+	}
+	s.sendToAll(MsgResponse, &ResponseMsg{
+		SessionId: s.id,
+		Response:  resp,
+	})
+}
+
+func (s *session) eventResponseMsg(msg *ResponseMsg, senderIndex uint16) {
+	if int(msg.Response.Response.Index) != s.newIndex(senderIndex) {
+		s.log.Warnf("response with wrong index from #%d", senderIndex)
+		return
+	}
+	j, err := s.gen.ProcessResponse(msg.Response)
+	if err != nil {
+		s.log.Debugf("response from #%d: %v", senderIndex, err)
+		return
+	}
+	if j != nil {
+		// complaint about the own deal
+		s.log.Warnf("participant #%d complained about the own deal. Sending justification", senderIndex)
+		s.sendToAll(MsgJustification, &JustificationMsg{
+			SessionId:     s.id,
+			Justification: j,
+		})
+	}
+}
+
+func (s *session) checkProgress() {
+	if s.gen != nil && s.isReceiver() && s.results[s.ownIndex] == nil && s.gen.Certified() {
+		s.produceResult()
+	}
+	s.checkResults()
+}
+
+func (s *session) produceResult() {
+	// the generator keeps the deal the node complained about even if the dealer justified it.
+	// The share revealed by the justification is used instead
+	verifiers := s.gen.Verifiers()
+	for idx, sh := range s.justifiedShares {
+		if deal := verifiers[idx].Deal(); deal != nil {
+			deal.SecShare = sh
+		}
+	}
+	dks, err := s.gen.DistKeyShare()
+	if err != nil {
+		s.setOwnResult(nil, err)
+		return
+	}
+	ks, err := tcrypto.NewDKShareFromDistKey(s.newT, uint16(len(s.newNodes)), dks.Share, dks.Commits)
+	if err != nil {
+		s.setOwnResult(nil, err)
+		return
+	}
+	if s.reshare && *ks.Address != s.address {
+		s.setOwnResult(nil, fmt.Errorf("reshared key set has different address %s", ks.Address.String()))
+		return
+	}
+	commits := make([][]byte, len(dks.Commits))
+	for i, c := range dks.Commits {
+		if commits[i], err = c.MarshalBinary(); err != nil {
+			s.setOwnResult(nil, err)
+			return
+		}
+	}
+	s.setOwnResult(ks, nil)
+	s.results[s.ownIndex].PubPolyHash = *hashing.HashData(commits...)
+	s.sendToAll(MsgResult, s.results[s.ownIndex])
+}
+
+func (s *session) setOwnResult(ks *tcrypto.DKShare, err error) {
+	s.dkshare = ks
+	s.results[s.ownIndex] = &ResultMsg{SessionId: s.id}
+	if err != nil {
+		s.log.Errorf("DKG failed: %v", err)
+		s.results[s.ownIndex].Error = err.Error()
+		s.sendToAll(MsgResult, s.results[s.ownIndex])
+	}
+}
+
+// checkResults finishes the session when results of all new holders of shares are known.
+// The key share is saved only if all of them have the same public polynomial
+func (s *session) checkResults() {
+	if s.finished {
+		return
+	}
+	own := s.results[s.ownIndex]
+	if own != nil && own.Error != "" {
+		s.finish(nil, fmt.Errorf("DKG failed: %s", own.Error))
+		return
+	}
+	if own == nil && s.isReceiver() {
+		return
+	}
+	for _, i := range s.newNodes {
+		res := s.results[i]
+		if res == nil {
+			continue
+		}
+		if res.Error != "" {
+			s.finish(nil, fmt.Errorf("DKG failed at participant #%d: %s", i, res.Error))
+			return
+		}
+		if own == nil {
+			// the node doesn't receive a new share. The result of the first holder is the reference
+			own = res
+		}
+		if res.PubPolyHash != own.PubPolyHash {
+			s.finish(nil, fmt.Errorf("DKG failed: participant #%d has different public key", i))
+			return
+		}
+	}
+	for _, i := range s.newNodes {
+		if s.results[i] == nil {
+			return
+		}
+	}
+	if !s.reshare {
+		if err := s.node.env.saveDKShare(s.dkshare); err != nil {
+			s.finish(nil, err)
+			return
+		}
+		s.log.Infow("Created new key share",
+			"address", s.dkshare.Address.String(),
+			"N", s.dkshare.N,
+			"T", s.dkshare.T,
+			"Index", s.dkshare.Index,
+		)
+		s.finish(s.dkshare.Address, nil)
+		return
+	}
+	if err := s.commitReshare(); err != nil {
+		s.finish(nil, err)
+		return
+	}
+	s.finish(&s.address, nil)
+}
+
+func (s *session) finish(addr *address.Address, err error) {
+	if s.finished {
+		return
+	}
+	s.finished = true
+	s.pending = nil
+	if s.onFinish != nil {
+		s.onFinish(addr, err)
+	}
+}
+
+// sendTo puts the message to the outbox of the participant and tries to deliver it
+func (s *session) sendTo(index uint16, msgType byte, msg interface{ Write(io.Writer) error }) {
+	if index == s.ownIndex {
+		return
+	}
+	s.outbox[index] = append(s.outbox[index], &peering.PeerMessage{
+		MsgType:     msgType,
+		SenderIndex: s.ownIndex,
+		MsgData:     util.MustBytes(msg),
+	})
+	s.flushPeer(index)
+}
+
+func (s *session) sendToAll(msgType byte, msg interface{ Write(io.Writer) error }) {
+	for i := range s.peers {
+		s.sendTo(uint16(i), msgType, msg)
+	}
+}
+
+func (s *session) flushPeer(index uint16) {
+	for len(s.outbox[index]) > 0 {
+		if err := s.peers[index].SendMsg(s.outbox[index][0]); err != nil {
+			return
+		}
+		s.outbox[index] = s.outbox[index][1:]
+	}
+}
+
+func (s *session) flushOutbox() {
+	for i := range s.outbox {
+		if uint16(i) != s.ownIndex {
+			s.flushPeer(uint16(i))
+		}
+	}
+}
+
+func (s *session) outboxEmpty() bool {
+	for _, msgs := range s.outbox {
+		if len(msgs) > 0 {
+			return false
+		}
+	}
+	return true
+}
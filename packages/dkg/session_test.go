@@ -0,0 +1,515 @@
+package dkg
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/stretchr/testify/assert"
+	"go.dedis.ch/kyber/v3"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+	"go.dedis.ch/protobuf"
+	"golang.org/x/crypto/hkdf"
+)
+
+func init() {
+	log = logger.NewNopLogger()
+}
+
+// testNetwork connects DKG nodes running in one process. Messages are delivered asynchronously,
+// each node processes incoming messages in its own goroutine
+type testNetwork struct {
+	nodes []*testNode
+	mutex sync.Mutex
+	// links which are down, by sender and receiver index
+	down map[[2]int]bool
+	// tamper is called for each message sent. It may change the message or return nil to drop it
+	tamper func(from, to int, msg *peering.PeerMessage) *peering.PeerMessage
+}
+
+// testNode is the DKG node with the in-memory registry
+type testNode struct {
+	net      *testNetwork
+	index    int
+	pubKey   string
+	location string
+	node     *node
+	inbox    chan *testDelivery
+	peers    map[string]*testPeer
+	dkshares map[address.Address]*tcrypto.DKShare
+	bootup   map[address.Address]*registry.BootupData
+	restarts int
+	mutex    sync.Mutex
+}
+
+type testDelivery struct {
+	from int
+	msg  *peering.PeerMessage
+}
+
+// testPeer is the connection from the node to another node
+type testPeer struct {
+	from *testNode
+	to   *testNode
+}
+
+func newTestNetwork(n int) *testNetwork {
+	ret := &testNetwork{
+		nodes: make([]*testNode, n),
+		down:  make(map[[2]int]bool),
+	}
+	for i := range ret.nodes {
+		tn := &testNode{
+			net:      ret,
+			index:    i,
+			pubKey:   fmt.Sprintf("pubkey#%d", i),
+			location: fmt.Sprintf("127.0.0.1:%d", 4000+i),
+			inbox:    make(chan *testDelivery, 1000),
+			peers:    make(map[string]*testPeer),
+			dkshares: make(map[address.Address]*tcrypto.DKShare),
+			bootup:   make(map[address.Address]*registry.BootupData),
+		}
+		tn.node = newNode(tn)
+		ret.nodes[i] = tn
+		go tn.receiveLoop()
+	}
+	return ret
+}
+
+func (tnet *testNetwork) close() {
+	for _, tn := range tnet.nodes {
+		close(tn.inbox)
+	}
+}
+
+func (tnet *testNetwork) locations() []string {
+	ret := make([]string, len(tnet.nodes))
+	for i, tn := range tnet.nodes {
+		ret[i] = tn.location
+	}
+	return ret
+}
+
+func (tnet *testNetwork) pubKeys() []string {
+	ret := make([]string, len(tnet.nodes))
+	for i, tn := range tnet.nodes {
+		ret[i] = tn.pubKey
+	}
+	return ret
+}
+
+func (tnet *testNetwork) setLink(from, to int, up bool) {
+	tnet.mutex.Lock()
+	defer tnet.mutex.Unlock()
+	tnet.down[[2]int{from, to}] = !up
+}
+
+func (tnet *testNetwork) setTamper(f func(from, to int, msg *peering.PeerMessage) *peering.PeerMessage) {
+	tnet.mutex.Lock()
+	defer tnet.mutex.Unlock()
+	tnet.tamper = f
+}
+
+func (tnet *testNetwork) nodeByPubKey(pubKey string) *testNode {
+	for _, tn := range tnet.nodes {
+		if tn.pubKey == pubKey {
+			return tn
+		}
+	}
+	return nil
+}
+
+// waitClosed waits until all DKG sessions of all nodes are closed
+func (tnet *testNetwork) waitClosed(t *testing.T) {
+	deadline := time.Now().Add(30 * time.Second)
+	for _, tn := range tnet.nodes {
+		for tn.numSessions() > 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("DKG sessions of node #%d are not closed", tn.index)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func (tn *testNode) receiveLoop() {
+	for d := range tn.inbox {
+		sender := tn.peer(tn.net.nodes[d.from].pubKey)
+		tn.node.processPeerMessage(&sessionMsg{msg: d.msg, sender: sender})
+	}
+}
+
+// peer returns the connection to the node. Same connection is returned each time
+func (tn *testNode) peer(pubKey string) *testPeer {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	ret, ok := tn.peers[pubKey]
+	if !ok {
+		ret = &testPeer{from: tn, to: tn.net.nodeByPubKey(pubKey)}
+		tn.peers[pubKey] = ret
+	}
+	return ret
+}
+
+func (tn *testNode) numSessions() int {
+	tn.node.sessionsMutex.Lock()
+	defer tn.node.sessionsMutex.Unlock()
+	return len(tn.node.sessions)
+}
+
+func (tn *testNode) numOrphans() int {
+	tn.node.sessionsMutex.Lock()
+	defer tn.node.sessionsMutex.Unlock()
+	return tn.node.numOrphans
+}
+
+// session returns the running DKG session of the node
+func (tn *testNode) session() *session {
+	tn.node.sessionsMutex.Lock()
+	defer tn.node.sessionsMutex.Unlock()
+	for _, s := range tn.node.sessions {
+		return s
+	}
+	return nil
+}
+
+func (tn *testNode) savedDKShares() []*tcrypto.DKShare {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	ret := make([]*tcrypto.DKShare, 0, len(tn.dkshares))
+	for _, ks := range tn.dkshares {
+		ret = append(ret, ks)
+	}
+	return ret
+}
+
+func (p *testPeer) PubKey() string {
+	return p.to.pubKey
+}
+
+func (p *testPeer) SendMsg(msg *peering.PeerMessage) error {
+	tnet := p.from.net
+	tnet.mutex.Lock()
+	down := tnet.down[[2]int{p.from.index, p.to.index}]
+	tamper := tnet.tamper
+	tnet.mutex.Unlock()
+	if down {
+		return fmt.Errorf("node #%d is not connected", p.to.index)
+	}
+	cpy := *msg
+	msg = &cpy
+	if tamper != nil {
+		if msg = tamper(p.from.index, p.to.index, msg); msg == nil {
+			return nil
+		}
+	}
+	p.to.inbox <- &testDelivery{from: p.from.index, msg: msg}
+	return nil
+}
+
+func (tn *testNode) myPubKey() string {
+	return tn.pubKey
+}
+
+func (tn *testNode) isKnownIdentity(pubKey string) bool {
+	return tn.net.nodeByPubKey(pubKey) != nil
+}
+
+func (tn *testNode) getPeerAddress(pubKey string) (string, bool, error) {
+	peer := tn.net.nodeByPubKey(pubKey)
+	if peer == nil {
+		return "", false, nil
+	}
+	return peer.location, true, nil
+}
+
+func (tn *testNode) usePeer(location, pubKey string) (participant, error) {
+	if pubKey == tn.pubKey {
+		return nil, nil
+	}
+	peer := tn.net.nodeByPubKey(pubKey)
+	if peer == nil || peer.location != location {
+		return nil, fmt.Errorf("unknown peer %s", pubKey)
+	}
+	return tn.peer(pubKey), nil
+}
+
+func (tn *testNode) stopUsingPeer(p participant) {
+}
+
+func (tn *testNode) getDKShare(addr *address.Address) (*tcrypto.DKShare, bool, error) {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	ks, ok := tn.dkshares[*addr]
+	return ks, ok, nil
+}
+
+func (tn *testNode) saveDKShare(ks *tcrypto.DKShare) error {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	if _, ok := tn.dkshares[*ks.Address]; ok {
+		return fmt.Errorf("key share of %s already exists", ks.Address.String())
+	}
+	tn.dkshares[*ks.Address] = ks
+	return nil
+}
+
+func (tn *testNode) replaceDKShare(ks *tcrypto.DKShare) error {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	tn.dkshares[*ks.Address] = ks
+	return nil
+}
+
+func (tn *testNode) deleteDKShare(addr *address.Address) error {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	delete(tn.dkshares, *addr)
+	return nil
+}
+
+func (tn *testNode) getBootupData(addr *address.Address) (*registry.BootupData, error) {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	bd, ok := tn.bootup[*addr]
+	if !ok {
+		return nil, nil
+	}
+	cpy := *bd
+	return &cpy, nil
+}
+
+func (tn *testNode) saveBootupData(bd *registry.BootupData) error {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	cpy := *bd
+	tn.bootup[bd.Address] = &cpy
+	return nil
+}
+
+func (tn *testNode) restartCommittee(bd *registry.BootupData) error {
+	tn.mutex.Lock()
+	defer tn.mutex.Unlock()
+	tn.restarts++
+	return nil
+}
+
+// checkKeySet checks that the key shares saved by the nodes belong to one key set and can sign with it
+func checkKeySet(t *testing.T, nodes []*testNode, addr *address.Address) {
+	var first *tcrypto.DKShare
+	sigShares := make([][]byte, 0, len(nodes))
+	data := []byte("data to sign")
+	for _, tn := range nodes {
+		ks, ok, _ := tn.getDKShare(addr)
+		if !assert.True(t, ok, "node #%d has no key share", tn.index) {
+			return
+		}
+		if first == nil {
+			first = ks
+		}
+		assert.True(t, ks.PubKeyMaster.Equal(first.PubKeyMaster))
+		assert.EqualValues(t, first.N, ks.N)
+		assert.EqualValues(t, first.T, ks.T)
+		for i, c := range ks.PubCoeffs() {
+			assert.True(t, c.Equal(first.PubCoeffs()[i]))
+		}
+		sigShare, err := ks.SignShare(data)
+		assert.NoError(t, err)
+		assert.NoError(t, first.VerifySigShare(data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	sig, err := first.RecoverFullSignature(sigShares[:first.T], data)
+	assert.NoError(t, err)
+	assert.True(t, sig.IsValid(data))
+}
+
+func TestDKG(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+
+	// the last node doesn't receive the init message of the initiator at first.
+	// Messages of other participants wait until the session is started
+	tnet.setLink(0, 3, false)
+
+	type result struct {
+		addr *address.Address
+		err  error
+	}
+	chDone := make(chan result, 1)
+	go func() {
+		addr, err := tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 3, 10*time.Second)
+		chDone <- result{addr, err}
+	}()
+	for tnet.nodes[3].numOrphans() < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// undelivered messages are resent by the initiator
+	tnet.setLink(0, 3, true)
+
+	res := <-chDone
+	assert.NoError(t, res.err)
+	tnet.waitClosed(t)
+	if !assert.NotNil(t, res.addr) {
+		return
+	}
+	checkKeySet(t, tnet.nodes, res.addr)
+	assert.EqualValues(t, 0, tnet.nodes[3].numOrphans())
+}
+
+func TestDKGWrongParams(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+
+	_, err := tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 1, 10*time.Second)
+	assert.Error(t, err)
+	_, err = tnet.nodes[0].node.runDKG(tnet.locations()[1:], tnet.pubKeys()[1:], 2, 10*time.Second)
+	assert.Error(t, err)
+	_, err = tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 3, MaxTimeout+time.Second)
+	assert.Error(t, err)
+}
+
+// badDeal replaces the share of the deal with the wrong one. The deal is still encrypted to the receiver
+// and signed by the dealer, so the receiver complains about it
+func badDeal(t *testing.T, dealer, receiver *session, msg *peering.PeerMessage) *peering.PeerMessage {
+	dm := &DealMsg{}
+	if !assert.NoError(t, dm.Read(bytes.NewReader(msg.MsgData))) {
+		return msg
+	}
+	ed := dm.Deal.Deal
+	dhKey := suite.Point()
+	assert.NoError(t, dhKey.UnmarshalBinary(ed.DHKey))
+	gcm := dealAEAD(t, suite.Point().Mul(receiver.longterm, dhKey), dealer.longtermPubs[dealer.ownIndex], dealer.longtermPubs)
+	hkdfContext := dealContext(dealer.longtermPubs[dealer.ownIndex], dealer.longtermPubs)
+	plain, err := gcm.Open(nil, ed.Nonce, ed.Cipher, hkdfContext)
+	if !assert.NoError(t, err) {
+		return msg
+	}
+	deal := &vss.Deal{}
+	var point kyber.Point
+	var scalar kyber.Scalar
+	constructors := protobuf.Constructors{
+		reflect.TypeOf(&point).Elem():  func() interface{} { return suite.Point() },
+		reflect.TypeOf(&scalar).Elem(): func() interface{} { return suite.Scalar() },
+	}
+	assert.NoError(t, protobuf.DecodeWithConstructors(plain, deal, constructors))
+	deal.SecShare.V = suite.Scalar().Add(deal.SecShare.V, suite.Scalar().One())
+	plain, err = protobuf.Encode(deal)
+	assert.NoError(t, err)
+	ed.Cipher = gcm.Seal(nil, ed.Nonce, plain, hkdfContext)
+
+	buf, err := dm.Deal.MarshalBinary()
+	assert.NoError(t, err)
+	dm.Deal.Signature, err = schnorr.Sign(suite, dealer.longterm, buf)
+	assert.NoError(t, err)
+	msg.MsgData = util.MustBytes(dm)
+	return msg
+}
+
+// dealAEAD and dealContext repeat the encryption of deals by the vss package
+func dealAEAD(t *testing.T, pre, dealerPub kyber.Point, verifiers []kyber.Point) cipher.AEAD {
+	preBuf, err := pre.MarshalBinary()
+	assert.NoError(t, err)
+	key := make([]byte, 32)
+	_, err = hkdf.New(suite.Hash, preBuf, nil, dealContext(dealerPub, verifiers)).Read(key)
+	assert.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	return gcm
+}
+
+func dealContext(dealerPub kyber.Point, verifiers []kyber.Point) []byte {
+	h := suite.Hash()
+	_, _ = h.Write([]byte("vss-dealer"))
+	_, _ = dealerPub.MarshalTo(h)
+	_, _ = h.Write([]byte("vss-verifiers"))
+	for _, v := range verifiers {
+		_, _ = v.MarshalTo(h)
+	}
+	return h.Sum(nil)
+}
+
+// faultyDealer makes the dealer send the wrong share to the receiver
+func faultyDealer(t *testing.T, tnet *testNetwork, dealer, receiver int, dropJustification bool) {
+	tnet.setTamper(func(from, to int, msg *peering.PeerMessage) *peering.PeerMessage {
+		if from != dealer {
+			return msg
+		}
+		switch {
+		case msg.MsgType == MsgDeal && to == receiver:
+			return badDeal(t, tnet.nodes[dealer].session(), tnet.nodes[receiver].session(), msg)
+		case msg.MsgType == MsgJustification && dropJustification:
+			return nil
+		}
+		return msg
+	})
+}
+
+func TestDKGFaultyDealer(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+
+	// the receiver complains about the wrong share. The dealer justifies the complaint by revealing
+	// the right share, so the deal is approved and the receiver uses the revealed share
+	faultyDealer(t, tnet, 1, 2, false)
+
+	addr, err := tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 3, 4*time.Second)
+	assert.NoError(t, err)
+	tnet.waitClosed(t)
+	if !assert.NotNil(t, addr) {
+		return
+	}
+	checkKeySet(t, tnet.nodes, addr)
+}
+
+func TestDKGUnjustifiedComplaint(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+
+	// the justification of the dealer is lost. Other participants exclude its deal after the timeout,
+	// while the dealer keeps it. Public polynomials differ, so no node saves the key set
+	faultyDealer(t, tnet, 1, 2, true)
+
+	_, err := tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 3, 4*time.Second)
+	assert.Error(t, err)
+	tnet.waitClosed(t)
+	for _, tn := range tnet.nodes {
+		assert.Len(t, tn.savedDKShares(), 0)
+	}
+}
+
+func TestDKGDifferentPubPoly(t *testing.T) {
+	tnet := newTestNetwork(4)
+	defer tnet.close()
+
+	// one node reports the different public polynomial to others. Honest nodes don't save the key set
+	tnet.setTamper(func(from, to int, msg *peering.PeerMessage) *peering.PeerMessage {
+		if from != 3 || msg.MsgType != MsgResult {
+			return msg
+		}
+		res := &ResultMsg{}
+		assert.NoError(t, res.Read(bytes.NewReader(msg.MsgData)))
+		res.PubPolyHash[0] ^= 0xFF
+		msg.MsgData = util.MustBytes(res)
+		return msg
+	})
+	_, err := tnet.nodes[0].node.runDKG(tnet.locations(), tnet.pubKeys(), 3, 4*time.Second)
+	assert.Error(t, err)
+	tnet.waitClosed(t)
+	for _, tn := range tnet.nodes[:3] {
+		assert.Len(t, tn.savedDKShares(), 0)
+	}
+}
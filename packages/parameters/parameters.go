@@ -1,6 +1,8 @@
 package parameters
 
 import (
+	"time"
+
 	"github.com/iotaledger/wasp/plugins/config"
 	flag "github.com/spf13/pflag"
 )
@@ -19,10 +21,29 @@ const (
 	WebAPIBindAddress    = "webapi.bindAddress"
 	WebAPIAdminWhitelist = "webapi.adminWhitelist"
 	WebAPIAuth           = "webapi.auth"
+	WebAPITokenAuth      = "webapi.tokenAuth"
+	WebAPITokenSecret    = "webapi.tokenSecretFile"
+
+	WebAPITLSCertFile     = "webapi.tlsCertFile"
+	WebAPITLSKeyFile      = "webapi.tlsKeyFile"
+	WebAPITLSClientCAFile = "webapi.tlsClientCAFile"
+
+	WebAPIRateLimit        = "webapi.rateLimit"
+	WebAPIRateBurst        = "webapi.rateBurst"
+	WebAPIMaxBodySize      = "webapi.maxBodySize"
+	WebAPIMaxQueryKeys     = "webapi.maxQueryKeys"
+	WebAPIMaxQueryElements = "webapi.maxQueryElements"
+	WebAPIQueryTimeout     = "webapi.queryTimeout"
+
+	WebAPIMaxStreams          = "webapi.maxStreams"
+	WebAPIMaxStreamsPerClient = "webapi.maxStreamsPerClient"
+	WebAPIStreamOrigins       = "webapi.streamOrigins"
 
 	DashboardBindAddress       = "dashboard.bindAddress"
 	DashboardExploreAddressUrl = "dashboard.exploreAddressUrl"
 	DashboardAuth              = "dashboard.auth"
+	DashboardTLSCertFile       = "dashboard.tlsCertFile"
+	DashboardTLSKeyFile        = "dashboard.tlsKeyFile"
 
 	VMBinaryDir     = "vm.binaries"
 	VMDefaultVmType = "vm.defaultvm"
@@ -32,7 +53,20 @@ const (
 	PeeringMyNetId = "peering.netid"
 	PeeringPort    = "peering.port"
 
+	PeeringMaxQueueSize         = "peering.maxQueueSize"
+	PeeringCompressionThreshold = "peering.compressionThreshold"
+	PeeringClockOffsetWarning   = "peering.clockOffsetWarning"
+	PeeringAllowedPeers         = "peering.allowedPeers"
+
+	SignerSocket = "signer.socket"
+
 	NanomsgPublisherPort = "nanomsg.port"
+
+	ConsensusMaxBatchSize   = "consensus.maxBatchSize"
+	ConsensusMaxBatchVMTime = "consensus.maxBatchVMTime"
+	ConsensusPipelining     = "consensus.pipelining"
+
+	ConsensusTimestampTolerance = "consensus.timestampTolerance"
 )
 
 func InitFlags() {
@@ -49,10 +83,26 @@ func InitFlags() {
 	flag.String(WebAPIBindAddress, "127.0.0.1:8080", "the bind address for the web API")
 	flag.StringSlice(WebAPIAdminWhitelist, []string{}, "IP whitelist for /adm wndpoints")
 	flag.StringToString(WebAPIAuth, nil, "authentication scheme for web API")
+	flag.Bool(WebAPITokenAuth, false, "require API tokens with roles for web API endpoints")
+	flag.String(WebAPITokenSecret, "apitoken.key", "file with the secret which signs API tokens. Generated if not exists")
+	flag.String(WebAPITLSCertFile, "", "PEM file with the TLS certificate of the web API. Plain HTTP if empty")
+	flag.String(WebAPITLSKeyFile, "", "PEM file with the private key of the TLS certificate of the web API")
+	flag.String(WebAPITLSClientCAFile, "", "PEM file with CAs of client certificates required by admin endpoints. Not required if empty")
+	flag.Int(WebAPIRateLimit, 20, "requests per second from one client to public endpoints. 0 means no limit")
+	flag.Int(WebAPIRateBurst, 50, "maximum burst of requests from one client to public endpoints")
+	flag.Int(WebAPIMaxBodySize, 1024*1024, "maximum size in bytes of the body of requests to public endpoints. 0 means no limit")
+	flag.Int(WebAPIMaxQueryKeys, 100, "maximum number of keys or fields in one state query. 0 means no limit")
+	flag.Int(WebAPIMaxQueryElements, 10000, "maximum total number of elements of collections in one state query. 0 means no limit")
+	flag.Duration(WebAPIQueryTimeout, 5*time.Second, "state queries not completed in time are aborted. 0 means no timeout")
+	flag.Int(WebAPIMaxStreams, 1000, "maximum number of open request streams. 0 means no limit")
+	flag.Int(WebAPIMaxStreamsPerClient, 10, "maximum number of open request streams of one client. 0 means no limit")
+	flag.StringSlice(WebAPIStreamOrigins, []string{}, "origins of web pages, other than the web API itself, allowed to open request streams")
 
 	flag.String(DashboardBindAddress, "127.0.0.1:7000", "the bind address for the node dashboard")
 	flag.String(DashboardExploreAddressUrl, "", "URL to add as href to addresses in the dashboard [default: <nodeconn.address>:8081/explorer/address]")
 	flag.StringToString(DashboardAuth, nil, "authentication scheme for the node dashboard")
+	flag.String(DashboardTLSCertFile, "", "PEM file with the TLS certificate of the dashboard. Plain HTTP if empty")
+	flag.String(DashboardTLSKeyFile, "", "PEM file with the private key of the TLS certificate of the dashboard")
 
 	flag.String(VMBinaryDir, "wasm", "path where Wasm binaries are located (using file:// schema")
 	flag.String(VMDefaultVmType, "dummmy", "default VM type")
@@ -61,8 +111,19 @@ func InitFlags() {
 
 	flag.Int(PeeringPort, 4000, "port for Wasp committee connection/peering")
 	flag.String(PeeringMyNetId, "127.0.0.1:4000", "node host address as it is recognized by other peers")
+	flag.Int(PeeringMaxQueueSize, 32*1024*1024, "maximum size in bytes of outgoing messages queued for one peer")
+	flag.Int(PeeringCompressionThreshold, 0, "messages larger than that are compressed. 0 means no compression")
+	flag.Duration(PeeringClockOffsetWarning, 1*time.Second, "clock offset of the committee peer which is reported as warning")
+	flag.StringSlice(PeeringAllowedPeers, []string{}, "public keys (base58) of nodes which are accepted as peers and DKG participants in addition to the address book")
+
+	flag.String(SignerSocket, "", "unix socket of the remote signer. Keys are kept in the node if empty")
 
 	flag.Int(NanomsgPublisherPort, 5550, "the port for nanomsg even publisher")
+
+	flag.Int(ConsensusMaxBatchSize, 100, "maximum number of requests in one batch")
+	flag.Duration(ConsensusMaxBatchVMTime, 5*time.Second, "estimated VM run time budget for one batch")
+	flag.Bool(ConsensusPipelining, false, "whether the leader starts calculating next batch on top of the unconfirmed state")
+	flag.Duration(ConsensusTimestampTolerance, 3*time.Second, "maximum difference between batch timestamp and local clock accepted by the node")
 }
 
 func GetBool(name string) bool {
@@ -81,6 +142,10 @@ func GetInt(name string) int {
 	return config.Node.GetInt(name)
 }
 
+func GetDuration(name string) time.Duration {
+	return config.Node.GetDuration(name)
+}
+
 func GetStringToString(name string) map[string]string {
 	return config.Node.GetStringMapString(name)
 }
@@ -11,8 +11,13 @@ import (
 	"github.com/iotaledger/wasp/plugins/publisher"
 	"github.com/mr-tron/base58"
 	"io"
+	"sync"
 )
 
+// bootup data is saved under the mutex, so checks which smart contracts use the program stay valid
+// while the program is deleted or its metadata is changed, see WithBootupDataLocked
+var bootupMutex sync.Mutex
+
 // BootupData is a minimum data needed to load a committee for the smart contract
 // it is up to the node (not smart contract) to check authorisations to create/update this record
 type BootupData struct {
@@ -21,7 +26,11 @@ type BootupData struct {
 	Color          balance.Color   // origin tx hash
 	CommitteeNodes []string        // "host_addr:port"
 	AccessNodes    []string        // "host_addr:port"
-	Active         bool
+	// base58 encoded identity public keys of nodes, same order as network locations.
+	// The node is member of the committee or access node by its identity, not by the network location
+	CommitteePubKeys []string
+	AccessPubKeys    []string
+	Active           bool
 }
 
 func dbkeyBootupData(addr *address.Address) []byte {
@@ -36,17 +45,31 @@ func SaveBootupData(bd *BootupData) error {
 	if bd.Color == balance.ColorNew || bd.Color == balance.ColorIOTA {
 		return fmt.Errorf("can't be IOTA or New color")
 	}
+	if len(bd.CommitteePubKeys) != len(bd.CommitteeNodes) || len(bd.AccessPubKeys) != len(bd.AccessNodes) {
+		return fmt.Errorf("identity public key must be specified for each committee and access node")
+	}
 	var buf bytes.Buffer
 	if err := bd.Write(&buf); err != nil {
 		return err
 	}
-	if err := database.GetRegistryPartition().Set(dbkeyBootupData(&bd.Address), buf.Bytes()); err != nil {
+	bootupMutex.Lock()
+	err := database.GetRegistryPartition().Set(dbkeyBootupData(&bd.Address), buf.Bytes())
+	bootupMutex.Unlock()
+	if err != nil {
 		return err
 	}
 	publisher.Publish("bootuprec", bd.Address.String(), bd.Color.String())
 	return nil
 }
 
+// WithBootupDataLocked runs f while bootup data can't be saved, i.e. no smart contract is added to the node
+// until f returns. f must not save bootup data
+func WithBootupDataLocked(f func() error) error {
+	bootupMutex.Lock()
+	defer bootupMutex.Unlock()
+	return f()
+}
+
 func GetBootupData(addr *address.Address) (*BootupData, error) {
 	data, err := database.GetRegistryPartition().Get(dbkeyBootupData(addr))
 	if err == kvstore.ErrKeyNotFound {
@@ -131,6 +154,12 @@ func (bd *BootupData) Write(w io.Writer) error {
 	if err := util.WriteStrings16(w, bd.AccessNodes); err != nil {
 		return err
 	}
+	if err := util.WriteStrings16(w, bd.CommitteePubKeys); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, bd.AccessPubKeys); err != nil {
+		return err
+	}
 	if err := util.WriteBoolByte(w, bd.Active); err != nil {
 		return err
 	}
@@ -154,6 +183,12 @@ func (bd *BootupData) Read(r io.Reader) error {
 	if bd.AccessNodes, err = util.ReadStrings16(r); err != nil {
 		return err
 	}
+	if bd.CommitteePubKeys, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if bd.AccessPubKeys, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
 	if err = util.ReadBoolByte(r, &bd.Active); err != nil {
 		return err
 	}
@@ -166,5 +201,7 @@ func (bd *BootupData) String() string {
 	ret += "      Owner address: " + bd.OwnerAddress.String() + "\n"
 	ret += fmt.Sprintf("      Committee nodes: %+v\n", bd.CommitteeNodes)
 	ret += fmt.Sprintf("      Access nodes: %+v\n", bd.AccessNodes)
+	ret += fmt.Sprintf("      Committee identities: %+v\n", bd.CommitteePubKeys)
+	ret += fmt.Sprintf("      Access identities: %+v\n", bd.AccessPubKeys)
 	return ret
 }
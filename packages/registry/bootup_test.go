@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBootupDataLocked(t *testing.T) {
+	database.InitMemDB()
+	bd := &BootupData{
+		Address: address.Random(),
+		Color:   balance.Color(*hashing.RandomHash(nil)),
+	}
+
+	saved := make(chan struct{})
+	err := WithBootupDataLocked(func() error {
+		go func() {
+			assert.NoError(t, SaveBootupData(bd))
+			close(saved)
+		}()
+		select {
+		case <-saved:
+			t.Errorf("bootup data saved while locked")
+		case <-time.After(100 * time.Millisecond):
+		}
+		rec, err := GetBootupData(&bd.Address)
+		assert.NoError(t, err)
+		assert.Nil(t, rec)
+		return nil
+	})
+	assert.NoError(t, err)
+	<-saved
+
+	rec, err := GetBootupData(&bd.Address)
+	assert.NoError(t, err)
+	assert.NotNil(t, rec)
+}
@@ -6,16 +6,8 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/plugins/database"
-	"go.dedis.ch/kyber/v3"
 )
 
-func CommitDKShare(ks *tcrypto.DKShare, pubKeys []kyber.Point) error {
-	if err := ks.FinalizeDKS(pubKeys); err != nil {
-		return err
-	}
-	return SaveDKShareToRegistry(ks)
-}
-
 func dbkey(addr *address.Address) []byte {
 	return database.MakeKey(database.ObjectTypeDistributedKeyData, addr.Bytes())
 }
@@ -33,13 +25,23 @@ func SaveDKShareToRegistry(ks *tcrypto.DKShare) error {
 		return fmt.Errorf("attempt to overwrite existing DK key share")
 	}
 
-	var buf bytes.Buffer
+	return putDKShare(ks)
+}
 
-	err = ks.Write(&buf)
-	if err != nil {
+// putDKShare saves the key share. With the remote signer, the private key goes to the signer
+// and only the public part is saved
+func putDKShare(ks *tcrypto.DKShare) error {
+	if keySigner != nil && ks.HasPrivateKey() {
+		if err := importToSigner(ks); err != nil {
+			return err
+		}
+		ks = ks.PublicPart()
+	}
+	var buf bytes.Buffer
+	if err := ks.Write(&buf); err != nil {
 		return err
 	}
-	return dbase.Set(dbkey(ks.Address), buf.Bytes())
+	return putSecret(dbkey(ks.Address), buf.Bytes())
 }
 
 func GetDKShare(addr *address.Address) (*tcrypto.DKShare, bool, error) {
@@ -56,7 +58,7 @@ func GetDKShare(addr *address.Address) (*tcrypto.DKShare, bool, error) {
 }
 
 func LoadDKShare(addr *address.Address, maskPrivate bool) (*tcrypto.DKShare, error) {
-	data, err := database.GetRegistryPartition().Get(dbkey(addr))
+	data, err := getSecret(dbkey(addr))
 	if err != nil {
 		return nil, err
 	}
@@ -66,3 +68,16 @@ func LoadDKShare(addr *address.Address, maskPrivate bool) (*tcrypto.DKShare, err
 	}
 	return ret, nil
 }
+
+// ReplaceDKShare saves the reshared key share in place of the old one. The address remains the same
+func ReplaceDKShare(ks *tcrypto.DKShare) error {
+	if !ks.Committed {
+		return fmt.Errorf("uncommited DK share: can't be saved to the registry")
+	}
+	return putDKShare(ks)
+}
+
+// DeleteDKShare removes the key share from the registry
+func DeleteDKShare(addr *address.Address) error {
+	return database.GetRegistryPartition().Delete(dbkey(addr))
+}
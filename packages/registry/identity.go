@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/database"
+)
+
+func dbkeyNodeIdentity() []byte {
+	return database.MakeKey(database.ObjectTypeNodeIdentity)
+}
+
+// LoadOrCreateNodeIdentity returns the Ed25519 private key which identifies the node among its peers.
+// The key is generated and saved to the registry upon the first start of the node
+func LoadOrCreateNodeIdentity() (ed25519.PrivateKey, error) {
+	data, err := getSecret(dbkeyNodeIdentity())
+	if err == nil {
+		if len(data) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("corrupted node identity key in the registry")
+		}
+		return data, nil
+	}
+	if err != kvstore.ErrKeyNotFound {
+		return nil, err
+	}
+	_, priKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := putSecret(dbkeyNodeIdentity(), priKey); err != nil {
+		return nil, err
+	}
+	return priKey, nil
+}
+
+// keySigner is the remote signer which keeps private keys of committees, if the node uses one.
+// The registry keeps only public parts of keys then
+var keySigner tcrypto.Signer
+
+// KeepKeysInSigner makes the registry hand private keys of committees over to the remote signer when they are saved
+func KeepKeysInSigner(signer tcrypto.Signer) {
+	keySigner = signer
+}
+
+// importToSigner hands private keys over to the remote signer, sealed for its identity.
+// The registry decides whether keys may be replaced, so the signer replaces keys of the same address
+func importToSigner(ks tcrypto.CommitteeKeys) error {
+	pubKey, err := keySigner.IdentityPubKey()
+	if err != nil {
+		return err
+	}
+	data, err := tcrypto.MarshalCommitteeKeys(ks)
+	if err != nil {
+		return err
+	}
+	sealed, err := tcrypto.SealForIdentity(pubKey, data)
+	if err != nil {
+		return err
+	}
+	_, err = keySigner.ImportKeys(sealed, true)
+	return err
+}
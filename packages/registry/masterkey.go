@@ -0,0 +1,356 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/config"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Secrets in the registry (key shares and the node identity) are sealed with the master key of the node,
+// so the copy of the database doesn't reveal them.
+// The master key is derived from the passphrase, if it is configured. Otherwise it is read from the key file,
+// which is generated upon the first start. The key file must be kept outside of the database directory
+const (
+	CfgMasterKeyFile       = "registry.masterKeyFile"
+	CfgMasterKeyPassphrase = "registry.masterKeyPassphrase"
+)
+
+const (
+	masterKeySize = chacha20poly1305.KeySize
+	saltSize      = 16
+	// first byte of sealed records. Plaintext key shares start with the address version byte
+	sealedRecordMarker = byte(0xff)
+	masterKeyCheckText = "wasp master key check"
+)
+
+var (
+	masterKey      []byte
+	masterKeyOnce  sync.Once
+	masterKeyErr   error
+	masterKeyMutex = &sync.RWMutex{}
+)
+
+func dbkeyMasterKeyCheck() []byte {
+	return database.MakeKey(database.ObjectTypeMasterKeyCheck)
+}
+
+// InitMasterKey loads the master key from the configuration and checks it against the registry.
+// Secrets saved in plaintext by previous versions are sealed with the key.
+// Called upon the first access to secrets, if not called before
+func InitMasterKey() error {
+	masterKeyOnce.Do(func() {
+		masterKeyMutex.Lock()
+		defer masterKeyMutex.Unlock()
+		masterKey, masterKeyErr = loadMasterKey()
+	})
+	return masterKeyErr
+}
+
+// masterKeyCheck is stored in the registry to detect wrong passphrase or key file at startup
+type masterKeyCheck struct {
+	salt   []byte
+	sealed []byte
+}
+
+func loadMasterKey() ([]byte, error) {
+	dbase := database.GetRegistryPartition()
+	check, err := readMasterKeyCheck()
+	if err != nil {
+		return nil, err
+	}
+	if check == nil {
+		// first start or database of the previous version
+		check = &masterKeyCheck{salt: make([]byte, saltSize)}
+		if _, err := rand.Read(check.salt); err != nil {
+			return nil, err
+		}
+		key, err := masterKeyFromConfig(check.salt)
+		if err != nil {
+			return nil, err
+		}
+		if err := sealPlaintextRecords(key); err != nil {
+			return nil, err
+		}
+		check.sealed = sealRecord(key, dbkeyMasterKeyCheck(), []byte(masterKeyCheckText))
+		if err := dbase.Set(dbkeyMasterKeyCheck(), util.MustBytes(check)); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	key, err := masterKeyFromConfig(check.salt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := openRecord(key, dbkeyMasterKeyCheck(), check.sealed)
+	if err != nil || string(data) != masterKeyCheckText {
+		return nil, fmt.Errorf("wrong master key. Check '%s' or '%s' parameters", CfgMasterKeyPassphrase, CfgMasterKeyFile)
+	}
+	return key, nil
+}
+
+func masterKeyFromConfig(salt []byte) ([]byte, error) {
+	if passphrase := config.Node.GetString(CfgMasterKeyPassphrase); passphrase != "" {
+		return keyFromPassphrase(passphrase, salt)
+	}
+	fname := config.Node.GetString(CfgMasterKeyFile)
+	if fname == "" {
+		return nil, fmt.Errorf("master key is not configured. Set '%s' or '%s' parameter", CfgMasterKeyPassphrase, CfgMasterKeyFile)
+	}
+	key, err := readKeyFile(fname)
+	if os.IsNotExist(err) {
+		if key, err = createKeyFile(fname); err == nil {
+			log.Infof("generated new master key file %s", fname)
+		}
+	}
+	return key, err
+}
+
+func keyFromPassphrase(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, masterKeySize)
+}
+
+// key file contains base58 encoded master key
+func readKeyFile(fname string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base58.Decode(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != masterKeySize {
+		return nil, fmt.Errorf("wrong master key file %s", fname)
+	}
+	return key, nil
+}
+
+func createKeyFile(fname string) ([]byte, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(base58.Encode(key) + "\n"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func readMasterKeyCheck() (*masterKeyCheck, error) {
+	data, err := database.GetRegistryPartition().Get(dbkeyMasterKeyCheck())
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := &masterKeyCheck{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (c *masterKeyCheck) Write(w io.Writer) error {
+	if err := util.WriteBytes16(w, c.salt); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, c.sealed)
+}
+
+func (c *masterKeyCheck) Read(r io.Reader) error {
+	var err error
+	if c.salt, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	c.sealed, err = util.ReadBytes16(r)
+	return err
+}
+
+// sealRecord encrypts the value of the registry record. The key of the record is authenticated too,
+// so sealed values can't be swapped between records
+func sealRecord(key, dbkey, data []byte) []byte {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		panic(err)
+	}
+	ret := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(data)+aead.Overhead())
+	ret[0] = sealedRecordMarker
+	if _, err := rand.Read(ret[1:]); err != nil {
+		panic(err)
+	}
+	return aead.Seal(ret, ret[1:], data, dbkey)
+}
+
+func openRecord(key, dbkey, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1+aead.NonceSize()+aead.Overhead() || data[0] != sealedRecordMarker {
+		return nil, errors.New("record is not sealed")
+	}
+	ret, err := aead.Open(nil, data[1:1+aead.NonceSize()], data[1+aead.NonceSize():], dbkey)
+	if err != nil {
+		return nil, errors.New("can't open sealed record: wrong master key or corrupted data")
+	}
+	return ret, nil
+}
+
+// isPlaintextRecord recognizes records written by previous versions of the node
+func isPlaintextRecord(dbkey, data []byte) bool {
+	if dbkey[0] == database.ObjectTypeNodeIdentity {
+		return len(data) == ed25519.PrivateKeySize
+	}
+	return len(data) > 0 && data[0] != sealedRecordMarker
+}
+
+// putSecret seals the value with the master key and saves it to the registry
+func putSecret(dbkey, data []byte) error {
+	if err := InitMasterKey(); err != nil {
+		return err
+	}
+	masterKeyMutex.RLock()
+	defer masterKeyMutex.RUnlock()
+
+	return database.GetRegistryPartition().Set(dbkey, sealRecord(masterKey, dbkey, data))
+}
+
+// getSecret reads the value from the registry and opens it with the master key
+func getSecret(dbkey []byte) ([]byte, error) {
+	if err := InitMasterKey(); err != nil {
+		return nil, err
+	}
+	masterKeyMutex.RLock()
+	defer masterKeyMutex.RUnlock()
+
+	data, err := database.GetRegistryPartition().Get(dbkey)
+	if err != nil {
+		return nil, err
+	}
+	return openRecord(masterKey, dbkey, data)
+}
+
+var secretPrefixes = []byte{
+	database.ObjectTypeDistributedKeyData,
+	database.ObjectTypeNodeIdentity,
+	database.ObjectTypeMultiSigKeys,
+	database.ObjectTypeMultiSigMemberKey,
+}
+
+// iterateSecrets calls the function for each record with the secret value
+func iterateSecrets(f func(dbkey, data []byte) error) error {
+	dbase := database.GetRegistryPartition()
+	var retErr error
+	for _, prefix := range secretPrefixes {
+		err := dbase.Iterate([]byte{prefix}, func(key kvstore.Key, value kvstore.Value) bool {
+			retErr = f(append([]byte{}, key...), append([]byte{}, value...))
+			return retErr == nil
+		})
+		if err != nil {
+			return err
+		}
+		if retErr != nil {
+			return retErr
+		}
+	}
+	return nil
+}
+
+func sealPlaintextRecords(key []byte) error {
+	batch := database.GetRegistryPartition().Batched()
+	num := 0
+	err := iterateSecrets(func(dbkey, data []byte) error {
+		if !isPlaintextRecord(dbkey, data) {
+			return nil
+		}
+		num++
+		return batch.Set(dbkey, sealRecord(key, dbkey, data))
+	})
+	if err != nil {
+		batch.Cancel()
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	if num > 0 {
+		log.Infof("sealed %d plaintext records in the registry with the master key", num)
+	}
+	return nil
+}
+
+// RotateMasterKey re-encrypts all secrets in the registry with the new master key.
+// The new key is derived from the passphrase or, if the passphrase is empty, generated and written
+// to the new key file. The node configuration must be updated before the next start of the node
+func RotateMasterKey(passphrase string, keyFile string) error {
+	if (passphrase == "") == (keyFile == "") {
+		return errors.New("either new passphrase or new key file must be specified")
+	}
+	if err := InitMasterKey(); err != nil {
+		return err
+	}
+	masterKeyMutex.Lock()
+	defer masterKeyMutex.Unlock()
+
+	check := &masterKeyCheck{salt: make([]byte, saltSize)}
+	if _, err := rand.Read(check.salt); err != nil {
+		return err
+	}
+	var newKey []byte
+	var err error
+	if passphrase != "" {
+		newKey, err = keyFromPassphrase(passphrase, check.salt)
+	} else {
+		newKey, err = createKeyFile(keyFile)
+	}
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() {
+		if !success && keyFile != "" {
+			_ = os.Remove(keyFile)
+		}
+	}()
+	batch := database.GetRegistryPartition().Batched()
+	err = iterateSecrets(func(dbkey, data []byte) error {
+		plain, err := openRecord(masterKey, dbkey, data)
+		if err != nil {
+			return err
+		}
+		return batch.Set(dbkey, sealRecord(newKey, dbkey, plain))
+	})
+	if err == nil {
+		check.sealed = sealRecord(newKey, dbkeyMasterKeyCheck(), []byte(masterKeyCheckText))
+		err = batch.Set(dbkeyMasterKeyCheck(), util.MustBytes(check))
+	}
+	if err != nil {
+		batch.Cancel()
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	success = true
+	masterKey = newKey
+	log.Infof("master key was rotated")
+	return nil
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealRecord(t *testing.T) {
+	key := make([]byte, masterKeySize)
+	_, _ = rand.Read(key)
+	dbkey := database.MakeKey(database.ObjectTypeDistributedKeyData, []byte("addr"))
+	data := []byte("private key share")
+
+	sealed := sealRecord(key, dbkey, data)
+	assert.False(t, isPlaintextRecord(dbkey, sealed))
+	assert.True(t, isPlaintextRecord(dbkey, data))
+
+	opened, err := openRecord(key, dbkey, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, data, opened)
+
+	// record can't be moved under another key
+	_, err = openRecord(key, database.MakeKey(database.ObjectTypeDistributedKeyData, []byte("other")), sealed)
+	assert.Error(t, err)
+
+	wrongKey := make([]byte, masterKeySize)
+	_, _ = rand.Read(wrongKey)
+	_, err = openRecord(wrongKey, dbkey, sealed)
+	assert.Error(t, err)
+
+	_, err = openRecord(key, dbkey, data)
+	assert.Error(t, err)
+}
+
+func TestPlaintextIdentity(t *testing.T) {
+	_, pri, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	key := make([]byte, masterKeySize)
+	_, _ = rand.Read(key)
+
+	assert.True(t, isPlaintextRecord(dbkeyNodeIdentity(), pri))
+	assert.False(t, isPlaintextRecord(dbkeyNodeIdentity(), sealRecord(key, dbkeyNodeIdentity(), pri)))
+}
+
+func TestKeyFromPassphrase(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	k1, err := keyFromPassphrase("passphrase", salt)
+	assert.NoError(t, err)
+	k2, err := keyFromPassphrase("passphrase", salt)
+	assert.NoError(t, err)
+	assert.Equal(t, k1, k2)
+	k3, err := keyFromPassphrase("passphrase", []byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, k1, k3)
+}
@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/database"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+)
+
+// Keys of multi-signature committees are created in three steps of the DKG driven by the admin (see tcrypto.MultiSigKeys).
+// First each member generates its own key, which is kept in the registry until the committee is formed.
+// Then each member deals shares of its key to all members. Then all members form the committee from public keys
+// of all members and deals
+
+func dbkeyMultiSigKeys(addr *address.Address) []byte {
+	return database.MakeKey(database.ObjectTypeMultiSigKeys, addr.Bytes())
+}
+
+func dbkeyMultiSigMemberKey(pubKeyBin []byte) []byte {
+	return database.MakeKey(database.ObjectTypeMultiSigMemberKey, pubKeyBin)
+}
+
+// NewMultiSigMemberKey generates the key of the member of the new multi-signature committee and saves it.
+// Returns the public key and the public key to seal shares dealt to the member for (see tcrypto.MultiSigSealKey)
+func NewMultiSigMemberKey() (kyber.Point, ed25519.PublicKey, error) {
+	priKey, pubKey := tcrypto.NewMultiSigMemberKey()
+	sealKey, err := tcrypto.MultiSigSealKey(priKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKeyBin, err := pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	priKeyBin, err := priKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := putSecret(dbkeyMultiSigMemberKey(pubKeyBin), priKeyBin); err != nil {
+		return nil, nil, err
+	}
+	return pubKey, sealKey.Public().(ed25519.PublicKey), nil
+}
+
+// DealMultiSig shares the key of the member among members of the committee with quorum t.
+// One of public keys must be the key generated by the node with NewMultiSigMemberKey
+func DealMultiSig(pubKeys []kyber.Point, t uint16) (*tcrypto.MultiSigDeal, error) {
+	priKey, _, err := getMultiSigMemberKey(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	return tcrypto.NewMultiSigDeal(pubKeys, t, priKey)
+}
+
+// CommitMultiSigKeys forms the multi-signature committee with quorum t from public keys of all members,
+// commitments of their deals and shares dealt to the node, in the order of members.
+// Shares are sealed for the seal key of the member. One of public keys must be the key generated by the node
+// with NewMultiSigMemberKey
+func CommitMultiSigKeys(pubKeys []kyber.Point, t uint16, commits [][]kyber.Point, sealedShares [][]byte) (*tcrypto.MultiSigKeys, error) {
+	priKey, dbkey, err := getMultiSigMemberKey(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	sealKey, err := tcrypto.MultiSigSealKey(priKey)
+	if err != nil {
+		return nil, err
+	}
+	suite := bn256.NewSuite()
+	shares := make([]kyber.Scalar, len(sealedShares))
+	for i, sealed := range sealedShares {
+		data, err := tcrypto.OpenSealed(sealKey, sealed)
+		if err != nil {
+			return nil, fmt.Errorf("can't open share of member #%d: %v", i, err)
+		}
+		shares[i] = suite.G2().Scalar()
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("invalid share of member #%d: %v", i, err)
+		}
+	}
+	ks, err := tcrypto.NewMultiSigKeys(pubKeys, t, priKey, commits, shares)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveMultiSigKeys(ks); err != nil {
+		return nil, err
+	}
+	if err := database.GetRegistryPartition().Delete(dbkey); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// getMultiSigMemberKey returns the private key of the member generated by the node and its database key
+func getMultiSigMemberKey(pubKeys []kyber.Point) (kyber.Scalar, []byte, error) {
+	suite := bn256.NewSuite()
+	for _, pk := range pubKeys {
+		pubKeyBin, err := pk.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		dbkey := dbkeyMultiSigMemberKey(pubKeyBin)
+		priKeyBin, err := getSecret(dbkey)
+		if err == kvstore.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		priKey := suite.G2().Scalar()
+		if err := priKey.UnmarshalBinary(priKeyBin); err != nil {
+			return nil, nil, err
+		}
+		return priKey, dbkey, nil
+	}
+	return nil, nil, fmt.Errorf("none of public keys was generated by the node")
+}
+
+func saveMultiSigKeys(ks *tcrypto.MultiSigKeys) error {
+	dbkey := dbkeyMultiSigKeys(ks.Address)
+	exists, err := database.GetRegistryPartition().Has(dbkey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("attempt to overwrite existing multi-signature keys")
+	}
+	if keySigner != nil {
+		// the private share goes to the remote signer
+		if err := importToSigner(ks); err != nil {
+			return err
+		}
+		ks = ks.PublicPart()
+	}
+	var buf bytes.Buffer
+	if err := ks.Write(&buf); err != nil {
+		return err
+	}
+	return putSecret(dbkey, buf.Bytes())
+}
+
+func GetMultiSigKeys(addr *address.Address) (*tcrypto.MultiSigKeys, bool, error) {
+	data, err := getSecret(dbkeyMultiSigKeys(addr))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	ret, err := tcrypto.UnmarshalMultiSigKeys(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return ret, true, nil
+}
+
+// GetCommitteeKeys returns keys of the committee member of any scheme
+func GetCommitteeKeys(addr *address.Address) (tcrypto.CommitteeKeys, bool, error) {
+	dks, exists, err := GetDKShare(addr)
+	if err != nil {
+		return nil, false, err
+	}
+	if exists {
+		return dks, true, nil
+	}
+	mks, exists, err := GetMultiSigKeys(addr)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	return mks, true, nil
+}
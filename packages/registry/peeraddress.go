@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/plugins/database"
+)
+
+// PeerAddress is an entry of the address book of the node: the network location where
+// the node with the identity can be dialed. It overrides locations listed in the bootup data
+type PeerAddress struct {
+	// base58 encoded identity public key
+	PubKey string `json:"pubKey"`
+	NetID  string `json:"netId"`
+}
+
+func dbkeyPeerAddress(pubKey string) []byte {
+	return database.MakeKey(database.ObjectTypePeerAddress, []byte(pubKey))
+}
+
+func SavePeerAddress(pa *PeerAddress) error {
+	if pa.PubKey == "" || pa.NetID == "" {
+		return fmt.Errorf("identity and network location of the peer must be specified")
+	}
+	return database.GetRegistryPartition().Set(dbkeyPeerAddress(pa.PubKey), []byte(pa.NetID))
+}
+
+// GetPeerAddress returns the network location of the peer from the address book
+func GetPeerAddress(pubKey string) (string, bool, error) {
+	data, err := database.GetRegistryPartition().Get(dbkeyPeerAddress(pubKey))
+	if err == kvstore.ErrKeyNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+func DeletePeerAddress(pubKey string) error {
+	return database.GetRegistryPartition().Delete(dbkeyPeerAddress(pubKey))
+}
+
+func GetPeerAddresses() ([]*PeerAddress, error) {
+	ret := make([]*PeerAddress, 0)
+	err := database.GetRegistryPartition().Iterate([]byte{database.ObjectTypePeerAddress}, func(key kvstore.Key, value kvstore.Value) bool {
+		ret = append(ret, &PeerAddress{
+			PubKey: string(key[1:]),
+			NetID:  string(value),
+		})
+		return true
+	})
+	return ret, err
+}
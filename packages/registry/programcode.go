@@ -3,6 +3,7 @@ package registry
 import (
 	"fmt"
 
+	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/plugins/database"
 	"github.com/iotaledger/wasp/plugins/publisher"
@@ -26,6 +27,19 @@ func GetProgramCode(progHash *hashing.HashValue) ([]byte, error) {
 	return data, nil
 }
 
+// GetProgramCodeHash returns the hash and the size of the program code as it is stored in the registry,
+// without checking it against the program hash. Returns nil if there is no code
+func GetProgramCodeHash(progHash *hashing.HashValue) (*hashing.HashValue, int, error) {
+	data, err := database.GetRegistryPartition().Get(dbkeyProgramCode(progHash))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return hashing.HashData(data), len(data), nil
+}
+
 func SaveProgramCode(programCode []byte) (ret hashing.HashValue, err error) {
 	progHash := hashing.HashData(programCode)
 	db := database.GetRegistryPartition()
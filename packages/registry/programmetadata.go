@@ -7,9 +7,11 @@ import (
 
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/stateschema"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/plugins/database"
 	"github.com/iotaledger/wasp/plugins/publisher"
+	"github.com/mr-tron/base58"
 )
 
 // each program is uniquely identified by the hash of its binary code
@@ -21,15 +23,19 @@ type ProgramMetadata struct {
 	VMType string
 	// description any text
 	Description string
+	// schema of the state of smart contracts running the program. nil if not provided
+	StateSchema *stateschema.Schema
 }
 
 var builtinPrograms = make(map[hashing.HashValue]*ProgramMetadata)
 
-func RegisterBuiltinProgramMetadata(progHash *hashing.HashValue, description string) {
+// RegisterBuiltinProgramMetadata registers metadata of the builtin program. The schema of the state may be nil
+func RegisterBuiltinProgramMetadata(progHash *hashing.HashValue, description string, schema *stateschema.Schema) {
 	builtinPrograms[*progHash] = &ProgramMetadata{
 		ProgramHash: *progHash,
 		VMType:      "builtin",
 		Description: description,
+		StateSchema: schema,
 	}
 }
 
@@ -57,6 +63,12 @@ func (md *ProgramMetadata) Save() error {
 	return nil
 }
 
+// IsBuiltinProgram returns true if the program is part of the node and has no code in the registry
+func IsBuiltinProgram(progHash *hashing.HashValue) bool {
+	_, ok := builtinPrograms[*progHash]
+	return ok
+}
+
 func GetProgramMetadata(progHash *hashing.HashValue) (*ProgramMetadata, error) {
 	md, ok := builtinPrograms[*progHash]
 	if ok {
@@ -80,6 +92,59 @@ func GetProgramMetadata(progHash *hashing.HashValue) (*ProgramMetadata, error) {
 	return ret, nil
 }
 
+// GetProgramMetadataList returns metadata of builtin programs and of programs saved in the registry
+func GetProgramMetadataList() ([]*ProgramMetadata, error) {
+	ret := make([]*ProgramMetadata, 0, len(builtinPrograms))
+	for _, md := range builtinPrograms {
+		ret = append(ret, md)
+	}
+	db := database.GetRegistryPartition()
+	err := db.Iterate([]byte{database.ObjectTypeProgramMetadata}, func(key kvstore.Key, value kvstore.Value) bool {
+		md := &ProgramMetadata{}
+		if len(key) != 1+hashing.HashSize || md.Read(bytes.NewReader(value)) != nil {
+			log.Warnf("corrupted program metadata record with key %s", base58.Encode(key))
+			return true
+		}
+		copy(md.ProgramHash[:], key[1:])
+		ret = append(ret, md)
+		return true
+	})
+	return ret, err
+}
+
+// DeleteProgram removes metadata, code and the pin of the program from the registry.
+// It is up to the caller to check if the program is still in use
+func DeleteProgram(progHash *hashing.HashValue) error {
+	if IsBuiltinProgram(progHash) {
+		return fmt.Errorf("Cannot delete builtin program %s", progHash.String())
+	}
+	db := database.GetRegistryPartition()
+	if err := db.Delete(dbkeyProgramMetadata(progHash)); err != nil {
+		return err
+	}
+	if err := db.Delete(dbkeyProgramCode(progHash)); err != nil {
+		return err
+	}
+	return db.Delete(dbkeyProgramPin(progHash))
+}
+
+func dbkeyProgramPin(progHash *hashing.HashValue) []byte {
+	return database.MakeKey(database.ObjectTypeProgramPin, progHash[:])
+}
+
+// PinProgram protects the program from deletion, even if no smart contract uses it
+func PinProgram(progHash *hashing.HashValue) error {
+	return database.GetRegistryPartition().Set(dbkeyProgramPin(progHash), []byte{1})
+}
+
+func UnpinProgram(progHash *hashing.HashValue) error {
+	return database.GetRegistryPartition().Delete(dbkeyProgramPin(progHash))
+}
+
+func IsProgramPinned(progHash *hashing.HashValue) (bool, error) {
+	return database.GetRegistryPartition().Has(dbkeyProgramPin(progHash))
+}
+
 func (md *ProgramMetadata) Write(w io.Writer) error {
 	if err := util.WriteString16(w, md.VMType); err != nil {
 		return err
@@ -87,6 +152,12 @@ func (md *ProgramMetadata) Write(w io.Writer) error {
 	if err := util.WriteString16(w, md.Description); err != nil {
 		return err
 	}
+	if err := util.WriteBoolByte(w, md.StateSchema != nil); err != nil {
+		return err
+	}
+	if md.StateSchema != nil {
+		return md.StateSchema.Write(w)
+	}
 	return nil
 }
 
@@ -98,5 +169,17 @@ func (md *ProgramMetadata) Read(r io.Reader) error {
 	if md.Description, err = util.ReadString16(r); err != nil {
 		return err
 	}
+	var hasSchema bool
+	if err = util.ReadBoolByte(r, &hasSchema); err != nil {
+		if err == io.EOF {
+			// saved before state schemas were introduced
+			return nil
+		}
+		return err
+	}
+	if hasSchema {
+		md.StateSchema = &stateschema.Schema{}
+		return md.StateSchema.Read(r)
+	}
 	return nil
 }
@@ -13,6 +13,8 @@ const (
 
 func InitFlags() {
 	flag.String(CfgRewardAddress, "", "reward address for this Wasp node. Empty (default) means no rewards are collected")
+	flag.String(CfgMasterKeyFile, "wasp.key", "file with the master key which seals secrets in the registry. Generated if it doesn't exist")
+	flag.String(CfgMasterKeyPassphrase, "", "passphrase to derive the master key from. Overrides the key file")
 }
 
 func GetRewardAddress(scaddr *address.Address) address.Address {
@@ -180,10 +180,10 @@ func (vs *virtualState) CommitToDb(b Batch) error {
 	keys := [][]byte{varStateDbkey, batchDbKey, solidStateKey}
 	values := [][]byte{varStateData, batchData, solidStateValue}
 
-	// store processed request IDs
+	// store processed request IDs with the index of the state they were processed in
 	for _, rid := range b.RequestIds() {
 		keys = append(keys, dbkeyRequest(rid))
-		values = append(values, []byte{0})
+		values = append(values, util.Uint32To4Bytes(b.StateIndex()))
 	}
 
 	// store uncommitted mutations
@@ -249,3 +249,23 @@ func dbkeyRequest(reqid *sctransaction.RequestId) []byte {
 func IsRequestCompleted(addr *address.Address, reqid *sctransaction.RequestId) (bool, error) {
 	return getSCPartition(addr).Has(dbkeyRequest(reqid))
 }
+
+// LoadRequestStateIndex returns index of the state which is the result of the batch with the request.
+// Returns false if the request is not processed or was processed before state indices were recorded
+func LoadRequestStateIndex(addr *address.Address, reqid *sctransaction.RequestId) (uint32, bool, error) {
+	return loadRequestStateIndex(getSCPartition(addr), reqid)
+}
+
+func loadRequestStateIndex(db kvstore.KVStore, reqid *sctransaction.RequestId) (uint32, bool, error) {
+	data, err := db.Get(dbkeyRequest(reqid))
+	if err == kvstore.ErrKeyNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) != 4 {
+		return 0, false, nil
+	}
+	return util.Uint32From4Bytes(data), true, nil
+}
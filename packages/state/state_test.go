@@ -201,6 +201,16 @@ func TestCommit(t *testing.T) {
 	v, _ = vs1_2.Variables().Get(kv.Key([]byte("x")))
 	assert.Equal(t, []byte{1}, v)
 
+	stateIndex, ok, err := loadRequestStateIndex(partition, &reqid1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, batch1.StateIndex(), stateIndex)
+
+	reqidUnknown := sctransaction.NewRequestId(txid1, 6)
+	_, ok, err = loadRequestStateIndex(partition, &reqidUnknown)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
 	txid2 := (transaction.ID)(*hashing.HashStrings("test string 2"))
 	reqid2 := sctransaction.NewRequestId(txid2, 6)
 	su2 := NewStateUpdate(&reqid2)
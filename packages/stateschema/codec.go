@@ -0,0 +1,108 @@
+package stateschema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Codec is the encoding of values in the state. Values are presented to clients in the text form:
+// base64 for raw bytes, base58 for addresses, hashes and colors
+type Codec string
+
+const (
+	CodecBytes   = Codec("bytes")
+	CodecString  = Codec("string")
+	CodecInt64   = Codec("int64")
+	CodecAddress = Codec("address")
+	CodecHash    = Codec("hash")
+	CodecColor   = Codec("color")
+)
+
+func (c Codec) valid() bool {
+	switch c {
+	case CodecBytes, CodecString, CodecInt64, CodecAddress, CodecHash, CodecColor:
+		return true
+	}
+	return false
+}
+
+// Decode decodes the value from the state to the JSON friendly form: int64 for CodecInt64, string otherwise.
+// Returns nil if the value doesn't exist
+func (c Codec) Decode(data []byte) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	switch c {
+	case CodecInt64:
+		return kv.DecodeInt64(data)
+	case CodecString:
+		return string(data), nil
+	case CodecAddress:
+		addr, _, err := address.FromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return addr.String(), nil
+	case CodecHash:
+		h, err := hashing.HashValueFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return h.String(), nil
+	case CodecColor:
+		col, err := util.ColorFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return col.String(), nil
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Text returns the value in the text form, the same as in the result of the query
+func (c Codec) Text(data []byte) (string, error) {
+	v, err := c.Decode(data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// Encode encodes the value in the text form to the bytes of the state
+func (c Codec) Encode(s string) ([]byte, error) {
+	switch c {
+	case CodecInt64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return util.Uint64To8Bytes(uint64(n)), nil
+	case CodecString:
+		return []byte(s), nil
+	case CodecAddress:
+		addr, err := address.FromBase58(s)
+		if err != nil {
+			return nil, err
+		}
+		return addr.Bytes(), nil
+	case CodecHash:
+		h, err := hashing.HashValueFromBase58(s)
+		if err != nil {
+			return nil, err
+		}
+		return h.Bytes(), nil
+	case CodecColor:
+		col, err := util.ColorFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return col.Bytes(), nil
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
@@ -0,0 +1,545 @@
+package stateschema
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/kv"
+)
+
+const (
+	// number of items returned for the collection if the limit is not specified
+	DefaultLimit = 20
+	// maximum number of items returned for the collection
+	MaxLimit = 1000
+	// maximum number of items of the array or the log scanned for one field of the query.
+	// If filters leave less than the limit, the result contains the offset to continue from
+	maxScan = 10 * MaxLimit
+)
+
+// SchemaField is the field of the query which returns the schema of the state
+const SchemaField = "__schema"
+
+var (
+	// ErrLimitExceeded is wrapped by errors of queries over Limits
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrTimeout is wrapped by errors of queries aborted at the deadline of Limits
+	ErrTimeout = errors.New("query timeout")
+)
+
+// Limits restrict the cost of the query. Zero values mean no limit
+type Limits struct {
+	// maximum number of fields
+	MaxFields int
+	// maximum total number of items of collections, counted by limits of fields
+	MaxItems int
+	Deadline time.Time
+}
+
+// budget of the running query
+type budget struct {
+	limits Limits
+	items  int
+}
+
+func (b *budget) takeItems(n int) error {
+	b.items += n
+	if b.limits.MaxItems > 0 && b.items > b.limits.MaxItems {
+		return fmt.Errorf("%w: the query requests more than %d items", ErrLimitExceeded, b.limits.MaxItems)
+	}
+	return nil
+}
+
+func (b *budget) checkDeadline() error {
+	if !b.limits.Deadline.IsZero() && time.Now().After(b.limits.Deadline) {
+		return ErrTimeout
+	}
+	return nil
+}
+
+type ArrayItem struct {
+	Index uint16      `json:"index"`
+	Value interface{} `json:"value"`
+}
+
+type ArrayPage struct {
+	Len   uint16       `json:"len"`
+	Items []*ArrayItem `json:"items"`
+	// offset of the next page, absent on the last page
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+type DictItem struct {
+	Key   interface{} `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type DictPage struct {
+	Len   uint32      `json:"len"`
+	Items []*DictItem `json:"items"`
+	// the cursor for the argument 'after' to get the next page, absent on the last page
+	Next string `json:"next,omitempty"`
+}
+
+type TLogItem struct {
+	Index     uint32      `json:"index"`
+	Timestamp int64       `json:"timestamp"`
+	Value     interface{} `json:"value"`
+}
+
+type TLogPage struct {
+	// number of records in the time slice
+	Len   uint32      `json:"len"`
+	Items []*TLogItem `json:"items"`
+	// offset of the next page, absent on the last page
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// arguments accepted by kinds of variables
+var kindArgs = map[Kind][]string{
+	KindScalar: {},
+	KindArray:  {"limit", "offset", "eq", "min", "max", "contains"},
+	KindDict:   {"limit", "after", "key", "eq", "min", "max", "contains"},
+	KindTLog:   {"limit", "offset", "from_ts", "to_ts", "desc", "eq", "min", "max", "contains"},
+}
+
+// Execute runs the query on variables of the state. The schema must include builtin variables.
+// Returns results by aliases of fields
+func Execute(schema *Schema, vars kv.Codec, fields []*Field) (map[string]interface{}, error) {
+	return ExecuteWithLimits(schema, vars, fields, Limits{})
+}
+
+// ExecuteWithLimits is Execute which fails with ErrLimitExceeded or ErrTimeout if the query is over limits
+func ExecuteWithLimits(schema *Schema, vars kv.Codec, fields []*Field, limits Limits) (map[string]interface{}, error) {
+	if limits.MaxFields > 0 && len(fields) > limits.MaxFields {
+		return nil, fmt.Errorf("%w: the query selects %d fields, maximum is %d", ErrLimitExceeded, len(fields), limits.MaxFields)
+	}
+	b := &budget{limits: limits}
+	ret := make(map[string]interface{})
+	for _, f := range fields {
+		if err := b.checkDeadline(); err != nil {
+			return nil, err
+		}
+		var err error
+		if ret[f.Alias], err = executeField(schema, vars, f, b); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Alias, err)
+		}
+	}
+	return ret, nil
+}
+
+func executeField(schema *Schema, vars kv.Codec, f *Field, b *budget) (interface{}, error) {
+	if f.Name == SchemaField {
+		if len(f.Args) > 0 {
+			return nil, fmt.Errorf("no arguments expected")
+		}
+		return schema, nil
+	}
+	v := schema.Variable(f.Name)
+	if v == nil {
+		return nil, fmt.Errorf("variable '%s' is not in the schema", f.Name)
+	}
+	a := &args{values: f.Args}
+	if err := a.check(kindArgs[v.Kind]); err != nil {
+		return nil, err
+	}
+	key := kv.Key(v.Name)
+	switch v.Kind {
+	case KindScalar:
+		data, err := vars.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		return v.ValueCodec.Decode(data)
+	case KindArray:
+		return queryArray(v, vars, a, b)
+	case KindDict:
+		return queryDict(v, vars, a, b)
+	case KindTLog:
+		return queryTLog(v, vars, a, b)
+	}
+	return nil, fmt.Errorf("unknown kind '%s'", v.Kind)
+}
+
+func queryArray(v *Variable, vars kv.Codec, a *args, b *budget) (*ArrayPage, error) {
+	limit, offset, err := a.page(b)
+	if err != nil {
+		return nil, err
+	}
+	flt, err := a.filter(v.ValueCodec)
+	if err != nil {
+		return nil, err
+	}
+	arr, err := vars.GetArray(kv.Key(v.Name))
+	if err != nil {
+		return nil, err
+	}
+	ret := &ArrayPage{Len: arr.Len(), Items: make([]*ArrayItem, 0)}
+	i := offset
+	for ; i < int(ret.Len) && len(ret.Items) < limit && i-offset < maxScan; i++ {
+		if err := b.checkDeadline(); err != nil {
+			return nil, err
+		}
+		data, err := arr.GetAt(uint16(i))
+		if err != nil {
+			return nil, err
+		}
+		item, ok, err := flt.apply(data)
+		if err != nil {
+			return nil, fmt.Errorf("element #%d: %v", i, err)
+		}
+		if ok {
+			ret.Items = append(ret.Items, &ArrayItem{Index: uint16(i), Value: item})
+		}
+	}
+	if i < int(ret.Len) {
+		ret.NextOffset = &i
+	}
+	return ret, nil
+}
+
+func queryDict(v *Variable, vars kv.Codec, a *args, b *budget) (*DictPage, error) {
+	limit, err := a.int("limit", DefaultLimit, 1, MaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	flt, err := a.filter(v.ValueCodec)
+	if err != nil {
+		return nil, err
+	}
+	dict, err := vars.GetDictionary(kv.Key(v.Name))
+	if err != nil {
+		return nil, err
+	}
+	ret := &DictPage{Len: dict.Len(), Items: make([]*DictItem, 0)}
+
+	if s, ok, err := a.string("key"); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		if err := b.takeItems(1); err != nil {
+			return nil, err
+		}
+		elemKey, err := v.KeyCodec.Encode(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key: %v", err)
+		}
+		data, err := dict.GetAt(elemKey)
+		if err != nil || data == nil {
+			return ret, err
+		}
+		item, ok, err := flt.apply(data)
+		if err != nil || !ok {
+			return ret, err
+		}
+		ret.Items = append(ret.Items, &DictItem{Key: s, Value: item})
+		return ret, nil
+	}
+
+	if err := b.takeItems(limit); err != nil {
+		return nil, err
+	}
+	var after []byte
+	if s, ok, err := a.string("after"); err != nil {
+		return nil, err
+	} else if ok {
+		if after, err = v.KeyCodec.Encode(s); err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+	}
+	// the order of iteration depends on the database, so entries with the least keys are selected.
+	// Only limit+1 entries are kept in memory, the extra one tells if there's the next page
+	entries := &dictEntries{}
+	var errIter error
+	err = dict.Iterate(func(elemKey []byte, value []byte) bool {
+		if errIter = b.checkDeadline(); errIter != nil {
+			return false
+		}
+		if after != nil && bytes.Compare(elemKey, after) <= 0 {
+			return true
+		}
+		if entries.Len() > limit && bytes.Compare(elemKey, (*entries)[0].key) >= 0 {
+			return true
+		}
+		var ok bool
+		if _, ok, errIter = flt.apply(value); errIter != nil {
+			return false
+		}
+		if !ok {
+			return true
+		}
+		heap.Push(entries, dictEntry{key: append([]byte{}, elemKey...), value: append([]byte{}, value...)})
+		if entries.Len() > limit+1 {
+			heap.Pop(entries)
+		}
+		return true
+	})
+	if err == nil {
+		err = errIter
+	}
+	if err != nil {
+		return nil, err
+	}
+	sorted := *entries
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].key, sorted[j].key) < 0
+	})
+	for i := 0; i < len(sorted) && i < limit; i++ {
+		k, err := v.KeyCodec.Decode(sorted[i].key)
+		if err != nil {
+			return nil, fmt.Errorf("key: %v", err)
+		}
+		value, err := v.ValueCodec.Decode(sorted[i].value)
+		if err != nil {
+			return nil, err
+		}
+		ret.Items = append(ret.Items, &DictItem{Key: k, Value: value})
+	}
+	if len(sorted) > limit {
+		if ret.Next, err = v.KeyCodec.Text(sorted[limit-1].key); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+type dictEntry struct{ key, value []byte }
+
+// dictEntries is the max-heap of dictionary entries by keys
+type dictEntries []dictEntry
+
+func (d dictEntries) Len() int            { return len(d) }
+func (d dictEntries) Less(i, j int) bool  { return bytes.Compare(d[i].key, d[j].key) > 0 }
+func (d dictEntries) Swap(i, j int)       { d[i], d[j] = d[j], d[i] }
+func (d *dictEntries) Push(x interface{}) { *d = append(*d, x.(dictEntry)) }
+func (d *dictEntries) Pop() interface{} {
+	old := *d
+	ret := old[len(old)-1]
+	*d = old[:len(old)-1]
+	return ret
+}
+
+func queryTLog(v *Variable, vars kv.Codec, a *args, b *budget) (*TLogPage, error) {
+	limit, offset, err := a.page(b)
+	if err != nil {
+		return nil, err
+	}
+	flt, err := a.filter(v.ValueCodec)
+	if err != nil {
+		return nil, err
+	}
+	fromTs, err := a.int64("from_ts")
+	if err != nil {
+		return nil, err
+	}
+	toTs, err := a.int64("to_ts")
+	if err != nil {
+		return nil, err
+	}
+	desc, err := a.bool("desc")
+	if err != nil {
+		return nil, err
+	}
+	tlog, err := vars.GetTimestampedLog(kv.Key(v.Name))
+	if err != nil {
+		return nil, err
+	}
+	ret := &TLogPage{Items: make([]*TLogItem, 0)}
+	slice, err := tlog.TakeTimeSlice(fromTs, toTs)
+	if err != nil {
+		return nil, err
+	}
+	if slice.IsEmpty() {
+		return ret, nil
+	}
+	ret.Len = slice.NumPoints()
+	first, last := slice.FromToIndices()
+	i := offset
+	for ; i < int(ret.Len) && len(ret.Items) < limit && i-offset < maxScan; i++ {
+		if err := b.checkDeadline(); err != nil {
+			return nil, err
+		}
+		idx := first + uint32(i)
+		if desc {
+			idx = last - uint32(i)
+		}
+		recs, err := tlog.LoadRecordsRaw(idx, idx, false)
+		if err != nil {
+			return nil, err
+		}
+		rec, err := kv.ParseRawLogRecord(recs[0])
+		if err != nil {
+			return nil, err
+		}
+		item, ok, err := flt.apply(rec.Data)
+		if err != nil {
+			return nil, fmt.Errorf("record #%d: %v", idx, err)
+		}
+		if ok {
+			ret.Items = append(ret.Items, &TLogItem{Index: idx, Timestamp: rec.Timestamp, Value: item})
+		}
+	}
+	if i < int(ret.Len) {
+		ret.NextOffset = &i
+	}
+	return ret, nil
+}
+
+type args struct {
+	values map[string]interface{}
+}
+
+func (a *args) check(allowed []string) error {
+	for name := range a.values {
+		found := false
+		for _, s := range allowed {
+			if s == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown argument '%s'", name)
+		}
+	}
+	return nil
+}
+
+func (a *args) string(name string) (string, bool, error) {
+	v, ok := a.values[name]
+	if !ok {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, fmt.Errorf("argument '%s' must be a string", name)
+	}
+	return s, true, nil
+}
+
+func (a *args) int64(name string) (int64, error) {
+	v, ok := a.values[name]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("argument '%s' must be a number", name)
+	}
+	return n, nil
+}
+
+func (a *args) int(name string, def, min, max int) (int, error) {
+	if _, ok := a.values[name]; !ok {
+		return def, nil
+	}
+	n, err := a.int64(name)
+	if err != nil {
+		return 0, err
+	}
+	if n < int64(min) || n > int64(max) {
+		return 0, fmt.Errorf("argument '%s' must be between %d and %d", name, min, max)
+	}
+	return int(n), nil
+}
+
+func (a *args) bool(name string) (bool, error) {
+	v, ok := a.values[name]
+	if !ok {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("argument '%s' must be true or false", name)
+	}
+	return b, nil
+}
+
+func (a *args) page(b *budget) (int, int, error) {
+	limit, err := a.int("limit", DefaultLimit, 1, MaxLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := b.takeItems(limit); err != nil {
+		return 0, 0, err
+	}
+	offset, err := a.int("offset", 0, 0, 1<<32-1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+// filter selects values: equal to the value, in the range of numbers or containing the substring
+type filter struct {
+	codec    Codec
+	eq       []byte
+	min, max *int64
+	contains *string
+}
+
+func (a *args) filter(codec Codec) (*filter, error) {
+	ret := &filter{codec: codec}
+	if s, ok, err := a.string("eq"); err != nil {
+		return nil, err
+	} else if ok {
+		if ret.eq, err = codec.Encode(s); err != nil {
+			return nil, fmt.Errorf("invalid argument 'eq': %v", err)
+		}
+	}
+	for _, name := range []string{"min", "max"} {
+		if _, ok := a.values[name]; !ok {
+			continue
+		}
+		if codec != CodecInt64 {
+			return nil, fmt.Errorf("argument '%s' is only allowed for int64 values", name)
+		}
+		n, err := a.int64(name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "min" {
+			ret.min = &n
+		} else {
+			ret.max = &n
+		}
+	}
+	if s, ok, err := a.string("contains"); err != nil {
+		return nil, err
+	} else if ok {
+		if codec != CodecString {
+			return nil, fmt.Errorf("argument 'contains' is only allowed for string values")
+		}
+		ret.contains = &s
+	}
+	return ret, nil
+}
+
+// apply decodes the value and checks if it passes the filter
+func (f *filter) apply(data []byte) (interface{}, bool, error) {
+	if f.eq != nil && !bytes.Equal(f.eq, data) {
+		return nil, false, nil
+	}
+	v, err := f.codec.Decode(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if f.min != nil || f.max != nil {
+		n, ok := v.(int64)
+		if !ok || (f.min != nil && n < *f.min) || (f.max != nil && n > *f.max) {
+			return nil, false, nil
+		}
+	}
+	if f.contains != nil {
+		s, ok := v.(string)
+		if !ok || !strings.Contains(s, *f.contains) {
+			return nil, false, nil
+		}
+	}
+	return v, true, nil
+}
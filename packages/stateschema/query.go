@@ -0,0 +1,240 @@
+package stateschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field is one selection of the query: the variable of the state and arguments of the selection
+type Field struct {
+	// key of the result. The name of the variable if there is no alias
+	Alias string
+	Name  string
+	// values are string, int64 or bool
+	Args map[string]interface{}
+}
+
+// ParseQuery parses the query in the GraphQL-like syntax: a selection of variables of the state with
+// optional aliases and arguments, e.g.
+//
+//	{ counter  registry(limit: 10, after: "abc")  latest: log(desc: true, limit: 5) }
+//
+// Names of variables which are not identifiers are quoted: { "my var" }. Braces and commas are optional
+func ParseQuery(s string) ([]*Field, error) {
+	p := &parser{lex: &lexer{src: s}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	braced := p.tok.is(tokPunct, "{")
+	if braced {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	ret := make([]*Field, 0)
+	aliases := make(map[string]bool)
+	for {
+		if braced && p.tok.is(tokPunct, "}") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if p.tok.kind == tokEOF {
+			if braced {
+				return nil, fmt.Errorf("query: '}' expected at the end")
+			}
+			break
+		}
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		if aliases[f.Alias] {
+			return nil, fmt.Errorf("query: duplicate field '%s', use aliases", f.Alias)
+		}
+		aliases[f.Alias] = true
+		ret = append(ret, f)
+		if p.tok.is(tokPunct, ",") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("query: unexpected '%s' after the end", p.tok.text)
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("query: no fields selected")
+	}
+	return ret, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	var err error
+	p.tok, err = p.lex.next()
+	return err
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName && p.tok.kind != tokString {
+		return "", fmt.Errorf("query: name expected at position %d, got '%s'", p.tok.pos, p.tok.text)
+	}
+	ret := p.tok.text
+	return ret, p.next()
+}
+
+func (p *parser) field() (*Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	f := &Field{Alias: name, Name: name, Args: make(map[string]interface{})}
+	if p.tok.is(tokPunct, ":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.tok.is(tokPunct, "(") {
+		return f, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	for !p.tok.is(tokPunct, ")") {
+		if p.tok.kind != tokName {
+			return nil, fmt.Errorf("query: argument name expected at position %d, got '%s'", p.tok.pos, p.tok.text)
+		}
+		argName := p.tok.text
+		if _, dup := f.Args[argName]; dup {
+			return nil, fmt.Errorf("query: duplicate argument '%s' of '%s'", argName, f.Alias)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if !p.tok.is(tokPunct, ":") {
+			return nil, fmt.Errorf("query: ':' expected after argument '%s'", argName)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.tok.kind == tokString:
+			f.Args[argName] = p.tok.text
+		case p.tok.kind == tokNumber:
+			n, err := strconv.ParseInt(p.tok.text, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("query: invalid number '%s' at position %d", p.tok.text, p.tok.pos)
+			}
+			f.Args[argName] = n
+		case p.tok.is(tokName, "true"):
+			f.Args[argName] = true
+		case p.tok.is(tokName, "false"):
+			f.Args[argName] = false
+		default:
+			return nil, fmt.Errorf("query: value of argument '%s' expected at position %d", argName, p.tok.pos)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.is(tokPunct, ",") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		if p.tok.kind == tokEOF {
+			return nil, fmt.Errorf("query: ')' expected")
+		}
+	}
+	return f, p.next()
+}
+
+type tokenKind int
+
+const (
+	tokEOF = tokenKind(iota)
+	tokPunct
+	tokName
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func isNameRune(r rune, first bool) bool {
+	if r == '_' || r == '$' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && (r == '.' || unicode.IsDigit(r))
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.ContainsRune(" \t\r\n", rune(l.src[l.pos])) {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+	start := l.pos
+	c := rune(l.src[l.pos])
+	switch {
+	case strings.ContainsRune("{}():,", c):
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("query: unterminated string at position %d", start)
+		}
+		l.pos++
+		s, err := strconv.Unquote(l.src[start:l.pos])
+		if err != nil {
+			return token{}, fmt.Errorf("query: invalid string at position %d", start)
+		}
+		return token{kind: tokString, text: s, pos: start}, nil
+
+	case c == '-' || unicode.IsDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && unicode.IsDigit(rune(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+
+	case isNameRune(c, true):
+		for l.pos < len(l.src) && isNameRune(rune(l.src[l.pos]), l.pos == start) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], pos: start}, nil
+	}
+	return token{}, fmt.Errorf("query: unexpected character '%c' at position %d", c, start)
+}
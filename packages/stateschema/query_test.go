@@ -0,0 +1,137 @@
+package stateschema
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	fields, err := ParseQuery(`{ counter, reg: registry(limit: 10, after: "a\"b") "$description$" log(desc: true from_ts: -5) }`)
+	assert.NoError(t, err)
+	assert.Len(t, fields, 4)
+	assert.Equal(t, &Field{Alias: "counter", Name: "counter", Args: map[string]interface{}{}}, fields[0])
+	assert.Equal(t, "reg", fields[1].Alias)
+	assert.Equal(t, "registry", fields[1].Name)
+	assert.Equal(t, map[string]interface{}{"limit": int64(10), "after": `a"b`}, fields[1].Args)
+	assert.Equal(t, vmconst.VarNameDescription, fields[2].Name)
+	assert.Equal(t, map[string]interface{}{"desc": true, "from_ts": int64(-5)}, fields[3].Args)
+
+	fields, err = ParseQuery("counter")
+	assert.NoError(t, err)
+	assert.Len(t, fields, 1)
+
+	for _, q := range []string{"", "{}", "{ counter", "{ a(limit 1) }", "{ a a }", "{ a(x: y) }", "{ a } b", `{ "a }`} {
+		_, err = ParseQuery(q)
+		assert.Error(t, err, q)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	schema := &Schema{Variables: []*Variable{
+		{Name: "counter", Kind: KindScalar, ValueCodec: CodecInt64},
+		{Name: "names", Kind: KindArray, ValueCodec: CodecString},
+		{Name: "balances", Kind: KindDict, KeyCodec: CodecString, ValueCodec: CodecInt64},
+		{Name: "log", Kind: KindTLog, ValueCodec: CodecString},
+	}}
+	assert.NoError(t, schema.Validate())
+	assert.Error(t, (&Schema{Variables: []*Variable{{Name: vmconst.VarNameDescription, Kind: KindScalar, ValueCodec: CodecString}}}).Validate())
+	assert.Error(t, (&Schema{Variables: []*Variable{{Name: "x", Kind: KindScalar, KeyCodec: CodecString, ValueCodec: CodecString}}}).Validate())
+
+	vars := kv.NewMap()
+	codec := vars.MustCodec()
+	codec.SetInt64("counter", 42)
+	codec.SetString(vmconst.VarNameDescription, "test")
+	arr := codec.GetArray("names")
+	for _, s := range []string{"alice", "bob", "carol", "dave"} {
+		arr.Push([]byte(s))
+	}
+	dict := codec.GetDictionary("balances")
+	for i, s := range []string{"c", "a", "d", "b"} {
+		dict.SetAt([]byte(s), util.Uint64To8Bytes(uint64(i*10)))
+	}
+	tlog := codec.GetTimestampedLog("log")
+	for i, s := range []string{"one", "two", "three"} {
+		tlog.Append(int64(i+1)*100, []byte(s))
+	}
+
+	run := func(q string) map[string]interface{} {
+		fields, err := ParseQuery(q)
+		assert.NoError(t, err)
+		ret, err := Execute(schema.WithBuiltins(), vars.Codec(), fields)
+		assert.NoError(t, err)
+		return ret
+	}
+
+	ret := run(`{ counter "$description$" "$minreward$" }`)
+	assert.EqualValues(t, 42, ret["counter"])
+	assert.Equal(t, "test", ret[vmconst.VarNameDescription])
+	assert.Nil(t, ret[vmconst.VarNameMinimumReward])
+
+	ret = run(`{ names(offset: 1, limit: 2) }`)
+	page := ret["names"].(*ArrayPage)
+	assert.EqualValues(t, 4, page.Len)
+	assert.Equal(t, []*ArrayItem{{1, "bob"}, {2, "carol"}}, page.Items)
+	assert.Equal(t, 3, *page.NextOffset)
+
+	ret = run(`{ names(contains: "a") }`)
+	page = ret["names"].(*ArrayPage)
+	assert.Equal(t, []*ArrayItem{{0, "alice"}, {2, "carol"}, {3, "dave"}}, page.Items)
+	assert.Nil(t, page.NextOffset)
+
+	ret = run(`{ first: balances(limit: 2) next: balances(limit: 2, after: "b") one: balances(key: "d") big: balances(min: 15) }`)
+	dpage := ret["first"].(*DictPage)
+	assert.EqualValues(t, 4, dpage.Len)
+	assert.Equal(t, []*DictItem{{"a", int64(10)}, {"b", int64(30)}}, dpage.Items)
+	assert.Equal(t, "b", dpage.Next)
+	dpage = ret["next"].(*DictPage)
+	assert.Equal(t, []*DictItem{{"c", int64(0)}, {"d", int64(20)}}, dpage.Items)
+	assert.Empty(t, dpage.Next)
+	assert.Equal(t, []*DictItem{{"d", int64(20)}}, ret["one"].(*DictPage).Items)
+	assert.Equal(t, []*DictItem{{"b", int64(30)}, {"d", int64(20)}}, ret["big"].(*DictPage).Items)
+
+	ret = run(`{ log(desc: true, limit: 1) slice: log(from_ts: 150, to_ts: 300) }`)
+	tpage := ret["log"].(*TLogPage)
+	assert.EqualValues(t, 3, tpage.Len)
+	assert.Equal(t, []*TLogItem{{2, 300, "three"}}, tpage.Items)
+	assert.Equal(t, 1, *tpage.NextOffset)
+	tpage = ret["slice"].(*TLogPage)
+	assert.EqualValues(t, 2, tpage.Len)
+	assert.Equal(t, []*TLogItem{{1, 200, "two"}, {2, 300, "three"}}, tpage.Items)
+
+	ret = run(`{ __schema }`)
+	assert.Equal(t, len(schema.Variables)+len(builtinVariables), len(ret[SchemaField].(*Schema).Variables))
+
+	for _, q := range []string{`{ unknown }`, `{ counter(limit: 1) }`, `{ names(min: 1) }`, `{ balances(limit: 0) }`, `{ balances(key: 1) }`} {
+		fields, err := ParseQuery(q)
+		assert.NoError(t, err)
+		_, err = Execute(schema.WithBuiltins(), vars.Codec(), fields)
+		assert.Error(t, err, q)
+	}
+
+	limits := Limits{MaxFields: 2, MaxItems: 30}
+	for q, expected := range map[string]error{
+		`{ counter names(limit: 10) balances(key: "a") }`: ErrLimitExceeded,
+		`{ names(limit: 10) balances(limit: 21) }`:        ErrLimitExceeded,
+		`{ names(limit: 10) balances(limit: 20) }`:        nil,
+		`{ counter balances }`:                            nil,
+	} {
+		fields, err := ParseQuery(q)
+		assert.NoError(t, err)
+		_, err = ExecuteWithLimits(schema.WithBuiltins(), vars.Codec(), fields, limits)
+		if expected == nil {
+			assert.NoError(t, err, q)
+		} else {
+			assert.True(t, errors.Is(err, expected), q)
+		}
+	}
+	fields, err := ParseQuery(`{ counter }`)
+	assert.NoError(t, err)
+	_, err = ExecuteWithLimits(schema.WithBuiltins(), vars.Codec(), fields, Limits{Deadline: time.Now().Add(-time.Second)})
+	assert.True(t, errors.Is(err, ErrTimeout))
+}
@@ -0,0 +1,147 @@
+// Package stateschema describes variables of the state of the smart contract: their names, kinds of
+// collections and encodings of values. The schema is registered together with the program metadata and
+// makes the state browsable by generic clients with the query language of the package
+package stateschema
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
+)
+
+type Kind string
+
+const (
+	KindScalar = Kind("scalar")
+	KindArray  = Kind("array")
+	KindDict   = Kind("dict")
+	KindTLog   = Kind("tlog")
+)
+
+type Variable struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	// encoding of keys of the dictionary. Empty for other kinds
+	KeyCodec Codec `json:"key_codec,omitempty"`
+	// encoding of the scalar value, elements of the array or the dictionary, data of records of the log
+	ValueCodec  Codec  `json:"value_codec"`
+	Description string `json:"description,omitempty"`
+}
+
+type Schema struct {
+	Variables []*Variable `json:"variables"`
+}
+
+// builtinVariables are set by the VM in the state of every smart contract
+var builtinVariables = []*Variable{
+	{Name: vmconst.VarNameOwnerAddress, Kind: KindScalar, ValueCodec: CodecAddress, Description: "address of the owner"},
+	{Name: vmconst.VarNameProgramHash, Kind: KindScalar, ValueCodec: CodecHash, Description: "hash of the program"},
+	{Name: vmconst.VarNameDescription, Kind: KindScalar, ValueCodec: CodecString, Description: "description"},
+	{Name: vmconst.VarNameMinimumReward, Kind: KindScalar, ValueCodec: CodecInt64, Description: "minimum reward for requests, iotas"},
+}
+
+// Validate checks if names of variables are unique and kinds and codecs are known
+func (s *Schema) Validate() error {
+	names := make(map[string]bool)
+	for _, v := range builtinVariables {
+		names[v.Name] = true
+	}
+	for _, v := range s.Variables {
+		if v.Name == "" {
+			return fmt.Errorf("name of the variable is empty")
+		}
+		if names[v.Name] {
+			return fmt.Errorf("duplicate or builtin variable '%s'", v.Name)
+		}
+		names[v.Name] = true
+		switch v.Kind {
+		case KindScalar, KindArray, KindTLog:
+			if v.KeyCodec != "" {
+				return fmt.Errorf("variable '%s': key codec is only allowed for dictionaries", v.Name)
+			}
+		case KindDict:
+			if !v.KeyCodec.valid() {
+				return fmt.Errorf("variable '%s': unknown key codec '%s'", v.Name, v.KeyCodec)
+			}
+		default:
+			return fmt.Errorf("variable '%s': unknown kind '%s'", v.Name, v.Kind)
+		}
+		if !v.ValueCodec.valid() {
+			return fmt.Errorf("variable '%s': unknown value codec '%s'", v.Name, v.ValueCodec)
+		}
+	}
+	return nil
+}
+
+// WithBuiltins returns the schema extended with builtin variables of every smart contract.
+// The schema may be nil
+func (s *Schema) WithBuiltins() *Schema {
+	ret := &Schema{Variables: make([]*Variable, 0, len(builtinVariables))}
+	ret.Variables = append(ret.Variables, builtinVariables...)
+	if s != nil {
+		ret.Variables = append(ret.Variables, s.Variables...)
+	}
+	return ret
+}
+
+// Variable returns the variable by name or nil
+func (s *Schema) Variable(name string) *Variable {
+	for _, v := range s.Variables {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *Schema) Write(w io.Writer) error {
+	if err := util.WriteUint16(w, uint16(len(s.Variables))); err != nil {
+		return err
+	}
+	for _, v := range s.Variables {
+		if err := v.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) Read(r io.Reader) error {
+	var num uint16
+	if err := util.ReadUint16(r, &num); err != nil {
+		return err
+	}
+	s.Variables = make([]*Variable, num)
+	for i := range s.Variables {
+		s.Variables[i] = &Variable{}
+		if err := s.Variables[i].Read(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Variable) Write(w io.Writer) error {
+	for _, s := range []string{v.Name, string(v.Kind), string(v.KeyCodec), string(v.ValueCodec), v.Description} {
+		if err := util.WriteString16(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Variable) Read(r io.Reader) error {
+	var err error
+	var kind, keyCodec, valueCodec string
+	for _, s := range []*string{&v.Name, &kind, &keyCodec, &valueCodec, &v.Description} {
+		if *s, err = util.ReadString16(r); err != nil {
+			return err
+		}
+	}
+	v.Kind = Kind(kind)
+	v.KeyCodec = Codec(keyCodec)
+	v.ValueCodec = Codec(valueCodec)
+	return nil
+}
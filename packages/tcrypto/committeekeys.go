@@ -0,0 +1,109 @@
+package tcrypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+)
+
+// KeyScheme is the scheme of keys of the committee
+type KeyScheme byte
+
+const (
+	// key shares of one master key, generated by DKG. Any T of N signature shares make the signature
+	KeySchemeThreshold = KeyScheme(iota)
+	// shares of the aggregated key of members, made by dealing of members relayed by the admin (see MultiSigKeys).
+	// Any T of N signature shares make the signature, as with DKG
+	KeySchemeMultiSig
+)
+
+func (s KeyScheme) String() string {
+	switch s {
+	case KeySchemeThreshold:
+		return "threshold"
+	case KeySchemeMultiSig:
+		return "multisig"
+	}
+	return "unknown"
+}
+
+// CommitteeKeys are keys of the committee member. The consensus operator uses them to sign the result transaction
+// with the own signature share, to verify signature shares of peers and to make the final signature of the address.
+// Both key schemes produce BLS addresses
+type CommitteeKeys interface {
+	Scheme() KeyScheme
+	// KeyAddress is the address of the smart contract
+	KeyAddress() *address.Address
+	// Size is the number of members
+	Size() uint16
+	// Quorum is the number of signature shares needed to make the final signature
+	Quorum() uint16
+	// OwnIndex is the index of the own node among members
+	OwnIndex() uint16
+	SignShare(data []byte) (tbdn.SigShare, error)
+	VerifySigShareOfPeer(peerIndex uint16, data []byte, sigshare tbdn.SigShare) error
+	RecoverFullSignature(sigShares [][]byte, data []byte) (signaturescheme.Signature, error)
+	// HasPrivateKey is false if the node has only the public part of keys and the private key is kept by the remote signer
+	HasPrivateKey() bool
+	Write(w io.Writer) error
+}
+
+// MarshalCommitteeKeys serializes keys of any scheme, prefixed with the scheme
+func MarshalCommitteeKeys(ks CommitteeKeys) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(ks.Scheme()))
+	if err := ks.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCommitteeKeys parses and validates keys serialized with MarshalCommitteeKeys
+func UnmarshalCommitteeKeys(data []byte) (CommitteeKeys, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty committee keys data")
+	}
+	switch KeyScheme(data[0]) {
+	case KeySchemeThreshold:
+		return UnmarshalDKShare(data[1:], false)
+	case KeySchemeMultiSig:
+		return UnmarshalMultiSigKeys(data[1:])
+	}
+	return nil, fmt.Errorf("unknown key scheme %d", data[0])
+}
+
+// PublicPartOf returns copy of keys of any scheme without the private key
+func PublicPartOf(ks CommitteeKeys) CommitteeKeys {
+	switch ks := ks.(type) {
+	case *DKShare:
+		return ks.PublicPart()
+	case *MultiSigKeys:
+		return ks.PublicPart()
+	}
+	panic(fmt.Sprintf("unknown committee keys of scheme %s", ks.Scheme()))
+}
+
+func (ks *DKShare) Scheme() KeyScheme {
+	return KeySchemeThreshold
+}
+
+func (ks *DKShare) KeyAddress() *address.Address {
+	return ks.Address
+}
+
+func (ks *DKShare) Size() uint16 {
+	return ks.N
+}
+
+func (ks *DKShare) Quorum() uint16 {
+	return ks.T
+}
+
+func (ks *DKShare) OwnIndex() uint16 {
+	return ks.Index
+}
@@ -1,5 +1,4 @@
 // wrapper package for BLS threshold cryptography used in the Wasp node
-// TODO DKG protocol must be rewritten because currently it is not 100% secure
 package tcrypto
 
 import (
@@ -44,15 +43,9 @@ type DKShare struct {
 	// secret partial private key
 	// it is a sum of private shares, generated during DKG
 	// partial private key not known to anyone
-	// TODO however owner can reconstruct master secret from the information gathered during the DKG
-	// make that optional during DKG
 	priKey kyber.Scalar
-	// temporary fields used during DKG process
-	// not used after
-	// TODO refactor during cleanup, remove tmp fields from the permanent structure
-	Aggregated bool              // true after DKG
-	Committed  bool              // true after DKG
-	PriShares  []*share.PriShare // nil after DKG
+	// true after DKG
+	Committed bool
 }
 
 func ValidateDKSParams(t, n, index uint16) error {
@@ -68,77 +61,77 @@ func ValidateDKSParams(t, n, index uint16) error {
 	return nil
 }
 
-// NewRndDKShare creates empty structure
-func NewRndDKShare(t, n, index uint16) (*DKShare, error) {
-	if err := ValidateDKSParams(t, n, index); err != nil {
+// NewDKShareFromDistKey creates committed key share from the result of the distributed key generation:
+// the own private share and public commitments of the master polynomial, which are the same for all nodes
+func NewDKShareFromDistKey(t, n uint16, priShare *share.PriShare, commits []kyber.Point) (*DKShare, error) {
+	if err := ValidateDKSParams(t, n, uint16(priShare.I)); err != nil {
 		return nil, err
 	}
+	if len(commits) != int(t) {
+		return nil, fmt.Errorf("wrong number of public commitments %d, expected %d", len(commits), t)
+	}
 	suite := bn256.NewSuite()
-	// create seed secret
-	secret := suite.G1().Scalar().Pick(suite.RandomStream())
-	// create random polynomial of degree t
-	priPoly := share.NewPriPoly(suite.G2(), int(t), secret, suite.RandomStream())
-	// create private shares of the random polynomial
-	// with index n corresponds to p(n+1)
-	shares := priPoly.Shares(int(n))
-	ret := &DKShare{
+	ks := &DKShare{
 		Suite:     suite,
 		N:         n,
 		T:         t,
-		Index:     index,
-		PriShares: shares,
+		Index:     uint16(priShare.I),
+		PubPoly:   share.NewPubPoly(suite.G2(), nil, commits),
+		priKey:    priShare.V,
+		Committed: true,
 	}
-	return ret, nil
-}
-
-// AggregateDKS is a call in DKG process
-func (ks *DKShare) AggregateDKS(priShares []kyber.Scalar) error {
-	if ks.Aggregated {
-		return errors.New("already Aggregated")
-	}
-	// aggregate (add up) secret shares
-	ks.priKey = ks.Suite.G2().Scalar().Zero()
-	for i, pshare := range priShares {
-		if uint16(i) == ks.Index {
-			ks.priKey = ks.priKey.Add(ks.priKey, ks.PriShares[ks.Index].V)
-			continue
-		}
-		ks.priKey = ks.priKey.Add(ks.priKey, pshare)
+	ks.PubKeys = make([]kyber.Point, n)
+	for i := range ks.PubKeys {
+		ks.PubKeys[i] = ks.PubPoly.Eval(i).V
 	}
-	// calculate own public key
-	ks.PubKeyOwn = ks.Suite.G2().Point().Mul(ks.priKey, nil)
-	ks.Aggregated = true
-	return nil
-}
-
-// FinalizeDKS is a call in DKG process
-func (ks *DKShare) FinalizeDKS(pubKeys []kyber.Point) error {
-	if ks.Committed {
-		return errors.New("already Committed")
+	ks.PubKeyOwn = ks.PubKeys[ks.Index]
+	if !suite.G2().Point().Mul(ks.priKey, nil).Equal(ks.PubKeyOwn) {
+		return nil, errors.New("private share is inconsistent with public commitments")
 	}
-	ks.PubKeys = pubKeys
-	var err error
-	ks.PubPoly, err = RecoverPubPoly(ks.Suite, ks.PubKeys, ks.T, ks.N)
-	if err != nil {
-		return err
-	}
-	pubKeyMaster := ks.PubPoly.Commit()
-	pubKeyBin, err := pubKeyMaster.MarshalBinary()
+	ks.PubKeyMaster = ks.PubPoly.Commit()
+	pubKeyBin, err := ks.PubKeyMaster.MarshalBinary()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// calculate address, the permanent key ID
 	a := address.FromBLSPubKey(pubKeyBin)
 	ks.Address = &a
+	return ks, nil
+}
 
-	ks.PriShares = nil // not needed anymore
-	ks.Committed = true
-	return nil
+// HasPrivateKey is false if the private key share is kept by the remote signer and the node has only the public part
+func (ks *DKShare) HasPrivateKey() bool {
+	return ks.priKey != nil
+}
+
+// PublicPart returns copy of the key share without the private key
+func (ks *DKShare) PublicPart() *DKShare {
+	ret := *ks
+	ret.priKey = nil
+	return &ret
+}
+
+// PriShare returns the own private share. It is needed to reshare the key set among new nodes
+func (ks *DKShare) PriShare() *share.PriShare {
+	return &share.PriShare{
+		I: int(ks.Index),
+		V: ks.priKey,
+	}
+}
+
+// PubCoeffs returns public commitments of the master polynomial. The first one is the master public key.
+// They are same for all holders of shares and remain same for the reshared key set
+func (ks *DKShare) PubCoeffs() []kyber.Point {
+	_, commits := ks.PubPoly.Info()
+	return commits
 }
 
 // SignShare signs the data with the own key share.
 // returns SigShare, which contains signature and the index
 func (ks *DKShare) SignShare(data []byte) (tbdn.SigShare, error) {
+	if ks.priKey == nil {
+		return nil, errors.New("private key share is kept by the remote signer")
+	}
 	priShare := share.PriShare{
 		I: int(ks.Index),
 		V: ks.priKey,
@@ -164,12 +157,28 @@ func (ks *DKShare) VerifySigShare(data []byte, sigshare tbdn.SigShare) error {
 		return errors.New("key set is not committed")
 	}
 	idx, err := sigshare.Index()
-	if err != nil || idx >= int(ks.N) || idx < 0 {
+	if err != nil {
 		return err
 	}
+	if idx >= int(ks.N) || idx < 0 {
+		return fmt.Errorf("wrong index of the signature share: %d", idx)
+	}
 	return bdn.Verify(ks.Suite, ks.PubKeys[idx], data, sigshare.Value())
 }
 
+// VerifySigShareOfPeer checks if partial signature (sigshare) of the data is valid
+// and was produced by the peer with the index peerIndex
+func (ks *DKShare) VerifySigShareOfPeer(peerIndex uint16, data []byte, sigshare tbdn.SigShare) error {
+	idx, err := sigshare.Index()
+	if err != nil {
+		return err
+	}
+	if idx != int(peerIndex) {
+		return fmt.Errorf("signature share of peer #%d has index %d", peerIndex, idx)
+	}
+	return ks.VerifySigShare(data, sigshare)
+}
+
 // VerifyMasterSignature checks signature against master public key
 func (ks *DKShare) VerifyMasterSignature(data []byte, signature []byte) error {
 	if !ks.Committed {
@@ -4,26 +4,206 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
 )
 
-func TestNewRandomDKShare(t *testing.T) {
-	ks, err := NewRndDKShare(67, 100, 5)
-	assert.NoError(t, err)
+// runDKG runs Pedersen DKG among n in-memory participants
+func runDKG(t *testing.T, n, thr int) []*dkg.DistKeyShare {
+	suite := bn256.NewSuiteG2()
+	longterms := make([]kyber.Scalar, n)
+	pubs := make([]kyber.Point, n)
+	for i := range longterms {
+		longterms[i] = suite.Scalar().Pick(suite.RandomStream())
+		pubs[i] = suite.Point().Mul(longterms[i], nil)
+	}
+	gens := make([]*dkg.DistKeyGenerator, n)
+	for i := range gens {
+		var err error
+		gens[i], err = dkg.NewDistKeyGenerator(suite, longterms[i], pubs, thr)
+		assert.NoError(t, err)
+	}
+	resps := make([]*dkg.Response, 0)
+	for _, gen := range gens {
+		deals, err := gen.Deals()
+		assert.NoError(t, err)
+		for i, d := range deals {
+			resp, err := gens[i].ProcessDeal(d)
+			assert.NoError(t, err)
+			resps = append(resps, resp)
+		}
+	}
+	for _, resp := range resps {
+		for i, gen := range gens {
+			if resp.Response.Index == uint32(i) {
+				continue
+			}
+			_, err := gen.ProcessResponse(resp)
+			assert.NoError(t, err)
+		}
+	}
+	ret := make([]*dkg.DistKeyShare, n)
+	for i, gen := range gens {
+		assert.True(t, gen.Certified())
+		var err error
+		ret[i], err = gen.DistKeyShare()
+		assert.NoError(t, err)
+	}
+	return ret
+}
 
-	assert.NotNil(t, ks)
-	assert.Equal(t, ks.N, uint16(100))
-	assert.Equal(t, ks.T, uint16(67))
-	assert.Equal(t, ks.Index, uint16(5))
-	assert.Equal(t, len(ks.PriShares), 100)
-	assert.Equal(t, ks.Aggregated, false)
-	assert.Equal(t, ks.Committed, false)
+func TestDKShareFromDistKey(t *testing.T) {
+	const n, thr = 4, 3
+	dks := runDKG(t, n, thr)
 
-	_, err = NewRndDKShare(2, 2, 0)
+	shares := make([]*DKShare, n)
+	for i, dk := range dks {
+		var err error
+		shares[i], err = NewDKShareFromDistKey(thr, n, dk.Share, dk.Commits)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(i), shares[i].Index)
+		assert.Equal(t, *shares[0].Address, *shares[i].Address)
+	}
+	data := []byte("data to sign")
+	sigShares := make([][]byte, 0, thr)
+	for _, ks := range shares[1:] {
+		sigShare, err := ks.SignShare(data)
+		assert.NoError(t, err)
+		assert.NoError(t, shares[0].VerifySigShare(data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	sig, err := shares[0].RecoverFullSignature(sigShares, data)
 	assert.NoError(t, err)
+	assert.Equal(t, *shares[0].Address, sig.Address())
 
-	_, err = NewRndDKShare(5, 4, 0)
+	_, err = NewDKShareFromDistKey(2, n, dks[0].Share, dks[0].Commits)
 	assert.Error(t, err)
+}
 
-	_, err = NewRndDKShare(4, 5, 6)
-	assert.Error(t, err)
+func TestVerifySigShareOfPeer(t *testing.T) {
+	const n, thr = 4, 3
+	shares := make([]*DKShare, n)
+	for i, dk := range runDKG(t, n, thr) {
+		var err error
+		shares[i], err = NewDKShareFromDistKey(thr, n, dk.Share, dk.Commits)
+		assert.NoError(t, err)
+	}
+	data := []byte("data to sign")
+	sigShare, err := shares[2].SignShare(data)
+	assert.NoError(t, err)
+	assert.NoError(t, shares[0].VerifySigShareOfPeer(2, data, sigShare))
+	// valid share sent on behalf of another peer
+	assert.Error(t, shares[0].VerifySigShareOfPeer(1, data, sigShare))
+	// share of other data
+	assert.Error(t, shares[0].VerifySigShareOfPeer(2, []byte("other data"), sigShare))
+
+	// share with the index out of range
+	wrongIndex := make([]byte, len(sigShare))
+	copy(wrongIndex, sigShare)
+	wrongIndex[0], wrongIndex[1] = 0, n
+	assert.Error(t, shares[0].VerifySigShare(data, wrongIndex))
+}
+
+func TestValidateDKSParams(t *testing.T) {
+	assert.NoError(t, ValidateDKSParams(67, 100, 5))
+	assert.NoError(t, ValidateDKSParams(2, 2, 0))
+	assert.Error(t, ValidateDKSParams(5, 4, 0))
+	assert.Error(t, ValidateDKSParams(4, 5, 6))
+}
+
+// reshare runs resharing of the key set among in-memory participants. Old nodes are given by their key shares,
+// new nodes are the last ones of old nodes followed by numJoining new ones
+func reshare(t *testing.T, old []*DKShare, numLeaving, numJoining, newThr int) []*dkg.DistKeyShare {
+	suite := bn256.NewSuiteG2()
+	numAll := len(old) + numJoining
+	longterms := make([]kyber.Scalar, numAll)
+	pubs := make([]kyber.Point, numAll)
+	for i := range longterms {
+		longterms[i] = suite.Scalar().Pick(suite.RandomStream())
+		pubs[i] = suite.Point().Mul(longterms[i], nil)
+	}
+	oldNodes := pubs[:len(old)]
+	newNodes := pubs[numLeaving:]
+	gens := make([]*dkg.DistKeyGenerator, numAll)
+	for i := range gens {
+		c := &dkg.Config{
+			Suite:        suite,
+			Longterm:     longterms[i],
+			OldNodes:     oldNodes,
+			NewNodes:     newNodes,
+			Threshold:    newThr,
+			OldThreshold: int(old[0].T),
+		}
+		if i < len(old) {
+			c.Share = &dkg.DistKeyShare{Share: old[i].PriShare(), Commits: old[i].PubCoeffs()}
+		} else {
+			c.PublicCoeffs = old[0].PubCoeffs()
+		}
+		var err error
+		gens[i], err = dkg.NewDistKeyHandler(c)
+		assert.NoError(t, err)
+	}
+	resps := make([]*dkg.Response, 0)
+	for _, gen := range gens[:len(old)] {
+		deals, err := gen.Deals()
+		assert.NoError(t, err)
+		for i, d := range deals {
+			resp, err := gens[numLeaving+i].ProcessDeal(d)
+			assert.NoError(t, err)
+			resps = append(resps, resp)
+		}
+	}
+	for _, resp := range resps {
+		for i, gen := range gens {
+			if i >= numLeaving && resp.Response.Index == uint32(i-numLeaving) {
+				continue
+			}
+			_, err := gen.ProcessResponse(resp)
+			assert.NoError(t, err)
+		}
+	}
+	ret := make([]*dkg.DistKeyShare, 0)
+	for _, gen := range gens[numLeaving:] {
+		assert.True(t, gen.Certified())
+		dks, err := gen.DistKeyShare()
+		assert.NoError(t, err)
+		ret = append(ret, dks)
+	}
+	return ret
+}
+
+func TestReshare(t *testing.T) {
+	const n, thr = 4, 3
+	old := make([]*DKShare, n)
+	for i, dk := range runDKG(t, n, thr) {
+		var err error
+		old[i], err = NewDKShareFromDistKey(thr, n, dk.Share, dk.Commits)
+		assert.NoError(t, err)
+	}
+	// node #0 leaves, two nodes join
+	const newN, newThr = 5, 4
+	shares := make([]*DKShare, newN)
+	for i, dk := range reshare(t, old, 1, 2, newThr) {
+		var err error
+		shares[i], err = NewDKShareFromDistKey(newThr, newN, dk.Share, dk.Commits)
+		assert.NoError(t, err)
+		assert.Equal(t, *old[0].Address, *shares[i].Address)
+	}
+	data := []byte("data to sign")
+	sigShares := make([][]byte, 0, newThr)
+	for _, ks := range shares[1:] {
+		sigShare, err := ks.SignShare(data)
+		assert.NoError(t, err)
+		assert.NoError(t, shares[0].VerifySigShare(data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	sig, err := shares[0].RecoverFullSignature(sigShares, data)
+	assert.NoError(t, err)
+	assert.Equal(t, *old[0].Address, sig.Address())
+
+	// old shares are useless with the new ones
+	oldSigShare, err := old[0].SignShare(data)
+	assert.NoError(t, err)
+	assert.Error(t, shares[0].VerifySigShare(data, oldSigShare))
 }
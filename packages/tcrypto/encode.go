@@ -41,9 +41,13 @@ func (ks *DKShare) Write(w io.Writer) error {
 			return err
 		}
 	}
-	pkdata, err := ks.priKey.MarshalBinary()
-	if err != nil {
-		return err
+	// the private key is empty when it is kept by the remote signer
+	var pkdata []byte
+	if ks.priKey != nil {
+		pkdata, err = ks.priKey.MarshalBinary()
+		if err != nil {
+			return err
+		}
 	}
 	err = util.WriteBytes16(w, pkdata)
 	if err != nil {
@@ -97,10 +101,13 @@ func (ks *DKShare) Read(r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	priKey := ks.Suite.G2().Scalar()
-	err = priKey.UnmarshalBinary(data)
-	if err != nil {
-		return err
+	var priKey kyber.Scalar
+	if len(data) > 0 {
+		priKey = ks.Suite.G2().Scalar()
+		err = priKey.UnmarshalBinary(data)
+		if err != nil {
+			return err
+		}
 	}
 	ks.N = n
 	ks.T = t
@@ -119,15 +126,16 @@ func UnmarshalDKShare(data []byte, maskPrivate bool) (*DKShare, error) {
 	if err != nil {
 		return nil, err
 	}
-	ret.Aggregated = true
 	ret.Committed = true
 	ret.PubPoly, err = RecoverPubPoly(ret.Suite, ret.PubKeys, ret.T, ret.N)
 	if err != nil {
 		return nil, err
 	}
-	pubKeyOwn := ret.Suite.G2().Point().Mul(ret.priKey, nil)
-	if !pubKeyOwn.Equal(ret.PubKeys[ret.Index]) {
-		return nil, errors.New("crosscheck I: inconsistency while calculating public key")
+	if ret.priKey != nil {
+		pubKeyOwn := ret.Suite.G2().Point().Mul(ret.priKey, nil)
+		if !pubKeyOwn.Equal(ret.PubKeys[ret.Index]) {
+			return nil, errors.New("crosscheck I: inconsistency while calculating public key")
+		}
 	}
 	ret.PubKeyOwn = ret.PubKeys[ret.Index]
 	ret.PubKeyMaster = ret.PubPoly.Commit()
@@ -0,0 +1,368 @@
+package tcrypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+)
+
+// MultiSigKeys is the key of the member of the multi-signature committee. Each member generates its own
+// BLS key and deals Shamir shares of it to all members, together with commitments of the polynomial (see MultiSigDeal).
+// So it is a joint-Feldman DKG whose messages are relayed by the admin instead of being exchanged by nodes,
+// without complaints and justifications: a member which receives a share not matching commitments of the dealer
+// refuses to form the committee, naming the dealer.
+// Members don't sign with their own keys. The value tangle only knows addresses of single BLS public keys,
+// so M-of-N signatures of independent keys can't unlock an address. The address is made from the aggregated key:
+// the sum of keys of members weighted with coefficients of the committee, which protects against rogue keys.
+// Each member holds the same weighted sum of shares it was dealt, which is the share of the aggregated key,
+// so any T members can sign
+type MultiSigKeys struct {
+	Suite *bn256.Suite
+	// size of the committee
+	N uint16
+	// quorum: number of members needed to sign
+	T uint16
+	// index of the owner of the key in the committee
+	Index uint16
+	// address made from the aggregated public key
+	Address *address.Address
+	// public keys of all members, in the order of the committee
+	PubKeys []kyber.Point
+	// public polynomial of shares of the aggregated key
+	PubPoly *share.PubPoly
+	// aggregated public key, the free coefficient of PubPoly
+	PubKeyAggregated kyber.Point
+	// own share of the aggregated private key
+	priShare kyber.Scalar
+}
+
+const multiSigSealKeyContext = "wasp-multisig-seal-v1"
+
+// MultiSigDeal is the Shamir sharing of the key of one member of the multi-signature committee
+type MultiSigDeal struct {
+	// commitments of the coefficients of the polynomial. The first one is the public key of the member
+	Commits []kyber.Point
+	// shares of the private key, in the order of members of the committee
+	Shares []kyber.Scalar
+}
+
+// NewMultiSigMemberKey generates private key of the member of the multi-signature committee.
+// Returns the key and its public key
+func NewMultiSigMemberKey() (kyber.Scalar, kyber.Point) {
+	return bdn.NewKeyPair(suiteLoc, suiteLoc.RandomStream())
+}
+
+// MultiSigSealKey derives the Ed25519 key from the private key of the member. Shares dealt to the member
+// are sealed for its public key, so the member opens them itself, not the holder of the node identity
+func MultiSigSealKey(priKey kyber.Scalar) (ed25519.PrivateKey, error) {
+	priKeyBin, err := priKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	seed := hashing.HashData([]byte(multiSigSealKeyContext), priKeyBin)
+	return ed25519.NewKeyFromSeed(seed[:]), nil
+}
+
+// NewMultiSigDeal shares the private key of the member among all members of the committee with quorum t
+func NewMultiSigDeal(pubKeys []kyber.Point, t uint16, priKey kyber.Scalar) (*MultiSigDeal, error) {
+	suite := bn256.NewSuite()
+	if _, err := memberIndex(suite, pubKeys, t, priKey); err != nil {
+		return nil, err
+	}
+	priPoly := share.NewPriPoly(suite.G2(), int(t), priKey, suite.RandomStream())
+	_, commits := priPoly.Commit(nil).Info()
+	ret := &MultiSigDeal{
+		Commits: commits,
+		Shares:  make([]kyber.Scalar, len(pubKeys)),
+	}
+	for _, s := range priPoly.Shares(len(pubKeys)) {
+		ret.Shares[s.I] = s.V
+	}
+	return ret, nil
+}
+
+// memberIndex checks parameters of the committee and returns the index of the owner of the private key
+func memberIndex(suite *bn256.Suite, pubKeys []kyber.Point, t uint16, priKey kyber.Scalar) (uint16, error) {
+	if len(pubKeys) < 2 || len(pubKeys) > 0xFFFF {
+		return 0, fmt.Errorf("wrong number of members %d", len(pubKeys))
+	}
+	if err := ValidateDKSParams(t, uint16(len(pubKeys)), 0); err != nil {
+		return 0, err
+	}
+	pubKeyOwn := suite.G2().Point().Mul(priKey, nil)
+	index := -1
+	for i, pk := range pubKeys {
+		for _, pk1 := range pubKeys[:i] {
+			if pk.Equal(pk1) {
+				return 0, errors.New("duplicate public keys of members")
+			}
+		}
+		if pk.Equal(pubKeyOwn) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, errors.New("own public key is not among public keys of members")
+	}
+	return uint16(index), nil
+}
+
+// NewMultiSigKeys creates the key set of the member from public keys of all members, the own private key,
+// commitments of deals of all members and shares of those deals dealt to the member, in the order of members.
+// Shares are checked against commitments and the first commitment of each deal against the key of the dealer
+func NewMultiSigKeys(pubKeys []kyber.Point, t uint16, priKey kyber.Scalar, commits [][]kyber.Point, shares []kyber.Scalar) (*MultiSigKeys, error) {
+	suite := bn256.NewSuite()
+	index, err := memberIndex(suite, pubKeys, t, priKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) != len(pubKeys) || len(shares) != len(pubKeys) {
+		return nil, errors.New("deals of all members are needed")
+	}
+	coefs, err := memberCoefficients(suite, pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	aggrCommits := make([]kyber.Point, t)
+	for k := range aggrCommits {
+		aggrCommits[k] = suite.G2().Point().Null()
+	}
+	priShare := suite.G2().Scalar().Zero()
+	for i := range pubKeys {
+		if len(commits[i]) != int(t) || !commits[i][0].Equal(pubKeys[i]) {
+			return nil, fmt.Errorf("wrong commitments of the deal of member #%d", i)
+		}
+		expected := share.NewPubPoly(suite.G2(), nil, commits[i]).Eval(int(index)).V
+		if !suite.G2().Point().Mul(shares[i], nil).Equal(expected) {
+			return nil, fmt.Errorf("share dealt by member #%d doesn't match commitments", i)
+		}
+		for k, c := range commits[i] {
+			aggrCommits[k].Add(aggrCommits[k], suite.G2().Point().Mul(coefs[i], c))
+		}
+		priShare.Add(priShare, suite.G2().Scalar().Mul(coefs[i], shares[i]))
+	}
+	return newMultiSigKeys(suite, pubKeys, t, index, aggrCommits, priShare)
+}
+
+// memberCoefficients returns weights of keys of members in the aggregated key. They depend on keys of all members
+func memberCoefficients(suite *bn256.Suite, pubKeys []kyber.Point) ([]kyber.Scalar, error) {
+	var buf bytes.Buffer
+	for _, pk := range pubKeys {
+		data, err := pk.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	ret := make([]kyber.Scalar, len(pubKeys))
+	for i := range ret {
+		h := hashing.HashData(buf.Bytes(), util.Uint16To2Bytes(uint16(i)))
+		// 128 bits are enough, as in BDN
+		ret[i] = suite.G2().Scalar().SetBytes(h[:16])
+	}
+	return ret, nil
+}
+
+func newMultiSigKeys(suite *bn256.Suite, pubKeys []kyber.Point, t, index uint16, commits []kyber.Point, priShare kyber.Scalar) (*MultiSigKeys, error) {
+	ret := &MultiSigKeys{
+		Suite:    suite,
+		N:        uint16(len(pubKeys)),
+		T:        t,
+		Index:    index,
+		PubKeys:  pubKeys,
+		PubPoly:  share.NewPubPoly(suite.G2(), nil, commits),
+		priShare: priShare,
+	}
+	if priShare != nil && !suite.G2().Point().Mul(priShare, nil).Equal(ret.PubPoly.Eval(int(index)).V) {
+		return nil, errors.New("own share doesn't match the public polynomial")
+	}
+	ret.PubKeyAggregated = ret.PubPoly.Commit()
+	pubKeyBin, err := ret.PubKeyAggregated.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	addr := address.FromBLSPubKey(pubKeyBin)
+	ret.Address = &addr
+	return ret, nil
+}
+
+// HasPrivateKey is false if the private share is kept by the remote signer and the node has only the public part
+func (ks *MultiSigKeys) HasPrivateKey() bool {
+	return ks.priShare != nil
+}
+
+// PublicPart returns copy of the keys without the private share
+func (ks *MultiSigKeys) PublicPart() *MultiSigKeys {
+	ret := *ks
+	ret.priShare = nil
+	return &ret
+}
+
+func (ks *MultiSigKeys) Scheme() KeyScheme {
+	return KeySchemeMultiSig
+}
+
+func (ks *MultiSigKeys) KeyAddress() *address.Address {
+	return ks.Address
+}
+
+func (ks *MultiSigKeys) Size() uint16 {
+	return ks.N
+}
+
+func (ks *MultiSigKeys) Quorum() uint16 {
+	return ks.T
+}
+
+func (ks *MultiSigKeys) OwnIndex() uint16 {
+	return ks.Index
+}
+
+// SignShare signs the data with the own share of the aggregated key
+func (ks *MultiSigKeys) SignShare(data []byte) (tbdn.SigShare, error) {
+	if ks.priShare == nil {
+		return nil, errors.New("private share is kept by the remote signer")
+	}
+	return tbdn.Sign(ks.Suite, &share.PriShare{
+		I: int(ks.Index),
+		V: ks.priShare,
+	}, data)
+}
+
+// VerifySigShareOfPeer checks signature share of the peer against its public share
+func (ks *MultiSigKeys) VerifySigShareOfPeer(peerIndex uint16, data []byte, sigshare tbdn.SigShare) error {
+	idx, err := sigshare.Index()
+	if err != nil {
+		return err
+	}
+	if idx != int(peerIndex) {
+		return fmt.Errorf("signature share of peer #%d has index %d", peerIndex, idx)
+	}
+	if idx >= int(ks.N) {
+		return fmt.Errorf("wrong index of the signature share: %d", idx)
+	}
+	return tbdn.Verify(ks.Suite, ks.PubPoly, data, sigshare)
+}
+
+// RecoverFullSignature recovers the signature of the address from signature shares of any T members
+func (ks *MultiSigKeys) RecoverFullSignature(sigShares [][]byte, data []byte) (signaturescheme.Signature, error) {
+	for _, s := range sigShares {
+		idx, err := tbdn.SigShare(s).Index()
+		if err != nil {
+			return nil, err
+		}
+		if idx >= int(ks.N) {
+			return nil, fmt.Errorf("wrong index of the signature share: %d", idx)
+		}
+	}
+	sigBin, err := tbdn.Recover(ks.Suite, ks.PubPoly, data, sigShares, int(ks.T), int(ks.N))
+	if err != nil {
+		return nil, err
+	}
+	if err := bdn.Verify(ks.Suite, ks.PubKeyAggregated, data, sigBin); err != nil {
+		return nil, err
+	}
+	pubKeyBin, err := ks.PubKeyAggregated.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return signaturescheme.NewBLSSignature(pubKeyBin, sigBin), nil
+}
+
+func (ks *MultiSigKeys) Write(w io.Writer) error {
+	if err := util.WriteUint16(w, ks.Index); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, ks.N); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, ks.T); err != nil {
+		return err
+	}
+	_, commits := ks.PubPoly.Info()
+	for _, pk := range append(append([]kyber.Point{}, ks.PubKeys...), commits...) {
+		data, err := pk.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err := util.WriteBytes16(w, data); err != nil {
+			return err
+		}
+	}
+	// the private share is empty when it is kept by the remote signer
+	var data []byte
+	if ks.priShare != nil {
+		var err error
+		if data, err = ks.priShare.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+	return util.WriteBytes16(w, data)
+}
+
+func (ks *MultiSigKeys) Read(r io.Reader) error {
+	suite := bn256.NewSuite()
+	var index, n, t uint16
+	if err := util.ReadUint16(r, &index); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &t); err != nil {
+		return err
+	}
+	if err := ValidateDKSParams(t, n, index); err != nil {
+		return err
+	}
+	// public keys of members followed by commitments of the public polynomial
+	points := make([]kyber.Point, n+t)
+	for i := range points {
+		data, err := util.ReadBytes16(r)
+		if err != nil {
+			return err
+		}
+		points[i] = suite.G2().Point()
+		if err := points[i].UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+	data, err := util.ReadBytes16(r)
+	if err != nil {
+		return err
+	}
+	var priShare kyber.Scalar
+	if len(data) > 0 {
+		priShare = suite.G2().Scalar()
+		if err := priShare.UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+	ret, err := newMultiSigKeys(suite, points[:n], t, index, points[n:], priShare)
+	if err != nil {
+		return err
+	}
+	*ks = *ret
+	return nil
+}
+
+// UnmarshalMultiSigKeys parses the key set and calculates the address
+func UnmarshalMultiSigKeys(data []byte) (*MultiSigKeys, error) {
+	ret := &MultiSigKeys{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
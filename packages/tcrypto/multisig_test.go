@@ -0,0 +1,207 @@
+package tcrypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.dedis.ch/kyber/v3"
+)
+
+func newMultiSigCommittee(t *testing.T, n, quorum int) []*MultiSigKeys {
+	priKeys := make([]kyber.Scalar, n)
+	pubKeys := make([]kyber.Point, n)
+	for i := range priKeys {
+		priKeys[i], pubKeys[i] = NewMultiSigMemberKey()
+	}
+	commits := make([][]kyber.Point, n)
+	deals := make([]*MultiSigDeal, n)
+	for i := range deals {
+		var err error
+		deals[i], err = NewMultiSigDeal(pubKeys, uint16(quorum), priKeys[i])
+		assert.NoError(t, err)
+		commits[i] = deals[i].Commits
+	}
+	ret := make([]*MultiSigKeys, n)
+	for i := range ret {
+		shares := make([]kyber.Scalar, n)
+		for j := range shares {
+			shares[j] = deals[j].Shares[i]
+		}
+		var err error
+		ret[i], err = NewMultiSigKeys(pubKeys, uint16(quorum), priKeys[i], commits, shares)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(i), ret[i].Index)
+		assert.Equal(t, *ret[0].Address, *ret[i].Address)
+	}
+	return ret
+}
+
+func TestMultiSigKeys(t *testing.T) {
+	const n = 4
+	const quorum = 3
+	members := newMultiSigCommittee(t, n, quorum)
+
+	data := []byte("data to sign")
+	sigShares := make([][]byte, 0, n)
+	// order of signature shares doesn't matter
+	for i := n - 1; i >= 0; i-- {
+		sigShare, err := members[i].SignShare(data)
+		assert.NoError(t, err)
+		assert.NoError(t, members[0].VerifySigShareOfPeer(uint16(i), data, sigShare))
+		assert.Error(t, members[0].VerifySigShareOfPeer(uint16((i+1)%n), data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	sig, err := members[0].RecoverFullSignature(sigShares, data)
+	assert.NoError(t, err)
+	assert.Equal(t, *members[0].Address, sig.Address())
+	assert.True(t, sig.IsValid(data))
+
+	// any quorum of members is enough
+	sig, err = members[1].RecoverFullSignature(sigShares[1:], data)
+	assert.NoError(t, err)
+	assert.True(t, sig.IsValid(data))
+
+	_, err = members[0].RecoverFullSignature(sigShares[2:], data)
+	assert.Error(t, err)
+}
+
+func TestMultiSigDealCheck(t *testing.T) {
+	const n = 3
+	priKeys := make([]kyber.Scalar, n)
+	pubKeys := make([]kyber.Point, n)
+	for i := range priKeys {
+		priKeys[i], pubKeys[i] = NewMultiSigMemberKey()
+	}
+	commits := make([][]kyber.Point, n)
+	shares := make([]kyber.Scalar, n)
+	for i := range priKeys {
+		deal, err := NewMultiSigDeal(pubKeys, 2, priKeys[i])
+		assert.NoError(t, err)
+		commits[i] = deal.Commits
+		shares[i] = deal.Shares[0]
+	}
+	_, err := NewMultiSigKeys(pubKeys, 2, priKeys[0], commits, shares)
+	assert.NoError(t, err)
+
+	// the share dealt to another member
+	deal, err := NewMultiSigDeal(pubKeys, 2, priKeys[1])
+	assert.NoError(t, err)
+	commits[1], shares[1] = deal.Commits, deal.Shares[2]
+	_, err = NewMultiSigKeys(pubKeys, 2, priKeys[0], commits, shares)
+	assert.Error(t, err)
+
+	// the deal of a key other than the key of the member
+	deal, err = NewMultiSigDeal(pubKeys, 2, priKeys[2])
+	assert.NoError(t, err)
+	commits[1], shares[1] = deal.Commits, deal.Shares[0]
+	_, err = NewMultiSigKeys(pubKeys, 2, priKeys[0], commits, shares)
+	assert.Error(t, err)
+}
+
+func TestMultiSigKeysMarshal(t *testing.T) {
+	members := newMultiSigCommittee(t, 4, 3)
+
+	var buf bytes.Buffer
+	assert.NoError(t, members[2].Write(&buf))
+	back, err := UnmarshalMultiSigKeys(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, *members[2].Address, *back.Address)
+	assert.Equal(t, members[2].Index, back.Index)
+	assert.Equal(t, members[2].T, back.T)
+
+	// own key must be among keys of members
+	priKey, _ := NewMultiSigMemberKey()
+	_, err = NewMultiSigDeal(members[0].PubKeys, 3, priKey)
+	assert.Error(t, err)
+}
+
+func TestMultiSigSealKey(t *testing.T) {
+	priKey, _ := NewMultiSigMemberKey()
+	sealKey, err := MultiSigSealKey(priKey)
+	assert.NoError(t, err)
+	again, err := MultiSigSealKey(priKey)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(sealKey, again))
+
+	otherKey, _ := NewMultiSigMemberKey()
+	otherSealKey, err := MultiSigSealKey(otherKey)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Equal(sealKey, otherSealKey))
+
+	data := []byte("share")
+	sealed, err := SealForIdentity(sealKey.Public().(ed25519.PublicKey), data)
+	assert.NoError(t, err)
+	opened, err := OpenSealed(sealKey, sealed)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(data, opened))
+	_, err = OpenSealed(otherSealKey, sealed)
+	assert.Error(t, err)
+}
+
+func TestMultiSigBadDealer(t *testing.T) {
+	const n = 4
+	const quorum = 3
+	const bad = 1
+	priKeys := make([]kyber.Scalar, n)
+	pubKeys := make([]kyber.Point, n)
+	for i := range priKeys {
+		priKeys[i], pubKeys[i] = NewMultiSigMemberKey()
+	}
+	deals := make([]*MultiSigDeal, n)
+	commits := make([][]kyber.Point, n)
+	for i := range deals {
+		var err error
+		deals[i], err = NewMultiSigDeal(pubKeys, quorum, priKeys[i])
+		assert.NoError(t, err)
+		commits[i] = deals[i].Commits
+	}
+	sharesOf := func(member int) []kyber.Scalar {
+		ret := make([]kyber.Scalar, n)
+		for j := range ret {
+			ret[j] = deals[j].Shares[member]
+		}
+		return ret
+	}
+	// the bad dealer deals a wrong share to member #2
+	shares := sharesOf(2)
+	shares[bad] = suiteLoc.G2().Scalar().Add(shares[bad], suiteLoc.G2().Scalar().One())
+	_, err := NewMultiSigKeys(pubKeys, quorum, priKeys[2], commits, shares)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "member #1")
+
+	// other members form the committee and any quorum of them signs
+	members := make([]*MultiSigKeys, 0, n)
+	for _, i := range []int{0, 1, 3} {
+		ks, err := NewMultiSigKeys(pubKeys, quorum, priKeys[i], commits, sharesOf(i))
+		assert.NoError(t, err)
+		members = append(members, ks)
+	}
+	data := []byte("data to sign")
+	sigShares := make([][]byte, len(members))
+	for i, ks := range members {
+		sigShares[i], err = ks.SignShare(data)
+		assert.NoError(t, err)
+	}
+	sig, err := members[0].RecoverFullSignature(sigShares, data)
+	assert.NoError(t, err)
+	assert.Equal(t, *members[0].Address, sig.Address())
+	assert.True(t, sig.IsValid(data))
+
+	// the bad dealer deals a share of another polynomial of its key. Commitments are relayed by the admin
+	// to all members, so the share doesn't match them
+	other, err := NewMultiSigDeal(pubKeys, quorum, priKeys[bad])
+	assert.NoError(t, err)
+	shares = sharesOf(3)
+	shares[bad] = other.Shares[3]
+	_, err = NewMultiSigKeys(pubKeys, quorum, priKeys[3], commits, shares)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "member #1")
+
+	// commitments of the polynomial of the wrong degree
+	otherCommits := append([][]kyber.Point{}, commits...)
+	otherCommits[bad] = commits[bad][:quorum-1]
+	_, err = NewMultiSigKeys(pubKeys, quorum, priKeys[0], otherCommits, sharesOf(0))
+	assert.Error(t, err)
+}
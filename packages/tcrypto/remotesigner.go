@@ -0,0 +1,209 @@
+package tcrypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/mr-tron/base58"
+)
+
+// The remote signer is a separate process which holds the keys. The node talks to it with JSON over HTTP
+// on a Unix socket. All binary values are base58 encoded. Endpoints:
+//   POST /sign/share     {"address": ..., "data": ...} -> {"result": <sig share>}
+//   POST /sign/identity  {"data": ...}                 -> {"result": <signature>}
+//   POST /import         {"data": <sealed keys>, "replace": ...} -> {"result": <public part of keys>}
+//   GET  /identity                                     -> {"result": <public key>}
+// In case of failure the response has status other than 200 and the "err" field is set.
+// Data sealed for the identity of the signer is never returned opened: imported keys are kept by the signer
+// and only their public part is returned
+
+const remoteSignerTimeout = 10 * time.Second
+
+type SignerRequest struct {
+	Address string `json:"address,omitempty"`
+	Data    string `json:"data"`
+	Replace bool   `json:"replace,omitempty"`
+}
+
+type SignerResponse struct {
+	Result string `json:"result"`
+	Err    string `json:"err"`
+}
+
+type remoteSigner struct {
+	client *http.Client
+	// public key of the identity doesn't change, it is requested once
+	pubKeyMutex sync.Mutex
+	pubKey      ed25519.PublicKey
+}
+
+// NewRemoteSigner creates signer which calls the signer process listening on the Unix socket
+func NewRemoteSigner(socketPath string) Signer {
+	return &remoteSigner{
+		client: &http.Client{
+			Timeout: remoteSignerTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (s *remoteSigner) call(method, path string, req *SignerRequest) ([]byte, error) {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return nil, err
+		}
+	}
+	// host part of the url is ignored, the connection is always to the socket
+	httpReq, err := http.NewRequest(method, "http://signer"+path, &body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var sresp SignerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sresp); err != nil {
+		return nil, fmt.Errorf("remote signer: response status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || sresp.Err != "" {
+		return nil, fmt.Errorf("remote signer: response status %d: %s", resp.StatusCode, sresp.Err)
+	}
+	return decodeSignerData(sresp.Result)
+}
+
+func (s *remoteSigner) SignShare(addr *address.Address, data []byte) (tbdn.SigShare, error) {
+	return s.call(http.MethodPost, "/sign/share", &SignerRequest{
+		Address: addr.String(),
+		Data:    base58.Encode(data),
+	})
+}
+
+func (s *remoteSigner) SignIdentity(data []byte) ([]byte, error) {
+	return s.call(http.MethodPost, "/sign/identity", &SignerRequest{
+		Data: base58.Encode(data),
+	})
+}
+
+func (s *remoteSigner) IdentityPubKey() (ed25519.PublicKey, error) {
+	s.pubKeyMutex.Lock()
+	defer s.pubKeyMutex.Unlock()
+
+	if s.pubKey != nil {
+		return s.pubKey, nil
+	}
+	ret, err := s.call(http.MethodGet, "/identity", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("remote signer: wrong public key length %d", len(ret))
+	}
+	s.pubKey = ret
+	return s.pubKey, nil
+}
+
+func (s *remoteSigner) ImportKeys(sealed []byte, replace bool) (CommitteeKeys, error) {
+	data, err := s.call(http.MethodPost, "/import", &SignerRequest{
+		Data:    base58.Encode(sealed),
+		Replace: replace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret, err := UnmarshalCommitteeKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer: %v", err)
+	}
+	if ret.HasPrivateKey() {
+		return nil, fmt.Errorf("remote signer: private key of %s returned", ret.KeyAddress())
+	}
+	return ret, nil
+}
+
+// NewSignerHandler serves the remote signer protocol with the signer.
+// It is used by the signer process
+func NewSignerHandler(signer Signer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sign/share", signerHandlerFunc(http.MethodPost, func(req *SignerRequest, data []byte) ([]byte, error) {
+		addr, err := address.FromBase58(req.Address)
+		if err != nil {
+			return nil, err
+		}
+		return signer.SignShare(&addr, data)
+	}))
+	mux.HandleFunc("/sign/identity", signerHandlerFunc(http.MethodPost, func(_ *SignerRequest, data []byte) ([]byte, error) {
+		return signer.SignIdentity(data)
+	}))
+	mux.HandleFunc("/import", signerHandlerFunc(http.MethodPost, func(req *SignerRequest, data []byte) ([]byte, error) {
+		ks, err := signer.ImportKeys(data, req.Replace)
+		if err != nil {
+			return nil, err
+		}
+		return MarshalCommitteeKeys(PublicPartOf(ks))
+	}))
+	mux.HandleFunc("/identity", signerHandlerFunc(http.MethodGet, func(_ *SignerRequest, _ []byte) ([]byte, error) {
+		return signer.IdentityPubKey()
+	}))
+	return mux
+}
+
+func signerHandlerFunc(method string, f func(req *SignerRequest, data []byte) ([]byte, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeSignerResponse(w, http.StatusMethodNotAllowed, &SignerResponse{Err: "method not allowed"})
+			return
+		}
+		var req SignerRequest
+		var data []byte
+		if method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeSignerResponse(w, http.StatusBadRequest, &SignerResponse{Err: err.Error()})
+				return
+			}
+			var err error
+			if data, err = decodeSignerData(req.Data); err != nil {
+				writeSignerResponse(w, http.StatusBadRequest, &SignerResponse{Err: err.Error()})
+				return
+			}
+		}
+		ret, err := f(&req, data)
+		if err != nil {
+			writeSignerResponse(w, http.StatusBadRequest, &SignerResponse{Err: err.Error()})
+			return
+		}
+		writeSignerResponse(w, http.StatusOK, &SignerResponse{Result: base58.Encode(ret)})
+	}
+}
+
+func writeSignerResponse(w http.ResponseWriter, status int, resp *SignerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// decodeSignerData decodes base58 value. Unlike base58.Decode, accepts empty string
+func decodeSignerData(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return base58.Decode(s)
+}
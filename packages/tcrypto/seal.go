@@ -0,0 +1,125 @@
+package tcrypto
+
+import (
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const sealContext = "wasp-sealed-v1"
+
+// prime of the curve25519 field: 2^255 - 19
+var fieldPrime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// SealForIdentity encrypts data so that only the owner of the Ed25519 identity key can decrypt it.
+// The sealed data is: ephemeral X25519 public key || ciphertext
+func SealForIdentity(pubKey ed25519.PublicKey, data []byte) ([]byte, error) {
+	recipient, err := x25519FromEd25519Public(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	var ephPriv [32]byte
+	if _, err := io.ReadFull(rand.Reader, ephPriv[:]); err != nil {
+		return nil, err
+	}
+	ephPub, err := curve25519.X25519(ephPriv[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := curve25519.X25519(ephPriv[:], recipient)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := sealCipher(shared, ephPub, recipient)
+	if err != nil {
+		return nil, err
+	}
+	// the key is unique for each sealed message, so the nonce may be constant
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	return aead.Seal(ephPub, nonce, data, nil), nil
+}
+
+// OpenSealed decrypts data sealed for the identity with SealForIdentity
+func OpenSealed(priKey ed25519.PrivateKey, data []byte) ([]byte, error) {
+	if len(data) < curve25519.PointSize {
+		return nil, errors.New("sealed data too short")
+	}
+	ephPub := data[:curve25519.PointSize]
+	own := x25519FromEd25519Private(priKey)
+	ownPub, err := curve25519.X25519(own, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := curve25519.X25519(own, ephPub)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := sealCipher(shared, ephPub, ownPub)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	ret, err := aead.Open(nil, nonce, data[curve25519.PointSize:], nil)
+	if err != nil {
+		return nil, errors.New("can't open sealed data: wrong recipient or corrupted data")
+	}
+	return ret, nil
+}
+
+func sealCipher(shared, ephPub, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephPub...), recipient...)
+	kdf := hkdf.New(sha256.New, shared, salt, []byte(sealContext))
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// x25519FromEd25519Private converts Ed25519 private key to the X25519 scalar, same way as Ed25519 derives it
+func x25519FromEd25519Private(priKey ed25519.PrivateKey) []byte {
+	h := sha512.Sum512(priKey.Seed())
+	return h[:32]
+}
+
+// x25519FromEd25519Public converts Ed25519 public key (Edwards y) to the X25519 one (Montgomery u):
+// u = (1 + y) / (1 - y)
+func x25519FromEd25519Public(pubKey ed25519.PublicKey) ([]byte, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("wrong public key length")
+	}
+	// little endian, highest bit is the sign of x
+	be := make([]byte, 32)
+	for i := range be {
+		be[i] = pubKey[31-i]
+	}
+	be[0] &= 0x7f
+	y := new(big.Int).SetBytes(be)
+	if y.Cmp(fieldPrime) >= 0 {
+		return nil, errors.New("wrong public key")
+	}
+	den := new(big.Int).Sub(big.NewInt(1), y)
+	den.Mod(den, fieldPrime)
+	if den.Sign() == 0 {
+		return nil, errors.New("wrong public key")
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, den.ModInverse(den, fieldPrime))
+	u.Mod(u, fieldPrime)
+
+	ret := make([]byte, 32)
+	ube := u.Bytes()
+	for i := range ube {
+		ret[i] = ube[len(ube)-1-i]
+	}
+	return ret, nil
+}
@@ -0,0 +1,38 @@
+package tcrypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestSealForIdentity(t *testing.T) {
+	pub, pri, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// converted keys must be a valid X25519 key pair
+	xpub, err := x25519FromEd25519Public(pub)
+	assert.NoError(t, err)
+	xpub2, err := curve25519.X25519(x25519FromEd25519Private(pri), curve25519.Basepoint)
+	assert.NoError(t, err)
+	assert.Equal(t, xpub, xpub2)
+
+	data := []byte("secret key share")
+	sealed, err := SealForIdentity(pub, data)
+	assert.NoError(t, err)
+	opened, err := OpenSealed(pri, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, data, opened)
+
+	_, pri2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = OpenSealed(pri2, sealed)
+	assert.Error(t, err)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = OpenSealed(pri, sealed)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package tcrypto
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+)
+
+// Signer performs all operations with secret keys of the node: partial signing with key shares
+// of committees and signing with the node identity key.
+// The default implementation keeps the keys in the Wasp process. The remote one
+// delegates the operations to a separate signer process (see remotesigner.go)
+type Signer interface {
+	// SignShare signs the data with the own key of the committee address, of any key scheme
+	SignShare(addr *address.Address, data []byte) (tbdn.SigShare, error)
+	// SignIdentity signs the data with the Ed25519 identity key of the node
+	SignIdentity(data []byte) ([]byte, error)
+	// IdentityPubKey returns public key of the node identity
+	IdentityPubKey() (ed25519.PublicKey, error)
+	// ImportKeys takes committee keys, serialized with MarshalCommitteeKeys and sealed for the node identity.
+	// The remote signer keeps them and signs with them afterwards. It refuses to replace other keys of the same
+	// address unless replace is true. Returns the keys to be saved in the registry: only the public part
+	// if the private key is kept by the signer
+	ImportKeys(sealed []byte, replace bool) (CommitteeKeys, error)
+}
+
+type localSigner struct {
+	identity ed25519.PrivateKey
+	getKeys  func(addr *address.Address) (CommitteeKeys, bool, error)
+}
+
+// NewLocalSigner creates signer which holds the identity key in memory. Committee keys of any scheme are
+// taken with getKeys when needed
+func NewLocalSigner(identity ed25519.PrivateKey, getKeys func(addr *address.Address) (CommitteeKeys, bool, error)) Signer {
+	return &localSigner{
+		identity: identity,
+		getKeys:  getKeys,
+	}
+}
+
+func (s *localSigner) SignShare(addr *address.Address, data []byte) (tbdn.SigShare, error) {
+	ks, ok, err := s.getKeys(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key share %s", addr.String())
+	}
+	return ks.SignShare(data)
+}
+
+func (s *localSigner) SignIdentity(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.identity, data), nil
+}
+
+func (s *localSigner) IdentityPubKey() (ed25519.PublicKey, error) {
+	return s.identity.Public().(ed25519.PublicKey), nil
+}
+
+// ImportKeys opens the keys and returns them whole: the local signer takes committee keys from the registry
+func (s *localSigner) ImportKeys(sealed []byte, _ bool) (CommitteeKeys, error) {
+	data, err := OpenSealed(s.identity, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalCommitteeKeys(data)
+}
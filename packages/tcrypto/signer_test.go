@@ -0,0 +1,171 @@
+package tcrypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+// importingSigner keeps imported keys in memory, like the signer process does in files
+type importingSigner struct {
+	Signer
+	identity ed25519.PrivateKey
+	keys     map[address.Address]CommitteeKeys
+}
+
+func (s *importingSigner) ImportKeys(sealed []byte, replace bool) (CommitteeKeys, error) {
+	data, err := OpenSealed(s.identity, sealed)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := UnmarshalCommitteeKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.keys[*ks.KeyAddress()]; exists && !replace {
+		return nil, errors.New("keys already exist")
+	}
+	s.keys[*ks.KeyAddress()] = ks
+	return PublicPartOf(ks), nil
+}
+
+func TestRemoteSigner(t *testing.T) {
+	const n, thr = 4, 3
+	shares := make([]*DKShare, n)
+	for i, dk := range runDKG(t, n, thr) {
+		var err error
+		shares[i], err = NewDKShareFromDistKey(thr, n, dk.Share, dk.Commits)
+		assert.NoError(t, err)
+	}
+	pubKey, identity, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	local := &importingSigner{
+		identity: identity,
+		keys:     make(map[address.Address]CommitteeKeys),
+	}
+	local.Signer = NewLocalSigner(identity, func(addr *address.Address) (CommitteeKeys, bool, error) {
+		ks, ok := local.keys[*addr]
+		return ks, ok, nil
+	})
+
+	dir, err := ioutil.TempDir("", "signer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+	srv := &http.Server{Handler: NewSignerHandler(local)}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	remote := NewRemoteSigner(socketPath)
+
+	remotePubKey, err := remote.IdentityPubKey()
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(pubKey, remotePubKey))
+
+	data := []byte("data to sign")
+	sig, err := remote.SignIdentity(data)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(pubKey, data, sig))
+
+	_, err = remote.SignShare(shares[1].Address, data)
+	assert.Error(t, err)
+
+	keysData, err := MarshalCommitteeKeys(shares[1])
+	assert.NoError(t, err)
+	sealedKeys, err := SealForIdentity(pubKey, keysData)
+	assert.NoError(t, err)
+	imported, err := remote.ImportKeys(sealedKeys, false)
+	assert.NoError(t, err)
+	// only the public part is returned
+	assert.False(t, imported.HasPrivateKey())
+	assert.Equal(t, *shares[1].Address, *imported.KeyAddress())
+	assert.Equal(t, shares[1].Index, imported.OwnIndex())
+	_, err = remote.ImportKeys(keysData, false)
+	assert.Error(t, err)
+	_, err = remote.ImportKeys(sealedKeys, false)
+	assert.Error(t, err)
+	_, err = remote.ImportKeys(sealedKeys, true)
+	assert.NoError(t, err)
+
+	sigShare, err := remote.SignShare(shares[1].Address, data)
+	assert.NoError(t, err)
+	assert.NoError(t, shares[0].VerifySigShareOfPeer(1, data, sigShare))
+
+	var otherAddr address.Address
+	_, err = remote.SignShare(&otherAddr, data)
+	assert.Error(t, err)
+
+	// the signer doesn't open sealed data other than keys
+	sealed, err := SealForIdentity(pubKey, data)
+	assert.NoError(t, err)
+	_, err = remote.ImportKeys(sealed, false)
+	assert.Error(t, err)
+	resp, err := remote.(*remoteSigner).client.Post("http://signer/open", "application/json",
+		bytes.NewBufferString(`{"data": "`+base58.Encode(sealed)+`"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestLocalSignerImportKeys(t *testing.T) {
+	dks := runDKG(t, 4, 3)
+	dkshare, err := NewDKShareFromDistKey(3, 4, dks[0].Share, dks[0].Commits)
+	assert.NoError(t, err)
+	pubKey, identity, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signer := NewLocalSigner(identity, nil)
+
+	keysData, err := MarshalCommitteeKeys(dkshare)
+	assert.NoError(t, err)
+	sealed, err := SealForIdentity(pubKey, keysData)
+	assert.NoError(t, err)
+	// keys are returned whole to be saved in the registry
+	ks, err := signer.ImportKeys(sealed, false)
+	assert.NoError(t, err)
+	assert.True(t, ks.HasPrivateKey())
+	assert.Equal(t, *dkshare.Address, *ks.KeyAddress())
+
+	otherPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sealed, err = SealForIdentity(otherPubKey, keysData)
+	assert.NoError(t, err)
+	_, err = signer.ImportKeys(sealed, false)
+	assert.Error(t, err)
+}
+
+func TestCommitteeKeysPublicPart(t *testing.T) {
+	const n, thr = 4, 3
+	dks := runDKG(t, n, thr)
+	dkshare, err := NewDKShareFromDistKey(thr, n, dks[2].Share, dks[2].Commits)
+	assert.NoError(t, err)
+	multisig := newMultiSigCommittee(t, n, thr)[2]
+
+	data := []byte("data to sign")
+	for _, ks := range []CommitteeKeys{dkshare.PublicPart(), multisig.PublicPart()} {
+		assert.False(t, ks.HasPrivateKey())
+		keysData, err := MarshalCommitteeKeys(ks)
+		assert.NoError(t, err)
+		back, err := UnmarshalCommitteeKeys(keysData)
+		assert.NoError(t, err)
+		assert.Equal(t, ks.Scheme(), back.Scheme())
+		assert.Equal(t, *ks.KeyAddress(), *back.KeyAddress())
+		assert.False(t, back.HasPrivateKey())
+		_, err = back.SignShare(data)
+		assert.Error(t, err)
+	}
+	assert.True(t, dkshare.HasPrivateKey())
+	assert.True(t, multisig.HasPrivateKey())
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+)
+
+// API tokens are JWTs signed with HMAC-SHA256 by the secret of the node. The token lists roles granted
+// to its holder. Tokens are issued offline by the tool 'apitoken' with the secret file of the node
+
+// Role is the permission to call a group of endpoints
+type Role string
+
+const (
+	// read the state of smart contracts, request status and node info
+	RoleRead = Role("read")
+	// submit requests to smart contracts
+	RoleRequest = Role("request")
+	// manage smart contracts: bootup data, activation, programs
+	RoleSCAdmin = Role("scadmin")
+	// run DKG and manage key shares
+	RoleDKGAdmin = Role("dkgadmin")
+	// shut down the node
+	RoleShutdown = Role("shutdown")
+	// all permissions, including node settings such as peers and the master key
+	RoleAdmin = Role("admin")
+)
+
+var AllRoles = []Role{RoleRead, RoleRequest, RoleSCAdmin, RoleDKGAdmin, RoleShutdown, RoleAdmin}
+
+// ParseRoles parses comma separated list of roles
+func ParseRoles(s string) ([]Role, error) {
+	ret := make([]Role, 0)
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if !isKnownRole(Role(r)) {
+			return nil, fmt.Errorf("unknown role '%s'", r)
+		}
+		ret = append(ret, Role(r))
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("no roles")
+	}
+	return ret, nil
+}
+
+func isKnownRole(role Role) bool {
+	for _, r := range AllRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type TokenClaims struct {
+	jwt.StandardClaims
+	Roles []Role `json:"roles"`
+}
+
+// HasRole checks if the token grants the role. The admin role grants all roles
+func (c *TokenClaims) HasRole(role Role) bool {
+	for _, r := range c.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueToken creates the token for the subject with roles, valid for the period of time.
+// Tokens can't be revoked one by one, so each token expires
+func IssueToken(secret []byte, subject string, roles []Role, validity time.Duration) (string, error) {
+	if validity <= 0 {
+		return "", errors.New("validity of the token must be positive")
+	}
+	now := time.Now()
+	claims := &TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   subject,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(validity).Unix(),
+		},
+		Roles: roles,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// ParseToken checks the signature and expiration of the token and returns its claims.
+// Tokens without expiration, issued by previous versions, are rejected
+func ParseToken(secret []byte, tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token without expiration")
+	}
+	return claims, nil
+}
+
+const tokenSecretSize = 32
+
+// LoadOrCreateTokenSecret reads the secret from the file or generates the new one if the file doesn't exist
+func LoadOrCreateTokenSecret(fname string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err == nil {
+		if len(data) != tokenSecretSize {
+			return nil, fmt.Errorf("wrong size of the token secret in %s", fname)
+		}
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	data = make([]byte, tokenSecretSize)
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(fname, data, 0600); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// RequireRole returns the middleware which allows the request only if it carries the valid token
+// granting the role in the header 'Authorization: Bearer <token>'
+func RequireRole(secret []byte, role Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(header, "Bearer ") {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing API token")
+			}
+			claims, err := ParseToken(secret, strings.TrimPrefix(header, "Bearer "))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("invalid API token: %v", err))
+			}
+			if !claims.HasRole(role) {
+				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("API token doesn't grant role '%s'", role))
+			}
+			return next(c)
+		}
+	}
+}
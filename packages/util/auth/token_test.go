@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	token, err := IssueToken(secret, "alice", []Role{RoleRead, RoleRequest}, time.Hour)
+	assert.NoError(t, err)
+	claims, err := ParseToken(secret, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.True(t, claims.HasRole(RoleRead))
+	assert.False(t, claims.HasRole(RoleSCAdmin))
+
+	_, err = ParseToken([]byte("other secret"), token)
+	assert.Error(t, err)
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+		Roles:          []Role{RoleRead},
+	}).SignedString(secret)
+	assert.NoError(t, err)
+	_, err = ParseToken(secret, expired)
+	assert.Error(t, err)
+
+	_, err = IssueToken(secret, "root", []Role{RoleAdmin}, 0)
+	assert.Error(t, err)
+	neverExpires, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "root"},
+		Roles:          []Role{RoleAdmin},
+	}).SignedString(secret)
+	assert.NoError(t, err)
+	_, err = ParseToken(secret, neverExpires)
+	assert.Error(t, err)
+
+	admin, err := IssueToken(secret, "root", []Role{RoleAdmin}, time.Hour)
+	assert.NoError(t, err)
+	claims, err = ParseToken(secret, admin)
+	assert.NoError(t, err)
+	assert.True(t, claims.HasRole(RoleShutdown))
+}
+
+func TestRequireRole(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RequireRole(secret, RoleSCAdmin))
+
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	reader, err := IssueToken(secret, "reader", []Role{RoleRead}, time.Hour)
+	assert.NoError(t, err)
+	scAdmin, err := IssueToken(secret, "scadmin", []Role{RoleSCAdmin}, time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, call(""))
+	assert.Equal(t, http.StatusUnauthorized, call("garbage"))
+	assert.Equal(t, http.StatusForbidden, call(reader))
+	assert.Equal(t, http.StatusOK, call(scAdmin))
+}
+
+func TestParseRoles(t *testing.T) {
+	roles, err := ParseRoles("read, request")
+	assert.NoError(t, err)
+	assert.Equal(t, []Role{RoleRead, RoleRequest}, roles)
+	_, err = ParseRoles("read,superuser")
+	assert.Error(t, err)
+	_, err = ParseRoles("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+// Package tlsutil makes TLS configurations of servers and clients of the node from PEM files
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// files of the certificate are checked for changes not more often than that
+const certCheckPeriod = 1 * time.Second
+
+// CertReloader serves the certificate loaded from files and reloads it when files change,
+// so renewed certificates are used without restarting the server
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	// called after each attempt to reload the certificate. The previous certificate is kept if it fails
+	onReload func(err error)
+
+	mutex     sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader loads the certificate and the key from PEM files
+func NewCertReloader(certFile, keyFile string, onReload func(err error)) (*CertReloader, error) {
+	ret := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		onReload: onReload,
+	}
+	modTime, err := ret.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.load(modTime); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetCertificate is tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if time.Since(r.lastCheck) >= certCheckPeriod {
+		r.reloadIfChanged()
+	}
+	return r.cert, nil
+}
+
+func (r *CertReloader) reloadIfChanged() {
+	r.lastCheck = time.Now()
+	modTime, err := r.filesModTime()
+	if err == nil && modTime.Equal(r.modTime) {
+		return
+	}
+	if err == nil {
+		// failed files are not loaded again until they change
+		r.modTime = modTime
+		err = r.load(modTime)
+	}
+	if r.onReload != nil {
+		r.onReload(err)
+	}
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// filesModTime is the time of the latest modification of the certificate or the key
+func (r *CertReloader) filesModTime() (time.Time, error) {
+	var ret time.Time
+	for _, fname := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(fname)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(ret) {
+			ret = fi.ModTime()
+		}
+	}
+	return ret, nil
+}
+
+// LoadCertPool loads certificates of CAs from the PEM file
+func LoadCertPool(fname string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	ret := x509.NewCertPool()
+	if !ret.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", fname)
+	}
+	return ret, nil
+}
+
+// ServerConfig makes the TLS configuration of the server with the certificate reloaded on change.
+// If clientCAFile is not empty, client certificates signed by the CAs from the file are verified if presented.
+// Whether the certificate is required is up to handlers
+func ServerConfig(certFile, keyFile, clientCAFile string, onReload func(err error)) (*tls.Config, error) {
+	reloader, err := NewCertReloader(certFile, keyFile, onReload)
+	if err != nil {
+		return nil, err
+	}
+	ret := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if clientCAFile != "" {
+		if ret.ClientCAs, err = LoadCertPool(clientCAFile); err != nil {
+			return nil, err
+		}
+		ret.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return ret, nil
+}
+
+// ClientConfig makes the TLS configuration of the client. Server certificates are verified with CAs from
+// caFile or the system CAs if it is empty. The client certificate is presented if certFile is not empty
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	ret := &tls.Config{MinVersion: tls.VersionTLS12}
+	var err error
+	if caFile != "" {
+		if ret.RootCAs, err = LoadCertPool(caFile); err != nil {
+			return nil, err
+		}
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		ret.Certificates = []tls.Certificate{cert}
+	}
+	return ret, nil
+}
@@ -0,0 +1,79 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCert(t *testing.T, dir, name string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsutil")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	writeCert(t, dir, "first", now.Add(-time.Minute))
+	reloads := 0
+	r, err := NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), func(err error) {
+		assert.NoError(t, err)
+		reloads++
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, r))
+
+	writeCert(t, dir, "second", now)
+	// changes are not checked more often than certCheckPeriod
+	r.lastCheck = time.Now()
+	assert.Equal(t, "first", commonName(t, r))
+	r.lastCheck = time.Time{}
+	assert.Equal(t, "second", commonName(t, r))
+	assert.Equal(t, 1, reloads)
+
+	// the previous certificate is kept if the new one can't be loaded
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "key.pem"), []byte("garbage"), 0600))
+	r.onReload = func(err error) { assert.Error(t, err) }
+	r.lastCheck = time.Time{}
+	assert.Equal(t, "second", commonName(t, r))
+}
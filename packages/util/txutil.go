@@ -136,6 +136,45 @@ func BalancesHash(outs map[valuetransaction.ID][]*balance.Balance) *hashing.Hash
 	return hashing.HashData(buf.Bytes())
 }
 
+// BalancesAfterTransaction calculates outputs of the address which will exist in the ledger
+// after the transaction is confirmed: outputs consumed by the transaction are removed and new outputs
+// of the transaction to the address are added under txid
+func BalancesAfterTransaction(outs map[valuetransaction.ID][]*balance.Balance, addr *address.Address, tx *valuetransaction.Transaction, txid valuetransaction.ID) map[valuetransaction.ID][]*balance.Balance {
+	ret := make(map[valuetransaction.ID][]*balance.Balance, len(outs)+1)
+	for id, bals := range outs {
+		ret[id] = bals
+	}
+	tx.Inputs().ForEach(func(oid valuetransaction.OutputID) bool {
+		if oid.Address() == *addr {
+			delete(ret, oid.TransactionID())
+		}
+		return true
+	})
+	tx.Outputs().ForEach(func(a address.Address, bals []*balance.Balance) bool {
+		if a == *addr {
+			ret[txid] = CloneBalances(bals)
+		}
+		return true
+	})
+	return ret
+}
+
+// BalancesContained returns true if every output in outs exists among the ledger outputs with same balances
+func BalancesContained(outs, ledger map[valuetransaction.ID][]*balance.Balance) bool {
+	for txid, bals := range outs {
+		ledgerBals, ok := ledger[txid]
+		if !ok || len(ledgerBals) != len(bals) {
+			return false
+		}
+		for _, b := range bals {
+			if BalanceOfColor(ledgerBals, b.Color) != BalanceOfColor(bals, b.Color) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func InputsToStringByAddress(inputs *valuetransaction.Inputs) string {
 	imap := make(map[string][]string)
 	inputs.ForEach(func(oid valuetransaction.OutputID) bool {
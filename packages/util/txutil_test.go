@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancesAfterTransaction(t *testing.T) {
+	addr := address.Random()
+	other := address.Random()
+	txid1 := valuetransaction.RandomID()
+	txid2 := valuetransaction.RandomID()
+	outs := map[valuetransaction.ID][]*balance.Balance{
+		txid1: {balance.New(balance.ColorIOTA, 10)},
+		txid2: {balance.New(balance.ColorIOTA, 5)},
+	}
+	tx := valuetransaction.New(
+		valuetransaction.NewInputs(valuetransaction.NewOutputID(addr, txid1)),
+		valuetransaction.NewOutputs(map[address.Address][]*balance.Balance{
+			addr:  {balance.New(balance.ColorIOTA, 7)},
+			other: {balance.New(balance.ColorIOTA, 3)},
+		}),
+	)
+	txid := tx.ID()
+	after := BalancesAfterTransaction(outs, &addr, tx, txid)
+
+	assert.Equal(t, 2, len(after))
+	_, ok := after[txid1]
+	assert.False(t, ok)
+	assert.EqualValues(t, 5, BalanceOfColor(after[txid2], balance.ColorIOTA))
+	assert.EqualValues(t, 7, BalanceOfColor(after[txid], balance.ColorIOTA))
+
+	// original outputs are not modified
+	assert.Equal(t, 2, len(outs))
+
+	assert.True(t, BalancesContained(outs, outs))
+	assert.False(t, BalancesContained(after, outs))
+	outs[txid] = []*balance.Balance{balance.New(balance.ColorIOTA, 7)}
+	assert.True(t, BalancesContained(after, outs))
+	outs[txid2] = []*balance.Balance{balance.New(balance.ColorIOTA, 4)}
+	assert.False(t, BalancesContained(after, outs))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
+	"time"
 )
 
 // task context (for batch of requests)
@@ -34,6 +35,8 @@ type VMTask struct {
 	// outputs
 	ResultTransaction *sctransaction.Transaction
 	ResultBatch       state.Batch
+	// time spent by the VM to process the batch
+	RunTime time.Duration
 }
 
 // BatchHash is used to uniquely identify the VM task
@@ -113,6 +113,36 @@ func DeactivateCommittee(bootupData *registry.BootupData) error {
 	return nil
 }
 
+// RestartCommittee dismisses the committee of the smart contract, if it is running, and activates it again
+// with the current bootup data and key share
+func RestartCommittee(bootupData *registry.BootupData) error {
+	committeesMutex.Lock()
+	if c, ok := committeesByAddress[bootupData.Address]; ok {
+		c.Dismiss()
+		delete(committeesByAddress, bootupData.Address)
+	}
+	committeesMutex.Unlock()
+
+	if !bootupData.Active {
+		return nil
+	}
+	return ActivateCommittee(bootupData)
+}
+
+// ActiveCommittees returns committees of all active smart contracts
+func ActiveCommittees() []committee.Committee {
+	committeesMutex.RLock()
+	defer committeesMutex.RUnlock()
+
+	ret := make([]committee.Committee, 0, len(committeesByAddress))
+	for _, c := range committeesByAddress {
+		if !c.IsDismissed() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
 func CommitteeByAddress(addr address.Address) committee.Committee {
 	committeesMutex.RLock()
 	defer committeesMutex.RUnlock()
@@ -16,6 +16,10 @@ type CommittteeStatus struct {
 	NumPeers     uint16
 	HasQuorum    bool
 	PeerStatus   []*committee.PeerStatus
+	// false for access node
+	IsCommitteeNode bool
+	// nil for access node
+	ConsensusStats *committee.ConsensusStats
 }
 
 func GetStatus(address *address.Address) *CommittteeStatus {
@@ -24,14 +28,16 @@ func GetStatus(address *address.Address) *CommittteeStatus {
 		return nil
 	}
 	return &CommittteeStatus{
-		Address:      c.Address(),
-		OwnerAddress: c.OwnerAddress(),
-		Color:        c.Color(),
-		Size:         c.Size(),
-		Quorum:       c.Quorum(),
-		OwnPeerIndex: c.OwnPeerIndex(),
-		NumPeers:     c.NumPeers(),
-		HasQuorum:    c.HasQuorum(),
-		PeerStatus:   c.PeerStatus(),
+		Address:         c.Address(),
+		OwnerAddress:    c.OwnerAddress(),
+		Color:           c.Color(),
+		Size:            c.Size(),
+		Quorum:          c.Quorum(),
+		OwnPeerIndex:    c.OwnPeerIndex(),
+		NumPeers:        c.NumPeers(),
+		HasQuorum:       c.HasQuorum(),
+		PeerStatus:      c.PeerStatus(),
+		IsCommitteeNode: c.IsCommitteeNode(),
+		ConsensusStats:  c.ConsensusStats(),
 	}
 }
@@ -41,23 +41,34 @@ const tplPeering = `
 {{define "title"}}Peering{{end}}
 
 {{define "body"}}
+	<p>Node identity: <code>{{.Status.MyPubKey}}</code></p>
 	<h2>Peers</h2>
 	<table>
 		<thead>
 			<tr>
 				<th>Location</th>
+				<th>Identity</th>
 				<th>Type</th>
 				<th>Status</th>
 				<th>#Users</th>
+				<th>Queue</th>
+				<th>Dropped</th>
+				<th>Latency</th>
+				<th>Clock offset</th>
 			</tr>
 		</thead>
 		<tbody>
 		{{range $_, $peer := .Status.Peers}}
 			<tr>
 				<td><code>{{$peer.RemoteLocation}}</code></td>
+				<td><code>{{$peer.PubKey}}</code></td>
 				<td>{{if $peer.IsInbound}}inbound{{else}}outbound{{end}}</td>
 				<td>{{if $peer.IsAlive}}up{{else}}down{{end}}</td>
 				<td>{{$peer.NumUsers}}</td>
+				<td>{{$peer.QueueLen}} msgs / {{$peer.QueueBytes}} bytes</td>
+				<td>{{$peer.NumDropped}}</td>
+				<td>{{$peer.Latency}}</td>
+				<td>{{$peer.ClockOffset}}</td>
 			</tr>
 		{{end}}
 		</tbody>
@@ -12,6 +12,7 @@ import (
 	"github.com/iotaledger/wasp/packages/dashboard"
 	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/util/tlsutil"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
@@ -40,6 +41,21 @@ func configure(*node.Plugin) {
 	auth.AddAuthentication(Server, parameters.GetStringToString(parameters.DashboardAuth))
 	dashboard.UseHTMLErrorHandler(Server)
 
+	if certFile := parameters.GetString(parameters.DashboardTLSCertFile); certFile != "" {
+		keyFile := parameters.GetString(parameters.DashboardTLSKeyFile)
+		cfg, err := tlsutil.ServerConfig(certFile, keyFile, "", func(err error) {
+			if err != nil {
+				log.Errorf("failed to reload the TLS certificate, the previous one is used: %v", err)
+				return
+			}
+			log.Infof("TLS certificate reloaded")
+		})
+		if err != nil {
+			log.Panicf("failed to load the TLS certificate from %s, %s: %v", certFile, keyFile, err)
+		}
+		Server.TLSServer.TLSConfig = cfg
+	}
+
 	renderer := Renderer{}
 	Server.Renderer = renderer
 
@@ -67,7 +83,14 @@ func worker(shutdownSignal <-chan struct{}) {
 		defer close(stopped)
 
 		bindAddr := parameters.GetString(parameters.DashboardBindAddress)
-		if err := Server.Start(bindAddr); err != nil {
+		var err error
+		if Server.TLSServer.TLSConfig == nil {
+			err = Server.Start(bindAddr)
+		} else {
+			Server.TLSServer.Addr = bindAddr
+			err = Server.StartServer(Server.TLSServer)
+		}
+		if err != nil {
 			if !errors.Is(err, http.ErrServerClosed) {
 				log.Errorf("Error serving: %s", err)
 			}
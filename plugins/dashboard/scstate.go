@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/stateschema"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/labstack/echo"
+)
+
+const scStateRoute = "/smart-contracts/:address/state"
+const scStateTplName = "scState"
+
+const defaultStateQuery = "{ " + stateschema.SchemaField + " }"
+
+func addScStateEndpoint(e *echo.Echo) {
+	e.GET(scStateRoute, func(c echo.Context) error {
+		addr, err := address.FromBase58(c.Param("address"))
+		if err != nil {
+			return err
+		}
+		result := &ScStateTemplateParams{
+			BaseTemplateParams: BaseParams(c, scListRoute),
+			Address:            &addr,
+			Query:              c.QueryParam("query"),
+		}
+		if result.Query == "" {
+			result.Query = defaultStateQuery
+		}
+		resp, err := stateapi.BrowseState(&addr, result.Query)
+		if err != nil {
+			if he, ok := err.(*echo.HTTPError); ok {
+				result.Error = fmt.Sprintf("%v", he.Message)
+			} else {
+				result.Error = err.Error()
+			}
+			return c.Render(http.StatusOK, scStateTplName, result)
+		}
+		result.StateIndex = resp.StateIndex
+		result.ProgramHash = resp.ProgramHash
+		data, err := json.MarshalIndent(resp.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		result.Result = string(data)
+		return c.Render(http.StatusOK, scStateTplName, result)
+	})
+}
+
+type ScStateTemplateParams struct {
+	BaseTemplateParams
+	Address     *address.Address
+	Query       string
+	StateIndex  uint32
+	ProgramHash string
+	Result      string
+	Error       string
+}
+
+const tplScState = `
+{{define "title"}}State explorer{{end}}
+
+{{define "body"}}
+	<h2>State explorer</h2>
+	<p>Address: {{template "address" .Address}} (<a href="/smart-contracts/{{.Address}}">details</a>)</p>
+	<form method="get">
+		<textarea name="query" rows="5" cols="80">{{.Query}}</textarea>
+		<br/>
+		<button type="submit">Run query</button>
+	</form>
+	<p>Example: <code>{ counter  registry(limit: 10, after: "key")  latest: log(desc: true, limit: 5) }</code>.
+	Query <code>{ __schema }</code> to list variables of the state.</p>
+	<hr/>
+	{{if .Error}}
+		<p>Error: <code>{{.Error}}</code></p>
+	{{else}}
+		<p>State index: <code>{{.StateIndex}}</code></p>
+		<p>Schema of program: <code>{{if .ProgramHash}}{{.ProgramHash}}{{else}}unknown, builtin variables only{{end}}</code></p>
+		<pre>{{.Result}}</pre>
+	{{end}}
+{{end}}
+`
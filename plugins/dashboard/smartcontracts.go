@@ -30,6 +30,7 @@ func (n *scNavPage) Href() string  { return scListRoute }
 func (n *scNavPage) AddTemplates(renderer Renderer) {
 	renderer[scTplName] = MakeTemplate(tplSc)
 	renderer[scListTplName] = MakeTemplate(tplScList)
+	renderer[scStateTplName] = MakeTemplate(tplScState)
 }
 
 func (n *scNavPage) AddEndpoints(e *echo.Echo) {
@@ -87,6 +88,8 @@ func (n *scNavPage) AddEndpoints(e *echo.Echo) {
 
 		return c.Render(http.StatusOK, scTplName, result)
 	})
+
+	addScStateEndpoint(e)
 }
 
 func fetchSmartContracts() ([]*SmartContractOverview, error) {
@@ -195,6 +198,7 @@ const tplSc = `
 			<p>SC Program Hash: <code>{{.ProgramHash}}</code></p>
 			<p>SC Description: <code>{{.Description}}</code></p>
 			<p>SC Minimum Reward: <code>{{.MinimumReward}}</code></p>
+			<p><a href="/smart-contracts/{{.Address}}/state">Explore state</a></p>
 		</div>
 	{{else}}
 		<p>State is empty.</p>
@@ -226,6 +230,7 @@ const tplSc = `
 			<p>Quorum:         <code>{{.Committee.Quorum}}</code></p>
 			<p>NumPeers:       <code>{{.Committee.NumPeers}}</code></p>
 			<p>HasQuorum:      <code>{{.Committee.HasQuorum}}</code></p>
+			<p>Node role:      <code>{{if .Committee.IsCommitteeNode}}committee node{{else}}access node{{end}}</code></p>
 			<table>
 			<caption>Peer status</caption>
 			<thead>
@@ -233,6 +238,8 @@ const tplSc = `
 					<th>Index</th>
 					<th>ID</th>
 					<th>Status</th>
+					<th>Clock offset</th>
+					<th>Signature faults</th>
 				</tr>
 			</thead>
 			<tbody>
@@ -241,10 +248,55 @@ const tplSc = `
 					<td>{{$s.Index}}</td>
 					<td><code>{{$s.PeeringID}}</code></td>
 					<td>{{if $s.Connected}}up{{else}}down{{end}}</td>
+					<td>{{if not $s.IsSelf}}{{$s.ClockOffset}}{{end}}</td>
+					<td>{{if not $s.IsSelf}}{{$s.SigShareFaults}}{{end}}</td>
+				</tr>
+			{{end}}
+			</tbody>
+			</table>
+			{{if .Committee.ConsensusStats}}
+			{{with .Committee.ConsensusStats.Metrics}}
+			<p>Consensus stage: <code>{{.Stage}}</code></p>
+			<p>Rounds:          <code>{{.Rounds}}</code></p>
+			<p>Leader rotations: <code>{{.LeaderRotations}}</code></p>
+			<p>Batches started / requests: <code>{{.BatchesStarted}}</code> / <code>{{.RequestsInBatches}}</code></p>
+			<p>VM run time:     <code>{{.VMRunTime}}</code></p>
+			<p>Results posted / confirmed: <code>{{.ResultsPosted}}</code> / <code>{{.ResultsConfirmed}}</code></p>
+			<p>Last inclusion latency: <code>{{.LastInclusionLatency}}</code></p>
+			<p>Invalid signature shares: <code>{{.SigShareFaults}}</code></p>
+			{{end}}
+			<table>
+			<caption>Recent consensus rounds</caption>
+			<thead>
+				<tr>
+					<th>State index</th>
+					<th>Started</th>
+					<th>Leader</th>
+					<th>Leader changes</th>
+					<th>Batch size</th>
+					<th>VM run time</th>
+					<th>Signatures</th>
+					<th>Inclusion latency</th>
+					<th>Stages</th>
+				</tr>
+			</thead>
+			<tbody>
+			{{range $_, $r := .Committee.ConsensusStats.Rounds}}
+				<tr>
+					<td>{{$r.StateIndex}}</td>
+					<td>{{$r.Started.Format "15:04:05.000"}}</td>
+					<td>{{$r.Leader}}</td>
+					<td>{{$r.LeaderChanges}}</td>
+					<td>{{$r.BatchSize}}</td>
+					<td>{{$r.VMRunTime}}</td>
+					<td>{{$r.SignaturesCollected}}{{if $r.FaultyPeers}} (faulty: {{$r.FaultyPeers}}){{end}}</td>
+					<td>{{if $r.Confirmed}}{{$r.InclusionLatency}}{{else}}-{{end}}</td>
+					<td>{{range $_, $st := $r.Stages}}<code>{{$st.Time.Format "15:04:05.000"}} {{$st.Stage}}</code><br>{{end}}</td>
 				</tr>
 			{{end}}
 			</tbody>
 			</table>
+			{{end}}
 		</div>
 	{{else}}
 		<p>No committee available for this smart contract.</p>
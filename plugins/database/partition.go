@@ -23,6 +23,14 @@ const (
 	ObjectTypeStateVariable
 	ObjectTypeProgramMetadata
 	ObjectTypeProgramCode
+	ObjectTypeRequestBacklog
+	ObjectTypePostedResultTx
+	ObjectTypeNodeIdentity
+	ObjectTypeMasterKeyCheck
+	ObjectTypePeerAddress
+	ObjectTypeMultiSigKeys
+	ObjectTypeMultiSigMemberKey
+	ObjectTypeProgramPin
 )
 
 type Partition struct {
@@ -118,3 +126,17 @@ func createStore() {
 
 	store = db.NewStore()
 }
+
+// InitMemDB creates the store in memory instead of the configured database. It has effect only before
+// the store is used. Tests of packages which keep data in the registry use it
+func InitMemDB() {
+	storeOnce.Do(func() {
+		log = logger.NewNopLogger()
+
+		var err error
+		if db, err = database.NewMemDB(); err != nil {
+			panic(err)
+		}
+		store = db.NewStore()
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/packages/database"
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/kvstore"
@@ -24,6 +25,10 @@ var (
 	db        database.DB
 	store     kvstore.KVStore
 	storeOnce sync.Once
+
+	// set when the database is closed on shutdown
+	closed   bool
+	closedMu sync.RWMutex
 )
 
 func Init() *node.Plugin {
@@ -58,6 +63,9 @@ func run(_ *node.Plugin) {
 func closeDB(shutdownSignal <-chan struct{}) {
 	<-shutdownSignal
 	log.Infof("Syncing database to disk...")
+	closedMu.Lock()
+	closed = true
+	closedMu.Unlock()
 	if err := db.Close(); err != nil {
 		log.Errorf("Failed to flush the database: %s", err)
 	}
@@ -75,3 +83,15 @@ func runGC(shutdownSignal <-chan struct{}) {
 		}
 	}, 5*time.Minute, shutdownSignal)
 }
+
+// CheckHealth returns an error if the database is closed or can't be read
+func CheckHealth() error {
+	closedMu.RLock()
+	defer closedMu.RUnlock()
+	if closed {
+		return errors.New("database is closed")
+	}
+	var niladdr address.Address
+	_, err := GetPartition(&niladdr).Get(MakeKey(ObjectTypeDBSchemaVersion))
+	return err
+}
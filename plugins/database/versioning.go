@@ -13,7 +13,7 @@ import (
 const (
 	// DBVersion defines the version of the database schema this version of Wasp supports.
 	// Every time there's a breaking change regarding the stored data, this version flag should be adjusted.
-	DBVersion = 0
+	DBVersion = 1
 )
 
 var (
@@ -7,6 +7,7 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	_ "github.com/iotaledger/wasp/packages/committee/commiteeimpl" // activate init
+	"github.com/iotaledger/wasp/packages/dkg"
 	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
@@ -28,6 +29,7 @@ func Init() *node.Plugin {
 func configure(_ *node.Plugin) {
 	log = logger.NewLogger(PluginName)
 	state.InitLogger()
+	dkg.InitLogger()
 }
 
 func run(_ *node.Plugin) {
@@ -40,6 +42,10 @@ func run(_ *node.Plugin) {
 		})
 
 		processPeerMsgClosure := events.NewClosure(func(msg *peering.PeerMessage) {
+			if msg.MsgType >= peering.FirstNodeMsgCode {
+				dkg.ProcessPeerMessage(msg)
+				return
+			}
 			if committee := committees.CommitteeByAddress(msg.Address); committee != nil {
 				committee.ReceiveMessage(msg)
 			}
@@ -72,7 +78,7 @@ func run(_ *node.Plugin) {
 		// event attachments
 		// receiving events from NodeConn --> producing dispatcher events
 		nodeconn.EventMessageReceived.Attach(processNodeMsgClosure)
-		// receiving messages from peering --> send to respective committees
+		// receiving messages from peering --> send to respective committees or to DKG sessions
 		peering.EventPeerMessageReceived.Attach(processPeerMsgClosure)
 
 		log.Infof("dispatcher started")
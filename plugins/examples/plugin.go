@@ -4,6 +4,7 @@ import (
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/stateschema"
 	"github.com/iotaledger/wasp/packages/vm/examples/donatewithfeedback/dwfimpl"
 	"github.com/iotaledger/wasp/packages/vm/examples/fairauction"
 	"github.com/iotaledger/wasp/packages/vm/examples/fairroulette"
@@ -24,6 +25,7 @@ type example struct {
 	programHash  string
 	getProcessor func() vmtypes.Processor
 	name         string
+	stateSchema  *stateschema.Schema
 }
 
 func Init() *node.Plugin {
@@ -32,22 +34,22 @@ func Init() *node.Plugin {
 
 func configure(ctx *node.Plugin) {
 	allExamples := []example{
-		{vmnil.ProgramHash, vmnil.GetProcessor, "vmnil"},
-		{logsc.ProgramHash, logsc.GetProcessor, "logsc"},
-		{inccounter.ProgramHash, inccounter.GetProcessor, "inccounter"},
-		{fairroulette.ProgramHash, fairroulette.GetProcessor, "FairRoulette"},
+		{vmnil.ProgramHash, vmnil.GetProcessor, "vmnil", nil},
+		{logsc.ProgramHash, logsc.GetProcessor, "logsc", nil},
+		{inccounter.ProgramHash, inccounter.GetProcessor, "inccounter", incCounterSchema},
+		{fairroulette.ProgramHash, fairroulette.GetProcessor, "FairRoulette", fairRouletteSchema},
 		//{wasmpoc.ProgramHash, wasmpoc.GetProcessor, "wasmpoc"},
-		{fairauction.ProgramHash, fairauction.GetProcessor, "FairAuction"},
-		{tokenregistry.ProgramHash, tokenregistry.GetProcessor, "TokenRegistry"},
-		{sc7.ProgramHash, sc7.GetProcessor, "sc7"},
-		{sc8.ProgramHash, sc8.GetProcessor, "sc8"},
-		{sc9.ProgramHash, sc9.GetProcessor, "sc9"},
-		{dwfimpl.ProgramHash, dwfimpl.GetProcessor, "DonateWithFeedback"},
+		{fairauction.ProgramHash, fairauction.GetProcessor, "FairAuction", nil},
+		{tokenregistry.ProgramHash, tokenregistry.GetProcessor, "TokenRegistry", tokenRegistrySchema},
+		{sc7.ProgramHash, sc7.GetProcessor, "sc7", nil},
+		{sc8.ProgramHash, sc8.GetProcessor, "sc8", nil},
+		{sc9.ProgramHash, sc9.GetProcessor, "sc9", nil},
+		{dwfimpl.ProgramHash, dwfimpl.GetProcessor, "DonateWithFeedback", donateWithFeedbackSchema},
 	}
 
 	for _, ex := range allExamples {
 		hash, _ := hashing.HashValueFromBase58(ex.programHash)
-		registry.RegisterBuiltinProgramMetadata(&hash, ex.name+" (Built-in Smart Contract example)")
+		registry.RegisterBuiltinProgramMetadata(&hash, ex.name+" (Built-in Smart Contract example)", ex.stateSchema)
 		processor.RegisterBuiltinProcessor(&hash, ex.getProcessor)
 	}
 }
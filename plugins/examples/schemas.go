@@ -0,0 +1,38 @@
+package examples
+
+import (
+	"github.com/iotaledger/wasp/packages/stateschema"
+	"github.com/iotaledger/wasp/packages/vm/examples/donatewithfeedback"
+	"github.com/iotaledger/wasp/packages/vm/examples/fairroulette"
+	"github.com/iotaledger/wasp/packages/vm/examples/inccounter"
+	"github.com/iotaledger/wasp/packages/vm/examples/tokenregistry"
+)
+
+// schemas of the state of examples, for the state browser
+
+var incCounterSchema = &stateschema.Schema{Variables: []*stateschema.Variable{
+	{Name: inccounter.VarCounter, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "the counter"},
+	{Name: inccounter.VarNumRepeats, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "remaining repetitions"},
+}}
+
+var fairRouletteSchema = &stateschema.Schema{Variables: []*stateschema.Variable{
+	{Name: fairroulette.StateVarBets, Kind: stateschema.KindArray, ValueCodec: stateschema.CodecBytes, Description: "current bets"},
+	{Name: fairroulette.StateVarLockedBets, Kind: stateschema.KindArray, ValueCodec: stateschema.CodecBytes, Description: "locked bets"},
+	{Name: fairroulette.StateVarLastWinningColor, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "last winning color"},
+	{Name: fairroulette.StateVarEntropyFromLocking, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecHash, Description: "entropy from the locking transaction"},
+	{Name: fairroulette.StateVarNextPlayTimestamp, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "estimated timestamp of the next play, ns"},
+	{Name: fairroulette.ReqVarPlayPeriodSec, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "play period, seconds"},
+	{Name: fairroulette.StateArrayWinsPerColor, Kind: stateschema.KindArray, ValueCodec: stateschema.CodecBytes, Description: "wins per color"},
+	{Name: fairroulette.StateVarPlayerStats, Kind: stateschema.KindDict, KeyCodec: stateschema.CodecAddress, ValueCodec: stateschema.CodecBytes, Description: "statistics by player"},
+}}
+
+var tokenRegistrySchema = &stateschema.Schema{Variables: []*stateschema.Variable{
+	{Name: tokenregistry.VarStateTheRegistry, Kind: stateschema.KindDict, KeyCodec: stateschema.CodecColor, ValueCodec: stateschema.CodecBytes, Description: "token metadata by color"},
+	{Name: tokenregistry.VarStateListColors, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecString, Description: "list of colors"},
+}}
+
+var donateWithFeedbackSchema = &stateschema.Schema{Variables: []*stateschema.Variable{
+	{Name: donatewithfeedback.VarStateTheLog, Kind: stateschema.KindTLog, ValueCodec: stateschema.CodecBytes, Description: "donations with feedback"},
+	{Name: donatewithfeedback.VarStateMaxDonation, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "largest donation"},
+	{Name: donatewithfeedback.VarStateTotalDonations, Kind: stateschema.KindScalar, ValueCodec: stateschema.CodecInt64, Description: "total donations"},
+}}
@@ -0,0 +1,38 @@
+package nodeconn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/hive.go/events"
+)
+
+// GetOutputsFromNode requests outputs of the address from the node and waits for the answer.
+// Returns outputs (balances by transaction id) on the ledger
+func GetOutputsFromNode(addr *address.Address, timeout time.Duration) (map[valuetransaction.ID][]*balance.Balance, error) {
+	ch := make(chan map[valuetransaction.ID][]*balance.Balance, 1)
+	closure := events.NewClosure(func(msg interface{}) {
+		if msgt, ok := msg.(*waspconn.WaspFromNodeAddressOutputsMsg); ok && msgt.Address == *addr {
+			select {
+			case ch <- msgt.Balances:
+			default:
+			}
+		}
+	})
+	EventMessageReceived.Attach(closure)
+	defer EventMessageReceived.Detach(closure)
+
+	if err := RequestOutputsFromNode(addr); err != nil {
+		return nil, err
+	}
+	select {
+	case bals := <-ch:
+		return bals, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("no outputs of %s from the node in %v", addr.String(), timeout)
+	}
+}
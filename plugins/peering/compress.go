@@ -0,0 +1,51 @@
+package peering
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// compressMessage wraps encoded message into compressed message if compression is enabled,
+// the message is large enough and compression makes it smaller. Otherwise returns data unchanged
+func compressMessage(data []byte, ts int64) []byte {
+	if compressionThreshold <= 0 || len(data) < compressionThreshold {
+		return data
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return data
+	}
+	if _, err = w.Write(data); err != nil {
+		return data
+	}
+	if err = w.Close(); err != nil {
+		return data
+	}
+	ret := encodeMessage(&PeerMessage{
+		MsgType: MsgTypeCompressed,
+		MsgData: buf.Bytes(),
+	}, ts)
+	if len(ret) >= len(data) {
+		return data
+	}
+	return ret
+}
+
+// decompressMessage restores encoded message from data of the compressed message
+func decompressMessage(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	ret, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed message exceeds %d bytes", maxDecompressedSize)
+	}
+	return ret, nil
+}
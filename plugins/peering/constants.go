@@ -1,18 +1,46 @@
 package peering
 
-import "time"
+import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/packages/binary/messagelayer/payload"
+)
 
 const (
-	// equal and larger msg types are committee messages
+	// equal and larger msg types (up to FirstNodeMsgCode) are committee messages
 	// those with smaller are reserved by the package for heartbeat and handshake messages
 	FirstCommitteeMsgCode = byte(0x10)
+	// equal and larger msg types are messages of node level protocols, not related to any committee, such as DKG
+	FirstNodeMsgCode = byte(0x80)
 
 	MsgTypeReserved  = byte(0)
 	MsgTypeHandshake = byte(1)
 	MsgTypeMsgChunk  = byte(2)
+	// frames of the transport, carrying other encoded messages
+	MsgTypeCompressed = byte(3)
+	MsgTypeBatch      = byte(4)
+	MsgTypeHeartbeat  = byte(5)
+
+	restartAfter   = 1 * time.Second
+	callbackPeriod = 3 * time.Second
+	dialTimeout    = 1 * time.Second
+	dialRetries    = 10
+	backoffDelay   = 500 * time.Millisecond
+
+	// maximum number of peers which connected to the node by themselves and are not used yet
+	maxUnsolicitedPeers = 16
+
+	// messages not larger than that are coalesced into batches when sent
+	maxCoalescedMsgSize = 1024
+	// limit of the size of decompressed message
+	maxDecompressedSize = 64 * 1024 * 1024
+	// MaxMessageDataSize is the maximum size of the data of one message. Larger messages can't be chopped into chunks.
+	// One chunk is left for the encoding and compression overhead
+	MaxMessageDataSize = 254 * (payload.MaxMessageSize - chunkMessageOverhead - 8)
+	// period of checking if sender loop of the peer must stop
+	senderCheckPeriod = 1 * time.Second
 
-	restartAfter = 1 * time.Second
-	dialTimeout  = 1 * time.Second
-	dialRetries  = 10
-	backoffDelay = 500 * time.Millisecond
+	// period of heartbeat pings and number of last measurements kept
+	heartbeatPeriod  = 5 * time.Second
+	heartbeatSamples = 10
 )
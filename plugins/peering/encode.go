@@ -6,19 +6,29 @@ import (
 	"github.com/iotaledger/wasp/packages/util"
 )
 
+// After the handshake each encoded message (frame) is encrypted, see handshake.go
+//
 // structure of the encoded PeerMessage:
 // Timestamp   8 bytes
 // MsgType type    1 byte
-//  -- if MsgType == 0 (heartbeat) --> the end of message
+//  -- if MsgType == 0 (reserved) --> panic
 //  -- if MsgType == 1 (handshake)
-// MsgData (a string of peer network location) --> end of message
+// MsgData (identity, ephemeral key, signature and peering id) --> end of message
+//  -- if MsgType == 2 (chunk)
+// MsgData (piece of the chopped encoded message) --> end of message
+//  -- if MsgType == 3 (compressed)
+// MsgData (deflate compressed encoded message) --> end of message
+//  -- if MsgType == 4 (batch)
+// MsgData (sequence of encoded messages, each prefixed with 4 bytes length) --> end of message
+//  -- if MsgType == 5 (heartbeat)
+// MsgData (empty for ping, ping timestamp and receive time for pong) --> end of message
 //  -- if MsgType >= FirstCommitteeMsgCode
 // Addresses 32 bytes
 // SenderIndex 2 bytes
 // MsgData variable bytes to the end
 //  -- otherwise panic wrong MsgType
 
-const chunkMessageOverhead = 8 + 1
+const chunkMessageOverhead = 8 + 1 + sessionTagSize
 
 // always puts timestamp into first 8 bytes and 1 byte msg type
 func encodeMessage(msg *PeerMessage, ts int64) []byte {
@@ -38,8 +48,9 @@ func encodeMessage(msg *PeerMessage, ts int64) []byte {
 		buf.WriteByte(MsgTypeHandshake)
 		buf.Write(msg.MsgData)
 
-	case msg.MsgType == MsgTypeMsgChunk:
-		buf.WriteByte(MsgTypeMsgChunk)
+	case msg.MsgType == MsgTypeMsgChunk, msg.MsgType == MsgTypeCompressed, msg.MsgType == MsgTypeBatch,
+		msg.MsgType == MsgTypeHeartbeat:
+		buf.WriteByte(msg.MsgType)
 		buf.Write(msg.MsgData)
 
 	case msg.MsgType >= FirstCommitteeMsgCode:
@@ -76,7 +87,8 @@ func decodeMessage(data []byte) (*PeerMessage, error) {
 		ret.MsgData = rdr.Bytes()
 		return ret, nil
 
-	case ret.MsgType == MsgTypeMsgChunk:
+	case ret.MsgType == MsgTypeMsgChunk, ret.MsgType == MsgTypeCompressed, ret.MsgType == MsgTypeBatch,
+		ret.MsgType == MsgTypeHeartbeat:
 		ret.MsgData = rdr.Bytes()
 		return ret, nil
 
@@ -97,3 +109,34 @@ func decodeMessage(data []byte) (*PeerMessage, error) {
 		return nil, fmt.Errorf("peering.decodeMessage.wrong message type: %d", ret.MsgType)
 	}
 }
+
+// encodeBatch puts encoded messages into one batch message
+func encodeBatch(frames [][]byte, ts int64) []byte {
+	var buf bytes.Buffer
+	for _, frame := range frames {
+		_ = util.WriteBytes32(&buf, frame)
+	}
+	return encodeMessage(&PeerMessage{
+		MsgType: MsgTypeBatch,
+		MsgData: buf.Bytes(),
+	}, ts)
+}
+
+// decodeBatch splits data of the batch message into encoded messages
+func decodeBatch(data []byte) ([][]byte, error) {
+	ret := make([][]byte, 0)
+	rdr := bytes.NewReader(data)
+	for rdr.Len() > 0 {
+		var size uint32
+		if err := util.ReadUint32(rdr, &size); err != nil {
+			return nil, err
+		}
+		if int(size) > rdr.Len() {
+			return nil, fmt.Errorf("wrong size of the message in the batch: %d", size)
+		}
+		frame := make([]byte, size)
+		_, _ = rdr.Read(frame)
+		ret = append(ret, frame)
+	}
+	return ret, nil
+}
@@ -15,4 +15,6 @@ type PeerMessage struct {
 	Timestamp   int64
 	MsgType     byte
 	MsgData     []byte
+	// peer the message was received from. It is authenticated in the handshake. Set upon receive
+	Sender *Peer
 }
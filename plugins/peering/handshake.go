@@ -0,0 +1,167 @@
+package peering
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// The handshake is a signed ephemeral Diffie-Hellman exchange, similar to the Noise KK pattern.
+// The inbound peer starts with the challenge: its ephemeral X25519 key, sent as soon as the connection is accepted.
+// The outbound peer responds with its own ephemeral key, signed by the identity key together with the challenge.
+// The inbound peer checks the identity is the expected one and responds with its ephemeral key, signed together
+// with the ephemeral key of the outbound peer. The challenge is new for each connection, so the recorded handshake
+// of the outbound peer can't be replayed. Both sides derive session keys from the shared secret.
+// All messages after the handshake are encrypted and authenticated with ChaCha20-Poly1305.
+//
+// the challenge message data is the ephemeral public key, 32 bytes
+//
+// structure of the handshake message data:
+// identity public key   32 bytes
+// ephemeral public key  32 bytes
+// signature             64 bytes
+// peering id            to the end
+
+const handshakeContext = "wasp peering handshake"
+
+// size of the authentication tag added to each encrypted frame
+const sessionTagSize = 16
+
+const handshakeMsgMinSize = ed25519.PublicKeySize + 32 + ed25519.SignatureSize
+
+const handshakeChallengeSize = 32
+
+type handshakeMsg struct {
+	pubKey    ed25519.PublicKey
+	ephemeral [32]byte
+	signature []byte
+	peeringId string
+}
+
+func (hs *handshakeMsg) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(hs.pubKey)
+	buf.Write(hs.ephemeral[:])
+	buf.Write(hs.signature)
+	buf.WriteString(hs.peeringId)
+	return buf.Bytes()
+}
+
+func parseHandshakeMsg(data []byte) (*handshakeMsg, error) {
+	if len(data) <= handshakeMsgMinSize {
+		return nil, fmt.Errorf("wrong handshake message")
+	}
+	ret := &handshakeMsg{
+		pubKey:    data[:ed25519.PublicKeySize],
+		signature: data[ed25519.PublicKeySize+32 : handshakeMsgMinSize],
+		peeringId: string(data[handshakeMsgMinSize:]),
+	}
+	copy(ret.ephemeral[:], data[ed25519.PublicKeySize:ed25519.PublicKeySize+32])
+	return ret, nil
+}
+
+func parseHandshakeChallenge(data []byte) (ret [32]byte, err error) {
+	if len(data) != handshakeChallengeSize {
+		return ret, fmt.Errorf("wrong handshake challenge")
+	}
+	copy(ret[:], data)
+	return ret, nil
+}
+
+// handshakeSignedData is the data signed by the identity key: own ephemeral key followed by the one of the peer
+func handshakeSignedData(ownEphemeral, remoteEphemeral *[32]byte, peeringId string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(handshakeContext)
+	buf.Write(ownEphemeral[:])
+	buf.Write(remoteEphemeral[:])
+	buf.WriteString(peeringId)
+	return buf.Bytes()
+}
+
+func signHandshake(ownEphemeral, remoteEphemeral *[32]byte, peeringId string) ([]byte, error) {
+	return signer.SignIdentity(handshakeSignedData(ownEphemeral, remoteEphemeral, peeringId))
+}
+
+func (hs *handshakeMsg) verify(remoteEphemeral *[32]byte) bool {
+	return ed25519.Verify(hs.pubKey, handshakeSignedData(&hs.ephemeral, remoteEphemeral, hs.peeringId), hs.signature)
+}
+
+func newEphemeralKey() (priv, pub [32]byte, err error) {
+	if _, err = io.ReadFull(rand.Reader, priv[:]); err != nil {
+		return
+	}
+	curve25519.ScalarBaseMult(&pub, &priv)
+	return
+}
+
+// peerSession encrypts outgoing and decrypts incoming frames of the connection.
+// Nonces are counters, separate for each direction. TCP keeps the frames ordered
+type peerSession struct {
+	sendAead  cipher.AEAD
+	recvAead  cipher.AEAD
+	sendNonce uint64
+	recvNonce uint64
+}
+
+func newPeerSession(ownPriv, ownPub, remotePub *[32]byte, outbound bool) (*peerSession, error) {
+	shared, err := curve25519.X25519(ownPriv[:], remotePub[:])
+	if err != nil {
+		return nil, err
+	}
+	// salt is ephemeral key of the outbound peer followed by the one of the inbound peer
+	var salt []byte
+	if outbound {
+		salt = append(append(salt, ownPub[:]...), remotePub[:]...)
+	} else {
+		salt = append(append(salt, remotePub[:]...), ownPub[:]...)
+	}
+	kdf := hkdf.New(sha256.New, shared, salt, []byte(handshakeContext))
+	var outboundKey, inboundKey [chacha20poly1305.KeySize]byte
+	if _, err := io.ReadFull(kdf, outboundKey[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(kdf, inboundKey[:]); err != nil {
+		return nil, err
+	}
+	if !outbound {
+		outboundKey, inboundKey = inboundKey, outboundKey
+	}
+	ret := &peerSession{}
+	if ret.sendAead, err = chacha20poly1305.New(outboundKey[:]); err != nil {
+		return nil, err
+	}
+	if ret.recvAead, err = chacha20poly1305.New(inboundKey[:]); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func counterNonce(counter uint64) []byte {
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], counter)
+	return nonce[:]
+}
+
+func (s *peerSession) seal(data []byte) []byte {
+	ret := s.sendAead.Seal(nil, counterNonce(s.sendNonce), data, nil)
+	s.sendNonce++
+	return ret
+}
+
+func (s *peerSession) open(data []byte) ([]byte, error) {
+	ret, err := s.recvAead.Open(nil, counterNonce(s.recvNonce), data, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.recvNonce++
+	return ret, nil
+}
@@ -0,0 +1,135 @@
+package peering
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func useTestIdentity(identity ed25519.PrivateKey) {
+	signer = tcrypto.NewLocalSigner(identity, nil)
+	myIdentityPubKey = identity.Public().(ed25519.PublicKey)
+}
+
+func TestHandshakeSession(t *testing.T) {
+	_, identity, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	useTestIdentity(identity)
+
+	privA, pubA, err := newEphemeralKey()
+	assert.NoError(t, err)
+	privB, pubB, err := newEphemeralKey()
+	assert.NoError(t, err)
+
+	// B is the inbound peer, pubB is its challenge
+	signature, err := signHandshake(&pubA, &pubB, "peering-id")
+	assert.NoError(t, err)
+	hs := &handshakeMsg{
+		pubKey:    myPublicKey(),
+		ephemeral: pubA,
+		signature: signature,
+		peeringId: "peering-id",
+	}
+	hsBack, err := parseHandshakeMsg(hs.bytes())
+	assert.NoError(t, err)
+	assert.True(t, hsBack.verify(&pubB))
+	assert.False(t, hsBack.verify(&pubA))
+
+	sessA, err := newPeerSession(&privA, &pubA, &pubB, true)
+	assert.NoError(t, err)
+	sessB, err := newPeerSession(&privB, &pubB, &pubA, false)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		data := []byte("message from A")
+		opened, err := sessB.open(sessA.seal(data))
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(data, opened))
+
+		data = []byte("message from B")
+		opened, err = sessA.open(sessB.seal(data))
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(data, opened))
+	}
+	sealed := sessA.seal([]byte("tampered"))
+	sealed[0] ^= 1
+	_, err = sessB.open(sealed)
+	assert.Error(t, err)
+}
+
+// newTestInboundConn returns the inbound connection which sent the challenge. Everything written to it is discarded
+func newTestInboundConn(t *testing.T) *peeredConnection {
+	local, remote := net.Pipe()
+	go io.Copy(ioutil.Discard, remote)
+	bconn := newPeeredConnection(local, nil)
+	assert.NoError(t, bconn.sendChallenge())
+	return bconn
+}
+
+func TestHandshakeReplay(t *testing.T) {
+	log = logger.NewNopLogger()
+	config.Node = viper.New()
+	pubA, privA, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, privB, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	// the node is B, A dials it
+	useTestIdentity(privB)
+	peerA := newPeer("", pubA, 1)
+	peersMutex.Lock()
+	peers[peerA.PeeringId()] = peerA
+	peersMutex.Unlock()
+	defer func() {
+		peersMutex.Lock()
+		delete(peers, peerA.PeeringId())
+		peersMutex.Unlock()
+	}()
+
+	handshakeOfA := func(challenge *[32]byte) *PeerMessage {
+		_, ephemeral, err := newEphemeralKey()
+		assert.NoError(t, err)
+		hs := &handshakeMsg{
+			pubKey:    pubA,
+			ephemeral: ephemeral,
+			signature: ed25519.Sign(privA, handshakeSignedData(&ephemeral, challenge, peerA.PeeringId())),
+			peeringId: peerA.PeeringId(),
+		}
+		return &PeerMessage{MsgType: MsgTypeHandshake, MsgData: hs.bytes()}
+	}
+
+	conn1 := newTestInboundConn(t)
+	recorded := handshakeOfA(&conn1.ephemeralPub)
+	conn1.processHandShakeInbound(recorded)
+	peerA.RLock()
+	assert.True(t, peerA.peerconn == conn1)
+	assert.True(t, peerA.handshakeOk)
+	peerA.RUnlock()
+
+	// the recorded handshake is replayed on another connection. It is refused and the live connection stays
+	conn2 := newTestInboundConn(t)
+	conn2.processHandShakeInbound(recorded)
+	assert.Nil(t, conn2.peer)
+	peerA.RLock()
+	assert.True(t, peerA.peerconn == conn1)
+	assert.True(t, peerA.handshakeOk)
+	peerA.RUnlock()
+
+	// the fresh handshake for the challenge of the connection replaces the old connection
+	conn3 := newTestInboundConn(t)
+	conn3.processHandShakeInbound(handshakeOfA(&conn3.ephemeralPub))
+	peerA.RLock()
+	assert.True(t, peerA.peerconn == conn3)
+	assert.True(t, peerA.handshakeOk)
+	peerA.RUnlock()
+	_ = conn3.Close()
+}
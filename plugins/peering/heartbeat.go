@@ -0,0 +1,145 @@
+package peering
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Connected peers exchange heartbeats to measure the round trip time and the offset of the peer's clock.
+// The ping carries the local time of the sender in the timestamp of the message.
+// The pong carries the timestamp of the ping and the time the ping was received,
+// the timestamp of the pong is the time it was sent. The estimation is the same as in NTP.
+// Heartbeats are written to the connection directly, bypassing the queue of outgoing messages
+
+type heartbeatSample struct {
+	latency time.Duration
+	offset  time.Duration
+}
+
+// heartbeats keeps last samples measured with the peer
+type heartbeats struct {
+	sync.Mutex
+	samples  [heartbeatSamples]heartbeatSample
+	num      int
+	next     int
+	lastPing time.Time
+}
+
+func (hb *heartbeats) add(s heartbeatSample) {
+	hb.Lock()
+	defer hb.Unlock()
+
+	hb.samples[hb.next] = s
+	hb.next = (hb.next + 1) % heartbeatSamples
+	if hb.num < heartbeatSamples {
+		hb.num++
+	}
+}
+
+// pingDue returns true and records the time of the ping if it is time to send the next one
+func (hb *heartbeats) pingDue() bool {
+	hb.Lock()
+	defer hb.Unlock()
+
+	if time.Since(hb.lastPing) < heartbeatPeriod {
+		return false
+	}
+	hb.lastPing = time.Now()
+	return true
+}
+
+// stats returns average round trip time and median clock offset of the peer. False if not measured yet
+func (hb *heartbeats) stats() (time.Duration, time.Duration, bool) {
+	hb.Lock()
+	defer hb.Unlock()
+
+	if hb.num == 0 {
+		return 0, 0, false
+	}
+	var sum time.Duration
+	offsets := make([]time.Duration, hb.num)
+	for i := 0; i < hb.num; i++ {
+		sum += hb.samples[i].latency
+		offsets[i] = hb.samples[i].offset
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return sum / time.Duration(hb.num), offsets[hb.num/2], true
+}
+
+// Latency returns average round trip time to the peer, measured by heartbeats. False if not measured yet
+func (peer *Peer) Latency() (time.Duration, bool) {
+	latency, _, ok := peer.heartbeats.stats()
+	return latency, ok
+}
+
+// ClockOffset returns estimated difference between the peer's clock and the local clock.
+// False if not measured yet
+func (peer *Peer) ClockOffset() (time.Duration, bool) {
+	_, offset, ok := peer.heartbeats.stats()
+	return offset, ok
+}
+
+// sendHeartbeat sends ping to the peer if it is connected and it is time for it
+func (peer *Peer) sendHeartbeat() {
+	peer.RLock()
+	conn := peer.peerconn
+	handshakeOk := peer.handshakeOk
+	peer.RUnlock()
+
+	if conn == nil || !handshakeOk || !peer.heartbeats.pingDue() {
+		return
+	}
+	data := encodeMessage(&PeerMessage{
+		MsgType: MsgTypeHeartbeat,
+	}, time.Now().UnixNano())
+	if _, err := conn.write(data); err != nil {
+		log.Debugf("sending heartbeat to %s failed: %v", peer.PubKey(), err)
+	}
+}
+
+// processHeartbeat responds to the ping with pong. Pong is turned into the sample of the peer
+func (bconn *peeredConnection) processHeartbeat(msg *PeerMessage) {
+	received := time.Now().UnixNano()
+
+	if len(msg.MsgData) == 0 {
+		// ping
+		var buf bytes.Buffer
+		_ = util.WriteUint64(&buf, uint64(msg.Timestamp))
+		_ = util.WriteUint64(&buf, uint64(received))
+		data := encodeMessage(&PeerMessage{
+			MsgType: MsgTypeHeartbeat,
+			MsgData: buf.Bytes(),
+		}, time.Now().UnixNano())
+		if _, err := bconn.write(data); err != nil {
+			log.Debugf("sending heartbeat response failed: %v", err)
+		}
+		return
+	}
+	sample, err := heartbeatSampleFromPong(msg, received)
+	if err != nil {
+		log.Errorf("peeredConnection.processHeartbeat: %v", err)
+		return
+	}
+	bconn.peer.heartbeats.add(sample)
+}
+
+func heartbeatSampleFromPong(msg *PeerMessage, received int64) (heartbeatSample, error) {
+	if len(msg.MsgData) != 16 {
+		return heartbeatSample{}, fmt.Errorf("wrong heartbeat message")
+	}
+	rdr := bytes.NewReader(msg.MsgData)
+	var pingSent, pingReceived uint64
+	_ = util.ReadUint64(rdr, &pingSent)
+	_ = util.ReadUint64(rdr, &pingReceived)
+
+	t0, t1, t2, t3 := int64(pingSent), int64(pingReceived), msg.Timestamp, received
+	return heartbeatSample{
+		latency: time.Duration((t3 - t0) - (t2 - t1)),
+		offset:  time.Duration(((t1 - t0) + (t2 - t3)) / 2),
+	}, nil
+}
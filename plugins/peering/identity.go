@@ -0,0 +1,67 @@
+package peering
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/mr-tron/base58"
+)
+
+// signer holds secret keys of the node: the identity key and key shares of committees.
+// It is either in the process or the remote signer process, if configured
+var signer tcrypto.Signer
+
+// public key of the node identity. Peers authenticate each other by the public key in the handshake
+var myIdentityPubKey ed25519.PublicKey
+
+func loadIdentity() error {
+	if socketPath := parameters.GetString(parameters.SignerSocket); socketPath != "" {
+		log.Infof("using remote signer at %s", socketPath)
+		signer = tcrypto.NewRemoteSigner(socketPath)
+		registry.KeepKeysInSigner(signer)
+	} else {
+		identity, err := registry.LoadOrCreateNodeIdentity()
+		if err != nil {
+			return err
+		}
+		signer = tcrypto.NewLocalSigner(identity, registry.GetCommitteeKeys)
+	}
+	var err error
+	myIdentityPubKey, err = signer.IdentityPubKey()
+	return err
+}
+
+// NodeSigner returns the signer which holds secret keys of the node
+func NodeSigner() tcrypto.Signer {
+	return signer
+}
+
+func myPublicKey() ed25519.PublicKey {
+	return myIdentityPubKey
+}
+
+// MyPubKey returns base58 encoded public key of the node identity
+func MyPubKey() string {
+	return base58.Encode(myPublicKey())
+}
+
+// PubKeyFromBase58 decodes public key of the node identity
+func PubKeyFromBase58(s string) (ed25519.PublicKey, error) {
+	data, err := base58.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("wrong public key length %d", len(data))
+	}
+	return data, nil
+}
+
+// ImportKeys hands committee keys sealed for the identity of the node over to the signer.
+// Other keys of the same address are not replaced. Returns the keys to be saved in the registry
+func ImportKeys(sealed []byte) (tcrypto.CommitteeKeys, error) {
+	return signer.ImportKeys(sealed, false)
+}
@@ -1,8 +1,10 @@
 package peering
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/iotaledger/wasp/packages/parameters"
-	"sync"
+	"github.com/iotaledger/wasp/packages/registry"
 )
 
 func MyNetworkId() string {
@@ -12,30 +14,72 @@ func MyNetworkId() string {
 // adds new connection to the peer pool
 // if it already exists, returns existing
 // connection added to the pool is picked by loops which will try to establish connection
-func UsePeer(remoteLocation string) *Peer {
+// The peer is identified by the identity public key pubKey (base58 encoded). The network location
+// is only used to dial the peer, unless the address book contains another location for the identity
+func UsePeer(remoteLocation string, pubKey string) (*Peer, error) {
 	if !initialized.Load() {
 		log.Panic("UsePeer: plugin not initialized")
 	}
-	if remoteLocation == MyNetworkId() {
+	pk, err := PubKeyFromBase58(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s: %v", remoteLocation, err)
+	}
+	if bytes.Equal(pk, myPublicKey()) {
 		// nil for itself
-		return nil
+		return nil, nil
+	}
+	if remoteLocation == MyNetworkId() {
+		return nil, fmt.Errorf("own network location %s is listed with identity %s which is not the own one",
+			remoteLocation, pubKey)
+	}
+	if location, ok, err := registry.GetPeerAddress(pubKey); err == nil && ok {
+		remoteLocation = location
 	}
 	peersMutex.Lock()
 	defer peersMutex.Unlock()
 
-	if peer, ok := peers[peeringId(remoteLocation)]; ok {
+	if peer, ok := peers[peeringId(pk)]; ok {
 		peer.numUsers++
-		return peer
-	}
-	ret := &Peer{
-		RWMutex:        &sync.RWMutex{},
-		remoteLocation: remoteLocation,
-		startOnce:      &sync.Once{},
-		numUsers:       1,
+		if peer.RemoteLocation() == "" {
+			// unsolicited peer
+			peer.setRemoteLocation(remoteLocation)
+		}
+		return peer, nil
 	}
+	ret := newPeer(remoteLocation, pk, 1)
 	peers[ret.PeeringId()] = ret
 	log.Debugf("added new peer id %s inbound = %v", ret.PeeringId(), ret.isInbound())
-	return ret
+	return ret, nil
+}
+
+// SetPeerAddress saves network location of the peer to the address book.
+// The location is used by the peer immediately: the peer is reconnected if it is not connected
+func SetPeerAddress(pubKey string, remoteLocation string) error {
+	if _, err := PubKeyFromBase58(pubKey); err != nil {
+		return err
+	}
+	if pubKey == MyPubKey() {
+		return fmt.Errorf("can't set network location of the own identity")
+	}
+	if err := registry.SavePeerAddress(&registry.PeerAddress{PubKey: pubKey, NetID: remoteLocation}); err != nil {
+		return err
+	}
+	iteratePeers(func(peer *Peer) {
+		if peer.PubKey() != pubKey {
+			return
+		}
+		peer.setRemoteLocation(remoteLocation)
+		if !peer.IsAlive() {
+			peer.closeConn()
+		}
+	})
+	return nil
+}
+
+// DeletePeerAddress removes the peer from the address book. The peer keeps its current location until
+// it is used next time
+func DeletePeerAddress(pubKey string) error {
+	return registry.DeletePeerAddress(pubKey)
 }
 
 // decreases counter
@@ -8,7 +8,18 @@ import (
 	"github.com/iotaledger/wasp/packages/parameters"
 )
 
-// check if network location from the committee list represents current node
+// validateMyNetworkID checks if the own network location has form host:port
+func validateMyNetworkID() error {
+	_, sport, err := net.SplitHostPort(MyNetworkId())
+	if err != nil {
+		return err
+	}
+	_, err = strconv.Atoi(sport)
+	return err
+}
+
+// check if network location from the committee list represents current node.
+// It may not be the case behind NAT or in container networks, so the result is only a warning
 func checkMyNetworkID() error {
 	shost, sport, err := net.SplitHostPort(MyNetworkId())
 	if err != nil {
@@ -1,11 +1,12 @@
 package peering
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
-	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/chopper"
-	"github.com/iotaledger/goshimmer/packages/binary/messagelayer/payload"
 	"github.com/iotaledger/hive.go/backoff"
+	"github.com/mr-tron/base58"
 	"go.uber.org/atomic"
 	"net"
 	"sync"
@@ -15,45 +16,71 @@ import (
 // represents point-to-point TCP connection between two qnodes and another
 // it is used as transport for message exchange
 // Another end is always using the same connection
-// the Peer takes care about exchanging heartbeat messages.
-// It keeps last several received heartbeats as "lad" data to be able to calculate how synced/unsynced
-// clocks of peer are.
+// The peer is identified by the public key of its identity. Its network location may change.
 type Peer struct {
 	*sync.RWMutex
 	isDismissed atomic.Bool       // to be GC-ed
 	peerconn    *peeredConnection // nil means not connected
 	handshakeOk bool
-	// network locations as taken from the SC data
+	// network location where the peer is dialed. Taken from the address book or from the SC data.
+	// Empty if not known: the peer is only connected when it dials the node
 	remoteLocation string
+	// public key of the identity the peer must authenticate with in the handshake
+	pubKey ed25519.PublicKey
 
 	startOnce *sync.Once
 	numUsers  int
+	// outgoing messages
+	queue *sendQueue
+	// round trip time and clock offset measurements
+	heartbeats heartbeats
 }
 
 // retry net.Dial once, on fail after 0.5s
 var dialRetryPolicy = backoff.ConstantBackOff(backoffDelay).With(backoff.MaxRetries(dialRetries))
 
-func isInbound(remoteLocation string) bool {
-	if remoteLocation == MyNetworkId() {
-		panic("remoteLocation == myLocation")
+// isInbound returns true if the peer with the identity is expected to dial the node.
+// The node with lesser identity public key dials
+func isInbound(pubKey ed25519.PublicKey) bool {
+	if bytes.Equal(pubKey, myPublicKey()) {
+		panic("pubKey == myPublicKey")
 	}
-	return remoteLocation < MyNetworkId()
+	return bytes.Compare(pubKey, myPublicKey()) < 0
 }
 
 func (peer *Peer) isInbound() bool {
-	return isInbound(peer.remoteLocation)
+	return isInbound(peer.pubKey)
 }
 
-func peeringId(remoteLocation string) string {
-	if isInbound(remoteLocation) {
-		return remoteLocation + "<" + MyNetworkId()
+// peeringId is the same on both ends of the connection and does not depend on network locations
+func peeringId(pubKey ed25519.PublicKey) string {
+	if isInbound(pubKey) {
+		return base58.Encode(pubKey) + "<" + MyPubKey()
 	} else {
-		return MyNetworkId() + "<" + remoteLocation
+		return MyPubKey() + "<" + base58.Encode(pubKey)
 	}
 }
 
 func (peer *Peer) PeeringId() string {
-	return peeringId(peer.remoteLocation)
+	return peeringId(peer.pubKey)
+}
+
+// PubKey returns base58 encoded public key of the peer's identity
+func (peer *Peer) PubKey() string {
+	return base58.Encode(peer.pubKey)
+}
+
+// RemoteLocation returns current network location of the peer
+func (peer *Peer) RemoteLocation() string {
+	peer.RLock()
+	defer peer.RUnlock()
+	return peer.remoteLocation
+}
+
+func (peer *Peer) setRemoteLocation(remoteLocation string) {
+	peer.Lock()
+	defer peer.Unlock()
+	peer.remoteLocation = remoteLocation
 }
 
 // return true if is alive and average latencyRingBuf in nanosec
@@ -90,108 +117,139 @@ func (peer *Peer) closeConn() {
 	}
 }
 
+// linkConn makes the new connection the connection of the peer, unless the peer is connected already
+func (peer *Peer) linkConn(bconn *peeredConnection) bool {
+	peer.Lock()
+	defer peer.Unlock()
+
+	if peer.isDismissed.Load() || peer.peerconn != nil {
+		return false
+	}
+	peer.peerconn = bconn
+	peer.handshakeOk = false
+	return true
+}
+
+// dialLocation returns location to dial or empty string if the peer is connected or the location is unknown
+func (peer *Peer) dialLocation() string {
+	peer.RLock()
+	defer peer.RUnlock()
+	if peer.peerconn != nil {
+		return ""
+	}
+	return peer.remoteLocation
+}
+
 // dials outbound address and established connection
 func (peer *Peer) runOutbound() {
 	if peer.isDismissed.Load() {
 		return
 	}
 	if peer.isInbound() {
+		peer.runCallback()
 		return
 	}
-	if peer.peerconn != nil {
-		panic("peer.peerconn != nil")
-	}
-	log.Debugf("runOutbound %s", peer.remoteLocation)
-
-	// always try to reconnect
+	// always try to reconnect, unless the peer is not used anymore
 	defer func() {
-		go func() {
-			time.Sleep(restartAfter)
-			peer.Lock()
-			if !peer.isDismissed.Load() {
-				peer.startOnce = &sync.Once{}
-				log.Debugf("will run again: %s", peer.PeeringId())
-			}
-			peer.Unlock()
-		}()
+		if dropIfUnused(peer) {
+			return
+		}
+		peer.scheduleRestart(restartAfter)
 	}()
 
+	location := peer.dialLocation()
+	if location == "" {
+		// connected by the peer or the location is unknown
+		return
+	}
+	log.Debugf("runOutbound %s", location)
+
 	var conn net.Conn
 
 	if err := backoff.Retry(dialRetryPolicy, func() error {
 		var err error
-		conn, err = net.DialTimeout("tcp", peer.remoteLocation, dialTimeout)
+		conn, err = net.DialTimeout("tcp", location, dialTimeout)
 		if err != nil {
-			return fmt.Errorf("dial %s failed: %w", peer.remoteLocation, err)
+			return fmt.Errorf("dial %s failed: %w", location, err)
 		}
 		return nil
 	}); err != nil {
 		log.Warn(err)
 		return
 	}
-	peer.peerconn = newPeeredConnection(conn, peer)
-	if err := peer.sendHandshake(); err != nil {
-		log.Errorf("error during sendHandshake: %v", err)
+	peer.runConn(newPeeredConnection(conn, peer), location)
+}
+
+// runConn links the dialed connection with the peer and reads the connection until it is closed.
+// The handshake starts with the challenge from the peer
+func (peer *Peer) runConn(bconn *peeredConnection, location string) {
+	if !peer.linkConn(bconn) {
+		// the peer has connected meanwhile
+		_ = bconn.Close()
 		return
 	}
-	log.Debugf("starting reading outbound %s", peer.remoteLocation)
-	err := peer.peerconn.Read()
-	log.Debugw("stopped reading outbound. Closing", "remote", peer.remoteLocation, "err", err)
-	peer.closeConn()
+	log.Debugf("starting reading outbound %s", location)
+	err := bconn.Read()
+	log.Debugw("stopped reading outbound. Closing", "remote", location, "err", err)
+	_ = bconn.Close()
 }
 
-// sends handshake message. It contains myLocation
-func (peer *Peer) sendHandshake() error {
-	data := encodeMessage(&PeerMessage{
-		MsgType: MsgTypeHandshake,
-		MsgData: []byte(peer.PeeringId()),
-	}, time.Now().UnixNano())
-	_, err := peer.peerconn.Write(data)
-	log.Debugf("sendHandshake '%s' --> '%s', id = %s", MyNetworkId(), peer.remoteLocation, peer.PeeringId())
-	return err
+func (peer *Peer) scheduleRestart(d time.Duration) {
+	go func() {
+		time.Sleep(d)
+		peer.Lock()
+		if !peer.isDismissed.Load() {
+			peer.startOnce = &sync.Once{}
+			log.Debugf("will run again: %s", peer.PeeringId())
+		}
+		peer.Unlock()
+	}()
+}
+
+// runCallback dials the peer which is expected to dial the node. The peer accepts the connection if it is not
+// connected, for example because it can't reach the node behind NAT. Otherwise the peer dials the node
+// and closes the callback connection
+func (peer *Peer) runCallback() {
+	defer peer.scheduleRestart(callbackPeriod)
+
+	if peer.NumUsers() == 0 {
+		return
+	}
+	location := peer.dialLocation()
+	if location == "" {
+		return
+	}
+	conn, err := net.DialTimeout("tcp", location, dialTimeout)
+	if err != nil {
+		return
+	}
+	peer.runConn(newPeeredConnection(conn, peer), location)
 }
 
 func (peer *Peer) SendMsg(msg *PeerMessage) error {
 	if msg.MsgType < FirstCommitteeMsgCode {
 		return errors.New("reserved message code")
 	}
-	data := encodeMessage(msg, time.Now().UnixNano())
-
-	choppedData, chopped := chopper.ChopData(data, payload.MaxMessageSize-chunkMessageOverhead)
+	if len(msg.MsgData) > MaxMessageDataSize {
+		return errors.New("message is too large")
+	}
+	qmsg := newQueuedMsg(msg, time.Now().UnixNano())
 
 	peer.RLock()
 	defer peer.RUnlock()
 
-	if !chopped {
-		return peer.sendData(data)
-	}
-	return peer.sendChunks(choppedData)
-}
-
-func (peer *Peer) sendChunks(chopped [][]byte) error {
-	ts := time.Now().UnixNano()
-	for _, piece := range chopped {
-		d := encodeMessage(&PeerMessage{
-			MsgType: MsgTypeMsgChunk,
-			MsgData: piece,
-		}, ts)
-		if err := peer.sendData(d); err != nil {
-			return err
-		}
-	}
-	return nil
+	return peer.sendData(qmsg)
 }
 
 // SendMsgToPeers sends same msg to all peers in the slice which are not nil
 // with the same timestamp
 // return number of successfully sent messages and timestamp
 func SendMsgToPeers(msg *PeerMessage, ts int64, peers ...*Peer) uint16 {
-	if msg.MsgType < FirstCommitteeMsgCode {
+	if msg.MsgType < FirstCommitteeMsgCode || len(msg.MsgData) > MaxMessageDataSize {
 		return 0
 	}
-	// timestamped here, once
-	data := encodeMessage(msg, ts)
-	choppedData, chopped := chopper.ChopData(data, payload.MaxMessageSize-chunkMessageOverhead)
+	// timestamped and encoded here, once
+	qmsg := newQueuedMsg(msg, ts)
 
 	numSent := uint16(0)
 	for _, peer := range peers {
@@ -199,27 +257,22 @@ func SendMsgToPeers(msg *PeerMessage, ts int64, peers ...*Peer) uint16 {
 			continue
 		}
 		peer.RLock()
-		if !chopped {
-			if err := peer.sendData(data); err == nil {
-				numSent++
-			}
-		} else {
-			if err := peer.sendChunks(choppedData); err == nil {
-				numSent++
-			}
+		if err := peer.sendData(qmsg); err == nil {
+			numSent++
 		}
 		peer.RUnlock()
 	}
 	return numSent
 }
 
-func (peer *Peer) sendData(data []byte) error {
-	if peer.peerconn == nil {
+// sendData puts the message into the outgoing queue of the peer.
+// Returns error if the peer is not connected or the queue is full
+func (peer *Peer) sendData(msg *queuedMsg) error {
+	if peer.peerconn == nil || !peer.handshakeOk {
 		return fmt.Errorf("no connection with %s", peer.remoteLocation)
 	}
-	num, err := peer.peerconn.Write(data)
-	if num != len(data) {
-		return fmt.Errorf("not all bytes were written. err = %v", err)
+	if err := peer.queue.push(msg); err != nil {
+		return fmt.Errorf("can't send to %s: %v", peer.remoteLocation, err)
 	}
 	return nil
 }
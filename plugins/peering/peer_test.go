@@ -0,0 +1,55 @@
+package peering
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeeringIdByIdentity(t *testing.T) {
+	pubA, privA, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pubB, privB, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	useTestIdentity(privA)
+	idA := peeringId(pubB)
+	inboundA := isInbound(pubB)
+
+	useTestIdentity(privB)
+	idB := peeringId(pubA)
+	inboundB := isInbound(pubA)
+
+	// same on both ends, exactly one of them dials
+	assert.Equal(t, idA, idB)
+	assert.NotEqual(t, inboundA, inboundB)
+}
+
+func TestHeartbeatSample(t *testing.T) {
+	// the peer's clock is 100 ahead, network delay is 10 each way, the peer responds in 5
+	const t0 = 1000
+	pong := &PeerMessage{
+		MsgType:   MsgTypeHeartbeat,
+		Timestamp: t0 + 10 + 100 + 5,
+		MsgData:   append(util.Uint64To8Bytes(t0), util.Uint64To8Bytes(t0+10+100)...),
+	}
+
+	sample, err := heartbeatSampleFromPong(pong, t0+10+5+10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 20, sample.latency)
+	assert.EqualValues(t, 100, sample.offset)
+
+	var hb heartbeats
+	_, _, ok := hb.stats()
+	assert.False(t, ok)
+	hb.add(sample)
+	hb.add(heartbeatSample{latency: 40, offset: 90})
+	hb.add(heartbeatSample{latency: 30, offset: 1000})
+	latency, offset, ok := hb.stats()
+	assert.True(t, ok)
+	assert.EqualValues(t, 30, latency)
+	assert.EqualValues(t, 100, offset)
+}
@@ -1,7 +1,10 @@
 package peering
 
 import (
+	"bytes"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/chopper"
 	"github.com/iotaledger/goshimmer/packages/binary/messagelayer/payload"
@@ -17,6 +20,15 @@ type peeredConnection struct {
 	*buffconn.BufferedConnection
 	peer        *Peer
 	handshakeOk bool
+	// own ephemeral key of the handshake
+	ephemeralPriv [32]byte
+	ephemeralPub  [32]byte
+	// ephemeral key of the inbound peer, received in the challenge. Used by the outbound peer
+	challenge         [32]byte
+	challengeReceived bool
+	// nil until handshake is finished. Then all frames are encrypted
+	session    *peerSession
+	writeMutex sync.Mutex
 }
 
 // creates new peered connection and attach event handlers for received data and closing
@@ -31,17 +43,52 @@ func newPeeredConnection(conn net.Conn, peer *Peer) *peeredConnection {
 	bconn.Events.Close.Attach(events.NewClosure(func() {
 		if bconn.peer != nil {
 			bconn.peer.Lock()
-			bconn.peer.peerconn = nil
-			bconn.peer.handshakeOk = false
+			if bconn.peer.peerconn == bconn {
+				// the connection may have been replaced by another one already
+				bconn.peer.peerconn = nil
+				bconn.peer.handshakeOk = false
+			}
 			bconn.peer.Unlock()
+			// unsolicited peers are kept only while connected
+			go dropIfUnused(bconn.peer)
 		}
 		log.Debugw("closed buff connection", "conn", conn.RemoteAddr().String())
 	}))
 	return bconn
 }
 
+// write encrypts the frame if handshake is finished and writes it to the connection
+// Returns number of bytes of the original frame
+func (bconn *peeredConnection) write(data []byte) (int, error) {
+	bconn.writeMutex.Lock()
+	defer bconn.writeMutex.Unlock()
+
+	if bconn.session == nil {
+		return bconn.Write(data)
+	}
+	sealed := bconn.session.seal(data)
+	num, err := bconn.Write(sealed)
+	if num == len(sealed) {
+		num = len(data)
+	}
+	return num, err
+}
+
 // receive data handler for peered connection
 func (bconn *peeredConnection) receiveData(data []byte) {
+	if bconn.session != nil {
+		var err error
+		if data, err = bconn.session.open(data); err != nil {
+			// gross violation of the protocol
+			log.Errorf("!!!!! peeredConnection.receiveData: failed to decrypt message: %v", err)
+			bconn.Close()
+			return
+		}
+	}
+	bconn.processData(data)
+}
+
+func (bconn *peeredConnection) processData(data []byte) {
 	msg, err := decodeMessage(data)
 	if err != nil {
 		// gross violation of the protocol
@@ -49,20 +96,53 @@ func (bconn *peeredConnection) receiveData(data []byte) {
 		bconn.Close()
 		return
 	}
-	if msg.MsgType == MsgTypeMsgChunk {
+	switch msg.MsgType {
+	case MsgTypeMsgChunk:
 		finalMsg, err := chopper.IncomingChunk(msg.MsgData, payload.MaxMessageSize-chunkMessageOverhead)
 		if err != nil {
 			log.Errorf("peeredConnection.receiveData: %v", err)
 			return
 		}
 		if finalMsg != nil {
-			bconn.receiveData(finalMsg)
+			bconn.processData(finalMsg)
 		}
+		return
+
+	case MsgTypeCompressed, MsgTypeBatch:
+		if bconn.session == nil {
+			// gross violation of the protocol
+			log.Errorf("!!!!! peeredConnection.receiveData: message type %d before handshake", msg.MsgType)
+			bconn.Close()
+			return
+		}
+		var frames [][]byte
+		if msg.MsgType == MsgTypeCompressed {
+			var data []byte
+			data, err = decompressMessage(msg.MsgData)
+			frames = [][]byte{data}
+		} else {
+			frames, err = decodeBatch(msg.MsgData)
+		}
+		if err != nil {
+			// gross violation of the protocol
+			log.Errorf("!!!!! peeredConnection.receiveData: %v", err)
+			bconn.Close()
+			return
+		}
+		for _, frame := range frames {
+			bconn.processData(frame)
+		}
+		return
 	}
 	if bconn.peer != nil {
 		// it is peered but maybe not handshaked yet (can only be outbound)
 		if bconn.peer.handshakeOk {
 			// it is handshake-ed
+			if msg.MsgType == MsgTypeHeartbeat {
+				bconn.processHeartbeat(msg)
+				return
+			}
+			msg.Sender = bconn.peer
 			EventPeerMessageReceived.Trigger(msg)
 		} else {
 			// expected handshake msg
@@ -86,51 +166,188 @@ func (bconn *peeredConnection) receiveData(data []byte) {
 	}
 }
 
-// receives handshake response from the outbound peer
+// sendChallenge starts the handshake with the new ephemeral key. Used by the inbound peer
+func (bconn *peeredConnection) sendChallenge() error {
+	var err error
+	if bconn.ephemeralPriv, bconn.ephemeralPub, err = newEphemeralKey(); err != nil {
+		return err
+	}
+	data := encodeMessage(&PeerMessage{
+		MsgType: MsgTypeHandshake,
+		MsgData: bconn.ephemeralPub[:],
+	}, time.Now().UnixNano())
+	_, err = bconn.write(data)
+	return err
+}
+
+// sends handshake message with the own identity and new ephemeral key, signed together with the challenge.
+// Used by the outbound peer
+func (bconn *peeredConnection) sendHandshake(peeringId string) error {
+	var err error
+	if bconn.ephemeralPriv, bconn.ephemeralPub, err = newEphemeralKey(); err != nil {
+		return err
+	}
+	signature, err := signHandshake(&bconn.ephemeralPub, &bconn.challenge, peeringId)
+	if err != nil {
+		return err
+	}
+	hs := &handshakeMsg{
+		pubKey:    myPublicKey(),
+		ephemeral: bconn.ephemeralPub,
+		signature: signature,
+		peeringId: peeringId,
+	}
+	data := encodeMessage(&PeerMessage{
+		MsgType: MsgTypeHandshake,
+		MsgData: hs.bytes(),
+	}, time.Now().UnixNano())
+	_, err = bconn.write(data)
+	log.Debugf("sendHandshake '%s' --> '%s', id = %s", MyNetworkId(), bconn.RemoteAddr().String(), peeringId)
+	return err
+}
+
+// receives the challenge and then the handshake response from the inbound peer
 // assumes the connection is already peered (i can be only for outbound peers)
 func (bconn *peeredConnection) processHandShakeOutbound(msg *PeerMessage) {
-	id := string(msg.MsgData)
-	log.Debugf("received handshake from outbound %s", id)
-	if id != bconn.peer.PeeringId() {
-		log.Error("closeConn the peer connection: wrong handshake message from outbound peer: expected %s got '%s'",
-			bconn.peer.PeeringId(), id)
-		if bconn.peer != nil {
-			// may ne be peered yet
-			bconn.peer.closeConn()
+	if !bconn.challengeReceived {
+		challenge, err := parseHandshakeChallenge(msg.MsgData)
+		if err != nil {
+			log.Errorf("closeConn the peer connection: %v", err)
+			_ = bconn.Close()
+			return
 		}
-	} else {
-		log.Infof("CONNECTED WITH PEER %s (outbound)", id)
-		bconn.peer.handshakeOk = true
+		bconn.challenge = challenge
+		bconn.challengeReceived = true
+		if err := bconn.sendHandshake(bconn.peer.PeeringId()); err != nil {
+			log.Errorf("error during sendHandshake: %v", err)
+			_ = bconn.Close()
+		}
+		return
+	}
+	hs, err := parseHandshakeMsg(msg.MsgData)
+	if err != nil {
+		log.Errorf("closeConn the peer connection: %v", err)
+		_ = bconn.Close()
+		return
 	}
+	log.Debugf("received handshake from outbound %s", hs.peeringId)
+	if hs.peeringId != bconn.peer.PeeringId() {
+		log.Errorf("closeConn the peer connection: wrong handshake message from outbound peer: expected %s got '%s'",
+			bconn.peer.PeeringId(), hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
+	if !bytes.Equal(hs.pubKey, bconn.peer.pubKey) || hs.ephemeral != bconn.challenge || !hs.verify(&bconn.ephemeralPub) {
+		log.Errorf("closeConn the peer connection: outbound peer %s failed to authenticate as %s",
+			hs.peeringId, bconn.peer.PubKey())
+		_ = bconn.Close()
+		return
+	}
+	session, err := newPeerSession(&bconn.ephemeralPriv, &bconn.ephemeralPub, &hs.ephemeral, true)
+	if err != nil {
+		log.Errorf("closeConn the peer connection: %v", err)
+		_ = bconn.Close()
+		return
+	}
+	bconn.writeMutex.Lock()
+	bconn.session = session
+	bconn.writeMutex.Unlock()
+
+	bconn.peer.Lock()
+	if bconn.peer.peerconn != bconn {
+		bconn.peer.Unlock()
+		log.Debugf("connection with %s was replaced during the handshake. Closing..", hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
+	bconn.peer.handshakeOk = true
+	bconn.peer.Unlock()
+
+	log.Infof("CONNECTED WITH PEER %s (outbound)", hs.peeringId)
 }
 
 // receives handshake from the inbound peer
 // links connection with the peer
 // sends response back to finish the handshake
 func (bconn *peeredConnection) processHandShakeInbound(msg *PeerMessage) {
-	peeringId := string(msg.MsgData)
-	log.Debugf("received handshake from inbound id = %s", peeringId)
-
-	peersMutex.RLock()
-	peer, ok := peers[peeringId]
-	peersMutex.RUnlock()
-
-	if !ok || !peer.isInbound() {
-		log.Debugf("inbound connection from unexpected peer id %s. Closing..", peeringId)
+	hs, err := parseHandshakeMsg(msg.MsgData)
+	if err != nil {
+		log.Debugf("inbound connection with wrong handshake: %v. Closing..", err)
 		_ = bconn.Close()
 		return
 	}
-	bconn.peer = peer
+	log.Debugf("received handshake from inbound id = %s", hs.peeringId)
 
+	// the signature covers the challenge, so the handshake recorded on another connection doesn't pass
+	if !hs.verify(&bconn.ephemeralPub) {
+		log.Warnf("inbound connection from peer id %s with wrong handshake signature. Closing..", hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
+	peer := peerForInboundHandshake(hs)
+	if peer == nil {
+		log.Debugf("inbound connection from unexpected peer id %s. Closing..", hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
+	if !bytes.Equal(hs.pubKey, peer.pubKey) {
+		log.Warnf("inbound connection from peer id %s with unknown identity. Closing..", hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
 	peer.Lock()
+	oldConn := peer.peerconn
+	if oldConn != nil && !peer.isInbound() && !peer.handshakeOk {
+		// the node is dialing the peer itself. The peer will accept that connection and close this one
+		peer.Unlock()
+		log.Debugf("callback from peer id %s while dialing it. Closing..", hs.peeringId)
+		_ = bconn.Close()
+		return
+	}
+	// response is sent in plain text, after it all frames are encrypted
+	bconn.writeMutex.Lock()
+	err = bconn.sendHandshakeResponse(hs)
+	bconn.writeMutex.Unlock()
+	if err != nil {
+		peer.Unlock()
+		log.Errorf("error while responding to handshake: %v. Closing connection", err)
+		_ = bconn.Close()
+		return
+	}
+	bconn.peer = peer
+	// the new connection replaces the previous one: the peer doesn't consider it alive anymore
 	peer.peerconn = bconn
 	peer.handshakeOk = true
 	peer.Unlock()
 
-	log.Infof("CONNECTED WITH PEER %s (inbound)", peeringId)
+	if oldConn != nil {
+		_ = oldConn.Close()
+	}
+	log.Infof("CONNECTED WITH PEER %s (inbound)", hs.peeringId)
+}
 
-	if err := peer.sendHandshake(); err != nil {
-		log.Error("error while responding to handshake: %v. Closing connection", err)
-		_ = bconn.Close()
+// sendHandshakeResponse sends the own handshake message with the ephemeral key of the challenge and starts the session.
+// Must be called with the write mutex locked
+func (bconn *peeredConnection) sendHandshakeResponse(hs *handshakeMsg) error {
+	signature, err := signHandshake(&bconn.ephemeralPub, &hs.ephemeral, hs.peeringId)
+	if err != nil {
+		return err
+	}
+	resp := &handshakeMsg{
+		pubKey:    myPublicKey(),
+		ephemeral: bconn.ephemeralPub,
+		signature: signature,
+		peeringId: hs.peeringId,
 	}
+	data := encodeMessage(&PeerMessage{
+		MsgType: MsgTypeHandshake,
+		MsgData: resp.bytes(),
+	}, time.Now().UnixNano())
+	if _, err = bconn.Write(data); err != nil {
+		return err
+	}
+	log.Debugf("sendHandshake '%s' --> '%s', id = %s", MyNetworkId(), bconn.RemoteAddr().String(), hs.peeringId)
+
+	bconn.session, err = newPeerSession(&bconn.ephemeralPriv, &bconn.ephemeralPub, &hs.ephemeral, false)
+	return err
 }
@@ -1,9 +1,13 @@
 package peering
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"fmt"
 	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/plugins/gracefulshutdown"
+	"github.com/mr-tron/base58"
 	"io"
 	"net"
 	"strings"
@@ -16,6 +20,88 @@ var (
 	peersMutex = &sync.RWMutex{}
 )
 
+func newPeer(remoteLocation string, pubKey ed25519.PublicKey, numUsers int) *Peer {
+	ret := &Peer{
+		RWMutex:        &sync.RWMutex{},
+		remoteLocation: remoteLocation,
+		pubKey:         pubKey,
+		startOnce:      &sync.Once{},
+		numUsers:       numUsers,
+		queue:          newSendQueue(),
+	}
+	go ret.runSender()
+	return ret
+}
+
+// IsKnownIdentity returns true if the node with the public key (base58) is either
+// in the address book of the node or in the list of allowed peers in the configuration
+func IsKnownIdentity(pubKey string) bool {
+	for _, allowed := range parameters.GetStringSlice(parameters.PeeringAllowedPeers) {
+		if allowed == pubKey {
+			return true
+		}
+	}
+	_, ok, err := registry.GetPeerAddress(pubKey)
+	return err == nil && ok
+}
+
+// peerForInboundHandshake returns the peer the authenticated handshake is coming from.
+// If the peer is not in the pool but is a known identity, it is added to the pool as unsolicited peer,
+// not used by anyone yet. It allows known nodes to start protocols, like DKG, with nodes which are not
+// their peers yet. Handshakes from unknown identities are rejected
+func peerForInboundHandshake(hs *handshakeMsg) *Peer {
+	if bytes.Equal(hs.pubKey, myPublicKey()) || hs.peeringId != peeringId(hs.pubKey) {
+		return nil
+	}
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	if peer, ok := peers[hs.peeringId]; ok {
+		return peer
+	}
+	pubKey := base58.Encode(hs.pubKey)
+	if !IsKnownIdentity(pubKey) {
+		log.Warnf("handshake from unknown identity %s rejected", pubKey)
+		return nil
+	}
+	numUnsolicited := 0
+	for _, peer := range peers {
+		if peer.numUsers == 0 {
+			numUnsolicited++
+		}
+	}
+	if numUnsolicited >= maxUnsolicitedPeers {
+		log.Warnf("too many unsolicited peers. Rejected %s", pubKey)
+		return nil
+	}
+	// location of the unsolicited peer is taken from the address book, if any.
+	// Otherwise it is only connected while it keeps the connection
+	location, _, _ := registry.GetPeerAddress(pubKey)
+	ret := newPeer(location, hs.pubKey, 0)
+	peers[ret.PeeringId()] = ret
+	log.Infof("added unsolicited peer with identity %s", ret.PubKey())
+	return ret
+}
+
+// dropIfUnused removes the peer from the pool if nobody uses it.
+// Returns true if the peer is dropped
+func dropIfUnused(peer *Peer) bool {
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	if peer.numUsers > 0 || peers[peer.PeeringId()] != peer {
+		return false
+	}
+	if connected, _ := peer.connStatus(); connected {
+		// unsolicited peers are kept while connected
+		return false
+	}
+	peer.isDismissed.Store(true)
+	delete(peers, peer.PeeringId())
+	log.Debugf("dropped unused peer %s", peer.PubKey())
+	return true
+}
+
 func iteratePeers(f func(p *Peer)) {
 	peersMutex.Lock()
 	defer peersMutex.Unlock()
@@ -76,6 +162,11 @@ func connectInboundLoop() {
 
 		// peer is not known yet
 		bconn := newPeeredConnection(conn, nil)
+		if err := bconn.sendChallenge(); err != nil {
+			log.Debugf("failed to send handshake challenge to %s: %v", conn.RemoteAddr().String(), err)
+			_ = bconn.Close()
+			continue
+		}
 		go func() {
 			log.Debugf("starting reading inbound %s", conn.RemoteAddr().String())
 			err := bconn.Read()
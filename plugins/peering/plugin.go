@@ -5,6 +5,7 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/registry"
 	"go.uber.org/atomic"
 )
 
@@ -22,12 +23,30 @@ func Init() *node.Plugin {
 
 func configure(_ *node.Plugin) {
 	log = logger.NewLogger(PluginName)
-	if err := checkMyNetworkID(); err != nil {
+	if err := validateMyNetworkID(); err != nil {
 		// can't continue because netid parameter is not correct
-		log.Panicf("checkMyNetworkID: '%v'. || Check the 'netid' parameter in config.json", err)
+		log.Panicf("validateMyNetworkID: '%v'. || Check the 'netid' parameter in config.json", err)
+		return
+	}
+	if err := checkMyNetworkID(); err != nil {
+		// the node is identified by its identity, so it can run behind NAT
+		log.Warnf("checkMyNetworkID: '%v'. Other nodes may not be able to dial the node", err)
+	}
+	// the registry is first accessed here. Secrets in it can't be read without the master key
+	registry.InitLogger()
+	if err := registry.InitMasterKey(); err != nil {
+		log.Panicf("failed to load the master key: %v", err)
+		return
+	}
+	if err := loadIdentity(); err != nil {
+		log.Panicf("failed to load node identity: %v", err)
 		return
 	}
+	maxQueueSize = parameters.GetInt(parameters.PeeringMaxQueueSize)
+	compressionThreshold = parameters.GetInt(parameters.PeeringCompressionThreshold)
+
 	log.Infof("--------------------------------- netid is %s -----------------------------------", MyNetworkId())
+	log.Infof("--------------------------------- identity is %s -----------------------------------", MyPubKey())
 	initialized.Store(true)
 }
 
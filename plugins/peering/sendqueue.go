@@ -0,0 +1,232 @@
+package peering
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/chopper"
+	"github.com/iotaledger/goshimmer/packages/binary/messagelayer/payload"
+)
+
+// Outgoing messages are put into the queue of the peer and written to the connection by the sender loop of the peer.
+// The size of the queue is limited. When the queue is full, the drop policy of the message type is applied
+
+// DropPolicy defines which messages are dropped when the outgoing queue of the peer is full
+type DropPolicy byte
+
+const (
+	// the new message is refused, the sender receives an error. Default policy
+	DropNewest = DropPolicy(iota)
+	// queued messages of the same type are dropped, oldest first, to make room for the new one.
+	// Used for messages which are superseded by the newer ones
+	DropOldest
+)
+
+var (
+	dropPolicies      = make(map[byte]DropPolicy)
+	dropPoliciesMutex = &sync.RWMutex{}
+
+	// set from parameters when the plugin is configured
+	maxQueueSize         int
+	compressionThreshold int
+)
+
+// SetDropPolicy sets the policy applied to messages of the type when outgoing queue of a peer is full
+func SetDropPolicy(msgType byte, policy DropPolicy) {
+	dropPoliciesMutex.Lock()
+	defer dropPoliciesMutex.Unlock()
+	dropPolicies[msgType] = policy
+}
+
+func getDropPolicy(msgType byte) DropPolicy {
+	dropPoliciesMutex.RLock()
+	defer dropPoliciesMutex.RUnlock()
+	return dropPolicies[msgType]
+}
+
+// queuedMsg is the encoded message ready to be written: either one frame or chunks of the message
+type queuedMsg struct {
+	msgType byte
+	frames  [][]byte
+	size    int
+}
+
+// newQueuedMsg encodes the message, compresses it if enabled and chops it if it is too large.
+// The result is shared between queues of all peers it is sent to
+func newQueuedMsg(msg *PeerMessage, ts int64) *queuedMsg {
+	data := compressMessage(encodeMessage(msg, ts), ts)
+	ret := &queuedMsg{
+		msgType: msg.MsgType,
+		size:    len(data),
+	}
+	choppedData, chopped := chopper.ChopData(data, payload.MaxMessageSize-chunkMessageOverhead)
+	if !chopped {
+		ret.frames = [][]byte{data}
+		return ret
+	}
+	ret.frames = make([][]byte, len(choppedData))
+	for i, piece := range choppedData {
+		ret.frames[i] = encodeMessage(&PeerMessage{
+			MsgType: MsgTypeMsgChunk,
+			MsgData: piece,
+		}, ts)
+	}
+	return ret
+}
+
+type sendQueue struct {
+	sync.Mutex
+	msgs []*queuedMsg
+	// total size of queued messages in bytes
+	size       int
+	numDropped uint64
+	// signals to the sender loop that queue is not empty
+	signal chan struct{}
+}
+
+func newSendQueue() *sendQueue {
+	return &sendQueue{
+		msgs:   make([]*queuedMsg, 0),
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// push puts the message into the queue, applying the drop policy of the message type if the queue is full
+func (q *sendQueue) push(msg *queuedMsg) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if maxQueueSize > 0 && q.size+msg.size > maxQueueSize {
+		if getDropPolicy(msg.msgType) == DropOldest {
+			q.dropOldest(msg.msgType, msg.size)
+		}
+		if q.size+msg.size > maxQueueSize {
+			q.numDropped++
+			return fmt.Errorf("outgoing queue is full: %d messages, %d bytes", len(q.msgs), q.size)
+		}
+	}
+	q.msgs = append(q.msgs, msg)
+	q.size += msg.size
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dropOldest drops queued messages of the type until there's room for the message of the size
+func (q *sendQueue) dropOldest(msgType byte, size int) {
+	kept := make([]*queuedMsg, 0, len(q.msgs))
+	for _, m := range q.msgs {
+		if m.msgType == msgType && q.size+size > maxQueueSize {
+			q.size -= m.size
+			q.numDropped++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	q.msgs = kept
+}
+
+func (q *sendQueue) takeAll() []*queuedMsg {
+	q.Lock()
+	defer q.Unlock()
+
+	ret := q.msgs
+	q.msgs = make([]*queuedMsg, 0)
+	q.size = 0
+	return ret
+}
+
+func (q *sendQueue) countDropped(n int) {
+	q.Lock()
+	defer q.Unlock()
+	q.numDropped += uint64(n)
+}
+
+// status returns number of queued messages, their size and number of messages dropped so far
+func (q *sendQueue) status() (int, int, uint64) {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.msgs), q.size, q.numDropped
+}
+
+// runSender writes queued messages to the connection with the peer and sends heartbeats
+// until the peer is dismissed
+func (peer *Peer) runSender() {
+	ticker := time.NewTicker(senderCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-peer.queue.signal:
+		case <-ticker.C:
+		}
+		if peer.isDismissed.Load() {
+			peer.queue.countDropped(len(peer.queue.takeAll()))
+			return
+		}
+		peer.writeQueued(peer.queue.takeAll())
+		peer.sendHeartbeat()
+	}
+}
+
+func (peer *Peer) writeQueued(msgs []*queuedMsg) {
+	if len(msgs) == 0 {
+		return
+	}
+	peer.RLock()
+	conn := peer.peerconn
+	handshakeOk := peer.handshakeOk
+	peer.RUnlock()
+
+	if conn == nil || !handshakeOk {
+		// messages were queued for the connection which is closed already
+		peer.queue.countDropped(len(msgs))
+		return
+	}
+	for _, frame := range coalesce(msgs, time.Now().UnixNano()) {
+		num, err := conn.write(frame)
+		if num != len(frame) {
+			log.Debugf("writing to %s failed: not all bytes were written. err = %v", peer.RemoteLocation(), err)
+			return
+		}
+	}
+}
+
+// coalesce returns frames to be written. Consecutive small messages are put together into batch messages
+func coalesce(msgs []*queuedMsg, ts int64) [][]byte {
+	const maxBatchSize = payload.MaxMessageSize - chunkMessageOverhead
+
+	ret := make([][]byte, 0, len(msgs))
+	batch := make([][]byte, 0)
+	batchSize := 0
+	flush := func() {
+		switch len(batch) {
+		case 0:
+		case 1:
+			ret = append(ret, batch[0])
+		default:
+			ret = append(ret, encodeBatch(batch, ts))
+		}
+		batch = make([][]byte, 0)
+		batchSize = 0
+	}
+	for _, msg := range msgs {
+		if len(msg.frames) != 1 || msg.size > maxCoalescedMsgSize {
+			flush()
+			ret = append(ret, msg.frames...)
+			continue
+		}
+		// each message in the batch is prefixed with 4 bytes length
+		if batchSize+msg.size+4 > maxBatchSize {
+			flush()
+		}
+		batch = append(batch, msg.frames[0])
+		batchSize += msg.size + 4
+	}
+	flush()
+	return ret
+}
@@ -0,0 +1,90 @@
+package peering
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testQueuedMsg(msgType byte, size int) *queuedMsg {
+	return newQueuedMsg(&PeerMessage{
+		MsgType: msgType,
+		MsgData: make([]byte, size),
+	}, 1)
+}
+
+func TestSendQueueDropPolicy(t *testing.T) {
+	const msgTypeNewest = FirstCommitteeMsgCode
+	const msgTypeOldest = FirstCommitteeMsgCode + 1
+	SetDropPolicy(msgTypeOldest, DropOldest)
+
+	msg := testQueuedMsg(msgTypeNewest, 100)
+	maxQueueSize = 3 * msg.size
+	defer func() { maxQueueSize = 0 }()
+
+	q := newSendQueue()
+	assert.NoError(t, q.push(testQueuedMsg(msgTypeOldest, 100)))
+	assert.NoError(t, q.push(testQueuedMsg(msgTypeNewest, 100)))
+	assert.NoError(t, q.push(testQueuedMsg(msgTypeOldest, 100)))
+
+	// queue is full: the new message is refused
+	assert.Error(t, q.push(msg))
+	// the oldest message of the same type is dropped
+	last := testQueuedMsg(msgTypeOldest, 100)
+	assert.NoError(t, q.push(last))
+
+	numMsgs, size, numDropped := q.status()
+	assert.Equal(t, 3, numMsgs)
+	assert.Equal(t, 3*msg.size, size)
+	assert.EqualValues(t, 2, numDropped)
+
+	msgs := q.takeAll()
+	assert.Equal(t, msgTypeNewest, msgs[0].msgType)
+	assert.True(t, last == msgs[2])
+}
+
+func TestCoalesce(t *testing.T) {
+	msgs := []*queuedMsg{
+		testQueuedMsg(FirstCommitteeMsgCode, 10),
+		testQueuedMsg(FirstCommitteeMsgCode, 20),
+		testQueuedMsg(FirstCommitteeMsgCode, 2*maxCoalescedMsgSize),
+		testQueuedMsg(FirstCommitteeMsgCode, 30),
+	}
+	frames := coalesce(msgs, 1)
+	assert.Equal(t, 3, len(frames))
+	assert.True(t, bytes.Equal(msgs[2].frames[0], frames[1]))
+	assert.True(t, bytes.Equal(msgs[3].frames[0], frames[2]))
+
+	batch, err := decodeMessage(frames[0])
+	assert.NoError(t, err)
+	assert.Equal(t, MsgTypeBatch, batch.MsgType)
+	inner, err := decodeBatch(batch.MsgData)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(inner))
+	assert.True(t, bytes.Equal(msgs[0].frames[0], inner[0]))
+	assert.True(t, bytes.Equal(msgs[1].frames[0], inner[1]))
+
+	_, err = decodeBatch([]byte{0, 0, 0, 10, 1, 2})
+	assert.Error(t, err)
+}
+
+func TestCompressMessage(t *testing.T) {
+	data := encodeMessage(&PeerMessage{
+		MsgType: FirstCommitteeMsgCode,
+		MsgData: bytes.Repeat([]byte("state update "), 1000),
+	}, 1)
+	assert.True(t, bytes.Equal(data, compressMessage(data, 1)))
+
+	compressionThreshold = 1024
+	defer func() { compressionThreshold = 0 }()
+
+	compressed := compressMessage(data, 1)
+	assert.True(t, len(compressed) < len(data))
+	msg, err := decodeMessage(compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, MsgTypeCompressed, msg.MsgType)
+	back, err := decompressMessage(msg.MsgData)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(data, back))
+}
@@ -1,20 +1,33 @@
 package peering
 
+import "time"
+
 type Status struct {
 	MyNetworkId string
+	MyPubKey    string
 	Peers       []*PeerStatus
 }
 
 type PeerStatus struct {
 	RemoteLocation string
+	PubKey         string
 	IsInbound      bool
 	IsAlive        bool
 	NumUsers       int
+	// outgoing queue: number of messages, their size in bytes and number of messages dropped so far
+	QueueLen   int
+	QueueBytes int
+	NumDropped uint64
+	// average round trip time and estimated peer's clock minus local clock, measured by heartbeats.
+	// Zero if not measured yet
+	Latency     time.Duration
+	ClockOffset time.Duration
 }
 
 func GetStatus() *Status {
 	return &Status{
 		MyNetworkId: MyNetworkId(),
+		MyPubKey:    MyPubKey(),
 		Peers:       getPeerStatus(),
 	}
 }
@@ -22,11 +35,19 @@ func GetStatus() *Status {
 func getPeerStatus() []*PeerStatus {
 	r := make([]*PeerStatus, 0)
 	iteratePeers(func(peer *Peer) {
+		queueLen, queueBytes, numDropped := peer.queue.status()
+		latency, clockOffset, _ := peer.heartbeats.stats()
 		r = append(r, &PeerStatus{
-			RemoteLocation: peer.remoteLocation,
+			RemoteLocation: peer.RemoteLocation(),
+			PubKey:         peer.PubKey(),
 			IsInbound:      peer.isInbound(),
 			IsAlive:        peer.IsAlive(),
 			NumUsers:       peer.NumUsers(),
+			QueueLen:       queueLen,
+			QueueBytes:     queueBytes,
+			NumDropped:     numDropped,
+			Latency:        latency,
+			ClockOffset:    clockOffset,
 		})
 	})
 	return r
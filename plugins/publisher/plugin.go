@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/wasp/packages/parameters"
@@ -22,6 +23,13 @@ var (
 	messages = make(chan []byte, 100)
 )
 
+// EventPublished is triggered with the type and parts of each published message.
+// Lets other plugins of the node follow the events without the nanomsg subscription.
+// Handlers are called in the goroutine of the publisher of the message and must not block
+var EventPublished = events.NewEvent(func(handler interface{}, params ...interface{}) {
+	handler.(func(msgType string, parts []string))(params[0].(string), params[1].([]string))
+})
+
 func Init() *node.Plugin {
 	return node.NewPlugin(PluginName, node.Enabled, configure, run)
 }
@@ -79,6 +87,8 @@ func openSocket(port int) error {
 }
 
 func Publish(msgType string, parts ...string) {
+	EventPublished.Trigger(msgType, parts)
+
 	msg := msgType
 	for _, s := range parts {
 		msg = msg + " " + s
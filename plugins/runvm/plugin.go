@@ -77,6 +77,7 @@ func RunComputationsAsync(ctx *vm.VMTask) error {
 
 // runs batch
 func runTask(ctx *vm.VMTask, txb *txbuilder.Builder, shutdownSignal <-chan struct{}) {
+	started := time.Now()
 	ctx.Log.Debugw("runTask IN",
 		"addr", ctx.Address.String(),
 		"finalTimestamp", ctx.Timestamp,
@@ -163,6 +164,7 @@ func runTask(ctx *vm.VMTask, txb *txbuilder.Builder, shutdownSignal <-chan struc
 		"result essence hash", hashing.HashData(ctx.ResultTransaction.EssenceBytes()).String(),
 		"result tx finalTimestamp", time.Unix(0, ctx.ResultTransaction.MustState().Timestamp()),
 	)
+	ctx.RunTime = time.Since(started)
 	// call back
 	ctx.OnFinish(nil)
 }
@@ -17,25 +17,49 @@ type BootupDataJsonable struct {
 	Color          string   `json:"color"`
 	CommitteeNodes []string `json:"committee_nodes"`
 	AccessNodes    []string `json:"access_nodes"`
-	Active         bool     `json:"active"`
+	// base58 encoded identity public keys, same order as nodes
+	CommitteePubKeys []string `json:"committee_pub_keys"`
+	AccessPubKeys    []string `json:"access_pub_keys"`
+	Active           bool     `json:"active"`
 }
 
 func HandlerPutSCData(c echo.Context) error {
 	var req BootupDataJsonable
-	var err error
 
 	if err := c.Bind(&req); err != nil {
 		return misc.OkJsonErr(c, err)
 	}
+	rec, err := req.BootupData()
+	if err != nil {
+		return misc.OkJsonErr(c, err)
+	}
+	bd, err := registry.GetBootupData(&rec.Address)
+	if err != nil {
+		return misc.OkJsonErr(c, err)
+	}
+	if bd != nil {
+		return misc.OkJsonErr(c, fmt.Errorf("Bootup data already exists"))
+	}
+	if err = registry.SaveBootupData(rec); err != nil {
+		return misc.OkJsonErr(c, err)
+	}
+
+	log.Infof("Bootup record saved for addr: %s color: %s", rec.Address.String(), rec.Color.String())
+
+	return misc.OkJsonErr(c, nil)
+}
 
-	rec := registry.BootupData{}
+// BootupData makes the registry record from the JSON representation
+func (req *BootupDataJsonable) BootupData() (*registry.BootupData, error) {
+	var err error
+	rec := &registry.BootupData{}
 
 	if rec.Address, err = address.FromBase58(req.Address); err != nil {
-		return misc.OkJsonErr(c, err)
+		return nil, err
 	}
 
 	if rec.Color, err = util.ColorFromString(req.Color); err != nil {
-		return misc.OkJsonErr(c, err)
+		return nil, err
 	}
 
 	if rec.OwnerAddress, err = address.FromBase58(req.OwnerAddress); err != nil {
@@ -46,22 +70,24 @@ func HandlerPutSCData(c echo.Context) error {
 
 	rec.CommitteeNodes = req.CommitteeNodes
 	rec.AccessNodes = req.AccessNodes
+	rec.CommitteePubKeys = req.CommitteePubKeys
+	rec.AccessPubKeys = req.AccessPubKeys
 	rec.Active = req.Active
+	return rec, nil
+}
 
-	bd, err := registry.GetBootupData(&rec.Address)
-	if err != nil {
-		return misc.OkJsonErr(c, err)
+// NewBootupDataJsonable makes the JSON representation of the registry record
+func NewBootupDataJsonable(bd *registry.BootupData) *BootupDataJsonable {
+	return &BootupDataJsonable{
+		Address:          bd.Address.String(),
+		OwnerAddress:     bd.OwnerAddress.String(),
+		Color:            base58.Encode(bd.Color.Bytes()),
+		CommitteeNodes:   bd.CommitteeNodes,
+		AccessNodes:      bd.AccessNodes,
+		CommitteePubKeys: bd.CommitteePubKeys,
+		AccessPubKeys:    bd.AccessPubKeys,
+		Active:           bd.Active,
 	}
-	if bd != nil {
-		return misc.OkJsonErr(c, fmt.Errorf("Bootup data already exists"))
-	}
-	if err = registry.SaveBootupData(&rec); err != nil {
-		return misc.OkJsonErr(c, err)
-	}
-
-	log.Infof("Bootup record saved for addr: %s color: %s", rec.Address.String(), rec.Color.String())
-
-	return misc.OkJsonErr(c, nil)
 }
 
 type GetBootupDataRequest struct {
@@ -97,15 +123,8 @@ func HandlerGetSCData(c echo.Context) error {
 		return misc.OkJson(c, &GetBootupDataResponse{Exists: false})
 	}
 	return misc.OkJson(c, &GetBootupDataResponse{
-		BootupDataJsonable: BootupDataJsonable{
-			Address:        bd.Address.String(),
-			OwnerAddress:   bd.OwnerAddress.String(),
-			Color:          base58.Encode(bd.Color.Bytes()),
-			CommitteeNodes: bd.CommitteeNodes,
-			AccessNodes:    bd.AccessNodes,
-			Active:         bd.Active,
-		},
-		Exists: true,
+		BootupDataJsonable: *NewBootupDataJsonable(bd),
+		Exists:             true,
 	})
 }
 
@@ -0,0 +1,120 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/webapi/limits"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+)
+
+type ConsensusStatsResponse struct {
+	*committee.ConsensusStats
+	Error string `json:"err"`
+}
+
+func HandlerConsensusStats(c echo.Context) error {
+	scAddress, err := address.FromBase58(c.Param("scaddress"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &ConsensusStatsResponse{Error: err.Error()})
+	}
+	cmt := committees.CommitteeByAddress(scAddress)
+	if cmt == nil {
+		return c.JSON(http.StatusNotFound, &ConsensusStatsResponse{Error: "committee is not active"})
+	}
+	stats := cmt.ConsensusStats()
+	if stats == nil {
+		return c.JSON(http.StatusNotFound, &ConsensusStatsResponse{Error: "node does not run consensus for the smart contract"})
+	}
+	return misc.OkJson(c, &ConsensusStatsResponse{ConsensusStats: stats})
+}
+
+// HandlerMetrics renders consensus metrics of all active committees, peering metrics and counters of requests
+// rejected by limits of the web API in Prometheus text format
+func HandlerMetrics(c echo.Context) error {
+	brs, err := registry.GetBootupRecords()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	all := make(map[string]*committee.ConsensusMetrics)
+	for _, br := range brs {
+		cmt := committees.CommitteeByAddress(br.Address)
+		if cmt == nil {
+			continue
+		}
+		if stats := cmt.ConsensusStats(); stats != nil {
+			all[br.Address.String()] = &stats.Metrics
+		}
+	}
+	var sb strings.Builder
+	writeMetric(&sb, all, "wasp_consensus_state_index", "gauge", "index of the current state",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.StateIndex) })
+	writeMetric(&sb, all, "wasp_consensus_rounds_total", "counter", "number of consensus rounds",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.Rounds) })
+	writeMetric(&sb, all, "wasp_consensus_leader_rotations_total", "counter", "number of leader rotations",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.LeaderRotations) })
+	writeMetric(&sb, all, "wasp_consensus_batches_started_total", "counter", "number of batches started",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.BatchesStarted) })
+	writeMetric(&sb, all, "wasp_consensus_batch_requests_total", "counter", "number of requests in started batches",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.RequestsInBatches) })
+	writeMetric(&sb, all, "wasp_consensus_vm_run_seconds_total", "counter", "VM run time",
+		func(m *committee.ConsensusMetrics) float64 { return m.VMRunTime.Seconds() })
+	writeMetric(&sb, all, "wasp_consensus_results_posted_total", "counter", "number of result transactions posted",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.ResultsPosted) })
+	writeMetric(&sb, all, "wasp_consensus_results_confirmed_total", "counter", "number of posted results confirmed",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.ResultsConfirmed) })
+	writeMetric(&sb, all, "wasp_consensus_inclusion_latency_seconds", "gauge", "inclusion latency of the last confirmed result",
+		func(m *committee.ConsensusMetrics) float64 { return m.LastInclusionLatency.Seconds() })
+	writeMetric(&sb, all, "wasp_consensus_sigshare_faults_total", "counter", "number of invalid signature shares received by the leader",
+		func(m *committee.ConsensusMetrics) float64 { return float64(m.SigShareFaults) })
+
+	peers := peering.GetStatus().Peers
+	writePeerMetric(&sb, peers, "wasp_peering_queue_messages", "gauge", "number of messages in the outgoing queue of the peer",
+		func(p *peering.PeerStatus) float64 { return float64(p.QueueLen) })
+	writePeerMetric(&sb, peers, "wasp_peering_queue_bytes", "gauge", "size of messages in the outgoing queue of the peer",
+		func(p *peering.PeerStatus) float64 { return float64(p.QueueBytes) })
+	writePeerMetric(&sb, peers, "wasp_peering_dropped_total", "counter", "number of outgoing messages dropped",
+		func(p *peering.PeerStatus) float64 { return float64(p.NumDropped) })
+	writePeerMetric(&sb, peers, "wasp_peering_latency_seconds", "gauge", "round trip time to the peer",
+		func(p *peering.PeerStatus) float64 { return p.Latency.Seconds() })
+	writePeerMetric(&sb, peers, "wasp_peering_clock_offset_seconds", "gauge", "clock of the peer minus local clock",
+		func(p *peering.PeerStatus) float64 { return p.ClockOffset.Seconds() })
+
+	lm := limits.GetMetrics()
+	writeCounter(&sb, "wasp_webapi_rate_limited_total", "number of requests rejected by rate limits", lm.RateLimited)
+	writeCounter(&sb, "wasp_webapi_body_too_large_total", "number of requests rejected because of the size of the body", lm.BodyTooLarge)
+	writeCounter(&sb, "wasp_webapi_query_rejected_total", "number of state queries rejected by limits of keys and elements", lm.QueryRejected)
+	writeCounter(&sb, "wasp_webapi_query_timeouts_total", "number of state queries aborted by the timeout", lm.QueryTimeouts)
+	writeCounter(&sb, "wasp_webapi_streams_rejected_total", "number of request streams rejected by limits of open streams", lm.StreamsRejected)
+
+	return c.String(http.StatusOK, sb.String())
+}
+
+func writeCounter(sb *strings.Builder, name, help string, value uint64) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s counter\n", name)
+	fmt.Fprintf(sb, "%s %d\n", name, value)
+}
+
+func writeMetric(sb *strings.Builder, all map[string]*committee.ConsensusMetrics, name, typ, help string, value func(*committee.ConsensusMetrics) float64) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s %s\n", name, typ)
+	for addr, m := range all {
+		fmt.Fprintf(sb, "%s{sc=%q} %g\n", name, addr, value(m))
+	}
+}
+
+func writePeerMetric(sb *strings.Builder, peers []*peering.PeerStatus, name, typ, help string, value func(*peering.PeerStatus) float64) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s %s\n", name, typ)
+	for _, p := range peers {
+		fmt.Fprintf(sb, "%s{peer=%q} %g\n", name, p.PubKey, value(p))
+	}
+}
@@ -0,0 +1,97 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/plugins/banner"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/iotaledger/wasp/plugins/nodeconn"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/labstack/echo"
+)
+
+var startTime = time.Now()
+
+type NodeInfo struct {
+	Version string `json:"version"`
+	NetID   string `json:"netid"`
+	PubKey  string `json:"pubkey"` // base58
+	// address of the Goshimmer node and whether the node is connected to it
+	NodeConnAddress   string `json:"nodeconn_address"`
+	NodeConnConnected bool   `json:"nodeconn_connected"`
+	// number of committees of active smart contracts and how many of them have the quorum of peers connected
+	Committees           int       `json:"committees"`
+	CommitteesWithQuorum int       `json:"committees_with_quorum"`
+	Ready                bool      `json:"ready"`
+	StartTime            time.Time `json:"start_time"`
+}
+
+// HealthStatus is the response of health checks. Problems are empty if the check passes
+type HealthStatus struct {
+	Status   string   `json:"status"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+const (
+	HealthStatusOk          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+func GetNodeInfo() *NodeInfo {
+	ret := &NodeInfo{
+		Version:           banner.AppVersion,
+		NetID:             peering.MyNetworkId(),
+		PubKey:            peering.MyPubKey(),
+		NodeConnAddress:   parameters.GetString(parameters.NodeAddress),
+		NodeConnConnected: nodeconn.IsConnected(),
+		StartTime:         startTime,
+	}
+	for _, c := range committees.ActiveCommittees() {
+		ret.Committees++
+		if c.HasQuorum() {
+			ret.CommitteesWithQuorum++
+		}
+	}
+	ret.Ready = len(ReadinessProblems()) == 0
+	return ret
+}
+
+// ReadinessProblems returns reasons why the node is not ready to serve requests: the database is not available,
+// the node is not connected to Goshimmer or some of active committees have no quorum of peers connected
+func ReadinessProblems() []string {
+	ret := make([]string, 0)
+	if err := database.CheckHealth(); err != nil {
+		ret = append(ret, fmt.Sprintf("database: %v", err))
+	}
+	if !nodeconn.IsConnected() {
+		ret = append(ret, fmt.Sprintf("not connected to the node %s", parameters.GetString(parameters.NodeAddress)))
+	}
+	for _, c := range committees.ActiveCommittees() {
+		if !c.HasQuorum() {
+			ret = append(ret, fmt.Sprintf("committee %s: no quorum of peers", c.Address().String()))
+		}
+	}
+	return ret
+}
+
+func HandlerNodeInfo(c echo.Context) error {
+	return c.JSON(http.StatusOK, GetNodeInfo())
+}
+
+// HandlerLive responds 200 while the node is running
+func HandlerLive(c echo.Context) error {
+	return c.JSON(http.StatusOK, &HealthStatus{Status: HealthStatusOk})
+}
+
+// HandlerReady responds 200 if the node is ready, otherwise 503 with the list of problems
+func HandlerReady(c echo.Context) error {
+	problems := ReadinessProblems()
+	if len(problems) > 0 {
+		return c.JSON(http.StatusServiceUnavailable, &HealthStatus{Status: HealthStatusUnavailable, Problems: problems})
+	}
+	return c.JSON(http.StatusOK, &HealthStatus{Status: HealthStatusOk})
+}
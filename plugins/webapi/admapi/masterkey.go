@@ -0,0 +1,29 @@
+package admapi
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+)
+
+// RotateMasterKeyRequest specifies the new master key: either the passphrase or
+// the path of the new key file, which is generated by the node
+type RotateMasterKeyRequest struct {
+	Passphrase string `json:"passphrase"`
+	KeyFile    string `json:"key_file"`
+}
+
+// HandlerRotateMasterKey re-encrypts secrets in the registry with the new master key.
+// The node configuration must be updated with the new passphrase or key file before the next start
+func HandlerRotateMasterKey(c echo.Context) error {
+	var req RotateMasterKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &misc.SimpleResponse{Error: err.Error()})
+	}
+	if err := registry.RotateMasterKey(req.Passphrase, req.KeyFile); err != nil {
+		return c.JSON(http.StatusBadRequest, &misc.SimpleResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &misc.SimpleResponse{})
+}
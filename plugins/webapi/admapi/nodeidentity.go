@@ -0,0 +1,22 @@
+package admapi
+
+import (
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+)
+
+type NodeIdentityResponse struct {
+	NetID  string `json:"netid"`
+	PubKey string `json:"pubkey"`
+	Error  string `json:"err"`
+}
+
+// HandlerNodeIdentity returns the network location and the identity public key of the node.
+// The public key is needed to include the node into the bootup data of smart contracts
+func HandlerNodeIdentity(c echo.Context) error {
+	return misc.OkJson(c, &NodeIdentityResponse{
+		NetID:  peering.MyNetworkId(),
+		PubKey: peering.MyPubKey(),
+	})
+}
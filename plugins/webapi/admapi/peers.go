@@ -0,0 +1,49 @@
+package admapi
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+)
+
+type PeerAddressBookResponse struct {
+	AddressBook []*registry.PeerAddress `json:"addressBook"`
+	Peers       []*peering.PeerStatus   `json:"peers"`
+	Error       string                  `json:"err"`
+}
+
+// HandlerGetPeers returns the address book of the node and the status of its current peers
+func HandlerGetPeers(c echo.Context) error {
+	book, err := registry.GetPeerAddresses()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &PeerAddressBookResponse{Error: err.Error()})
+	}
+	return misc.OkJson(c, &PeerAddressBookResponse{
+		AddressBook: book,
+		Peers:       peering.GetStatus().Peers,
+	})
+}
+
+// HandlerPutPeer sets the network location of the node with the identity in the address book.
+// The location takes effect immediately
+func HandlerPutPeer(c echo.Context) error {
+	var req registry.PeerAddress
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &misc.SimpleResponse{Error: err.Error()})
+	}
+	if err := peering.SetPeerAddress(req.PubKey, req.NetID); err != nil {
+		return c.JSON(http.StatusBadRequest, &misc.SimpleResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &misc.SimpleResponse{})
+}
+
+// HandlerDeletePeer removes the node with the identity from the address book
+func HandlerDeletePeer(c echo.Context) error {
+	if err := peering.DeletePeerAddress(c.Param("pubkey")); err != nil {
+		return c.JSON(http.StatusInternalServerError, &misc.SimpleResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &misc.SimpleResponse{})
+}
@@ -1,10 +1,16 @@
 package admapi
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/stateschema"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
 	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/labstack/echo"
 )
@@ -12,6 +18,8 @@ import (
 type ProgramMetadata struct {
 	VMType      string `json:"vm_type"`
 	Description string `json:"description"`
+	// optional schema of the state of smart contracts running the program
+	StateSchema *stateschema.Schema `json:"state_schema,omitempty"`
 }
 
 type PutProgramRequest struct {
@@ -27,41 +35,172 @@ type PutProgramResponse struct {
 //----------------------------------------------------------
 func HandlerPutProgram(c echo.Context) error {
 	var req PutProgramRequest
-	var err error
 
 	if err := c.Bind(&req); err != nil {
 		return c.JSONPretty(http.StatusBadRequest, &PutProgramResponse{Error: err.Error()}, " ")
 	}
+	progHash, err := SaveProgram(&req)
+	if err != nil {
+		code, msg := misc.HttpError(err)
+		return c.JSONPretty(code, &PutProgramResponse{Error: msg}, " ")
+	}
+	return misc.OkJson(c, &PutProgramResponse{ProgramHash: progHash.String()})
+}
 
+// SaveProgram checks the request and saves code and metadata of the program in the registry.
+// Errors are *echo.HTTPError
+func SaveProgram(req *PutProgramRequest) (*hashing.HashValue, error) {
 	if req.VMType == "" {
-		return c.JSONPretty(http.StatusBadRequest, &PutProgramResponse{Error: "vm_type is required"}, " ")
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "vm_type is required")
 	}
 	if req.Description == "" {
-		return c.JSONPretty(http.StatusBadRequest, &PutProgramResponse{Error: "description is required"}, " ")
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "description is required")
 	}
 	if req.Code == nil || len(req.Code) == 0 {
-		return c.JSONPretty(http.StatusBadRequest, &PutProgramResponse{Error: "code is required (base64-encoded binary data)"}, " ")
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "code is required (base64-encoded binary data)")
+	}
+
+	if req.StateSchema != nil {
+		if err := req.StateSchema.Validate(); err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid state_schema: %v", err))
+		}
 	}
 
 	progHash, err := registry.SaveProgramCode(req.Code)
 	if err != nil {
-		return c.JSONPretty(http.StatusInternalServerError, &PutProgramResponse{Error: err.Error()}, " ")
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	md := &registry.ProgramMetadata{
 		ProgramHash: progHash,
 		VMType:      req.VMType,
 		Description: req.Description,
+		StateSchema: req.StateSchema,
 	}
 
-	// TODO it is always overwritten!
-	if err = md.Save(); err != nil {
-		return c.JSONPretty(http.StatusInternalServerError, &PutProgramResponse{Error: err.Error()}, " ")
+	// metadata of the program used by smart contracts can't be changed. Smart contracts are not added
+	// between the check and saving of metadata
+	err = registry.WithBootupDataLocked(func() error {
+		old, err := registry.GetProgramMetadata(&progHash)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if old != nil && (old.VMType != md.VMType || old.Description != md.Description || !reflect.DeepEqual(old.StateSchema, md.StateSchema)) {
+			users, unknown, err := ProgramUsers(&progHash)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+			if len(users) > 0 {
+				return echo.NewHTTPError(http.StatusConflict,
+					fmt.Sprintf("metadata of the program %s is immutable: it is used by %d smart contract(s)", progHash.String(), len(users)))
+			}
+			if len(unknown) > 0 {
+				return echo.NewHTTPError(http.StatusConflict,
+					fmt.Sprintf("metadata of the program %s can't be changed: program of %d smart contract(s) is not known yet", progHash.String(), len(unknown)))
+			}
+		}
+		if err := md.Save(); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Infof("Program metadata record has been saved. Program hash: %s, description: %s",
 		md.ProgramHash.String(), md.Description)
-	return misc.OkJson(c, &PutProgramResponse{ProgramHash: progHash.String()})
+	return &progHash, nil
+}
+
+// DeleteProgram removes the program from the registry unless it is builtin, pinned or used by
+// smart contracts of the node. Errors are *echo.HTTPError
+func DeleteProgram(progHash *hashing.HashValue) error {
+	if registry.IsBuiltinProgram(progHash) {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("program %s is builtin", progHash.String()))
+	}
+	md, err := registry.GetProgramMetadata(progHash)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	codeHash, _, err := registry.GetProgramCodeHash(progHash)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if md == nil && codeHash == nil {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("program not found. Hash: %s", progHash.String()))
+	}
+	pinned, err := registry.IsProgramPinned(progHash)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if pinned {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("program %s is pinned", progHash.String()))
+	}
+	// smart contracts are not added between the check and the deletion
+	err = registry.WithBootupDataLocked(func() error {
+		users, unknown, err := ProgramUsers(progHash)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if len(users) > 0 {
+			return echo.NewHTTPError(http.StatusConflict,
+				fmt.Sprintf("program %s is used by smart contract %s", progHash.String(), users[0].String()))
+		}
+		if len(unknown) > 0 {
+			return echo.NewHTTPError(http.StatusConflict,
+				fmt.Sprintf("program %s may be used by smart contract %s: its state is not solid yet", progHash.String(), unknown[0].String()))
+		}
+		if err := registry.DeleteProgram(progHash); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Program has been deleted. Program hash: %s", progHash.String())
+	return nil
+}
+
+// ProgramUsers returns addresses of smart contracts which run the program and of smart contracts
+// which program is not known yet, see ProgramUsersByHash
+func ProgramUsers(progHash *hashing.HashValue) ([]address.Address, []address.Address, error) {
+	users, unknown, err := ProgramUsersByHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	return users[*progHash], unknown, nil
+}
+
+// ProgramUsersByHash returns addresses of smart contracts of the node, active or not, by the hash of the program
+// they run. The program hash is taken from the solid state. Smart contracts without the solid state,
+// which may run any program, are returned separately
+func ProgramUsersByHash() (map[hashing.HashValue][]address.Address, []address.Address, error) {
+	bds, err := registry.GetBootupRecords()
+	if err != nil {
+		return nil, nil, err
+	}
+	ret := make(map[hashing.HashValue][]address.Address)
+	unknown := make([]address.Address, 0)
+	for _, bd := range bds {
+		vs, _, ok, err := state.LoadSolidState(&bd.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			unknown = append(unknown, bd.Address)
+			continue
+		}
+		progHash, ok, err := vs.Variables().Codec().GetHashValue(vmconst.VarNameProgramHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			ret[*progHash] = append(ret[*progHash], bd.Address)
+		}
+	}
+	return ret, unknown, nil
 }
 
 type GetProgramMetadataResponse struct {
@@ -87,6 +226,7 @@ func HandlerGetProgramMetadata(c echo.Context) error {
 		ProgramMetadata: ProgramMetadata{
 			VMType:      md.VMType,
 			Description: md.Description,
+			StateSchema: md.StateSchema,
 		},
 	})
 }
@@ -0,0 +1,152 @@
+package admapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	log = logger.NewNopLogger()
+	database.InitMemDB()
+}
+
+func saveTestProgram(t *testing.T, description string) *hashing.HashValue {
+	progHash, err := SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: description},
+		Code:            []byte("code of " + description),
+	})
+	assert.NoError(t, err)
+	return progHash
+}
+
+// addSC saves bootup data of the smart contract. The solid state with the program hash is committed
+// if the hash is not nil, otherwise the state of the smart contract is not known
+func addSC(t *testing.T, progHash *hashing.HashValue) {
+	addr := address.Random()
+	if progHash != nil {
+		su := state.NewStateUpdate(nil)
+		su.Mutations().Add(kv.NewMutationSet(vmconst.VarNameProgramHash, progHash[:]))
+		batch, err := state.NewBatch([]state.StateUpdate{su})
+		assert.NoError(t, err)
+		vs := state.NewVirtualState(database.GetPartition(&addr), &addr)
+		assert.NoError(t, vs.ApplyBatch(batch))
+		assert.NoError(t, vs.CommitToDb(batch))
+	}
+	assert.NoError(t, registry.SaveBootupData(&registry.BootupData{
+		Address: addr,
+		Color:   balance.Color(*hashing.RandomHash(nil)),
+	}))
+}
+
+func assertHTTPError(t *testing.T, code int, err error) {
+	if assert.IsType(t, &echo.HTTPError{}, err) {
+		assert.Equal(t, code, err.(*echo.HTTPError).Code)
+	}
+}
+
+func assertProgramExists(t *testing.T, progHash *hashing.HashValue, exists bool) {
+	md, err := registry.GetProgramMetadata(progHash)
+	assert.NoError(t, err)
+	assert.Equal(t, exists, md != nil)
+	codeHash, _, err := registry.GetProgramCodeHash(progHash)
+	assert.NoError(t, err)
+	assert.Equal(t, exists, codeHash != nil)
+}
+
+// the registry is shared by tests, smart contracts added by one test are seen by the following ones.
+// So all rules are checked in one test, before and after smart contracts are added
+func TestProgramRules(t *testing.T) {
+	builtin := hashing.HashStrings("builtin program")
+	registry.RegisterBuiltinProgramMetadata(builtin, "builtin program", nil)
+	assertHTTPError(t, http.StatusConflict, DeleteProgram(builtin))
+	_, err := SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: "invalid"},
+	})
+	assertHTTPError(t, http.StatusBadRequest, err)
+
+	assertHTTPError(t, http.StatusNotFound, DeleteProgram(hashing.RandomHash(nil)))
+
+	// pinned program
+	pinned := saveTestProgram(t, "pinned")
+	assert.NoError(t, registry.PinProgram(pinned))
+	assertHTTPError(t, http.StatusConflict, DeleteProgram(pinned))
+	assertProgramExists(t, pinned, true)
+	assert.NoError(t, registry.UnpinProgram(pinned))
+	assert.NoError(t, DeleteProgram(pinned))
+	assertProgramExists(t, pinned, false)
+
+	// metadata of the unused program can be changed
+	used := saveTestProgram(t, "used")
+	unused := saveTestProgram(t, "unused")
+	_, err = SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: "changed"},
+		Code:            []byte("code of used"),
+	})
+	assert.NoError(t, err)
+
+	// the program used by the smart contract
+	addSC(t, used)
+	assertHTTPError(t, http.StatusConflict, DeleteProgram(used))
+	assertProgramExists(t, used, true)
+	_, err = SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: "changed again"},
+		Code:            []byte("code of used"),
+	})
+	assertHTTPError(t, http.StatusConflict, err)
+	// the same metadata is accepted
+	_, err = SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: "changed"},
+		Code:            []byte("code of used"),
+	})
+	assert.NoError(t, err)
+
+	// the smart contract without the solid state may run any program
+	other := saveTestProgram(t, "other")
+	addSC(t, nil)
+	assertHTTPError(t, http.StatusConflict, DeleteProgram(unused))
+	assertProgramExists(t, unused, true)
+	_, err = SaveProgram(&PutProgramRequest{
+		ProgramMetadata: ProgramMetadata{VMType: "wasmtimevm", Description: "changed"},
+		Code:            []byte("code of other"),
+	})
+	assertHTTPError(t, http.StatusConflict, err)
+	md, err := registry.GetProgramMetadata(other)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", md.Description)
+}
+
+// the program is not deleted while the smart contract is being added: the check of users and the deletion
+// wait until bootup data is saved
+func TestDeleteProgramLocked(t *testing.T) {
+	progHash := saveTestProgram(t, "locked")
+
+	deleted := make(chan error, 1)
+	err := registry.WithBootupDataLocked(func() error {
+		go func() {
+			deleted <- DeleteProgram(progHash)
+		}()
+		select {
+		case err := <-deleted:
+			t.Errorf("program deleted while bootup data is locked")
+			deleted <- err
+		case <-time.After(100 * time.Millisecond):
+		}
+		assertProgramExists(t, progHash, true)
+		return nil
+	})
+	assert.NoError(t, err)
+	<-deleted
+}
@@ -3,17 +3,24 @@ package dkgapi
 import (
 	"bytes"
 	"fmt"
-	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"net/http"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/labstack/echo"
 	"github.com/mr-tron/base58"
 )
 
+// DKShares are exported sealed for the identity of the recipient node (see /adm/nodeidentity),
+// so only the recipient is able to import them. The sealed data is serialized with tcrypto.MarshalCommitteeKeys.
+// With the remote signer the node doesn't open the imported key share: the signer keeps it and returns the public part
+
 type ExportDKShareRequest struct {
-	Address string `json:"address"` //base58
+	Address         string `json:"address"`          //base58
+	RecipientPubKey string `json:"recipient_pubkey"` //base58
 }
 
 type ExportDKShareResponse struct {
@@ -39,27 +46,50 @@ func HandlerExportDKShare(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, &ExportDKShareResponse{Err: err.Error()})
 	}
-	dkshare, exist, err := registry.GetDKShare(&addr)
+	sealed, err := ExportDKShare(&addr, req.RecipientPubKey)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, &ExportDKShareResponse{Err: err.Error()})
+		_, msg := misc.HttpError(err)
+		return c.JSON(http.StatusBadRequest, &ExportDKShareResponse{Err: msg})
+	}
+	return c.JSON(http.StatusOK, &ExportDKShareResponse{DKShare: sealed})
+}
+
+// ExportDKShare returns the key share with the address sealed for the identity of the recipient node,
+// base58 encoded. Errors are *echo.HTTPError
+func ExportDKShare(addr *address.Address, recipientPubKey string) (string, error) {
+	recipient, err := peering.PubKeyFromBase58(recipientPubKey)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("recipient public key: %v", err))
+	}
+	dkshare, exist, err := registry.GetDKShare(addr)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	if !exist {
-		return c.JSON(http.StatusBadRequest, &ExportDKShareResponse{Err: "dkshare not found"})
+		return "", echo.NewHTTPError(http.StatusNotFound, "dkshare not found")
+	}
+	if !dkshare.HasPrivateKey() {
+		return "", echo.NewHTTPError(http.StatusConflict, "dkshare is kept by the remote signer")
 	}
-	blob, err := exportDKShare(dkshare)
+	data, err := tcrypto.MarshalCommitteeKeys(dkshare)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, &ExportDKShareResponse{Err: err.Error()})
+		return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	sealed, err := tcrypto.SealForIdentity(recipient, data)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(http.StatusOK, &ExportDKShareResponse{DKShare: blob})
+	log.Infof("DKShare with address %s exported for %s", addr.String(), recipientPubKey)
+	return base58.Encode(sealed), nil
 }
 
-func exportDKShare(dkshare *tcrypto.DKShare) (string, error) {
+func dkshareBytes(dkshare *tcrypto.DKShare) ([]byte, error) {
 	var buf bytes.Buffer
 	err := dkshare.Write(&buf)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return base58.Encode(buf.Bytes()), nil
+	return buf.Bytes(), nil
 }
 
 func HandlerImportDKShare(c echo.Context) error {
@@ -67,33 +97,44 @@ func HandlerImportDKShare(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, &ImportDKShareResponse{Err: err.Error()})
 	}
-	err := importDKShare(req.Blob)
+	err := ImportDKShare(req.Blob)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, &ImportDKShareResponse{Err: err.Error()})
 	}
 	return c.JSON(http.StatusOK, &ImportDKShareResponse{})
 }
 
-func importDKShare(blob string) error {
-	data, err := base58.Decode(blob)
+// ImportDKShare hands the key share sealed for the identity of the node over to the signer and saves it in the registry.
+// Importing the same key share again is a no-op
+func ImportDKShare(blob string) error {
+	sealed, err := base58.Decode(blob)
 	if err != nil {
 		return err
 	}
-	dks, err := tcrypto.UnmarshalDKShare(data, false)
+	ks, err := peering.ImportKeys(sealed)
 	if err != nil {
 		return err
 	}
+	dks, ok := ks.(*tcrypto.DKShare)
+	if !ok {
+		return fmt.Errorf("keys of scheme %s can't be imported", ks.Scheme())
+	}
 
 	oldDks, exists, err := registry.GetDKShare(dks.Address)
 	if err != nil {
 		return err
 	}
 	if exists {
-		oldBlob, err := exportDKShare(oldDks)
+		// the saved key share may be only the public part, if the private key is kept by the remote signer
+		oldData, err := dkshareBytes(oldDks.PublicPart())
+		if err != nil {
+			return err
+		}
+		newData, err := dkshareBytes(dks.PublicPart())
 		if err != nil {
 			return err
 		}
-		if oldBlob != blob {
+		if !bytes.Equal(oldData, newData) {
 			return fmt.Errorf("A different DKShare exists with same address %s", dks.Address)
 		}
 		log.Debugf("DKShare with address %s already imported", dks.Address)
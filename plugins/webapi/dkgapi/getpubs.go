@@ -1,17 +1,21 @@
 package dkgapi
 
 import (
+	"net/http"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/labstack/echo"
 	"github.com/mr-tron/base58"
+	"go.dedis.ch/kyber/v3"
 )
 
 // The POST handler implements 'adm/getpubs' API
 // Parameters(see GetPubKeyInfoRequest struct):
 //     Addresses:   address of the DKShare
-// API responds with public info of DKShare:
+// API responds with public info of committee keys of any scheme:
 
 func HandlerGetKeyPubInfo(c echo.Context) error {
 	var req GetPubKeyInfoRequest
@@ -30,6 +34,7 @@ type GetPubKeyInfoRequest struct {
 
 type GetPubKeyInfoResponse struct {
 	Address      string   `json:"address"` //base58
+	Scheme       string   `json:"scheme"`  // "threshold" or "multisig"
 	N            uint16   `json:"n"`
 	T            uint16   `json:"t"`
 	Index        uint16   `json:"index"`
@@ -43,34 +48,53 @@ func GetKeyPubInfoReq(req *GetPubKeyInfoRequest) *GetPubKeyInfoResponse {
 	if err != nil {
 		return &GetPubKeyInfoResponse{Err: err.Error()}
 	}
-	log.Debugw("GetDKShare", "addr", addr.String())
-	ks, exist, err := registry.GetDKShare(&addr)
-	log.Debugw("GetDKShare", "addr", addr.String(), "err", err, "exist", exist, "ks", ks)
+	ret, err := KeyPubInfo(&addr)
+	if err != nil {
+		_, msg := misc.HttpError(err)
+		return &GetPubKeyInfoResponse{Err: msg}
+	}
+	return ret
+}
+
+// KeyPubInfo returns public info of committee keys with the address. Errors are *echo.HTTPError
+func KeyPubInfo(addr *address.Address) (*GetPubKeyInfoResponse, error) {
+	log.Debugw("GetCommitteeKeys", "addr", addr.String())
+	keys, exist, err := registry.GetCommitteeKeys(addr)
+	log.Debugw("GetCommitteeKeys", "addr", addr.String(), "err", err, "exist", exist, "keys", keys)
 
 	if err != nil {
-		return &GetPubKeyInfoResponse{Err: err.Error()}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	if !exist {
-		return &GetPubKeyInfoResponse{Err: "dkshare not found"}
+		return nil, echo.NewHTTPError(http.StatusNotFound, "dkshare not found")
+	}
+	var pubKeys []kyber.Point
+	var pubKeyMaster kyber.Point
+	switch ks := keys.(type) {
+	case *tcrypto.DKShare:
+		pubKeys, pubKeyMaster = ks.PubKeys, ks.PubKeyMaster
+	case *tcrypto.MultiSigKeys:
+		pubKeys, pubKeyMaster = ks.PubKeys, ks.PubKeyAggregated
 	}
-	pubkeys := make([]string, len(ks.PubKeys))
-	for i, pk := range ks.PubKeys {
+	pubkeys := make([]string, len(pubKeys))
+	for i, pk := range pubKeys {
 		pkb, err := pk.MarshalBinary()
 		if err != nil {
-			return &GetPubKeyInfoResponse{Err: err.Error()}
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 		pubkeys[i] = base58.Encode(pkb)
 	}
-	pkm, err := ks.PubKeyMaster.MarshalBinary()
+	pkm, err := pubKeyMaster.MarshalBinary()
 	if err != nil {
-		return &GetPubKeyInfoResponse{Err: err.Error()}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	return &GetPubKeyInfoResponse{
-		Address:      req.Address,
-		N:            ks.N,
-		T:            ks.T,
-		Index:        ks.Index,
+		Address:      addr.String(),
+		Scheme:       keys.Scheme().String(),
+		N:            keys.Size(),
+		T:            keys.Quorum(),
+		Index:        keys.OwnIndex(),
 		PubKeys:      pubkeys,
 		PubKeyMaster: base58.Encode(pkm),
-	}
+	}, nil
 }
@@ -0,0 +1,187 @@
+package dkgapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/labstack/echo"
+	"github.com/mr-tron/base58"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+)
+
+//----------------------------------------------------------
+// Multi-signature committees are an alternative to the DKG among nodes for small trusted committees.
+// Each member has its own BLS key and shares it among members, the address is made from the aggregated
+// public key of all members. Any T members can sign. Deals are relayed by the caller, so it is a DKG
+// driven by the admin, not a multisig of independent keys (see tcrypto.MultiSigKeys).
+//
+// 'adm/multisig/newkey' generates the key of the member and returns its public key and the key to seal shares for.
+// 'adm/multisig/deal' deals shares of the key of the member to all members (see DealMultiSigRequest).
+// Shares are sealed for seal keys of members, so they are passed to members by the caller.
+// 'adm/multisig/commit' forms the committee from public keys of all members and their deals (see CommitMultiSigRequest).
+// One of public keys must be generated by the called node. It returns the address of the committee
+
+type NewMultiSigKeyResponse struct {
+	PubKey  string `json:"pub_key"`  // base58
+	SealKey string `json:"seal_key"` // base58 Ed25519 public key to seal shares dealt to the member for
+	Err     string `json:"err"`
+}
+
+type DealMultiSigRequest struct {
+	PubKeys  []string `json:"pub_keys"`  // base58, in the order of the committee
+	SealKeys []string `json:"seal_keys"` // base58 seal keys of members, in the order of the committee
+	T        uint16   `json:"t"`
+}
+
+type DealMultiSigResponse struct {
+	Commits []string `json:"commits"` // base58 commitments of the polynomial
+	Shares  []string `json:"shares"`  // base58 shares sealed for seal keys of members, in the order of the committee
+	Err     string   `json:"err"`
+}
+
+type CommitMultiSigRequest struct {
+	PubKeys []string   `json:"pub_keys"` // base58, in the order of the committee
+	T       uint16     `json:"t"`
+	Commits [][]string `json:"commits"` // commitments of deals of all members, in the order of the committee
+	Shares  []string   `json:"shares"`  // sealed shares dealt to the node by all members, in the order of the committee
+}
+
+type CommitMultiSigResponse struct {
+	Address string `json:"address"` //base58
+	Index   uint16 `json:"index"`
+	Err     string `json:"err"`
+}
+
+func HandlerNewMultiSigKey(c echo.Context) error {
+	pubKey, sealKey, err := registry.NewMultiSigMemberKey()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &NewMultiSigKeyResponse{Err: err.Error()})
+	}
+	pubKeyBin, err := pubKey.MarshalBinary()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &NewMultiSigKeyResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &NewMultiSigKeyResponse{
+		PubKey:  base58.Encode(pubKeyBin),
+		SealKey: base58.Encode(sealKey),
+	})
+}
+
+func HandlerDealMultiSig(c echo.Context) error {
+	var req DealMultiSigRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &DealMultiSigResponse{Err: err.Error()})
+	}
+	resp, err := DealMultiSig(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &DealMultiSigResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DealMultiSig deals shares of the key of the member generated by the node, sealed for seal keys of members
+func DealMultiSig(req *DealMultiSigRequest) (*DealMultiSigResponse, error) {
+	pubKeys, err := ParseMultiSigPubKeys(req.PubKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(req.SealKeys) != len(pubKeys) {
+		return nil, errors.New("seal keys of all members are needed")
+	}
+	deal, err := registry.DealMultiSig(pubKeys, req.T)
+	if err != nil {
+		return nil, err
+	}
+	ret := &DealMultiSigResponse{
+		Commits: make([]string, len(deal.Commits)),
+		Shares:  make([]string, len(deal.Shares)),
+	}
+	for i, c := range deal.Commits {
+		data, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret.Commits[i] = base58.Encode(data)
+	}
+	for i, s := range deal.Shares {
+		sealKey, err := peering.PubKeyFromBase58(req.SealKeys[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid seal key of member #%d: %v", i, err)
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := tcrypto.SealForIdentity(sealKey, data)
+		if err != nil {
+			return nil, err
+		}
+		ret.Shares[i] = base58.Encode(sealed)
+	}
+	return ret, nil
+}
+
+func HandlerCommitMultiSig(c echo.Context) error {
+	var req CommitMultiSigRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &CommitMultiSigResponse{Err: err.Error()})
+	}
+	ks, err := CommitMultiSig(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &CommitMultiSigResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &CommitMultiSigResponse{
+		Address: ks.Address.String(),
+		Index:   ks.Index,
+	})
+}
+
+// CommitMultiSig checks shares dealt to the node and saves keys of the multi-signature committee
+func CommitMultiSig(req *CommitMultiSigRequest) (*tcrypto.MultiSigKeys, error) {
+	pubKeys, err := ParseMultiSigPubKeys(req.PubKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(req.Commits) != len(pubKeys) || len(req.Shares) != len(pubKeys) {
+		return nil, errors.New("deals of all members are needed")
+	}
+	commits := make([][]kyber.Point, len(pubKeys))
+	shares := make([][]byte, len(pubKeys))
+	for i := range pubKeys {
+		if commits[i], err = ParseMultiSigPubKeys(req.Commits[i]); err != nil {
+			return nil, fmt.Errorf("invalid commitment of member #%d: %v", i, err)
+		}
+		if shares[i], err = base58.Decode(req.Shares[i]); err != nil {
+			return nil, fmt.Errorf("invalid share of member #%d: %v", i, err)
+		}
+	}
+	ks, err := registry.CommitMultiSigKeys(pubKeys, req.T, commits, shares)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("multi-signature keys committed. Address: %s, N = %d, T = %d, index = %d",
+		ks.Address.String(), ks.N, ks.T, ks.Index)
+	return ks, nil
+}
+
+// ParseMultiSigPubKeys decodes base58 encoded BLS public keys of members of the multi-signature committee
+func ParseMultiSigPubKeys(strs []string) ([]kyber.Point, error) {
+	suite := bn256.NewSuite()
+	ret := make([]kyber.Point, len(strs))
+	for i, s := range strs {
+		data, err := base58.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = suite.G2().Point()
+		if err := ret[i].UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
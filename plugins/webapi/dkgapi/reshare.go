@@ -0,0 +1,52 @@
+package dkgapi
+
+import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/dkg"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+)
+
+//----------------------------------------------------------
+// The POST handler implements 'adm/reshare' API
+// Parameters (see ReshareRequest struct):
+//     address:       address of the key set (the smart contract). The called node must hold the key share
+//     peering_hosts: network locations (netid) of new holders of shares. May overlap with the current committee
+//     pub_keys:      identity public keys of new holders, same order as peering_hosts
+//     t:             new quorum
+//     timeout_ms:    optional timeout of resharing in milliseconds
+//
+// The called node initiates resharing of the key set from the current committee to new holders.
+// Fresh shares of the same master key are generated, so the address of the smart contract remains the same
+// and old shares become useless. Bootup data of the smart contract is updated on all participants which have it
+// and running committees are restarted with new shares.
+// The call returns when resharing is finished or failed
+//
+// Response: misc.SimpleResponse
+
+func HandlerReshare(c echo.Context) error {
+	var req ReshareRequest
+
+	if err := c.Bind(&req); err != nil {
+		return misc.OkJsonErr(c, err)
+	}
+	addr, err := address.FromBase58(req.Address)
+	if err != nil {
+		return misc.OkJsonErr(c, err)
+	}
+	timeout := dkg.DefaultTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	return misc.OkJsonErr(c, dkg.RunReshare(&addr, req.PeeringHosts, req.PubKeys, req.T, timeout))
+}
+
+type ReshareRequest struct {
+	Address      string   `json:"address"` //base58
+	PeeringHosts []string `json:"peering_hosts"`
+	PubKeys      []string `json:"pub_keys"` // base58
+	T            uint16   `json:"t"`
+	TimeoutMs    uint32   `json:"timeout_ms"`
+}
@@ -0,0 +1,54 @@
+package dkgapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/dkg"
+	"github.com/labstack/echo"
+)
+
+//----------------------------------------------------------
+// The POST handler implements 'adm/rundkg' API
+// Parameters (see RunDKGRequest struct):
+//     peering_hosts: network locations (netid) of participants. The called node must be one of them
+//     pub_keys:      identity public keys of participants, same order as peering_hosts
+//     t:             required quorum: normally t=floor( 2*n/3)+1
+//     timeout_ms:    optional timeout of the DKG in milliseconds
+//
+// The called node initiates the DKG and runs it with other participants over peering.
+// Each participant deals shares of its own secret polynomial to the others, so private shares
+// never leave nodes unencrypted and are never seen by the caller.
+// The call returns when all participants saved their key shares, or the DKG failed
+//
+// Response (see RunDKGResponse):
+// - address of the new distributed key set
+
+func HandlerRunDKG(c echo.Context) error {
+	var req RunDKGRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &RunDKGResponse{Err: err.Error()})
+	}
+	timeout := dkg.DefaultTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	addr, err := dkg.RunDKG(req.PeeringHosts, req.PubKeys, req.T, timeout)
+	if err != nil {
+		return c.JSON(http.StatusOK, &RunDKGResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &RunDKGResponse{Address: addr.String()})
+}
+
+type RunDKGRequest struct {
+	PeeringHosts []string `json:"peering_hosts"`
+	PubKeys      []string `json:"pub_keys"` // base58
+	T            uint16   `json:"t"`
+	TimeoutMs    uint32   `json:"timeout_ms"`
+}
+
+type RunDKGResponse struct {
+	Address string `json:"address"` //base58
+	Err     string `json:"err"`
+}
@@ -1,9 +1,11 @@
 package dkgapi
 
 import (
+	"net/http"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/hashing"
-	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/peering"
 	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/labstack/echo"
 	"github.com/mr-tron/base58"
@@ -35,24 +37,28 @@ func SignDigestReq(req *SignDigestRequest) *SignDigestResponse {
 	if err != nil {
 		return &SignDigestResponse{Err: err.Error()}
 	}
-	if addr.Version() != address.VersionBLS {
-		return &SignDigestResponse{Err: "expected BLS address"}
-	}
-	ks, ok, err := registry.GetDKShare(&addr)
+	sigShare, err := SignDigest(&addr, req.DataDigest)
 	if err != nil {
-		return &SignDigestResponse{Err: err.Error()}
+		_, msg := misc.HttpError(err)
+		return &SignDigestResponse{Err: msg}
+	}
+	return &SignDigestResponse{
+		SigShare: sigShare,
 	}
-	if !ok {
-		return &SignDigestResponse{Err: "unknown key share"}
+}
+
+// SignDigest signs the digest with the key share of the node. Returns base58 encoded signature share.
+// Errors are *echo.HTTPError
+func SignDigest(addr *address.Address, digest *hashing.HashValue) (string, error) {
+	if addr.Version() != address.VersionBLS {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "expected BLS address")
 	}
-	if !ks.Committed {
-		return &SignDigestResponse{Err: "uncommitted key set"}
+	if digest == nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "data_digest is required")
 	}
-	signature, err := ks.SignShare(req.DataDigest.Bytes())
+	signature, err := peering.NodeSigner().SignShare(addr, digest.Bytes())
 	if err != nil {
-		return &SignDigestResponse{Err: err.Error()}
-	}
-	return &SignDigestResponse{
-		SigShare: base58.Encode(signature),
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	return base58.Encode(signature), nil
 }
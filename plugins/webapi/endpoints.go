@@ -2,51 +2,152 @@ package webapi
 
 import (
 	"bytes"
+	"fmt"
 	"net"
 	"strings"
 
+	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/plugins/webapi/admapi"
 	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
 	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
 	"github.com/labstack/echo"
 )
 
-func addEndpoints(adminWhitelist []net.IP) {
+func addEndpoints(access *accessControl) {
+	v1.AddEndpoints(Server, access.public, access.admin)
+
+	// routes below are deprecated aliases of the v1 API
 	{
-		sc := Server.Group("/sc")
+		sc := Server.Group("/sc", deprecated)
+		read := access.public(auth.RoleRead)
 
-		sc.POST("/state/query", stateapi.HandlerQueryState)
-		sc.POST("/state/request", stateapi.HandlerQueryRequestState)
+		sc.POST("/state/query", stateapi.HandlerQueryState, read)
+		sc.POST("/state/request", stateapi.HandlerQueryRequestState, read)
+		sc.POST("/:address/request", stateapi.HandlerSubmitRequest, access.public(auth.RoleRequest))
+		sc.GET("/:address/request/:reqid/wait", stateapi.HandlerWaitRequest, read)
+		sc.GET("/:address/requests/stream", stateapi.HandlerRequestStream, read)
 	}
 
 	{
-		adm := Server.Group("/adm")
-		adm.Use(protected(adminWhitelist))
+		adm := Server.Group("/adm", deprecated)
+		read := access.admin(auth.RoleRead)
+		scAdmin := access.admin(auth.RoleSCAdmin)
+		dkgAdmin := access.admin(auth.RoleDKGAdmin)
+		nodeAdmin := access.admin(auth.RoleAdmin)
 
 		// dkgapi
-		adm.POST("/newdks", dkgapi.HandlerNewDks)
-		adm.POST("/aggregatedks", dkgapi.HandlerAggregateDks)
-		adm.POST("/commitdks", dkgapi.HandlerCommitDks)
-		adm.POST("/signdigest", dkgapi.HandlerSignDigest)
-		adm.POST("/getpubkeyinfo", dkgapi.HandlerGetKeyPubInfo)
-		adm.POST("/exportdkshare", dkgapi.HandlerExportDKShare)
-		adm.POST("/importdkshare", dkgapi.HandlerImportDKShare)
-
-		adm.POST("/putscdata", admapi.HandlerPutSCData)
-		adm.POST("/getscdata", admapi.HandlerGetSCData)
-		adm.GET("/getsclist", admapi.HandlerGetSCList)
-		adm.GET("/shutdown", admapi.HandlerShutdown)
-		adm.POST("/sc/:scaddress/activate", admapi.HandlerActivateSC)
-		adm.POST("/sc/:scaddress/deactivate", admapi.HandlerDeactivateSC)
-		adm.GET("/sc/:scaddress/dumpstate", admapi.HandlerDumpSCState)
-
-		adm.POST("/program", admapi.HandlerPutProgram)
-		adm.GET("/program/:hash", admapi.HandlerGetProgramMetadata)
+		adm.POST("/rundkg", dkgapi.HandlerRunDKG, dkgAdmin)
+		adm.POST("/reshare", dkgapi.HandlerReshare, dkgAdmin)
+		adm.POST("/signdigest", dkgapi.HandlerSignDigest, dkgAdmin)
+		adm.POST("/getpubkeyinfo", dkgapi.HandlerGetKeyPubInfo, read)
+		adm.POST("/exportdkshare", dkgapi.HandlerExportDKShare, dkgAdmin)
+		adm.POST("/importdkshare", dkgapi.HandlerImportDKShare, dkgAdmin)
+		adm.POST("/multisig/newkey", dkgapi.HandlerNewMultiSigKey, dkgAdmin)
+		adm.POST("/multisig/deal", dkgapi.HandlerDealMultiSig, dkgAdmin)
+		adm.POST("/multisig/commit", dkgapi.HandlerCommitMultiSig, dkgAdmin)
+
+		adm.POST("/putscdata", admapi.HandlerPutSCData, scAdmin)
+		adm.POST("/getscdata", admapi.HandlerGetSCData, read)
+		adm.GET("/getsclist", admapi.HandlerGetSCList, read)
+		adm.GET("/shutdown", admapi.HandlerShutdown, access.admin(auth.RoleShutdown))
+		adm.GET("/nodeidentity", admapi.HandlerNodeIdentity, read)
+		adm.POST("/rotatemasterkey", admapi.HandlerRotateMasterKey, nodeAdmin)
+		adm.GET("/peers", admapi.HandlerGetPeers, read)
+		adm.POST("/peers", admapi.HandlerPutPeer, nodeAdmin)
+		adm.DELETE("/peers/:pubkey", admapi.HandlerDeletePeer, nodeAdmin)
+		adm.POST("/sc/:scaddress/activate", admapi.HandlerActivateSC, scAdmin)
+		adm.POST("/sc/:scaddress/deactivate", admapi.HandlerDeactivateSC, scAdmin)
+		adm.GET("/sc/:scaddress/dumpstate", admapi.HandlerDumpSCState, read)
+		adm.GET("/sc/:scaddress/consensus", admapi.HandlerConsensusStats, read)
+
+		adm.POST("/program", admapi.HandlerPutProgram, scAdmin)
+		adm.GET("/program/:hash", admapi.HandlerGetProgramMetadata, read)
 	}
 
+	// metrics in Prometheus text format
+	Server.GET("/metrics", admapi.HandlerMetrics, access.admin(auth.RoleRead))
+
+	// node info and health checks for orchestration. Health checks are public without API tokens and limits
+	Server.GET("/info", admapi.HandlerNodeInfo, access.admin(auth.RoleRead))
+	Server.GET("/health/live", admapi.HandlerLive)
+	Server.GET("/health/ready", admapi.HandlerReady)
+
 	log.Infof("added web api endpoints")
 }
 
+// deprecated marks responses of routes replaced by the v1 API
+func deprecated(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set("Deprecation", "true")
+		c.Response().Header().Set("Link", fmt.Sprintf("<%s/openapi.json>; rel=\"successor-version\"", v1.Prefix))
+		return next(c)
+	}
+}
+
+// accessControl makes middlewares which check permissions of callers of endpoints.
+// With API tokens enabled, each endpoint requires the token granting the role.
+// Otherwise admin endpoints are only allowed from private or whitelisted addresses and the rest are public.
+// With client certificates required, admin endpoints are allowed to clients with the verified certificate
+// instead of whitelisted addresses, and in addition to the token.
+// Public endpoints are also guarded by limits: rate limits per client and the size of the body
+type accessControl struct {
+	whitelist         []net.IP
+	tokenSecret       []byte
+	requireClientCert bool
+	publicLimits      []echo.MiddlewareFunc
+}
+
+func newAccessControl(whitelist []net.IP, tokenSecret []byte, requireClientCert bool, publicLimits []echo.MiddlewareFunc) *accessControl {
+	return &accessControl{
+		whitelist:         whitelist,
+		tokenSecret:       tokenSecret,
+		requireClientCert: requireClientCert,
+		publicLimits:      publicLimits,
+	}
+}
+
+func (a *accessControl) public(role auth.Role) echo.MiddlewareFunc {
+	if a.tokenSecret == nil {
+		return chain(a.publicLimits...)
+	}
+	m := append([]echo.MiddlewareFunc{}, a.publicLimits...)
+	return chain(append(m, auth.RequireRole(a.tokenSecret, role))...)
+}
+
+// chain combines middlewares into one, the first one is called first
+func chain(m ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		for i := len(m) - 1; i >= 0; i-- {
+			next = m[i](next)
+		}
+		return next
+	}
+}
+
+func (a *accessControl) admin(role auth.Role) echo.MiddlewareFunc {
+	switch {
+	case a.tokenSecret == nil && a.requireClientCert:
+		return clientCertRequired
+	case a.tokenSecret == nil:
+		return protected(a.whitelist)
+	case a.requireClientCert:
+		return chain(clientCertRequired, auth.RequireRole(a.tokenSecret, role))
+	}
+	return auth.RequireRole(a.tokenSecret, role)
+}
+
+// clientCertRequired allows only clients which presented the certificate verified by the TLS server
+func clientCertRequired(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if tlsState := c.Request().TLS; tlsState != nil && len(tlsState.VerifiedChains) > 0 {
+			return next(c)
+		}
+		log.Warnf("Blocking request without client certificate from %s: %s %s", c.Request().RemoteAddr, c.Request().Method, c.Request().RequestURI)
+		return echo.ErrUnauthorized
+	}
+}
+
 // allow only if the remote address is private or in whitelist
 // TODO this is a very basic/limited form of protection
 func protected(whitelist []net.IP) echo.MiddlewareFunc {
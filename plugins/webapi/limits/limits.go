@@ -0,0 +1,129 @@
+// Package limits protects the node from clients which overload the web API: rate limits per client,
+// the maximum size of request bodies and limits of the cost of state queries
+package limits
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/labstack/echo"
+)
+
+// Metrics counts requests rejected by limits since the start of the node
+type Metrics struct {
+	RateLimited   uint64
+	BodyTooLarge  uint64
+	QueryRejected uint64
+	QueryTimeouts uint64
+	// streams not opened because of limits of open streams
+	StreamsRejected uint64
+}
+
+var metrics Metrics
+
+// error returned by the reader of http.MaxBytesReader when the body is over the limit
+const maxBytesErrorText = "http: request body too large"
+
+func GetMetrics() Metrics {
+	return Metrics{
+		RateLimited:     atomic.LoadUint64(&metrics.RateLimited),
+		BodyTooLarge:    atomic.LoadUint64(&metrics.BodyTooLarge),
+		QueryRejected:   atomic.LoadUint64(&metrics.QueryRejected),
+		QueryTimeouts:   atomic.LoadUint64(&metrics.QueryTimeouts),
+		StreamsRejected: atomic.LoadUint64(&metrics.StreamsRejected),
+	}
+}
+
+// BodyLimit rejects requests with the body larger than max bytes with 413. 0 means no limit
+func BodyLimit(max int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if max <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > max {
+				atomic.AddUint64(&metrics.BodyTooLarge, 1)
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "request body is too large")
+			}
+			if req.ContentLength >= 0 {
+				return next(c)
+			}
+			// the body without the content length is read before the handler, so that the body over the limit
+			// is rejected the same way instead of failing in the handler as the malformed one
+			data, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), req.Body, max))
+			if err != nil {
+				if err.Error() == maxBytesErrorText {
+					atomic.AddUint64(&metrics.BodyTooLarge, 1)
+					return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "request body is too large")
+				}
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(data))
+			return next(c)
+		}
+	}
+}
+
+// Query is the budget of one state query: the number of keys, the total number of elements of collections
+// and the deadline. Zero values mean no limit
+type Query struct {
+	MaxKeys     int
+	MaxElements int
+	Deadline    time.Time
+	elements    int
+}
+
+// NewQuery starts the budget of the query with limits from the configuration
+func NewQuery() *Query {
+	ret := &Query{
+		MaxKeys:     parameters.GetInt(parameters.WebAPIMaxQueryKeys),
+		MaxElements: parameters.GetInt(parameters.WebAPIMaxQueryElements),
+	}
+	if timeout := parameters.GetDuration(parameters.WebAPIQueryTimeout); timeout > 0 {
+		ret.Deadline = time.Now().Add(timeout)
+	}
+	return ret
+}
+
+// CheckKeys returns 400 if the query selects too many keys
+func (q *Query) CheckKeys(n int) error {
+	if q.MaxKeys > 0 && n > q.MaxKeys {
+		return Rejected("query selects %d keys, maximum is %d", n, q.MaxKeys)
+	}
+	return nil
+}
+
+// TakeElements takes n elements from the budget. Returns 400 if the budget is exceeded
+func (q *Query) TakeElements(n int) error {
+	q.elements += n
+	if q.MaxElements > 0 && q.elements > q.MaxElements {
+		return Rejected("query requests more than %d elements", q.MaxElements)
+	}
+	return nil
+}
+
+// CheckDeadline returns 503 after the deadline of the query
+func (q *Query) CheckDeadline() error {
+	if !q.Deadline.IsZero() && time.Now().After(q.Deadline) {
+		return Timeout()
+	}
+	return nil
+}
+
+// Rejected counts the query rejected by limits and returns the 400 error
+func Rejected(format string, args ...interface{}) error {
+	atomic.AddUint64(&metrics.QueryRejected, 1)
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+// Timeout counts the query which didn't complete in time and returns the 503 error
+func Timeout() error {
+	atomic.AddUint64(&metrics.QueryTimeouts, 1)
+	return echo.NewHTTPError(http.StatusServiceUnavailable, "query timeout")
+}
@@ -0,0 +1,48 @@
+package limits
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	e := echo.New()
+	handler := BodyLimit(10)(func(c echo.Context) error {
+		data, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		return c.String(http.StatusOK, string(data))
+	})
+	call := func(body string, contentLength int64) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.ContentLength = contentLength
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			e.HTTPErrorHandler(err, e.NewContext(req, rec))
+		}
+		return rec.Code, rec.Body.String()
+	}
+	before := GetMetrics().BodyTooLarge
+
+	code, body := call("0123456789", 10)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "0123456789", body)
+	code, _ = call("0123456789a", 11)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	// bodies without the content length
+	code, body = call("0123456789", -1)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "0123456789", body)
+	code, _ = call("0123456789a", -1)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	assert.Equal(t, before+2, GetMetrics().BodyTooLarge)
+}
@@ -0,0 +1,149 @@
+package limits
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	// maximum number of clients tracked at once. Beyond that buckets are evicted to make room for new clients
+	maxRateLimiterBuckets = 10000
+	// IPv6 clients are identified by the prefix of the address: one client usually owns the whole /64 network
+	ipv6ClientPrefixBits = 64
+)
+
+// RateLimit limits the number of requests of each client, identified by the remote IP address,
+// to rate requests per second with bursts up to burst requests. Requests over the limit are rejected with 429.
+// Rate 0 means no limit
+func RateLimit(rate, burst int) echo.MiddlewareFunc {
+	if rate <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	rl := newRateLimiter(rate, burst)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ok, wait := rl.allow(ClientID(c.Request()), time.Now())
+			if !ok {
+				atomic.AddUint64(&metrics.RateLimited, 1)
+				c.Response().Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+// ClientID identifies the client of the request by the remote IP address, by the /64 prefix for IPv6 addresses
+func ClientID(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	prefix := net.IPNet{IP: ip.Mask(net.CIDRMask(ipv6ClientPrefixBits, 128)), Mask: net.CIDRMask(ipv6ClientPrefixBits, 128)}
+	return prefix.String()
+}
+
+// rateLimiter is the token bucket per client. Buckets are kept in the order of the last request of the client,
+// so idle and evicted buckets are taken from the back of the list without scanning all of them
+type rateLimiter struct {
+	mutex      sync.Mutex
+	rate       float64
+	burst      float64
+	buckets    map[string]*list.Element
+	lru        *list.List
+	maxBuckets int
+	// the bucket idle that long is full
+	fillTime time.Duration
+}
+
+type bucket struct {
+	client string
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:       float64(rate),
+		burst:      float64(burst),
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+		maxBuckets: maxRateLimiterBuckets,
+		fillTime:   time.Duration(float64(burst) / float64(rate) * float64(time.Second)),
+	}
+}
+
+// allow takes the token from the bucket of the client. If the bucket is empty, returns the time until the next token
+func (rl *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.cleanup(now)
+	var b *bucket
+	if e, ok := rl.buckets[client]; ok {
+		rl.lru.MoveToFront(e)
+		b = e.Value.(*bucket)
+	} else {
+		if len(rl.buckets) >= rl.maxBuckets {
+			rl.evict()
+		}
+		b = &bucket{client: client, tokens: rl.burst, last: now}
+		rl.buckets[client] = rl.lru.PushFront(b)
+	}
+	rl.refill(b, now)
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (rl *rateLimiter) refill(b *bucket, now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+		b.last = now
+	}
+}
+
+// cleanup removes buckets of clients idle long enough to fill them: they are the same as new ones.
+// The least recently used buckets are at the back, so it stops at the first bucket in use
+func (rl *rateLimiter) cleanup(now time.Time) {
+	for e := rl.lru.Back(); e != nil; e = rl.lru.Back() {
+		if now.Sub(e.Value.(*bucket).last) < rl.fillTime {
+			return
+		}
+		rl.remove(e)
+	}
+}
+
+// evict makes room for the new bucket by removing the bucket of the least recently active client
+func (rl *rateLimiter) evict() {
+	if e := rl.lru.Back(); e != nil {
+		rl.remove(e)
+	}
+}
+
+func (rl *rateLimiter) remove(e *list.Element) {
+	rl.lru.Remove(e)
+	delete(rl.buckets, e.Value.(*bucket).client)
+}
@@ -0,0 +1,106 @@
+package limits
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(2, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		ok, _ := rl.allow("a", now)
+		assert.True(t, ok)
+	}
+	ok, wait := rl.allow("a", now)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// other clients have their own buckets
+	ok, _ = rl.allow("b", now)
+	assert.True(t, ok)
+
+	ok, _ = rl.allow("a", now.Add(500*time.Millisecond))
+	assert.True(t, ok)
+	ok, _ = rl.allow("a", now.Add(500*time.Millisecond))
+	assert.False(t, ok)
+
+	// the bucket is not filled over the burst
+	later := now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		ok, _ = rl.allow("a", later)
+		assert.True(t, ok)
+	}
+	ok, _ = rl.allow("a", later)
+	assert.False(t, ok)
+	// full bucket of the idle client is removed
+	_, exists := rl.buckets["b"]
+	assert.False(t, exists)
+}
+
+func TestRateLimiterMaxBuckets(t *testing.T) {
+	rl := newRateLimiter(1, 2)
+	rl.maxBuckets = 2
+	now := time.Now()
+
+	ok, _ := rl.allow("a", now)
+	assert.True(t, ok)
+	ok, _ = rl.allow("b", now)
+	assert.True(t, ok)
+	ok, _ = rl.allow("b", now)
+	assert.True(t, ok)
+
+	// "a" is the least recently active client, its bucket is evicted
+	ok, _ = rl.allow("c", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(rl.buckets))
+	_, exists := rl.buckets["a"]
+	assert.False(t, exists)
+
+	// limited client stays limited
+	ok, _ = rl.allow("b", now)
+	assert.False(t, ok)
+}
+
+func TestRateLimiterIdleBuckets(t *testing.T) {
+	rl := newRateLimiter(2, 4)
+	now := time.Now()
+
+	for i := 0; i < 1000; i++ {
+		ok, _ := rl.allow(fmt.Sprintf("client%d", i), now)
+		assert.True(t, ok)
+	}
+	ok, _ := rl.allow("client0", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 1000, len(rl.buckets))
+
+	// buckets are full in 2 seconds, all but the recently active one are removed
+	ok, _ = rl.allow("new", now.Add(2*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(rl.buckets))
+	assert.Equal(t, 2, rl.lru.Len())
+	_, exists := rl.buckets["client0"]
+	assert.True(t, exists)
+}
+
+func TestClientID(t *testing.T) {
+	clientID := func(remoteAddr string) string {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		return ClientID(req)
+	}
+	assert.Equal(t, "10.0.0.1", clientID("10.0.0.1:1000"))
+	assert.Equal(t, "10.0.0.1", clientID("[::ffff:10.0.0.1]:1000"))
+
+	// addresses of the same /64 network are the same client
+	assert.Equal(t, "2001:db8:1:2::/64", clientID("[2001:db8:1:2::1]:1000"))
+	assert.Equal(t, "2001:db8:1:2::/64", clientID("[2001:db8:1:2:aaaa:bbbb:cccc:dddd]:2000"))
+	assert.NotEqual(t, clientID("[2001:db8:1:2::1]:1000"), clientID("[2001:db8:1:3::1]:1000"))
+
+	assert.Equal(t, "unix", clientID("unix"))
+}
@@ -0,0 +1,66 @@
+package limits
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/labstack/echo"
+)
+
+// StreamLimiter limits the number of open streams (long-lived connections such as WebSockets)
+// in total and of each client. Limit 0 means no limit
+type StreamLimiter struct {
+	mutex        sync.Mutex
+	maxTotal     int
+	maxPerClient int
+	total        int
+	perClient    map[string]int
+}
+
+func NewStreamLimiter(maxTotal, maxPerClient int) *StreamLimiter {
+	return &StreamLimiter{
+		maxTotal:     maxTotal,
+		maxPerClient: maxPerClient,
+		perClient:    make(map[string]int),
+	}
+}
+
+// Open takes the slot for the stream of the client of the request. The returned function releases it.
+// If no slot is available, returns *echo.HTTPError with 429
+func (l *StreamLimiter) Open(req *http.Request) (func(), error) {
+	client := ClientID(req)
+	if !l.open(client) {
+		atomic.AddUint64(&metrics.StreamsRejected, 1)
+		return nil, echo.NewHTTPError(http.StatusTooManyRequests, "too many open streams")
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.close(client) })
+	}, nil
+}
+
+func (l *StreamLimiter) open(client string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return false
+	}
+	if l.maxPerClient > 0 && l.perClient[client] >= l.maxPerClient {
+		return false
+	}
+	l.total++
+	l.perClient[client]++
+	return true
+}
+
+func (l *StreamLimiter) close(client string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.total--
+	if l.perClient[client]--; l.perClient[client] <= 0 {
+		delete(l.perClient, client)
+	}
+}
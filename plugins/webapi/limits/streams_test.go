@@ -0,0 +1,60 @@
+package limits
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStreamRequest(remoteAddr string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/sc/address/requests/stream", nil)
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestStreamLimiter(t *testing.T) {
+	l := NewStreamLimiter(3, 2)
+	a := newStreamRequest("10.0.0.1:1000")
+	b := newStreamRequest("10.0.0.2:1000")
+	c := newStreamRequest("10.0.0.3:1000")
+
+	releaseA1, err := l.Open(a)
+	assert.NoError(t, err)
+	// other connections of the same client count together
+	_, err = l.Open(newStreamRequest("10.0.0.1:2000"))
+	assert.NoError(t, err)
+	_, err = l.Open(a)
+	if assert.IsType(t, &echo.HTTPError{}, err) {
+		assert.Equal(t, http.StatusTooManyRequests, err.(*echo.HTTPError).Code)
+	}
+
+	// the total limit
+	releaseB, err := l.Open(b)
+	assert.NoError(t, err)
+	_, err = l.Open(c)
+	assert.Error(t, err)
+
+	// released slots are available again, repeated release doesn't free more
+	releaseB()
+	releaseB()
+	_, err = l.Open(c)
+	assert.NoError(t, err)
+	_, err = l.Open(b)
+	assert.Error(t, err)
+
+	releaseA1()
+	_, err = l.Open(a)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, l.total)
+	assert.Equal(t, 2, len(l.perClient))
+}
+
+func TestStreamLimiterNoLimits(t *testing.T) {
+	l := NewStreamLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		_, err := l.Open(newStreamRequest("10.0.0.1:1000"))
+		assert.NoError(t, err)
+	}
+}
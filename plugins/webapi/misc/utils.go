@@ -1,6 +1,7 @@
 package misc
 
 import (
+	"fmt"
 	"github.com/labstack/echo"
 	"net/http"
 )
@@ -24,3 +25,12 @@ func OkJsonErr(c echo.Context, err error) error {
 	}
 	return OkJson(c, &SimpleResponse{Error: serr})
 }
+
+// HttpError returns the HTTP status and the message of the error. Errors other than *echo.HTTPError are
+// internal server errors
+func HttpError(err error) (int, string) {
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code, fmt.Sprintf("%v", he.Message)
+	}
+	return http.StatusInternalServerError, err.Error()
+}
@@ -10,8 +10,12 @@ import (
 
 	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/util/tlsutil"
 	"github.com/iotaledger/wasp/plugins/webapi/admapi"
 	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
+	"github.com/iotaledger/wasp/plugins/webapi/limits"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/iotaledger/wasp/plugins/webapi/v1"
 
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
@@ -45,13 +49,61 @@ func configure(*node.Plugin) {
 	log = logger.NewLogger(PluginName)
 	dkgapi.InitLogger()
 	admapi.InitLogger()
+	stateapi.InitRequestWatch()
+	stateapi.InitRequestStreams(
+		parameters.GetInt(parameters.WebAPIMaxStreams),
+		parameters.GetInt(parameters.WebAPIMaxStreamsPerClient),
+		parameters.GetStringSlice(parameters.WebAPIStreamOrigins),
+	)
+	v1.InitLogger()
 
 	Server.HideBanner = true
 	Server.HidePort = true
+	Server.HTTPErrorHandler = v1.ErrorHandler(Server.DefaultHTTPErrorHandler)
 
-	auth.AddAuthentication(Server, parameters.GetStringToString(parameters.WebAPIAuth))
+	authConfig := parameters.GetStringToString(parameters.WebAPIAuth)
+	if _, basicAuth := authConfig["scheme"]; basicAuth && parameters.GetBool(parameters.WebAPITokenAuth) {
+		// both schemes use the Authorization header, so no request would pass both
+		log.Panicf("%s and %s can't be enabled together", parameters.WebAPIAuth, parameters.WebAPITokenAuth)
+	}
+	auth.AddAuthentication(Server, authConfig)
+
+	var tokenSecret []byte
+	if parameters.GetBool(parameters.WebAPITokenAuth) {
+		var err error
+		fname := parameters.GetString(parameters.WebAPITokenSecret)
+		if tokenSecret, err = auth.LoadOrCreateTokenSecret(fname); err != nil {
+			log.Panicf("failed to load the API token secret from %s: %v", fname, err)
+		}
+		log.Infof("API tokens are required. Tokens are signed with the secret from %s", fname)
+	}
+	publicLimits := []echo.MiddlewareFunc{
+		limits.RateLimit(parameters.GetInt(parameters.WebAPIRateLimit), parameters.GetInt(parameters.WebAPIRateBurst)),
+		limits.BodyLimit(int64(parameters.GetInt(parameters.WebAPIMaxBodySize))),
+	}
+	clientCAFile := parameters.GetString(parameters.WebAPITLSClientCAFile)
+	if certFile := parameters.GetString(parameters.WebAPITLSCertFile); certFile != "" {
+		keyFile := parameters.GetString(parameters.WebAPITLSKeyFile)
+		cfg, err := tlsutil.ServerConfig(certFile, keyFile, clientCAFile, onCertReload)
+		if err != nil {
+			log.Panicf("failed to load the TLS certificate from %s, %s: %v", certFile, keyFile, err)
+		}
+		Server.TLSServer.TLSConfig = cfg
+		if clientCAFile != "" {
+			log.Infof("client certificates signed by CAs from %s are required by admin endpoints", clientCAFile)
+		}
+	} else if clientCAFile != "" {
+		log.Panicf("%s requires TLS enabled with %s", parameters.WebAPITLSClientCAFile, parameters.WebAPITLSCertFile)
+	}
+	addEndpoints(newAccessControl(adminWhitelist(), tokenSecret, clientCAFile != "", publicLimits))
+}
 
-	addEndpoints(adminWhitelist())
+func onCertReload(err error) {
+	if err != nil {
+		log.Errorf("failed to reload the TLS certificate, the previous one is used: %v", err)
+		return
+	}
+	log.Infof("TLS certificate reloaded")
 }
 
 func adminWhitelist() []net.IP {
@@ -77,8 +129,8 @@ func worker(shutdownSignal <-chan struct{}) {
 	stopped := make(chan struct{})
 	bindAddr := parameters.GetString(parameters.WebAPIBindAddress)
 	go func() {
-		log.Infof("%s started, bind-address=%s", PluginName, bindAddr)
-		if err := Server.Start(bindAddr); err != nil {
+		log.Infof("%s started, bind-address=%s, tls=%v", PluginName, bindAddr, Server.TLSServer.TLSConfig != nil)
+		if err := startServer(bindAddr); err != nil {
 			if !errors.Is(err, http.ErrServerClosed) {
 				log.Errorf("Error serving: %s", err)
 			}
@@ -99,3 +151,12 @@ func worker(shutdownSignal <-chan struct{}) {
 		log.Errorf("Error stopping: %s", err)
 	}
 }
+
+// startServer serves HTTPS if the TLS configuration is set, otherwise plain HTTP
+func startServer(bindAddr string) error {
+	if Server.TLSServer.TLSConfig == nil {
+		return Server.Start(bindAddr)
+	}
+	Server.TLSServer.Addr = bindAddr
+	return Server.StartServer(Server.TLSServer)
+}
@@ -0,0 +1,76 @@
+package stateapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/stateschema"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/iotaledger/wasp/plugins/webapi/limits"
+	"github.com/labstack/echo"
+)
+
+type BrowseRequest struct {
+	// query in the syntax of stateschema.ParseQuery, e.g. { counter log(desc: true, limit: 5) }
+	Query string `json:"query"`
+}
+
+type BrowseResponse struct {
+	StateIndex uint32 `json:"state_index"`
+	// hash of the program whose schema the state was browsed with. Empty if the program is unknown
+	ProgramHash string `json:"program_hash,omitempty"`
+	// results of fields of the query by their aliases
+	Data map[string]interface{} `json:"data"`
+}
+
+// BrowseState runs the query on the solid state of the smart contract with the schema registered
+// with metadata of its program. Without the schema only builtin variables are known.
+// Errors are *echo.HTTPError
+func BrowseState(addr *address.Address, query string) (*BrowseResponse, error) {
+	fields, err := stateschema.ParseQuery(query)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	vs, _, exist, err := state.LoadSolidState(addr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !exist {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("state not found. Address: %s", addr.String()))
+	}
+	ret := &BrowseResponse{StateIndex: vs.StateIndex()}
+	var schema *stateschema.Schema
+	progHash, ok, err := vs.Variables().Codec().GetHashValue(vmconst.VarNameProgramHash)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if ok {
+		md, err := registry.GetProgramMetadata(progHash)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if md != nil {
+			ret.ProgramHash = progHash.String()
+			schema = md.StateSchema
+		}
+	}
+	lim := limits.NewQuery()
+	ret.Data, err = stateschema.ExecuteWithLimits(schema.WithBuiltins(), vs.Variables().Codec(), fields, stateschema.Limits{
+		MaxFields: lim.MaxKeys,
+		MaxItems:  lim.MaxElements,
+		Deadline:  lim.Deadline,
+	})
+	switch {
+	case errors.Is(err, stateschema.ErrTimeout):
+		return nil, limits.Timeout()
+	case errors.Is(err, stateschema.ErrLimitExceeded):
+		return nil, limits.Rejected("%v", err)
+	case err != nil:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ret, nil
+}
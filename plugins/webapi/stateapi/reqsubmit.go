@@ -0,0 +1,144 @@
+package stateapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/nodeconn"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+	"github.com/mr-tron/base58"
+)
+
+// maximum time to wait for outputs of input addresses from the node
+const ledgerQueryTimeout = 5 * time.Second
+
+type SubmitRequestRequest struct {
+	Transaction string `json:"tx"` // base58 encoded bytes of the signed value transaction
+}
+
+type SubmitRequestResponse struct {
+	TransactionId string   `json:"txid"`
+	RequestIds    []string `json:"requests"` // ids of requests to the smart contract, base58
+	Error         string   `json:"error"`
+}
+
+// HandlerSubmitRequest accepts the signed request transaction, posts it to the ledger and puts requests
+// to the smart contract into the backlog of the committee without waiting for the confirmation.
+// Requests are processed only after the transaction is confirmed. Until then they are kept in memory only,
+// and evicted from the backlog if the transaction is not confirmed in time
+func HandlerSubmitRequest(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &SubmitRequestResponse{Error: fmt.Sprintf("invalid address: %v", err)})
+	}
+	var req SubmitRequestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &SubmitRequestResponse{Error: err.Error()})
+	}
+	resp, err := SubmitRequest(&addr, req.Transaction)
+	if err != nil {
+		code, msg := misc.HttpError(err)
+		return c.JSON(code, &SubmitRequestResponse{Error: msg})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// SubmitRequest posts the request transaction (base58 encoded bytes) to the ledger and puts requests to
+// the smart contract into the backlog. Errors are *echo.HTTPError
+func SubmitRequest(addr *address.Address, txData string) (*SubmitRequestResponse, error) {
+	tx, err := parseRequestTransaction(txData, addr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	cmt := committees.CommitteeByAddress(*addr)
+	if cmt == nil || cmt.IsDismissed() {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("smart contract unknown. Address: %s", addr.String()))
+	}
+	// requests are kept in the backlog until the transaction is confirmed. Transactions which can't be
+	// confirmed because they spend outputs not on the ledger are rejected
+	err = checkInputsOnLedger(tx, func(addr *address.Address) (map[valuetransaction.ID][]*balance.Balance, error) {
+		return nodeconn.GetOutputsFromNode(addr, ledgerQueryTimeout)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := nodeconn.PostTransactionToNode(tx.Transaction, addr, cmt.OwnPeerIndex()); err != nil {
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, fmt.Sprintf("can't post transaction to the node: %v", err))
+	}
+	resp := &SubmitRequestResponse{
+		TransactionId: tx.ID().String(),
+		RequestIds:    make([]string, 0, tx.NumRequestsToAddress(addr)),
+	}
+	for i, reqBlk := range tx.Requests() {
+		if reqBlk.Address() != *addr {
+			continue
+		}
+		reqMsg := &committee.RequestMsg{
+			Transaction: tx,
+			Index:       uint16(i),
+			Unconfirmed: true,
+		}
+		cmt.ReceiveMessage(reqMsg)
+		resp.RequestIds = append(resp.RequestIds, reqMsg.RequestId().ToBase58())
+	}
+	return resp, nil
+}
+
+// checkInputsOnLedger checks that all inputs of the transaction are outputs on the ledger.
+// getOutputs returns outputs of the address on the ledger. Errors are *echo.HTTPError
+func checkInputsOnLedger(tx *sctransaction.Transaction, getOutputs func(addr *address.Address) (map[valuetransaction.ID][]*balance.Balance, error)) error {
+	outputsByAddr := make(map[address.Address]map[valuetransaction.ID][]*balance.Balance)
+	var err error
+	tx.Inputs().ForEach(func(outputID valuetransaction.OutputID) bool {
+		addr := outputID.Address()
+		outputs, ok := outputsByAddr[addr]
+		if !ok {
+			if outputs, err = getOutputs(&addr); err != nil {
+				err = echo.NewHTTPError(http.StatusServiceUnavailable, fmt.Sprintf("can't get outputs from the node: %v", err))
+				return false
+			}
+			outputsByAddr[addr] = outputs
+		}
+		if _, ok := outputs[outputID.TransactionID()]; !ok {
+			err = echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("input %s is not on the ledger", outputID.String()))
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// parseRequestTransaction decodes the transaction and checks it is a valid signed request transaction
+// with at least one request to the smart contract
+func parseRequestTransaction(data string, addr *address.Address) (*sctransaction.Transaction, error) {
+	txBytes, err := base58.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction encoding: %v", err)
+	}
+	vtx, _, err := valuetransaction.FromBytes(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %v", err)
+	}
+	if !vtx.SignaturesValid() {
+		return nil, fmt.Errorf("invalid signatures of the transaction")
+	}
+	tx, err := sctransaction.ParseValueTransaction(vtx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smart contract transaction: %v", err)
+	}
+	if _, ok := tx.State(); ok {
+		return nil, fmt.Errorf("state transactions are not accepted")
+	}
+	if tx.NumRequestsToAddress(addr) == 0 {
+		return nil, fmt.Errorf("transaction contains no requests to %s", addr.String())
+	}
+	return tx, nil
+}
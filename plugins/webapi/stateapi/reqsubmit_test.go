@@ -0,0 +1,131 @@
+package stateapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/sctransaction/txbuilder"
+	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/labstack/echo"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRequestTx creates the request transaction to the smart contract, spending outputs of the sender in the ledger
+func newRequestTx(t *testing.T, u *utxodb.UtxoDB, sender signaturescheme.SignatureScheme, scAddr *address.Address) *sctransaction.Transaction {
+	txb, err := txbuilder.NewFromOutputBalances(u.GetAddressOutputs(sender.Address()))
+	assert.NoError(t, err)
+	assert.NoError(t, txb.AddRequestBlock(sctransaction.NewRequestBlock(*scAddr, vmconst.RequestCodeNOP)))
+	tx, err := txb.Build(false)
+	assert.NoError(t, err)
+	tx.Sign(sender)
+	return tx
+}
+
+// ledgerOutputs returns outputs of the address in the ledger the way the node reports them
+func ledgerOutputs(u *utxodb.UtxoDB) func(addr *address.Address) (map[valuetransaction.ID][]*balance.Balance, error) {
+	return func(addr *address.Address) (map[valuetransaction.ID][]*balance.Balance, error) {
+		ret := make(map[valuetransaction.ID][]*balance.Balance)
+		for outID, bals := range u.GetAddressOutputs(*addr) {
+			ret[outID.TransactionID()] = bals
+		}
+		return ret, nil
+	}
+}
+
+func encodeTx(tx *sctransaction.Transaction) string {
+	return base58.Encode(tx.Transaction.Bytes())
+}
+
+func TestParseRequestTransaction(t *testing.T) {
+	u := utxodb.New()
+	sender := signaturescheme.RandBLS()
+	_, err := u.RequestFunds(sender.Address())
+	assert.NoError(t, err)
+	scAddr := address.Random()
+	tx := newRequestTx(t, u, sender, &scAddr)
+
+	parsed, err := parseRequestTransaction(encodeTx(tx), &scAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), parsed.ID())
+
+	_, err = parseRequestTransaction("not base58!", &scAddr)
+	assert.Error(t, err)
+	_, err = parseRequestTransaction(base58.Encode([]byte("not a transaction")), &scAddr)
+	assert.Error(t, err)
+
+	// no requests to the smart contract
+	other := address.Random()
+	_, err = parseRequestTransaction(encodeTx(tx), &other)
+	assert.Error(t, err)
+
+	// not signed by the owner of inputs
+	unsigned := newRequestTx(t, u, sender, &scAddr)
+	forged := valuetransaction.New(unsigned.Inputs(), unsigned.Outputs())
+	forged.SetDataPayload(unsigned.GetDataPayload())
+	forged.Sign(signaturescheme.RandBLS())
+	_, err = parseRequestTransaction(base58.Encode(forged.Bytes()), &scAddr)
+	assert.Error(t, err)
+
+	// state transactions are posted by committees only
+	txb, err := txbuilder.NewFromOutputBalances(u.GetAddressOutputs(sender.Address()))
+	assert.NoError(t, err)
+	assert.NoError(t, txb.CreateOriginStateBlock(hashing.RandomHash(nil), &scAddr))
+	assert.NoError(t, txb.AddRequestBlock(sctransaction.NewRequestBlock(scAddr, vmconst.RequestCodeInit)))
+	stateTx, err := txb.Build(false)
+	assert.NoError(t, err)
+	stateTx.Sign(sender)
+	_, err = parseRequestTransaction(encodeTx(stateTx), &scAddr)
+	assert.Error(t, err)
+}
+
+func TestCheckInputsOnLedger(t *testing.T) {
+	u := utxodb.New()
+	sender := signaturescheme.RandBLS()
+	_, err := u.RequestFunds(sender.Address())
+	assert.NoError(t, err)
+	scAddr := address.Random()
+	tx := newRequestTx(t, u, sender, &scAddr)
+
+	assert.NoError(t, checkInputsOnLedger(tx, ledgerOutputs(u)))
+
+	// inputs are spent by another transaction
+	assert.NoError(t, u.AddTransaction(tx.Transaction))
+	err = checkInputsOnLedger(tx, ledgerOutputs(u))
+	if assert.IsType(t, &echo.HTTPError{}, err) {
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	}
+
+	// inputs which never were on the ledger
+	err = checkInputsOnLedger(newRequestTx(t, utxodbWithFunds(t, sender), sender, &scAddr), ledgerOutputs(u))
+	if assert.IsType(t, &echo.HTTPError{}, err) {
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	}
+
+	// the node doesn't answer
+	err = checkInputsOnLedger(tx, func(addr *address.Address) (map[valuetransaction.ID][]*balance.Balance, error) {
+		return nil, fmt.Errorf("timeout")
+	})
+	if assert.IsType(t, &echo.HTTPError{}, err) {
+		assert.Equal(t, http.StatusServiceUnavailable, err.(*echo.HTTPError).Code)
+	}
+}
+
+// utxodbWithFunds returns another ledger where the address has funds
+func utxodbWithFunds(t *testing.T, sender signaturescheme.SignatureScheme) *utxodb.UtxoDB {
+	u := utxodb.New()
+	_, err := u.RequestFunds(sender.Address())
+	assert.NoError(t, err)
+	// outputs in the other ledger differ from outputs of the same address in the first one
+	_, err = u.RequestFunds(sender.Address())
+	assert.NoError(t, err)
+	return u
+}
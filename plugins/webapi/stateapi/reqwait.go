@@ -0,0 +1,238 @@
+package stateapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/webapi/limits"
+	"github.com/iotaledger/wasp/plugins/webapi/misc"
+	"github.com/labstack/echo"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+var (
+	// open request streams. No limits until InitRequestStreams
+	streamLimiter = limits.NewStreamLimiter(0, 0)
+	// origins of web pages allowed to open request streams in addition to the host of the API
+	streamOrigins []string
+)
+
+// InitRequestStreams sets limits of open request streams in total and per client and origins of web pages,
+// other than the host of the API itself, which are allowed to open streams
+func InitRequestStreams(maxTotal, maxPerClient int, allowedOrigins []string) {
+	streamLimiter = limits.NewStreamLimiter(maxTotal, maxPerClient)
+	streamOrigins = allowedOrigins
+}
+
+// HandlerWaitRequest waits until the request is processed or the timeout (query parameter `timeout`, e.g. `10s`)
+// expires. Returns the status of the request: completed with the receipt or the last known one
+func HandlerWaitRequest(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &RequestStatusResponse{Error: fmt.Sprintf("invalid address: %v", err)})
+	}
+	reqId, err := sctransaction.RequestIdFromBase58(c.Param("reqid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &RequestStatusResponse{Error: fmt.Sprintf("invalid request id: %v", err)})
+	}
+	timeout, err := ParseWaitTimeout(c.QueryParam("timeout"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &RequestStatusResponse{Error: err.Error()})
+	}
+	status, err := WaitRequest(c.Request().Context(), &addr, reqId, timeout)
+	if err != nil {
+		code, msg := misc.HttpError(err)
+		return c.JSON(code, &RequestStatusResponse{Error: msg})
+	}
+	if status == nil {
+		// the caller has gone
+		return nil
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// ParseWaitTimeout parses the timeout of the long-poll call. Default if empty, limited by the maximum
+func ParseWaitTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultWaitTimeout, nil
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid timeout '%s'", s)
+	}
+	if timeout > maxWaitTimeout {
+		timeout = maxWaitTimeout
+	}
+	return timeout, nil
+}
+
+// RequestStatus returns the current status of the request. Errors are *echo.HTTPError
+func RequestStatus(addr *address.Address, reqId *sctransaction.RequestId) (*RequestStatusResponse, error) {
+	cmt, err := committeeOf(addr)
+	if err != nil {
+		return nil, err
+	}
+	return currentRequestStatus(cmt, reqId), nil
+}
+
+// WaitRequest waits until the request is processed, the timeout expires or the context is done.
+// Returns the completed status with the receipt or the last known one. Returns nil if the context is done.
+// Errors are *echo.HTTPError
+func WaitRequest(ctx context.Context, addr *address.Address, reqId *sctransaction.RequestId, timeout time.Duration) (*RequestStatusResponse, error) {
+	cmt, err := committeeOf(addr)
+	if err != nil {
+		return nil, err
+	}
+	// watching is started before the status is checked, so the completion can't be missed
+	w := watchRequests(*addr, []sctransaction.RequestId{*reqId})
+	defer w.close()
+
+	status := currentRequestStatus(cmt, reqId)
+	if status.Status == RequestStatusCompleted {
+		return status, nil
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case ev := <-w.events:
+			if ev.status == RequestStatusCompleted {
+				return completedRequestStatus(addr, reqId), nil
+			}
+			status.Status = ev.status
+		case <-timer.C:
+			return status, nil
+		case <-ctx.Done():
+			return nil, nil
+		}
+	}
+}
+
+// HandlerRequestStream streams changes of the state of requests to the smart contract over the WebSocket
+// as RequestStatusResponse JSON messages. Requests are selected by repeated `reqid` query parameters,
+// all requests to the smart contract if none. The current status of each selected request is sent first
+func HandlerRequestStream(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &RequestStatusResponse{Error: fmt.Sprintf("invalid address: %v", err)})
+	}
+	reqIds, err := ParseRequestIds(c.QueryParams()["reqid"])
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &RequestStatusResponse{Error: err.Error()})
+	}
+	if err := StreamRequests(c, &addr, reqIds); err != nil {
+		code, msg := misc.HttpError(err)
+		return c.JSON(code, &RequestStatusResponse{Error: msg})
+	}
+	return nil
+}
+
+func ParseRequestIds(strs []string) ([]sctransaction.RequestId, error) {
+	ret := make([]sctransaction.RequestId, len(strs))
+	for i, s := range strs {
+		reqId, err := sctransaction.RequestIdFromBase58(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request id: %v", err)
+		}
+		ret[i] = *reqId
+	}
+	return ret, nil
+}
+
+// StreamRequests upgrades the connection to the WebSocket and streams the status of requests until the
+// client closes the connection. Errors are *echo.HTTPError, returned before the upgrade
+func StreamRequests(c echo.Context, addr *address.Address, reqIds []sctransaction.RequestId) error {
+	cmt, err := committeeOf(addr)
+	if err != nil {
+		return err
+	}
+	release, err := streamLimiter.Open(c.Request())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	wsServer := websocket.Server{Handshake: checkStreamOrigin, Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		w := watchRequests(*addr, reqIds)
+		defer w.close()
+
+		for i := range reqIds {
+			if err := websocket.JSON.Send(ws, currentRequestStatus(cmt, &reqIds[i])); err != nil {
+				return
+			}
+		}
+		// nothing is expected from the client. Reading returns when the connection is closed
+		closed := make(chan struct{})
+		go func() {
+			var buf [64]byte
+			for {
+				if _, err := ws.Read(buf[:]); err != nil {
+					close(closed)
+					return
+				}
+			}
+		}()
+		for {
+			select {
+			case ev := <-w.events:
+				var status *RequestStatusResponse
+				if ev.status == RequestStatusCompleted {
+					status = completedRequestStatus(addr, &ev.reqId)
+				} else {
+					status = newRequestStatus(addr, &ev.reqId, ev.status)
+				}
+				if err := websocket.JSON.Send(ws, status); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}}
+	wsServer.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// checkStreamOrigin rejects streams opened by scripts of web pages from other sites. Browsers always send
+// the origin of the page. Clients which are not browsers usually don't, they are accepted
+func checkStreamOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin: %v", err)
+	}
+	if u.Host == req.Host {
+		return nil
+	}
+	for _, allowed := range streamOrigins {
+		if origin == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", origin)
+}
+
+func committeeOf(addr *address.Address) (committee.Committee, error) {
+	cmt := committees.CommitteeByAddress(*addr)
+	if cmt == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("smart contract unknown. Address: %s", addr.String()))
+	}
+	return cmt, nil
+}
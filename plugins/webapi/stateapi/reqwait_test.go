@@ -0,0 +1,32 @@
+package stateapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStreamRequest(host, origin string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "http://"+host+"/v1/sc/address/requests/stream", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	return req
+}
+
+func TestCheckStreamOrigin(t *testing.T) {
+	defer func(saved []string) { streamOrigins = saved }(streamOrigins)
+	streamOrigins = []string{"https://dashboard.example.com"}
+
+	// clients which aren't browsers
+	assert.NoError(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "")))
+	// pages served by the node itself
+	assert.NoError(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "http://wasp.example.com:8080")))
+	assert.NoError(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "https://dashboard.example.com")))
+
+	assert.Error(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "https://evil.example.com")))
+	assert.Error(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "http://wasp.example.com:9090")))
+	assert.Error(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "null")))
+	assert.Error(t, checkStreamOrigin(nil, newStreamRequest("wasp.example.com:8080", "https://dashboard.example.com.evil.com")))
+}
@@ -0,0 +1,197 @@
+package stateapi
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/plugins/publisher"
+)
+
+// Request states reported by the wait and stream endpoints.
+// A request in processing may return to the backlog if the consensus round doesn't succeed
+const (
+	RequestStatusUnknown    = "unknown"
+	RequestStatusBacklog    = "backlog"
+	RequestStatusProcessing = "processing"
+	RequestStatusCompleted  = "completed"
+)
+
+// RequestReceipt tells in which state update the request was processed
+type RequestReceipt struct {
+	StateIndex uint32 `json:"state_index"`
+	BatchIndex uint16 `json:"batch_index"` // position of the request in the batch
+	BatchSize  uint16 `json:"batch_size"`
+	StateTxId  string `json:"state_txid"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+type RequestStatusResponse struct {
+	Address   string          `json:"address"`
+	RequestId string          `json:"request"`
+	Status    string          `json:"status"`
+	Receipt   *RequestReceipt `json:"receipt,omitempty"` // only for completed requests
+	Error     string          `json:"error,omitempty"`
+}
+
+// size of the buffer of events of one watcher. Events are dropped if the watcher doesn't keep up
+const requestWatcherBufferSize = 100
+
+type requestEvent struct {
+	reqId  sctransaction.RequestId
+	status string
+}
+
+// requestWatcher receives events of requests to the smart contract. All requests if the filter is nil
+type requestWatcher struct {
+	addr   address.Address
+	filter map[sctransaction.RequestId]bool
+	events chan requestEvent
+}
+
+var (
+	requestWatchers      = make(map[address.Address]map[*requestWatcher]bool)
+	requestWatchersMutex = &sync.Mutex{}
+	requestWatchInit     sync.Once
+)
+
+// InitRequestWatch attaches to messages of the publisher to follow the state of requests
+func InitRequestWatch() {
+	requestWatchInit.Do(func() {
+		publisher.EventPublished.Attach(events.NewClosure(onPublished))
+	})
+}
+
+func onPublished(msgType string, parts []string) {
+	var status string
+	switch msgType {
+	case "request_in":
+		status = RequestStatusBacklog
+	case "request_processing":
+		status = RequestStatusProcessing
+	case "request_out":
+		status = RequestStatusCompleted
+	default:
+		return
+	}
+	if len(parts) < 3 {
+		return
+	}
+	addr, err := address.FromBase58(parts[0])
+	if err != nil {
+		return
+	}
+	requestWatchersMutex.Lock()
+	defer requestWatchersMutex.Unlock()
+
+	watchers, ok := requestWatchers[addr]
+	if !ok {
+		return
+	}
+	txid, err := valuetransaction.IDFromBase58(parts[1])
+	if err != nil {
+		return
+	}
+	index, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return
+	}
+	ev := requestEvent{
+		reqId:  sctransaction.NewRequestId(txid, uint16(index)),
+		status: status,
+	}
+	for w := range watchers {
+		if w.filter != nil && !w.filter[ev.reqId] {
+			continue
+		}
+		select {
+		case w.events <- ev:
+		default:
+		}
+	}
+}
+
+// watchRequests starts receiving events of the listed requests to the smart contract, or of all requests if
+// the list is empty. The watcher must be closed
+func watchRequests(addr address.Address, reqIds []sctransaction.RequestId) *requestWatcher {
+	ret := &requestWatcher{
+		addr:   addr,
+		events: make(chan requestEvent, requestWatcherBufferSize),
+	}
+	if len(reqIds) > 0 {
+		ret.filter = make(map[sctransaction.RequestId]bool, len(reqIds))
+		for _, reqId := range reqIds {
+			ret.filter[reqId] = true
+		}
+	}
+	requestWatchersMutex.Lock()
+	defer requestWatchersMutex.Unlock()
+
+	if _, ok := requestWatchers[addr]; !ok {
+		requestWatchers[addr] = make(map[*requestWatcher]bool)
+	}
+	requestWatchers[addr][ret] = true
+	return ret
+}
+
+func (w *requestWatcher) close() {
+	requestWatchersMutex.Lock()
+	defer requestWatchersMutex.Unlock()
+
+	delete(requestWatchers[w.addr], w)
+	if len(requestWatchers[w.addr]) == 0 {
+		delete(requestWatchers, w.addr)
+	}
+}
+
+// currentRequestStatus makes the status response from the current state of the request in the committee
+func currentRequestStatus(cmt committee.Committee, reqId *sctransaction.RequestId) *RequestStatusResponse {
+	switch cmt.GetRequestProcessingStatus(reqId) {
+	case committee.RequestProcessingStatusBacklog:
+		return newRequestStatus(cmt.Address(), reqId, RequestStatusBacklog)
+	case committee.RequestProcessingStatusCompleted:
+		return completedRequestStatus(cmt.Address(), reqId)
+	}
+	return newRequestStatus(cmt.Address(), reqId, RequestStatusUnknown)
+}
+
+func newRequestStatus(addr *address.Address, reqId *sctransaction.RequestId, status string) *RequestStatusResponse {
+	return &RequestStatusResponse{
+		Address:   addr.String(),
+		RequestId: reqId.ToBase58(),
+		Status:    status,
+	}
+}
+
+// completedRequestStatus makes the status of the processed request with the receipt.
+// The receipt is omitted if it can't be restored from the stored state
+func completedRequestStatus(addr *address.Address, reqId *sctransaction.RequestId) *RequestStatusResponse {
+	ret := newRequestStatus(addr, reqId, RequestStatusCompleted)
+	stateIndex, ok, err := state.LoadRequestStateIndex(addr, reqId)
+	if err != nil || !ok {
+		return ret
+	}
+	batch, err := state.LoadBatch(addr, stateIndex)
+	if err != nil || batch == nil {
+		return ret
+	}
+	for i, rid := range batch.RequestIds() {
+		if *rid != *reqId {
+			continue
+		}
+		ret.Receipt = &RequestReceipt{
+			StateIndex: stateIndex,
+			BatchIndex: uint16(i),
+			BatchSize:  batch.Size(),
+			StateTxId:  batch.StateTransactionId().String(),
+			Timestamp:  batch.Timestamp(),
+		}
+		break
+	}
+	return ret
+}
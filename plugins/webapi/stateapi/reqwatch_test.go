@@ -0,0 +1,50 @@
+package stateapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestWatch(t *testing.T) {
+	addr := address.Random()
+	txid := valuetransaction.RandomID()
+	reqId0 := sctransaction.NewRequestId(txid, 0)
+	reqId1 := sctransaction.NewRequestId(txid, 1)
+
+	all := watchRequests(addr, nil)
+	one := watchRequests(addr, []sctransaction.RequestId{reqId1})
+
+	onPublished("request_in", []string{addr.String(), txid.String(), "0"})
+	onPublished("request_processing", []string{addr.String(), txid.String(), "1"})
+	onPublished("request_out", []string{addr.String(), txid.String(), "1", "5", "0", "1"})
+	// other messages and other smart contracts are ignored
+	onPublished("state", []string{addr.String(), "5"})
+	other := address.Random()
+	onPublished("request_in", []string{other.String(), txid.String(), "1"})
+
+	assert.Equal(t, 3, len(all.events))
+	assert.Equal(t, requestEvent{reqId: reqId0, status: RequestStatusBacklog}, <-all.events)
+	assert.Equal(t, requestEvent{reqId: reqId1, status: RequestStatusProcessing}, <-all.events)
+	assert.Equal(t, requestEvent{reqId: reqId1, status: RequestStatusCompleted}, <-all.events)
+
+	assert.Equal(t, 2, len(one.events))
+	assert.Equal(t, requestEvent{reqId: reqId1, status: RequestStatusProcessing}, <-one.events)
+	assert.Equal(t, requestEvent{reqId: reqId1, status: RequestStatusCompleted}, <-one.events)
+
+	all.close()
+	one.close()
+	assert.Equal(t, 0, len(requestWatchers))
+
+	// events are dropped when the watcher doesn't keep up
+	w := watchRequests(addr, nil)
+	defer w.close()
+	for i := 0; i < requestWatcherBufferSize+10; i++ {
+		onPublished("request_in", []string{addr.String(), txid.String(), fmt.Sprintf("%d", i)})
+	}
+	assert.Equal(t, requestWatcherBufferSize, len(w.events))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/plugins/webapi/limits"
 	"github.com/iotaledger/wasp/plugins/webapi/misc"
 	"github.com/labstack/echo"
 )
@@ -298,16 +299,36 @@ func HandlerQueryState(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, &QueryResponse{Error: err.Error()})
 	}
-	// TODO serialize access to solid state
-	state, batch, exist, err := state.LoadSolidState(&addr)
+	ret, exist, err := QueryState(&addr, req.Query)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &QueryResponse{Error: err.Error()})
+		status, msg := misc.HttpError(err)
+		return c.JSON(status, &QueryResponse{Error: msg})
 	}
 	if !exist {
 		return c.JSON(http.StatusNotFound, &QueryResponse{
 			Error: fmt.Sprintf("State not found with address %s", addr),
 		})
 	}
+	return misc.OkJson(c, ret)
+}
+
+// QueryState returns general data of the solid state of the smart contract and results of queries.
+// Returns false if the state doesn't exist. Queries over configured limits fail with *echo.HTTPError
+func QueryState(addr *address.Address, query []*KeyQuery) (*QueryResponse, bool, error) {
+	lim := limits.NewQuery()
+	if err := lim.CheckKeys(len(query)); err != nil {
+		return nil, false, err
+	}
+	for _, q := range query {
+		if err := lim.TakeElements(requestedElements(q)); err != nil {
+			return nil, false, err
+		}
+	}
+	// TODO serialize access to solid state
+	state, batch, exist, err := state.LoadSolidState(addr)
+	if err != nil || !exist {
+		return nil, false, err
+	}
 	sh := state.Hash()
 	ret := &QueryResponse{
 		StateIndex: state.StateIndex(),
@@ -321,14 +342,17 @@ func HandlerQueryState(c echo.Context) error {
 		ret.Requests[i] = batch.RequestIds()[i].ToBase58()
 	}
 	vars := state.Variables()
-	for _, q := range req.Query {
-		value, err := processQuery(q, vars)
+	for _, q := range query {
+		if err := lim.CheckDeadline(); err != nil {
+			return nil, true, err
+		}
+		value, err := processQuery(q, vars, lim)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, &QueryResponse{Error: err.Error()})
+			return nil, true, err
 		}
 		b, err := json.Marshal(value)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, &QueryResponse{Error: err.Error()})
+			return nil, true, err
 		}
 		ret.Results = append(ret.Results, &QueryResult{
 			Key:   q.Key,
@@ -336,11 +360,34 @@ func HandlerQueryState(c echo.Context) error {
 			Value: json.RawMessage(b),
 		})
 	}
+	return ret, true, nil
+}
 
-	return misc.OkJson(c, ret)
+// requestedElements is the maximum number of elements of the collection returned by the query
+func requestedElements(q *KeyQuery) int {
+	switch q.Type {
+	case ValueTypeArray:
+		var params ArrayQueryParams
+		if json.Unmarshal(q.Params, &params) == nil && params.To > params.From {
+			return int(params.To - params.From)
+		}
+	case ValueTypeDict:
+		var params DictQueryParams
+		if json.Unmarshal(q.Params, &params) == nil {
+			return int(params.Limit)
+		}
+	case ValueTypeDictElement:
+		return 1
+	case ValueTypeTLogSliceData:
+		var params TLogSliceDataQueryParams
+		if json.Unmarshal(q.Params, &params) == nil && params.ToIndex >= params.FromIndex {
+			return int(params.ToIndex-params.FromIndex) + 1
+		}
+	}
+	return 0
 }
 
-func processQuery(q *KeyQuery, vars kv.BufferedKVStore) (interface{}, error) {
+func processQuery(q *KeyQuery, vars kv.BufferedKVStore, lim *limits.Query) (interface{}, error) {
 	key := kv.Key(q.Key)
 	switch q.Type {
 	case ValueTypeScalar:
@@ -365,6 +412,9 @@ func processQuery(q *KeyQuery, vars kv.BufferedKVStore) (interface{}, error) {
 		size := arr.Len()
 		values := make([][]byte, 0)
 		for i := params.From; i < size && i < params.To; i++ {
+			if err := lim.CheckDeadline(); err != nil {
+				return nil, err
+			}
 			v, err := arr.GetAt(i)
 			if err != nil {
 				return nil, err
@@ -386,10 +436,17 @@ func processQuery(q *KeyQuery, vars kv.BufferedKVStore) (interface{}, error) {
 		}
 
 		entries := make([]KeyValuePair, 0)
+		var errDeadline error
 		err = dict.Iterate(func(elemKey []byte, value []byte) bool {
+			if errDeadline = lim.CheckDeadline(); errDeadline != nil {
+				return false
+			}
 			entries = append(entries, KeyValuePair{Key: elemKey, Value: value})
 			return len(entries) < int(params.Limit)
 		})
+		if err == nil {
+			err = errDeadline
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/dkg"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
+	"github.com/labstack/echo"
+	"github.com/mr-tron/base58"
+)
+
+type KeySetAddress struct {
+	Address string `json:"address"` // base58
+}
+
+// KeySetInfo is public info of committee keys of any scheme
+type KeySetInfo struct {
+	Address      string   `json:"address"` // base58
+	Scheme       string   `json:"scheme"`  // "threshold" or "multisig"
+	N            uint16   `json:"n"`
+	T            uint16   `json:"t"`
+	Index        uint16   `json:"index"`
+	PubKeys      []string `json:"pub_keys"`       // base58
+	PubKeyMaster string   `json:"pub_key_master"` // base58
+}
+
+type ReshareRequest struct {
+	PeeringHosts []string `json:"peering_hosts"`
+	PubKeys      []string `json:"pub_keys"` // base58
+	T            uint16   `json:"t"`
+	TimeoutMs    uint32   `json:"timeout_ms"`
+}
+
+// ReshareAuthorization allows the node to deal its key share in the resharing initiated by another
+// member of the committee with the same new holders and quorum
+type ReshareAuthorization struct {
+	PubKeys []string `json:"pub_keys"` // base58
+	T       uint16   `json:"t"`
+	TTLMs   uint32   `json:"ttl_ms"`
+}
+
+type SignDigestRequest struct {
+	DataDigest *hashing.HashValue `json:"data_digest"`
+}
+
+type SignDigestResponse struct {
+	SigShare string `json:"sig_share"` // base58
+}
+
+type ExportDKShareResponse struct {
+	DKShare string `json:"dkshare"` // base58
+}
+
+type MultiSigKey struct {
+	PubKey  string `json:"pub_key"`  // base58
+	SealKey string `json:"seal_key"` // base58 Ed25519 public key to seal shares dealt to the member for
+}
+
+type MultiSigDeal struct {
+	Commits []string `json:"commits"` // base58 commitments of the polynomial
+	Shares  []string `json:"shares"`  // base58 shares sealed for seal keys of members, in the order of the committee
+}
+
+type MultiSigCommittee struct {
+	Address string `json:"address"` // base58
+	Index   uint16 `json:"index"`
+}
+
+func dkgTimeout(timeoutMs uint32) time.Duration {
+	if timeoutMs > 0 {
+		return time.Duration(timeoutMs) * time.Millisecond
+	}
+	return dkg.DefaultTimeout
+}
+
+func handlerRunDKG(c echo.Context) error {
+	var req dkgapi.RunDKGRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	addr, err := dkg.RunDKG(req.PeeringHosts, req.PubKeys, req.T, dkgTimeout(req.TimeoutMs))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, &KeySetAddress{Address: addr.String()})
+}
+
+func handlerGetKeySet(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	info, err := dkgapi.KeyPubInfo(addr)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &KeySetInfo{
+		Address:      info.Address,
+		Scheme:       info.Scheme,
+		N:            info.N,
+		T:            info.T,
+		Index:        info.Index,
+		PubKeys:      info.PubKeys,
+		PubKeyMaster: info.PubKeyMaster,
+	})
+}
+
+func handlerReshare(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req ReshareRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := dkg.RunReshare(addr, req.PeeringHosts, req.PubKeys, req.T, dkgTimeout(req.TimeoutMs)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerAuthorizeReshare(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req ReshareAuthorization
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := dkg.AuthorizeReshare(addr, req.PubKeys, req.T, time.Duration(req.TTLMs)*time.Millisecond); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerSignDigest(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req SignDigestRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	sigShare, err := dkgapi.SignDigest(addr, req.DataDigest)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &SignDigestResponse{SigShare: sigShare})
+}
+
+func handlerExportDKShare(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	sealed, err := dkgapi.ExportDKShare(addr, c.QueryParam("recipient_pubkey"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &ExportDKShareResponse{DKShare: sealed})
+}
+
+func handlerImportDKShare(c echo.Context) error {
+	var req dkgapi.ImportDKShareRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := dkgapi.ImportDKShare(req.Blob); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerNewMultiSigKey(c echo.Context) error {
+	pubKey, sealKey, err := registry.NewMultiSigMemberKey()
+	if err != nil {
+		return err
+	}
+	pubKeyBin, err := pubKey.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, &MultiSigKey{
+		PubKey:  base58.Encode(pubKeyBin),
+		SealKey: base58.Encode(sealKey),
+	})
+}
+
+func handlerDealMultiSig(c echo.Context) error {
+	var req dkgapi.DealMultiSigRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	resp, err := dkgapi.DealMultiSig(&req)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	return c.JSON(http.StatusOK, &MultiSigDeal{Commits: resp.Commits, Shares: resp.Shares})
+}
+
+func handlerCommitMultiSig(c echo.Context) error {
+	var req dkgapi.CommitMultiSigRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	ks, err := dkgapi.CommitMultiSig(&req)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	return c.JSON(http.StatusCreated, &MultiSigCommittee{
+		Address: ks.Address.String(),
+		Index:   ks.Index,
+	})
+}
@@ -0,0 +1,220 @@
+// Package v1 implements the versioned resource oriented web API of the node.
+// Routes are declared in one table, which is also the source of the OpenAPI document of the API.
+// Successful calls return the resource with 2xx status, failed calls return ErrorResponse
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/committee"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/dkgapi"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/labstack/echo"
+)
+
+// Prefix of all routes of the API
+const Prefix = "/v1"
+
+// path of the OpenAPI document of the API, available without access control
+const openAPIDocPath = "/openapi.json"
+
+type access int
+
+const (
+	// requires the role with API tokens, otherwise public
+	accessPublic = access(iota)
+	// requires the role with API tokens, otherwise allowed only from whitelisted addresses
+	accessAdmin
+)
+
+type queryParam struct {
+	name        string
+	description string
+}
+
+type endpoint struct {
+	method  string
+	path    string // echo path relative to Prefix, e.g. /sc/:address
+	summary string
+	tag     string
+	access  access
+	role    auth.Role
+	query   []queryParam
+	// zero values of types of the request and response bodies. nil if no body
+	request  interface{}
+	response interface{}
+	// status of the successful response
+	status  int
+	handler echo.HandlerFunc
+}
+
+var endpoints = []*endpoint{
+	// smart contracts
+	{method: http.MethodGet, path: "/sc", tag: "sc", summary: "List bootup data of all smart contracts",
+		access: accessAdmin, role: auth.RoleRead,
+		response: []*admapi.BootupDataJsonable{}, status: http.StatusOK, handler: handlerListSC},
+	{method: http.MethodPost, path: "/sc", tag: "sc", summary: "Create bootup data of the smart contract",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		request: admapi.BootupDataJsonable{}, response: admapi.BootupDataJsonable{}, status: http.StatusCreated, handler: handlerPutSC},
+	{method: http.MethodGet, path: "/sc/:address", tag: "sc", summary: "Get bootup data of the smart contract",
+		access: accessAdmin, role: auth.RoleRead,
+		response: admapi.BootupDataJsonable{}, status: http.StatusOK, handler: handlerGetSC},
+	{method: http.MethodPost, path: "/sc/:address/activate", tag: "sc", summary: "Activate the smart contract",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		status: http.StatusNoContent, handler: handlerActivateSC},
+	{method: http.MethodPost, path: "/sc/:address/deactivate", tag: "sc", summary: "Deactivate the smart contract",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		status: http.StatusNoContent, handler: handlerDeactivateSC},
+	{method: http.MethodGet, path: "/sc/:address/consensus", tag: "sc", summary: "Get statistics of recent consensus rounds",
+		access: accessAdmin, role: auth.RoleRead,
+		response: committee.ConsensusStats{}, status: http.StatusOK, handler: handlerConsensusStats},
+
+	// state
+	{method: http.MethodGet, path: "/sc/:address/state", tag: "state", summary: "Get general data of the solid state",
+		access: accessPublic, role: auth.RoleRead,
+		response: SCState{}, status: http.StatusOK, handler: handlerGetState},
+	{method: http.MethodPost, path: "/sc/:address/state/query", tag: "state", summary: "Query variables of the solid state. Doesn't change anything",
+		access: accessPublic, role: auth.RoleRead,
+		request: StateQueryRequest{}, response: StateQueryResponse{}, status: http.StatusOK, handler: handlerQueryState},
+	{method: http.MethodPost, path: "/sc/:address/state/browse", tag: "state", summary: "Browse the solid state with the query on the schema of the state. Doesn't change anything",
+		access: accessPublic, role: auth.RoleRead,
+		request: stateapi.BrowseRequest{}, response: stateapi.BrowseResponse{}, status: http.StatusOK, handler: handlerBrowseState},
+	{method: http.MethodGet, path: "/sc/:address/state/variables", tag: "state", summary: "Dump all variables of the solid state",
+		access: accessAdmin, role: auth.RoleRead,
+		response: StateVariables{}, status: http.StatusOK, handler: handlerDumpState},
+
+	// requests
+	{method: http.MethodPost, path: "/sc/:address/requests", tag: "requests", summary: "Submit the signed request transaction",
+		access: accessPublic, role: auth.RoleRequest,
+		request: stateapi.SubmitRequestRequest{}, response: SubmitRequestResponse{}, status: http.StatusAccepted, handler: handlerSubmitRequest},
+	{method: http.MethodGet, path: "/sc/:address/requests/stream", tag: "requests", summary: "WebSocket stream of the status of requests",
+		access: accessPublic, role: auth.RoleRead,
+		query:    []queryParam{{"reqid", "request to follow, repeated. All requests to the smart contract if none"}},
+		response: stateapi.RequestStatusResponse{}, status: http.StatusSwitchingProtocols, handler: handlerRequestStream},
+	{method: http.MethodGet, path: "/sc/:address/requests/:reqid", tag: "requests", summary: "Get the status of the request",
+		access: accessPublic, role: auth.RoleRead,
+		response: stateapi.RequestStatusResponse{}, status: http.StatusOK, handler: handlerGetRequest},
+	{method: http.MethodGet, path: "/sc/:address/requests/:reqid/wait", tag: "requests", summary: "Wait until the request is processed or the timeout expires",
+		access: accessPublic, role: auth.RoleRead,
+		query:    []queryParam{{"timeout", "duration, e.g. 10s. Default 30s, at most 5m"}},
+		response: stateapi.RequestStatusResponse{}, status: http.StatusOK, handler: handlerWaitRequest},
+
+	// programs
+	{method: http.MethodPost, path: "/programs", tag: "programs", summary: "Upload the program",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		request: admapi.PutProgramRequest{}, response: ProgramResponse{}, status: http.StatusCreated, handler: handlerPutProgram},
+	{method: http.MethodGet, path: "/programs", tag: "programs", summary: "List programs in the registry of the node",
+		access: accessAdmin, role: auth.RoleRead,
+		response: []*ProgramInfo{}, status: http.StatusOK, handler: handlerListPrograms},
+	{method: http.MethodGet, path: "/programs/:hash", tag: "programs", summary: "Get metadata, the code hash and users of the program",
+		access: accessAdmin, role: auth.RoleRead,
+		response: ProgramInfo{}, status: http.StatusOK, handler: handlerGetProgram},
+	{method: http.MethodDelete, path: "/programs/:hash", tag: "programs", summary: "Delete the program unless it is pinned or used by active smart contracts",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		status: http.StatusNoContent, handler: handlerDeleteProgram},
+	{method: http.MethodPut, path: "/programs/:hash/pin", tag: "programs", summary: "Protect the program from deletion",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		status: http.StatusNoContent, handler: handlerPinProgram},
+	{method: http.MethodDelete, path: "/programs/:hash/pin", tag: "programs", summary: "Remove the protection of the program from deletion",
+		access: accessAdmin, role: auth.RoleSCAdmin,
+		status: http.StatusNoContent, handler: handlerUnpinProgram},
+
+	// key sets
+	{method: http.MethodPost, path: "/dkshares", tag: "dkshares", summary: "Run DKG and create the key set",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: dkgapi.RunDKGRequest{}, response: KeySetAddress{}, status: http.StatusCreated, handler: handlerRunDKG},
+	{method: http.MethodPost, path: "/dkshares/import", tag: "dkshares", summary: "Import the key share exported for the node",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: dkgapi.ImportDKShareRequest{}, status: http.StatusNoContent, handler: handlerImportDKShare},
+	{method: http.MethodGet, path: "/dkshares/:address", tag: "dkshares", summary: "Get public info of the key set",
+		access: accessAdmin, role: auth.RoleRead,
+		response: KeySetInfo{}, status: http.StatusOK, handler: handlerGetKeySet},
+	{method: http.MethodPost, path: "/dkshares/:address/reshare", tag: "dkshares", summary: "Reshare the key set to new holders",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: ReshareRequest{}, status: http.StatusNoContent, handler: handlerReshare},
+	{method: http.MethodPost, path: "/dkshares/:address/reshare/authorize", tag: "dkshares", summary: "Allow the node to deal its key share in the resharing initiated by another committee member",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: ReshareAuthorization{}, status: http.StatusNoContent, handler: handlerAuthorizeReshare},
+	{method: http.MethodPost, path: "/dkshares/:address/sign", tag: "dkshares", summary: "Sign the digest with the key share of the node",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: SignDigestRequest{}, response: SignDigestResponse{}, status: http.StatusOK, handler: handlerSignDigest},
+	{method: http.MethodGet, path: "/dkshares/:address/export", tag: "dkshares", summary: "Export the key share sealed for the recipient node",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		query:    []queryParam{{"recipient_pubkey", "identity public key of the recipient node, base58"}},
+		response: ExportDKShareResponse{}, status: http.StatusOK, handler: handlerExportDKShare},
+	{method: http.MethodPost, path: "/multisig/keys", tag: "dkshares", summary: "Generate the key of the member of the multi-signature committee",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		response: MultiSigKey{}, status: http.StatusCreated, handler: handlerNewMultiSigKey},
+	{method: http.MethodPost, path: "/multisig/deals", tag: "dkshares", summary: "Deal shares of the key of the member to members of the multi-signature committee",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: dkgapi.DealMultiSigRequest{}, response: MultiSigDeal{}, status: http.StatusOK, handler: handlerDealMultiSig},
+	{method: http.MethodPost, path: "/multisig/committees", tag: "dkshares", summary: "Form the multi-signature committee from public keys and deals of members",
+		access: accessAdmin, role: auth.RoleDKGAdmin,
+		request: dkgapi.CommitMultiSigRequest{}, response: MultiSigCommittee{}, status: http.StatusCreated, handler: handlerCommitMultiSig},
+
+	// peers
+	{method: http.MethodGet, path: "/peers", tag: "peers", summary: "Get the address book and the status of peers",
+		access: accessAdmin, role: auth.RoleRead,
+		response: Peers{}, status: http.StatusOK, handler: handlerGetPeers},
+	{method: http.MethodPut, path: "/peers/:pubkey", tag: "peers", summary: "Set the network location of the node with the identity",
+		access: accessAdmin, role: auth.RoleAdmin,
+		request: PutPeerRequest{}, status: http.StatusNoContent, handler: handlerPutPeer},
+	{method: http.MethodDelete, path: "/peers/:pubkey", tag: "peers", summary: "Remove the node with the identity from the address book",
+		access: accessAdmin, role: auth.RoleAdmin,
+		status: http.StatusNoContent, handler: handlerDeletePeer},
+
+	// node
+	{method: http.MethodGet, path: "/node/info", tag: "node", summary: "Get the version, the identity, the connection to Goshimmer and readiness of the node",
+		access: accessAdmin, role: auth.RoleRead,
+		response: admapi.NodeInfo{}, status: http.StatusOK, handler: handlerNodeInfo},
+	{method: http.MethodGet, path: "/node/identity", tag: "node", summary: "Get the network location and the identity of the node",
+		access: accessAdmin, role: auth.RoleRead,
+		response: NodeIdentity{}, status: http.StatusOK, handler: handlerNodeIdentity},
+	{method: http.MethodPost, path: "/node/masterkey/rotate", tag: "node", summary: "Re-encrypt secrets with the new master key",
+		access: accessAdmin, role: auth.RoleAdmin,
+		request: admapi.RotateMasterKeyRequest{}, status: http.StatusNoContent, handler: handlerRotateMasterKey},
+	{method: http.MethodPost, path: "/node/shutdown", tag: "node", summary: "Shut the node down",
+		access: accessAdmin, role: auth.RoleShutdown,
+		status: http.StatusAccepted, handler: handlerShutdown},
+}
+
+// AddEndpoints adds routes of the API to the server. Middlewares checking access to public and admin
+// endpoints are made by the functions
+func AddEndpoints(server *echo.Echo, public, admin func(auth.Role) echo.MiddlewareFunc) {
+	g := server.Group(Prefix)
+	for _, ep := range endpoints {
+		var m []echo.MiddlewareFunc
+		switch ep.access {
+		case accessPublic:
+			m = append(m, public(ep.role))
+		case accessAdmin:
+			m = append(m, admin(ep.role))
+		}
+		g.Add(ep.method, ep.path, ep.handler, m...)
+	}
+	g.GET(openAPIDocPath, handlerOpenAPI)
+}
+
+// openAPIPath converts echo path parameters to the OpenAPI format: /sc/:address -> /sc/{address}
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return Prefix + strings.Join(parts, "/")
+}
+
+func pathParams(path string) []string {
+	ret := make([]string, 0)
+	for _, p := range strings.Split(path, "/") {
+		if strings.HasPrefix(p, ":") {
+			ret = append(ret, p[1:])
+		}
+	}
+	return ret
+}
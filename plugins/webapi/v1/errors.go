@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// ErrorResponse is the body of every error response of the v1 API
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// ErrorHandler renders errors of the v1 API as ErrorResponse with the status of the error.
+// Errors of other routes are passed to the fallback handler
+func ErrorHandler(fallback echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if !strings.HasPrefix(c.Request().URL.Path, Prefix+"/") {
+			fallback(err, c)
+			return
+		}
+		if c.Response().Committed {
+			return
+		}
+		resp := &ErrorResponse{Error: ErrorDetail{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+		}}
+		if he, ok := err.(*echo.HTTPError); ok {
+			resp.Error.Status = he.Code
+			resp.Error.Message = fmt.Sprintf("%v", he.Message)
+		}
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(resp.Error.Status)
+		} else {
+			err = c.JSON(resp.Error.Status, resp)
+		}
+		if err != nil {
+			log.Errorf("failed to send error response: %v", err)
+		}
+	}
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+func notFound(format string, args ...interface{}) error {
+	return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf(format, args...))
+}
+
+func conflict(format string, args ...interface{}) error {
+	return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,11 @@
+package v1
+
+import "github.com/iotaledger/hive.go/logger"
+
+const modulename = "webapi/v1"
+
+var log *logger.Logger
+
+func InitLogger() {
+	log = logger.NewLogger(modulename)
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/gracefulshutdown"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/labstack/echo"
+)
+
+type Peers struct {
+	AddressBook []*registry.PeerAddress `json:"address_book"`
+	Peers       []*peering.PeerStatus   `json:"peers"`
+}
+
+type PutPeerRequest struct {
+	NetID string `json:"netid"`
+}
+
+type NodeIdentity struct {
+	NetID  string `json:"netid"`
+	PubKey string `json:"pubkey"` // base58
+}
+
+func handlerGetPeers(c echo.Context) error {
+	book, err := registry.GetPeerAddresses()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &Peers{
+		AddressBook: book,
+		Peers:       peering.GetStatus().Peers,
+	})
+}
+
+func handlerPutPeer(c echo.Context) error {
+	var req PutPeerRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := peering.SetPeerAddress(c.Param("pubkey"), req.NetID); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerDeletePeer(c echo.Context) error {
+	if err := peering.DeletePeerAddress(c.Param("pubkey")); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerNodeIdentity(c echo.Context) error {
+	return c.JSON(http.StatusOK, &NodeIdentity{
+		NetID:  peering.MyNetworkId(),
+		PubKey: peering.MyPubKey(),
+	})
+}
+
+func handlerNodeInfo(c echo.Context) error {
+	return c.JSON(http.StatusOK, admapi.GetNodeInfo())
+}
+
+func handlerRotateMasterKey(c echo.Context) error {
+	var req admapi.RotateMasterKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := registry.RotateMasterKey(req.Passphrase, req.KeyFile); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerShutdown(c echo.Context) error {
+	log.Info("Received a shutdown request from WebAPI.")
+	gracefulshutdown.Shutdown()
+	return c.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,260 @@
+package v1
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// The OpenAPI document is generated from the table of endpoints. Schemas of request and response bodies
+// are made from Go types by reflection, following the rules of encoding/json
+
+const openAPIVersion = "3.0.3"
+
+var (
+	openAPIDoc     map[string]interface{}
+	openAPIDocOnce sync.Once
+)
+
+func handlerOpenAPI(c echo.Context) error {
+	openAPIDocOnce.Do(func() {
+		openAPIDoc = makeOpenAPIDoc(endpoints)
+	})
+	return c.JSON(http.StatusOK, openAPIDoc)
+}
+
+func makeOpenAPIDoc(eps []*endpoint) map[string]interface{} {
+	gen := newSchemaGen()
+	errorSchema := gen.schemaOf(reflect.TypeOf(ErrorResponse{}))
+
+	paths := make(map[string]interface{})
+	for _, ep := range eps {
+		p := openAPIPath(ep.path)
+		if _, ok := paths[p]; !ok {
+			paths[p] = make(map[string]interface{})
+		}
+		paths[p].(map[string]interface{})[strings.ToLower(ep.method)] = gen.operation(ep, errorSchema)
+	}
+	paths[Prefix+openAPIDocPath] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary": "OpenAPI document of the API",
+			"tags":    []string{"node"},
+			"responses": map[string]interface{}{
+				strconv.Itoa(http.StatusOK): map[string]interface{}{
+					"description": http.StatusText(http.StatusOK),
+					"content":     jsonContent(map[string]interface{}{"type": "object"}),
+				},
+			},
+		},
+	}
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "Wasp node web API",
+			"version": strings.TrimPrefix(Prefix, "/"),
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+func (g *schemaGen) operation(ep *endpoint, errorSchema map[string]interface{}) map[string]interface{} {
+	params := make([]interface{}, 0)
+	for _, name := range pathParams(ep.path) {
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, q := range ep.query {
+		params = append(params, map[string]interface{}{
+			"name":        q.name,
+			"in":          "query",
+			"description": q.description,
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	success := map[string]interface{}{
+		"description": http.StatusText(ep.status),
+	}
+	if ep.response != nil {
+		success["content"] = jsonContent(g.schemaOf(reflect.TypeOf(ep.response)))
+	}
+	description := fmt.Sprintf("Requires the role `%s` with API tokens enabled.", ep.role)
+	if ep.access == accessAdmin {
+		description += " Without API tokens only allowed from the admin whitelist."
+	} else {
+		description += " Rate limited per client, responds with 429 and `Retry-After` over the limit."
+	}
+	ret := map[string]interface{}{
+		"summary":     ep.summary,
+		"description": description,
+		"tags":        []string{ep.tag},
+		"parameters":  params,
+		"responses": map[string]interface{}{
+			strconv.Itoa(ep.status): success,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content":     jsonContent(errorSchema),
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+		},
+		"x-wasp-role": string(ep.role),
+	}
+	if ep.request != nil {
+		ret["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(g.schemaOf(reflect.TypeOf(ep.request))),
+		}
+	}
+	return ret
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		echo.MIMEApplicationJSON: map[string]interface{}{"schema": schema},
+	}
+}
+
+var (
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeRawMessage    = reflect.TypeOf(json.RawMessage{})
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeDuration      = reflect.TypeOf(time.Duration(0))
+)
+
+// schemaGen makes schemas of Go types. Named struct types are put into components
+type schemaGen struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+func newSchemaGen() *schemaGen {
+	return &schemaGen{
+		schemas: make(map[string]interface{}),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (g *schemaGen) schemaOf(t reflect.Type) map[string]interface{} {
+	switch t {
+	case typeRawMessage:
+		return map[string]interface{}{}
+	case typeTime:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case typeDuration:
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	}
+	if t.Kind() != reflect.Ptr && (reflect.PtrTo(t).Implements(typeJSONMarshaler) || reflect.PtrTo(t).Implements(typeTextMarshaler)) {
+		// custom encodings of the repository are strings, e.g. base58
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaOf(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+	// interfaces: any value
+	return map[string]interface{}{}
+}
+
+func (g *schemaGen) structRef(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := g.schemas[name]; taken {
+			// same name in different packages
+			name = path.Base(t.PkgPath()) + "." + t.Name()
+		}
+		g.names[t] = name
+		// placeholder, in case the type is recursive
+		g.schemas[name] = map[string]interface{}{}
+		g.schemas[name] = g.structSchema(t)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	g.addProperties(t, props)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+// addProperties adds properties of fields of the struct. Fields of embedded structs are promoted as in encoding/json
+func (g *schemaGen) addProperties(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addProperties(ft, props)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schemaOf(ft)
+	}
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDoc(t *testing.T) {
+	doc := makeOpenAPIDoc(endpoints)
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Paths      map[string]map[string]json.RawMessage
+		Components struct {
+			Schemas map[string]json.RawMessage
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+
+	for _, ep := range endpoints {
+		ops, ok := parsed.Paths[openAPIPath(ep.path)]
+		require.True(t, ok, "path %s", ep.path)
+		_, ok = ops[strings.ToLower(ep.method)]
+		assert.True(t, ok, "%s %s", ep.method, ep.path)
+	}
+	assert.Contains(t, parsed.Paths, "/v1/sc/{address}/requests/{reqid}/wait")
+
+	// all references are resolved
+	for _, ref := range strings.Split(string(data), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		assert.Contains(t, parsed.Components.Schemas, name)
+	}
+
+	var bootupData struct {
+		Properties map[string]map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(parsed.Components.Schemas["BootupDataJsonable"], &bootupData))
+	assert.Equal(t, "string", bootupData.Properties["address"]["type"])
+	assert.Equal(t, "array", bootupData.Properties["committee_nodes"]["type"])
+	assert.Equal(t, "boolean", bootupData.Properties["active"]["type"])
+}
+
+func TestErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(e.DefaultHTTPErrorHandler)
+	e.GET(Prefix+"/test", func(c echo.Context) error {
+		return notFound("thing %d not found", 5)
+	})
+	e.GET("/legacy", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "legacy")
+	})
+
+	call := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := call(Prefix + "/test")
+	assert.Equal(t, http.StatusNotFound, code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, ErrorDetail{Status: http.StatusNotFound, Message: "thing 5 not found"}, resp.Error)
+
+	// unknown routes of the API
+	code, body = call(Prefix + "/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+	resp = ErrorResponse{}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, http.StatusNotFound, resp.Error.Status)
+
+	// other routes are not affected
+	code, body = call("/legacy")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.NotContains(t, body, `"status"`)
+}
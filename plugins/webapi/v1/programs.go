@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/labstack/echo"
+)
+
+type ProgramResponse struct {
+	ProgramHash string `json:"program_hash"`
+}
+
+// ProgramInfo is the program in the registry of the node
+type ProgramInfo struct {
+	ProgramHash string `json:"program_hash"`
+	admapi.ProgramMetadata
+	Builtin bool `json:"builtin"`
+	// hash and size of the code stored by the node, which must be equal to the program hash. Empty if no code
+	CodeHash string `json:"code_hash,omitempty"`
+	CodeSize int    `json:"code_size"`
+	Pinned   bool   `json:"pinned"`
+	// addresses of smart contracts of the node running the program
+	UsedBy []string `json:"used_by"`
+}
+
+func paramProgramHash(c echo.Context) (*hashing.HashValue, error) {
+	progHash, err := hashing.HashValueFromBase58(c.Param("hash"))
+	if err != nil {
+		return nil, badRequest("invalid program hash: %v", err)
+	}
+	return &progHash, nil
+}
+
+func handlerPutProgram(c echo.Context) error {
+	var req admapi.PutProgramRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	progHash, err := admapi.SaveProgram(&req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, &ProgramResponse{ProgramHash: progHash.String()})
+}
+
+func handlerListPrograms(c echo.Context) error {
+	lst, err := registry.GetProgramMetadataList()
+	if err != nil {
+		return err
+	}
+	users, _, err := admapi.ProgramUsersByHash()
+	if err != nil {
+		return err
+	}
+	ret := make([]*ProgramInfo, len(lst))
+	for i, md := range lst {
+		if ret[i], err = programInfo(md, users[md.ProgramHash]); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func handlerGetProgram(c echo.Context) error {
+	progHash, err := paramProgramHash(c)
+	if err != nil {
+		return err
+	}
+	md, err := registry.GetProgramMetadata(progHash)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return notFound("program not found. Hash: %s", progHash.String())
+	}
+	users, _, err := admapi.ProgramUsers(progHash)
+	if err != nil {
+		return err
+	}
+	info, err := programInfo(md, users)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+func programInfo(md *registry.ProgramMetadata, users []address.Address) (*ProgramInfo, error) {
+	ret := &ProgramInfo{
+		ProgramHash: md.ProgramHash.String(),
+		ProgramMetadata: admapi.ProgramMetadata{
+			VMType:      md.VMType,
+			Description: md.Description,
+			StateSchema: md.StateSchema,
+		},
+		Builtin: registry.IsBuiltinProgram(&md.ProgramHash),
+		UsedBy:  make([]string, len(users)),
+	}
+	codeHash, size, err := registry.GetProgramCodeHash(&md.ProgramHash)
+	if err != nil {
+		return nil, err
+	}
+	if codeHash != nil {
+		ret.CodeHash = codeHash.String()
+		ret.CodeSize = size
+	}
+	if ret.Pinned, err = registry.IsProgramPinned(&md.ProgramHash); err != nil {
+		return nil, err
+	}
+	for i := range users {
+		ret.UsedBy[i] = users[i].String()
+	}
+	return ret, nil
+}
+
+func handlerDeleteProgram(c echo.Context) error {
+	progHash, err := paramProgramHash(c)
+	if err != nil {
+		return err
+	}
+	if err := admapi.DeleteProgram(progHash); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerPinProgram(c echo.Context) error {
+	progHash, err := paramProgramHash(c)
+	if err != nil {
+		return err
+	}
+	md, err := registry.GetProgramMetadata(progHash)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return notFound("program not found. Hash: %s", progHash.String())
+	}
+	if err := registry.PinProgram(progHash); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerUnpinProgram(c echo.Context) error {
+	progHash, err := paramProgramHash(c)
+	if err != nil {
+		return err
+	}
+	if err := registry.UnpinProgram(progHash); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/labstack/echo"
+)
+
+type SubmitRequestResponse struct {
+	TransactionId string   `json:"txid"`
+	RequestIds    []string `json:"requests"` // ids of requests to the smart contract, base58
+}
+
+func paramRequestId(c echo.Context) (*sctransaction.RequestId, error) {
+	reqId, err := sctransaction.RequestIdFromBase58(c.Param("reqid"))
+	if err != nil {
+		return nil, badRequest("invalid request id: %v", err)
+	}
+	return reqId, nil
+}
+
+func handlerSubmitRequest(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req stateapi.SubmitRequestRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	resp, err := stateapi.SubmitRequest(addr, req.Transaction)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, &SubmitRequestResponse{
+		TransactionId: resp.TransactionId,
+		RequestIds:    resp.RequestIds,
+	})
+}
+
+func handlerGetRequest(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	reqId, err := paramRequestId(c)
+	if err != nil {
+		return err
+	}
+	status, err := stateapi.RequestStatus(addr, reqId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+func handlerWaitRequest(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	reqId, err := paramRequestId(c)
+	if err != nil {
+		return err
+	}
+	timeout, err := stateapi.ParseWaitTimeout(c.QueryParam("timeout"))
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	status, err := stateapi.WaitRequest(c.Request().Context(), addr, reqId, timeout)
+	if err != nil || status == nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+func handlerRequestStream(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	reqIds, err := stateapi.ParseRequestIds(c.QueryParams()["reqid"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	return stateapi.StreamRequests(c, addr, reqIds)
+}
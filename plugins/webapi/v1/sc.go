@@ -0,0 +1,223 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/plugins/committees"
+	"github.com/iotaledger/wasp/plugins/webapi/admapi"
+	"github.com/iotaledger/wasp/plugins/webapi/stateapi"
+	"github.com/labstack/echo"
+)
+
+// SCState is general data of the solid state of the smart contract
+type SCState struct {
+	StateIndex uint32   `json:"state_index"`
+	Timestamp  int64    `json:"timestamp"`
+	StateHash  string   `json:"state_hash"`
+	StateTxId  string   `json:"state_txid"`
+	Requests   []string `json:"requests"` // ids of requests of the last batch, base58
+}
+
+type StateQueryRequest struct {
+	Query []*stateapi.KeyQuery `json:"query"`
+}
+
+type StateQueryResponse struct {
+	SCState
+	Results []*stateapi.QueryResult `json:"results"`
+}
+
+type StateVariables struct {
+	StateIndex uint32            `json:"state_index"`
+	Variables  map[kv.Key][]byte `json:"variables"`
+}
+
+func paramAddress(c echo.Context) (*address.Address, error) {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return nil, badRequest("invalid address: %v", err)
+	}
+	return &addr, nil
+}
+
+func handlerListSC(c echo.Context) error {
+	lst, err := registry.GetBootupRecords()
+	if err != nil {
+		return err
+	}
+	ret := make([]*admapi.BootupDataJsonable, len(lst))
+	for i, bd := range lst {
+		ret[i] = admapi.NewBootupDataJsonable(bd)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func handlerPutSC(c echo.Context) error {
+	var req admapi.BootupDataJsonable
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	rec, err := req.BootupData()
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	bd, err := registry.GetBootupData(&rec.Address)
+	if err != nil {
+		return err
+	}
+	if bd != nil {
+		return conflict("bootup data already exists. Address: %s", rec.Address.String())
+	}
+	if err = registry.SaveBootupData(rec); err != nil {
+		return err
+	}
+	log.Infof("Bootup record saved for addr: %s color: %s", rec.Address.String(), rec.Color.String())
+	return c.JSON(http.StatusCreated, admapi.NewBootupDataJsonable(rec))
+}
+
+func handlerGetSC(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	bd, err := registry.GetBootupData(addr)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		return notFound("bootup data not found. Address: %s", addr.String())
+	}
+	return c.JSON(http.StatusOK, admapi.NewBootupDataJsonable(bd))
+}
+
+func handlerActivateSC(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	bd, err := registry.ActivateBootupData(addr)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	if err := committees.ActivateCommittee(bd); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerDeactivateSC(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	bd, err := registry.DeactivateBootupData(addr)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	if err := committees.DeactivateCommittee(bd); err != nil {
+		return badRequest("%v", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerConsensusStats(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	cmt := committees.CommitteeByAddress(*addr)
+	if cmt == nil {
+		return notFound("committee is not active")
+	}
+	stats := cmt.ConsensusStats()
+	if stats == nil {
+		return notFound("node does not run consensus for the smart contract")
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+func handlerGetState(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	resp, err := queryState(addr, nil)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &resp.SCState)
+}
+
+func handlerQueryState(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req StateQueryRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	resp, err := queryState(addr, req.Query)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func queryState(addr *address.Address, query []*stateapi.KeyQuery) (*StateQueryResponse, error) {
+	resp, exist, err := stateapi.QueryState(addr, query)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, notFound("state not found. Address: %s", addr.String())
+	}
+	return &StateQueryResponse{
+		SCState: SCState{
+			StateIndex: resp.StateIndex,
+			Timestamp:  resp.Timestamp,
+			StateHash:  resp.StateHash,
+			StateTxId:  resp.StateTxId,
+			Requests:   resp.Requests,
+		},
+		Results: resp.Results,
+	}, nil
+}
+
+func handlerBrowseState(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	var req stateapi.BrowseRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	resp, err := stateapi.BrowseState(addr, req.Query)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func handlerDumpState(c echo.Context) error {
+	addr, err := paramAddress(c)
+	if err != nil {
+		return err
+	}
+	virtualState, _, ok, err := state.LoadSolidState(addr)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return notFound("state not found. Address: %s", addr.String())
+	}
+	return c.JSON(http.StatusOK, &StateVariables{
+		StateIndex: virtualState.StateIndex(),
+		Variables:  virtualState.Variables().DangerouslyDumpToMap().ToGoMap(),
+	})
+}
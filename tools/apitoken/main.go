@@ -0,0 +1,57 @@
+// apitoken issues API tokens for the web API of the Wasp node. Tokens are signed with the secret
+// from the file 'webapi.tokenSecretFile' of the node
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/util/auth"
+)
+
+func main() {
+	secretFile := flag.String("secret", "apitoken.key", "token secret file of the node")
+	subject := flag.String("subject", "", "holder of the token")
+	roles := flag.String("roles", "", "comma separated roles granted by the token")
+	validity := flag.Duration("validity", 720*time.Hour, "validity period of the token. Tokens can't be revoked one by one, so each token expires")
+	flag.Usage = func() {
+		fmt.Printf("usage: apitoken -secret <file> -subject <name> -roles <roles> [-validity <duration>]\n")
+		flag.PrintDefaults()
+		fmt.Printf("roles: %s\n", rolesString(auth.AllRoles))
+	}
+	flag.Parse()
+
+	if *subject == "" || *roles == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	r, err := auth.ParseRoles(*roles)
+	check(err)
+	// the secret must exist: it is generated by the node upon the first start with token authentication
+	if _, err := os.Stat(*secretFile); err != nil {
+		check(err)
+	}
+	secret, err := auth.LoadOrCreateTokenSecret(*secretFile)
+	check(err)
+	token, err := auth.IssueToken(secret, *subject, r, *validity)
+	check(err)
+	fmt.Printf("%s\n", token)
+}
+
+func rolesString(roles []auth.Role) string {
+	ret := make([]string, len(roles))
+	for i, r := range roles {
+		ret[i] = string(r)
+	}
+	return strings.Join(ret, ", ")
+}
+
+func check(err error) {
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+}
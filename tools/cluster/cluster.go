@@ -44,6 +44,8 @@ type SmartContractFinalConfig struct {
 	AccessNodes    []int    `json:"access_nodes,omitempty"`
 	OwnerSeed      []byte   `json:"owner_seed"`
 	DKShares       []string `json:"dkshares"` // [node index]
+	// DKShares are sealed for the key of the cluster and re-sealed for the identity of the node upon import
+	DKSharesKey []byte `json:"dkshares_key"`
 	//
 	originTx *sctransaction.Transaction // cached after CreateOrigin call
 }
@@ -333,7 +335,7 @@ func (cluster *Cluster) Start() error {
 func (cluster *Cluster) start() error {
 	fmt.Printf("[cluster] starting %d Wasp nodes...\n", len(cluster.Config.Nodes))
 
-	initOk := make(chan bool, len(cluster.Config.Nodes))
+	initOk := make(chan bool, 1)
 
 	if !cluster.Config.Goshimmer.Provided {
 		cmd, err := cluster.startServer("goshimmer", cluster.GoshimmerDataPath(), "goshimmer", initOk, "WebAPI started")
@@ -351,19 +353,16 @@ func (cluster *Cluster) start() error {
 	}
 
 	for i, _ := range cluster.Config.Nodes {
-		cmd, err := cluster.startServer("wasp", cluster.WaspNodeDataPath(i), fmt.Sprintf("wasp %d", i), initOk, "nanomsg publisher is running")
+		cmd, err := cluster.startServer("wasp", cluster.WaspNodeDataPath(i), fmt.Sprintf("wasp %d", i), nil, "")
 		if err != nil {
 			return err
 		}
 		cluster.Config.Nodes[i].cmd = cmd
 	}
 
-	for i := 0; i < len(cluster.Config.Nodes); i++ {
-		select {
-		case <-initOk:
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("Timeout starting wasp nodes\n")
-		}
+	// nodes are ready when connected to goshimmer and committees of active smart contracts have quorums
+	if err := waspapi.WaitUntilNodesReady(cluster.ApiHosts(), 30*time.Second); err != nil {
+		return fmt.Errorf("Timeout starting wasp nodes: %v\n", err)
 	}
 	fmt.Printf("[cluster] started %d Wasp nodes\n", len(cluster.Config.Nodes))
 	return nil
@@ -389,11 +388,11 @@ func (cluster *Cluster) startServer(command string, cwd string, name string, ini
 		stderrPipe,
 		func(line string) { fmt.Printf("[!%s] %s\n", name, line) },
 	)
-	go scanLog(
-		stdoutPipe,
-		func(line string) { fmt.Printf("[ %s] %s\n", name, line) },
-		waitFor(initOkMsg, initOk),
-	)
+	hooks := []func(string){func(line string) { fmt.Printf("[ %s] %s\n", name, line) }}
+	if initOk != nil {
+		hooks = append(hooks, waitFor(initOkMsg, initOk))
+	}
+	go scanLog(stdoutPipe, hooks...)
 
 	return cmd, nil
 }
@@ -442,7 +441,12 @@ func (cluster *Cluster) importKeys() error {
 	for _, scKeys := range cluster.SmartContractConfig {
 		fmt.Printf("[cluster] Importing DKShares for address %s...\n", scKeys.Address)
 		for nodeIndex, dks := range scKeys.DKShares {
-			err := waspapi.ImportDKShare(cluster.Config.Nodes[nodeIndex].ApiHost(), dks)
+			host := cluster.Config.Nodes[nodeIndex].ApiHost()
+			blob, err := scKeys.resealDKShare(dks, host)
+			if err != nil {
+				return err
+			}
+			err = waspapi.ImportDKShare(host, blob)
 			if err != nil {
 				return err
 			}
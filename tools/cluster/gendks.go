@@ -1,6 +1,8 @@
 package cluster
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +14,8 @@ import (
 	"github.com/iotaledger/wasp/packages/nodeclient"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/sctransaction/origin"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/mr-tron/base58"
 
 	waspapi "github.com/iotaledger/wasp/packages/apilib"
 )
@@ -29,10 +33,9 @@ func (cluster *Cluster) GenerateDKSetsToFile() error {
 	keys := make([]SmartContractFinalConfig, 0)
 
 	for _, sc := range cluster.Config.SmartContracts {
-		committee := cluster.WaspHosts(sc.CommitteeNodes, (*WaspNodeConfig).ApiHost)
-		addr, err := waspapi.GenerateNewDistributedKeySetOld(
-			committee,
-			uint16(len(committee)),
+		addr, err := waspapi.GenerateNewDistributedKeySet(
+			cluster.WaspHosts(sc.CommitteeNodes, (*WaspNodeConfig).ApiHost),
+			cluster.WaspHosts(sc.CommitteeNodes, (*WaspNodeConfig).PeeringHost),
 			uint16(sc.Quorum),
 		)
 		if err != nil {
@@ -41,9 +44,16 @@ func (cluster *Cluster) GenerateDKSetsToFile() error {
 
 		fmt.Printf("[cluster] Generated key set for SC with address %s\n", addr)
 
+		// key shares are exported sealed for the key of the cluster: identities of nodes
+		// change when the cluster is reset
+		_, dksKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		dksPubKey := base58.Encode(dksKey.Public().(ed25519.PublicKey))
 		dkShares := make([]string, 0)
 		for _, host := range cluster.ApiHosts() {
-			dks, err := waspapi.ExportDKShare(host, addr)
+			dks, err := waspapi.ExportDKShare(host, addr, dksPubKey)
 			if err != nil {
 				return err
 			}
@@ -57,6 +67,7 @@ func (cluster *Cluster) GenerateDKSetsToFile() error {
 			CommitteeNodes: sc.CommitteeNodes,
 			OwnerSeed:      seed.NewSeed().Bytes(),
 			DKShares:       dkShares,
+			DKSharesKey:    dksKey.Seed(),
 		}
 		keys = append(keys, scdata)
 	}
@@ -111,3 +122,27 @@ func (scdata *SmartContractFinalConfig) GetProgramHash() *hashing.HashValue {
 	}
 	return &h
 }
+
+// resealDKShare opens the exported key share with the key of the cluster and seals it for the identity of the node
+func (scdata *SmartContractFinalConfig) resealDKShare(blob string, apiHost string) (string, error) {
+	sealed, err := base58.Decode(blob)
+	if err != nil {
+		return "", err
+	}
+	data, err := tcrypto.OpenSealed(ed25519.NewKeyFromSeed(scdata.DKSharesKey), sealed)
+	if err != nil {
+		return "", err
+	}
+	_, pubKeyStr, err := waspapi.GetNodeIdentity(apiHost)
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := base58.Decode(pubKeyStr)
+	if err != nil {
+		return "", err
+	}
+	if sealed, err = tcrypto.SealForIdentity(pubKey, data); err != nil {
+		return "", err
+	}
+	return base58.Encode(sealed), nil
+}
@@ -53,12 +53,23 @@ func putScData(clu *cluster.Cluster, sc *cluster.SmartContractFinalConfig) (*bal
 	accessPeerNodes := clu.WaspHosts(sc.AccessNodes, (*cluster.WaspNodeConfig).PeeringHost)
 	allNodesApi := clu.WaspHosts(sc.AllNodes(), (*cluster.WaspNodeConfig).ApiHost)
 
+	committeePubKeys, err := waspapi.GetNodePubKeys(clu.WaspHosts(sc.CommitteeNodes, (*cluster.WaspNodeConfig).ApiHost), committeePeerNodes)
+	if err != nil {
+		return nil, err
+	}
+	accessPubKeys, err := waspapi.GetNodePubKeys(clu.WaspHosts(sc.AccessNodes, (*cluster.WaspNodeConfig).ApiHost), accessPeerNodes)
+	if err != nil {
+		return nil, err
+	}
+
 	succ, errs := waspapi.PutSCDataMulti(allNodesApi, registry.BootupData{
-		Address:        addr,
-		Color:          color,
-		OwnerAddress:   sc.OwnerAddress(),
-		CommitteeNodes: committeePeerNodes,
-		AccessNodes:    accessPeerNodes,
+		Address:          addr,
+		Color:            color,
+		OwnerAddress:     sc.OwnerAddress(),
+		CommitteeNodes:   committeePeerNodes,
+		AccessNodes:      accessPeerNodes,
+		CommitteePubKeys: committeePubKeys,
+		AccessPubKeys:    accessPubKeys,
 	})
 	if !succ {
 		fmt.Printf("[cluster] apilib.PutSCData returned: %v\n", multicall.WrapErrors(errs))
@@ -10,11 +10,12 @@ import (
 )
 
 type ioParams struct {
-	Hosts     []string `json:"hosts"`
-	N         uint16   `json:"n"`
-	T         uint16   `json:"t"`
-	NumKeys   uint16   `json:"num_keys"`
-	Addresses []string `json:"addresses"` //base58
+	Hosts        []string `json:"hosts"`
+	PeeringHosts []string `json:"peering_hosts"`
+	N            uint16   `json:"n"`
+	T            uint16   `json:"t"`
+	NumKeys      uint16   `json:"num_keys"`
+	Addresses    []string `json:"addresses"` //base58
 }
 
 func main() {
@@ -32,14 +33,14 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	if len(params.Hosts) != int(params.N) || params.N < params.T || params.N < 4 {
+	if len(params.Hosts) != int(params.N) || len(params.PeeringHosts) != int(params.N) || params.N < params.T || params.N < 4 {
 		panic("wrong assembly size parameters or number rof hosts")
 	}
 
 	params.Addresses = make([]string, 0, params.NumKeys)
 	numSuccess := 0
 	for i := 0; i < int(params.NumKeys); i++ {
-		addr, err := apilib.GenerateNewDistributedKeySetOld(params.Hosts, params.N, params.T)
+		addr, err := apilib.GenerateNewDistributedKeySet(params.Hosts, params.PeeringHosts, params.T)
 		if err == nil {
 			params.Addresses = append(params.Addresses, addr.String())
 			numSuccess++
@@ -0,0 +1,210 @@
+// signer is a stand-in for the remote signer of the Wasp node. It keeps the node identity key and
+// keys of committees of both key schemes in files of the key directory and serves signing requests on the Unix socket.
+// Run the node with the 'signer.socket' parameter pointing to the socket.
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/mr-tron/base58"
+)
+
+const identityFile = "identity.key"
+
+var (
+	keyDir     = flag.String("keys", "signer-keys", "directory with the keys")
+	socketPath = flag.String("socket", "signer.sock", "unix socket to listen on")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Printf("usage: signer [-keys <dir>] [-socket <path>] <run|pubkey|import <sealed dkshare>>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := os.MkdirAll(*keyDir, 0700); err != nil {
+		fail(err)
+	}
+	identity, err := loadOrCreateIdentity()
+	if err != nil {
+		fail(err)
+	}
+	signer := &fileSigner{
+		Signer:   tcrypto.NewLocalSigner(identity, loadKeys),
+		identity: identity,
+	}
+
+	switch flag.Arg(0) {
+	case "run":
+		run(signer)
+
+	case "pubkey":
+		// DKShares are exported from the node sealed for this public key (see /adm/exportdkshare)
+		fmt.Printf("%s\n", base58.Encode(identity.Public().(ed25519.PublicKey)))
+
+	case "import":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		addr, err := importDKShare(signer, flag.Arg(1))
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("imported key share of %s\n", addr)
+
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Printf("error: %v\n", err)
+	os.Exit(1)
+}
+
+func run(signer tcrypto.Signer) {
+	_ = os.Remove(*socketPath)
+	listener, err := net.Listen("unix", *socketPath)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.Chmod(*socketPath, 0600); err != nil {
+		fail(err)
+	}
+	srv := &http.Server{Handler: tcrypto.NewSignerHandler(signer)}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		_ = srv.Close()
+	}()
+
+	fmt.Printf("signer is listening on %s\n", *socketPath)
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		fail(err)
+	}
+}
+
+func loadOrCreateIdentity() (ed25519.PrivateKey, error) {
+	fname := filepath.Join(*keyDir, identityFile)
+	data, err := ioutil.ReadFile(fname)
+	if err == nil {
+		key, err := base58.Decode(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, err
+		}
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("corrupted identity key in %s", fname)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(fname, []byte(base58.Encode(key)), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// fileSigner saves keys handed over by the node to files of the key directory
+type fileSigner struct {
+	tcrypto.Signer
+	identity ed25519.PrivateKey
+}
+
+// ImportKeys opens and saves the keys. Only the public part is returned to the node
+func (s *fileSigner) ImportKeys(sealed []byte, replace bool) (tcrypto.CommitteeKeys, error) {
+	data, err := tcrypto.OpenSealed(s.identity, sealed)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := tcrypto.UnmarshalCommitteeKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	if !ks.HasPrivateKey() {
+		return nil, fmt.Errorf("keys of %s without the private key", ks.KeyAddress())
+	}
+	// reshared key share replaces the old one. Otherwise only the same keys may be imported again
+	if !replace {
+		old, exists, err := loadKeys(ks.KeyAddress())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			oldData, err := tcrypto.MarshalCommitteeKeys(old)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(oldData, data) {
+				return nil, fmt.Errorf("different keys of %s are already kept", ks.KeyAddress())
+			}
+		}
+	}
+	if err := saveKeys(ks); err != nil {
+		return nil, err
+	}
+	return tcrypto.PublicPartOf(ks), nil
+}
+
+func keysFile(addr *address.Address) string {
+	return filepath.Join(*keyDir, addr.String()+".keys")
+}
+
+// keys are saved with the key scheme, see tcrypto.MarshalCommitteeKeys
+func saveKeys(ks tcrypto.CommitteeKeys) error {
+	data, err := tcrypto.MarshalCommitteeKeys(ks)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(keysFile(ks.KeyAddress()), data, 0600)
+}
+
+func loadKeys(addr *address.Address) (tcrypto.CommitteeKeys, bool, error) {
+	data, err := ioutil.ReadFile(keysFile(addr))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	ret, err := tcrypto.UnmarshalCommitteeKeys(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return ret, true, nil
+}
+
+// importDKShare imports the key share exported by the node for the identity of the signer
+func importDKShare(signer tcrypto.Signer, blob string) (*address.Address, error) {
+	sealed, err := base58.Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := signer.ImportKeys(sealed, false)
+	if err != nil {
+		return nil, err
+	}
+	return ks.KeyAddress(), nil
+}
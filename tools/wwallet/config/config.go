@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/apilib"
 	"github.com/iotaledger/wasp/packages/nodeclient"
 	"github.com/iotaledger/wasp/packages/nodeclient/goshimmer"
 	"github.com/iotaledger/wasp/packages/testutil"
@@ -47,6 +48,15 @@ func setCmd(args []string) {
 func Read() {
 	viper.SetConfigFile(configPath)
 	_ = viper.ReadInConfig()
+	// API token for Wasp nodes with token authentication enabled
+	apilib.SetAuthToken(viper.GetString("wasp.token"))
+	// API hosts with the https:// scheme are verified with CAs from wasp.tlsCAFile, system CAs if not set.
+	// The client certificate is required by admin endpoints of nodes with client certificates enabled
+	caFile := viper.GetString("wasp.tlsCAFile")
+	certFile := viper.GetString("wasp.tlsCertFile")
+	if caFile != "" || certFile != "" {
+		check(apilib.SetTLSConfig(caFile, certFile, viper.GetString("wasp.tlsKeyFile")))
+	}
 }
 
 func GoshimmerApiConfigVar() string {
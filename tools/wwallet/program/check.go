@@ -0,0 +1,29 @@
+package program
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/tools/wwallet/config"
+)
+
+func checkCmd(args []string) {
+	if len(args) != 2 {
+		checkUsage()
+	}
+
+	hash, err := hashing.HashValueFromBase58(args[0])
+	check(err)
+	nodes := parseIntList(args[1])
+
+	check(apilib.CheckProgramCode(config.CommitteeApi(nodes), &hash))
+	fmt.Printf("All nodes have the same code of the program %s\n", hash.String())
+}
+
+func checkUsage() {
+	fmt.Printf("Usage: %s program check <program-hash> <nodes>\n", os.Args[0])
+	fmt.Printf("Example: %s program check aBcD...wXyZ '0,1,2,3'\n", os.Args[0])
+	os.Exit(1)
+}
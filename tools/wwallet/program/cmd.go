@@ -16,6 +16,7 @@ func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
 var subcmds = map[string]func([]string){
 	"upload": uploadCmd,
 	"info":   infoCmd,
+	"check":  checkCmd,
 }
 
 func programCmd(args []string) {
@@ -1,16 +1,18 @@
 package program
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 
 	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/stateschema"
 	"github.com/iotaledger/wasp/tools/wwallet/config"
 )
 
 func uploadCmd(args []string) {
-	if len(args) != 4 {
+	if len(args) != 4 && len(args) != 5 {
 		uploadUsage()
 	}
 
@@ -19,9 +21,16 @@ func uploadCmd(args []string) {
 	vmtype := args[1]
 	description := args[2]
 	nodes := parseIntList(args[3])
+	var schema *stateschema.Schema
+	if len(args) == 5 {
+		data, err := ioutil.ReadFile(args[4])
+		check(err)
+		schema = &stateschema.Schema{}
+		check(json.Unmarshal(data, schema))
+	}
 
 	for _, host := range config.CommitteeApi(nodes) {
-		hash, err := apilib.PutProgram(host, vmtype, description, code)
+		hash, err := apilib.PutProgram(host, vmtype, description, code, schema)
 		check(err)
 
 		fmt.Printf("Program uploaded to host %s. Program hash: %s\n", host, hash.String())
@@ -29,7 +38,7 @@ func uploadCmd(args []string) {
 }
 
 func uploadUsage() {
-	fmt.Printf("Usage: %s program upload <filename> <vmtype> <description> <nodes>\n", os.Args[0])
-	fmt.Printf("Example: %s program upload program-code.bin wasm 'Example smart contract' '0,1,2,3'\n", os.Args[0])
+	fmt.Printf("Usage: %s program upload <filename> <vmtype> <description> <nodes> [<state schema file>]\n", os.Args[0])
+	fmt.Printf("Example: %s program upload program-code.bin wasm 'Example smart contract' '0,1,2,3' schema.json\n", os.Args[0])
 	os.Exit(1)
 }